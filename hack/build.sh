@@ -40,6 +40,8 @@ if [ -z "${VERSION}" ]; then
 fi
 
 export CGO_ENABLED=0
+# Velero is built from GOPATH with dep, not as a Go module.
+export GO111MODULE=off
 
 GIT_SHA=$(git rev-parse HEAD)
 GIT_DIRTY=$(git status --porcelain 2> /dev/null)
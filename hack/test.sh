@@ -19,6 +19,8 @@ set -o nounset
 set -o pipefail
 
 export CGO_ENABLED=0
+# Velero is built from GOPATH with dep, not as a Go module.
+export GO111MODULE=off
 
 TARGETS=(
   ./cmd/...
@@ -64,6 +64,54 @@ type BackupSpec struct {
 
 	// VolumeSnapshotLocations is a list containing names of VolumeSnapshotLocations associated with this backup.
 	VolumeSnapshotLocations []string `json:"volumeSnapshotLocations"`
+
+	// ItemBackupWorkers is the number of items of each resource that are backed up
+	// concurrently. If zero, the Velero server's default is used.
+	ItemBackupWorkers int `json:"itemBackupWorkers,omitempty"`
+
+	// IncludeAllAPIGroupVersions specifies whether items should be backed up in
+	// every version served for their API group, rather than only the preferred
+	// one. Each version is stored under its own directory in the backup tarball,
+	// and restores use the best version supported by the target cluster.
+	IncludeAllAPIGroupVersions bool `json:"includeAllAPIGroupVersions,omitempty"`
+
+	// Cancel specifies whether the backup should be stopped. A new backup
+	// that's canceled is never run, and one that's in progress stops backing
+	// up items as soon as possible. Either way, it ends up Canceled.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+
+	// Compression configures how the backup's tarball is compressed. If not
+	// set, the backup storage location's setting is used.
+	// +optional
+	Compression *CompressionConfig `json:"compression,omitempty"`
+}
+
+// CompressionCodec is the algorithm used to compress a backup's tarball.
+type CompressionCodec string
+
+const (
+	// CompressionCodecGzip compresses the tarball with gzip. It's the default,
+	// and is what backups that don't record a codec were compressed with.
+	CompressionCodecGzip CompressionCodec = "gzip"
+
+	// CompressionCodecNone stores the tarball uncompressed.
+	CompressionCodecNone CompressionCodec = "none"
+
+	// CompressionCodecZstd compresses the tarball with zstd.
+	CompressionCodecZstd CompressionCodec = "zstd"
+)
+
+// CompressionConfig configures the compression of backup tarballs.
+type CompressionConfig struct {
+	// Codec is the compression algorithm. If empty, gzip is used.
+	// +optional
+	Codec CompressionCodec `json:"codec,omitempty"`
+
+	// Level is the codec's compression level. If zero, the codec's default
+	// level is used.
+	// +optional
+	Level int `json:"level,omitempty"`
 }
 
 // BackupHooks contains custom behaviors that should be executed at different phases of the backup.
@@ -138,6 +186,12 @@ const (
 	// yet processed by the BackupController.
 	BackupPhaseNew BackupPhase = "New"
 
+	// BackupPhaseQueued means the backup is waiting to run, because
+	// the server or its storage location is already running as many
+	// backups as it's allowed to, or because a running backup includes
+	// some of the same namespaces.
+	BackupPhaseQueued BackupPhase = "Queued"
+
 	// BackupPhaseFailedValidation means the backup has failed
 	// the controller's validations and therefore will not run.
 	BackupPhaseFailedValidation BackupPhase = "FailedValidation"
@@ -157,6 +211,10 @@ const (
 	// prevented it from completing successfully.
 	BackupPhaseFailed BackupPhase = "Failed"
 
+	// BackupPhaseCanceled means the backup was canceled before it
+	// finished, so its contents weren't uploaded to object storage.
+	BackupPhaseCanceled BackupPhase = "Canceled"
+
 	// BackupPhaseDeleting means the backup and all its associated data are being deleted.
 	BackupPhaseDeleting BackupPhase = "Deleting"
 )
@@ -196,6 +254,13 @@ type BackupStatus struct {
 	// completed volume snapshots for this backup.
 	VolumeSnapshotsCompleted int `json:"volumeSnapshotsCompleted"`
 
+	// CSIVolumeSnapshotContents are the names of the CSI
+	// VolumeSnapshotContents that hold this backup's CSI volume
+	// snapshots. They're deleted, along with their snapshots, when the
+	// backup is deleted.
+	// +optional
+	CSIVolumeSnapshotContents []string `json:"csiVolumeSnapshotContents,omitempty"`
+
 	// Warnings is a count of all warning messages that were generated during
 	// execution of the backup. The actual warnings are in the backup's log
 	// file in object storage.
@@ -205,6 +270,35 @@ type BackupStatus struct {
 	// execution of the backup.  The actual errors are in the backup's log
 	// file in object storage.
 	Errors int `json:"errors"`
+
+	// Progress contains information about the backup's execution progress. Note
+	// that this information is best-effort only -- if Velero fails to update it
+	// during a backup for any reason, it may be inaccurate/stale.
+	Progress *BackupProgress `json:"progress,omitempty"`
+
+	// QueuePosition is the backup's position in the queue of backups that
+	// are waiting to run, starting at 1. It's only set while the backup
+	// is Queued.
+	// +optional
+	QueuePosition int `json:"queuePosition,omitempty"`
+
+	// CompressionCodec is the codec the backup's tarball was compressed with.
+	// If empty, the tarball was compressed with gzip.
+	// +optional
+	CompressionCodec CompressionCodec `json:"compressionCodec,omitempty"`
+}
+
+// BackupProgress stores information about the progress of a Backup's execution.
+type BackupProgress struct {
+	// TotalItems is the total number of items to be backed up. This number may change
+	// throughout the execution of the backup due to resources being listed as the backup
+	// proceeds, plugins returning additional related items to back up, items being
+	// excluded, etc.
+	TotalItems int `json:"totalItems,omitempty"`
+
+	// ItemsBackedUp is the number of items that have actually been written to the
+	// backup tarball so far.
+	ItemsBackedUp int `json:"itemsBackedUp,omitempty"`
 }
 
 // +genclient
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -69,6 +70,45 @@ type BackupStorageLocationSpec struct {
 
 	// AccessMode defines the permissions for the backup storage location.
 	AccessMode BackupStorageLocationAccessMode `json:"accessMode,omitempty"`
+
+	// Encryption configures client-side encryption of the objects Velero
+	// stores in the location. If not set, objects are stored unencrypted.
+	// +optional
+	Encryption *EncryptionConfig `json:"encryption,omitempty"`
+
+	// MaxConcurrentBackups is the maximum number of backups to the location
+	// that can run at the same time. If it's zero, only the server's limit
+	// applies.
+	// +optional
+	MaxConcurrentBackups int `json:"maxConcurrentBackups,omitempty"`
+
+	// Credential selects the key of a Secret in the Velero namespace that
+	// holds the credentials for the location, in the provider's credentials
+	// file format. If not set, the server's credentials are used.
+	// +optional
+	Credential *corev1api.SecretKeySelector `json:"credential,omitempty"`
+
+	// Compression configures how the tarballs of backups stored in the
+	// location are compressed, unless a backup sets its own. If not set,
+	// tarballs are compressed with gzip at its default level.
+	// +optional
+	Compression *CompressionConfig `json:"compression,omitempty"`
+}
+
+// EncryptionConfig configures client-side envelope encryption of the objects
+// stored in a backup storage location. Each object is encrypted with its own
+// randomly generated data key, which is stored with the object after being
+// encrypted with the location's key.
+type EncryptionConfig struct {
+	// KeySecret selects the key of a Secret in the Velero namespace that
+	// holds the location's 32-byte AES-256 key, either raw or base64-encoded.
+	KeySecret corev1api.SecretKeySelector `json:"keySecret"`
+
+	// AllowUnencryptedObjects allows unencrypted objects, e.g. ones stored
+	// before encryption was enabled for the location, to be read from it.
+	// If it's false, they're rejected.
+	// +optional
+	AllowUnencryptedObjects bool `json:"allowUnencryptedObjects,omitempty"`
 }
 
 // BackupStorageLocationPhase is the lifecyle phase of a Velero BackupStorageLocation.
@@ -97,10 +137,20 @@ const (
 
 // BackupStorageLocationStatus describes the current status of a Velero BackupStorageLocation.
 type BackupStorageLocationStatus struct {
+	// Phase is whether the location was available the last time it was
+	// validated.
 	Phase              BackupStorageLocationPhase `json:"phase,omitempty"`
 	LastSyncedRevision types.UID                  `json:"lastSyncedRevision,omitempty"`
 	LastSyncedTime     metav1.Time                `json:"lastSyncedTime,omitempty"`
 
+	// LastValidationTime is the last time the location was validated.
+	// +optional
+	LastValidationTime *metav1.Time `json:"lastValidationTime,omitempty"`
+
+	// Message explains why the location is unavailable.
+	// +optional
+	Message string `json:"message,omitempty"`
+
 	// AccessMode is an unused field.
 	//
 	// Deprecated: there is now an AccessMode field on the Spec and this field
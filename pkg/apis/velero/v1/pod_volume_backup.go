@@ -43,6 +43,12 @@ type PodVolumeBackupSpec struct {
 	// Tags are a map of key-value pairs that should be applied to the
 	// volume backup as tags.
 	Tags map[string]string `json:"tags"`
+
+	// Cancel specifies whether the PodVolumeBackup should be stopped, e.g.
+	// because its backup was canceled. A restic backup that's running is
+	// killed, and the PodVolumeBackup ends up Canceled.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
 }
 
 // PodVolumeBackupPhase represents the lifecycle phase of a PodVolumeBackup.
@@ -53,6 +59,7 @@ const (
 	PodVolumeBackupPhaseInProgress PodVolumeBackupPhase = "InProgress"
 	PodVolumeBackupPhaseCompleted  PodVolumeBackupPhase = "Completed"
 	PodVolumeBackupPhaseFailed     PodVolumeBackupPhase = "Failed"
+	PodVolumeBackupPhaseCanceled   PodVolumeBackupPhase = "Canceled"
 )
 
 // PodVolumeBackupStatus is the current status of a PodVolumeBackup.
@@ -38,6 +38,12 @@ type PodVolumeRestoreSpec struct {
 
 	// SnapshotID is the ID of the volume snapshot to be restored.
 	SnapshotID string `json:"snapshotID"`
+
+	// Cancel specifies whether the PodVolumeRestore should be stopped, e.g.
+	// because its restore was canceled. A restic restore that's running is
+	// killed, and the PodVolumeRestore ends up Canceled.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
 }
 
 // PodVolumeRestorePhase represents the lifecycle phase of a PodVolumeRestore.
@@ -48,6 +54,7 @@ const (
 	PodVolumeRestorePhaseInProgress PodVolumeRestorePhase = "InProgress"
 	PodVolumeRestorePhaseCompleted  PodVolumeRestorePhase = "Completed"
 	PodVolumeRestorePhaseFailed     PodVolumeRestorePhase = "Failed"
+	PodVolumeRestorePhaseCanceled   PodVolumeRestorePhase = "Canceled"
 )
 
 // PodVolumeRestoreStatus is the current status of a PodVolumeRestore.
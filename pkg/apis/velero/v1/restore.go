@@ -16,7 +16,10 @@ limitations under the License.
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // RestoreSpec defines the specification for a Velero restore.
 type RestoreSpec struct {
@@ -51,6 +54,15 @@ type RestoreSpec struct {
 	// namespaces of the same name.
 	NamespaceMapping map[string]string `json:"namespaceMapping"`
 
+	// IncludedItems is a slice of references to individual items to
+	// restore, in the form <resource>/<namespace>/<name>, or
+	// <resource>/<name> for cluster-scoped items. If specified, only these
+	// items and the additional items that restore item actions return for
+	// them (such as a pod's PersistentVolumeClaims) are restored, subject
+	// to the restore's other filters. If empty, all items are included.
+	// +optional
+	IncludedItems []string `json:"includedItems,omitempty"`
+
 	// LabelSelector is a metav1.LabelSelector to filter with
 	// when restoring individual objects from the backup. If empty
 	// or nil, all objects are included. Optional.
@@ -64,6 +76,115 @@ type RestoreSpec struct {
 	// should be included for consideration in the restore. If null, defaults
 	// to true.
 	IncludeClusterResources *bool `json:"includeClusterResources,omitempty"`
+
+	// Hooks represent custom behaviors that should be executed on restored pods.
+	Hooks RestoreHooks `json:"hooks"`
+
+	// ExistingResourcePolicy specifies what to do with items in the backup
+	// that already exist in the cluster and differ from the backed-up copy.
+	// If empty, defaults to none.
+	// +optional
+	ExistingResourcePolicy ExistingResourcePolicyType `json:"existingResourcePolicy,omitempty"`
+
+	// DryRun specifies whether the restore should only report what it would
+	// do, without making any changes to the cluster.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ResourceModifiers names a ConfigMap in the Velero namespace that holds
+	// rules for modifying items before they're restored.
+	// +optional
+	ResourceModifiers *corev1api.LocalObjectReference `json:"resourceModifiers,omitempty"`
+
+	// Cancel specifies whether the restore should be stopped. A new restore
+	// that's canceled is never run, and one that's in progress stops restoring
+	// items as soon as possible. Either way, it ends up Canceled.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+// ExistingResourcePolicyType is a string representation of what a restore
+// does with items that already exist in the cluster.
+type ExistingResourcePolicyType string
+
+const (
+	// ExistingResourcePolicyNone means existing items are left as they are,
+	// and a warning is recorded for each one that differs from the backup.
+	ExistingResourcePolicyNone ExistingResourcePolicyType = "none"
+
+	// ExistingResourcePolicyUpdate means existing items are patched to match
+	// the backup. Fields that are set in the cluster but not in the backup,
+	// e.g. because they're set by the cluster, are left as they are.
+	ExistingResourcePolicyUpdate ExistingResourcePolicyType = "update"
+
+	// ExistingResourcePolicyRecreate means existing items are deleted and
+	// created again from the backup. Namespaces, persistent volumes,
+	// persistent volume claims and custom resource definitions are updated
+	// instead, since deleting them would also delete their contents.
+	ExistingResourcePolicyRecreate ExistingResourcePolicyType = "recreate"
+)
+
+// RestoreHooks contains custom behaviors that should be executed on restored pods.
+type RestoreHooks struct {
+	// Resources are hooks that should be executed when restoring individual instances of a resource.
+	Resources []RestoreResourceHookSpec `json:"resources"`
+}
+
+// RestoreResourceHookSpec defines one or more RestoreResourceHooks that should be executed based on
+// the rules defined for namespaces, resources, and label selector.
+type RestoreResourceHookSpec struct {
+	// Name is the name of this hook.
+	Name string `json:"name"`
+	// IncludedNamespaces specifies the namespaces to which this hook spec applies. If empty, it applies
+	// to all namespaces. Namespaces are matched using their names in the backup, i.e. before any
+	// namespace mapping is applied.
+	IncludedNamespaces []string `json:"includedNamespaces"`
+	// ExcludedNamespaces specifies the namespaces to which this hook spec does not apply.
+	ExcludedNamespaces []string `json:"excludedNamespaces"`
+	// IncludedResources specifies the resources to which this hook spec applies. If empty, it applies
+	// to all resources. Hooks are currently only supported for pods.
+	IncludedResources []string `json:"includedResources"`
+	// ExcludedResources specifies the resources to which this hook spec does not apply.
+	ExcludedResources []string `json:"excludedResources"`
+	// LabelSelector, if specified, filters the resources to which this hook spec applies.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// PostHooks is a list of RestoreResourceHooks to execute during and after restoring a resource.
+	PostHooks []RestoreResourceHook `json:"postHooks,omitempty"`
+}
+
+// RestoreResourceHook defines a restore hook for a resource. Exactly one of Exec or Init
+// should be specified.
+type RestoreResourceHook struct {
+	// Exec defines an exec restore hook.
+	Exec *ExecRestoreHook `json:"exec,omitempty"`
+	// Init defines an init restore hook.
+	Init *InitRestoreHook `json:"init,omitempty"`
+}
+
+// ExecRestoreHook is a hook that uses pod exec API to execute a command inside a container in a
+// pod once the container is running.
+type ExecRestoreHook struct {
+	// Container is the container in the pod where the command should be executed. If not specified,
+	// the pod's first container is used.
+	Container string `json:"container"`
+	// Command is the command and arguments to execute from within a container after a pod has been
+	// restored.
+	Command []string `json:"command"`
+	// OnError specifies how Velero should behave if it encounters an error executing this hook.
+	OnError HookErrorMode `json:"onError"`
+	// ExecTimeout defines the maximum amount of time Velero should wait for the hook to complete
+	// before considering the execution a failure.
+	ExecTimeout metav1.Duration `json:"execTimeout"`
+	// WaitTimeout defines the maximum amount of time Velero should wait for the container to be
+	// running before attempting to run the command.
+	WaitTimeout metav1.Duration `json:"waitTimeout"`
+}
+
+// InitRestoreHook is a hook that adds init containers to restored pods, so that they run before
+// the pod's own containers are started.
+type InitRestoreHook struct {
+	// InitContainers is a list of init containers to be added to a pod during its restore.
+	InitContainers []corev1api.Container `json:"initContainers"`
 }
 
 // RestorePhase is a string representation of the lifecycle phase
@@ -93,6 +214,11 @@ const (
 	// RestorePhaseFailed means the restore was unable to execute.
 	// The failing error is recorded in status.FailureReason.
 	RestorePhaseFailed RestorePhase = "Failed"
+
+	// RestorePhaseCanceled means the restore was canceled before it
+	// finished. Items that were restored before then are left in the
+	// cluster.
+	RestorePhaseCanceled RestorePhase = "Canceled"
 )
 
 // RestoreStatus captures the current status of a Velero restore
@@ -114,6 +240,47 @@ type RestoreStatus struct {
 
 	// FailureReason is an error that caused the entire restore to fail.
 	FailureReason string `json:"failureReason"`
+
+	// StartTimestamp records the time the restore operation was started.
+	// The server's time is used for StartTimestamps
+	StartTimestamp metav1.Time `json:"startTimestamp"`
+
+	// CompletionTimestamp records the time the restore operation was completed.
+	// Completion time is recorded even on failed restores.
+	// The server's time is used for CompletionTimestamps
+	CompletionTimestamp metav1.Time `json:"completionTimestamp"`
+
+	// Progress contains information about the restore's execution progress. Note
+	// that this information is best-effort only -- if Velero fails to update it
+	// during a restore for any reason, it may be inaccurate/stale.
+	Progress *RestoreProgress `json:"progress,omitempty"`
+}
+
+// RestoreProgress stores information about the progress of a Restore's execution.
+type RestoreProgress struct {
+	// TotalItems is the total number of items to be restored. This number may change
+	// throughout the execution of the restore due to items being filtered out by the
+	// restore's label selector.
+	TotalItems int `json:"totalItems,omitempty"`
+
+	// ItemsRestored is the number of items that have been processed so far,
+	// whether or not they were successfully created in the cluster.
+	ItemsRestored int `json:"itemsRestored,omitempty"`
+
+	// Resources contains the progress of the restore broken down by
+	// group-resource (e.g. "pods", "deployments.apps").
+	Resources map[string]RestoreResourceProgress `json:"resources,omitempty"`
+}
+
+// RestoreResourceProgress stores information about the progress of restoring
+// a single group-resource.
+type RestoreResourceProgress struct {
+	// TotalItems is the total number of items of the resource to be restored.
+	TotalItems int `json:"totalItems,omitempty"`
+
+	// ItemsRestored is the number of items of the resource that have been
+	// processed so far.
+	ItemsRestored int `json:"itemsRestored,omitempty"`
 }
 
 // +genclient
@@ -27,6 +27,40 @@ type ScheduleSpec struct {
 	// Schedule is a Cron expression defining when to run
 	// the Backup.
 	Schedule string `json:"schedule"`
+
+	// Paused specifies whether the schedule is paused. A paused
+	// schedule doesn't trigger any Backups until it's unpaused.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Retention specifies which of the Backups created by the
+	// schedule to keep. Backups that the policy doesn't keep are
+	// deleted even if their TTL hasn't expired yet.
+	// +optional
+	Retention *ScheduleRetentionPolicy `json:"retention,omitempty"`
+}
+
+// ScheduleRetentionPolicy specifies which of a schedule's completed
+// Backups to keep. A Backup is kept if any of the policy's rules keeps
+// it; rules that are zero keep nothing. Daily, weekly and monthly
+// rules keep the most recent Backup of each of the most recent days,
+// ISO weeks or months, in UTC, that have a Backup.
+type ScheduleRetentionPolicy struct {
+	// KeepLast is the number of most recent Backups to keep.
+	// +optional
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepDaily is the number of days to keep a Backup for.
+	// +optional
+	KeepDaily int `json:"keepDaily,omitempty"`
+
+	// KeepWeekly is the number of weeks to keep a Backup for.
+	// +optional
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly is the number of months to keep a Backup for.
+	// +optional
+	KeepMonthly int `json:"keepMonthly,omitempty"`
 }
 
 // SchedulePhase is a string representation of the lifecycle phase
@@ -59,6 +93,17 @@ type ScheduleStatus struct {
 	// ValidationErrors is a slice of all validation errors (if
 	// applicable)
 	ValidationErrors []string `json:"validationErrors"`
+
+	// PausedAt is when the schedule was paused, if it's currently
+	// paused.
+	// +optional
+	PausedAt *metav1.Time `json:"pausedAt,omitempty"`
+
+	// PausedBy is the kubeconfig user that the client which paused the
+	// schedule reported, if it's currently paused and this is known. It's
+	// supplied by the client and isn't verified, so it's informational only.
+	// +optional
+	PausedBy string `json:"pausedBy,omitempty"`
 }
 
 // +genclient
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// VerifyBackupRequestSpec is the specification for which backup to verify.
+type VerifyBackupRequestSpec struct {
+	BackupName string `json:"backupName"`
+}
+
+// VerifyBackupRequestPhase represents the lifecycle phase of a VerifyBackupRequest.
+type VerifyBackupRequestPhase string
+
+const (
+	// VerifyBackupRequestPhaseNew means the VerifyBackupRequest has not been processed yet.
+	VerifyBackupRequestPhaseNew VerifyBackupRequestPhase = "New"
+	// VerifyBackupRequestPhaseInProgress means the VerifyBackupRequest is being processed.
+	VerifyBackupRequestPhaseInProgress VerifyBackupRequestPhase = "InProgress"
+	// VerifyBackupRequestPhaseProcessed means the VerifyBackupRequest has been processed.
+	VerifyBackupRequestPhaseProcessed VerifyBackupRequestPhase = "Processed"
+)
+
+// VerifyBackupRequestStatus is the current status of a VerifyBackupRequest.
+type VerifyBackupRequestStatus struct {
+	// Phase is the current state of the VerifyBackupRequest.
+	Phase VerifyBackupRequestPhase `json:"phase"`
+
+	// ProcessedTimestamp is when the VerifyBackupRequest was processed.
+	ProcessedTimestamp metav1.Time `json:"processedTimestamp"`
+
+	// VerifiedFiles lists the backup's files in backup storage whose
+	// checksums matched the backup's checksum manifest.
+	VerifiedFiles []string `json:"verifiedFiles"`
+
+	// VerifiedResticSnapshots lists the IDs of the backup's restic
+	// snapshots that were found in their restic repositories.
+	VerifiedResticSnapshots []string `json:"verifiedResticSnapshots"`
+
+	// Errors contains any problems that were found with the backup.
+	Errors []string `json:"errors"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerifyBackupRequest is a request to check that all of a backup's data
+// is present and intact, without restoring it.
+type VerifyBackupRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   VerifyBackupRequestSpec   `json:"spec"`
+	Status VerifyBackupRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerifyBackupRequestList is a list of VerifyBackupRequests.
+type VerifyBackupRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VerifyBackupRequest `json:"items"`
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -46,6 +49,12 @@ type VolumeSnapshotLocationSpec struct {
 
 	// Config is for provider-specific configuration fields.
 	Config map[string]string `json:"config"`
+
+	// Credential selects the key of a Secret in the Velero namespace that
+	// holds the credentials for the location, in the provider's credentials
+	// file format. If not set, the server's credentials are used.
+	// +optional
+	Credential *corev1api.SecretKeySelector `json:"credential,omitempty"`
 }
 
 // VolumeSnapshotLocationPhase is the lifecyle phase of a Velero VolumeSnapshotLocation.
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Codec is the algorithm a tarball is compressed with.
+type Codec string
+
+const (
+	// CodecGzip compresses tarballs with gzip. Tarballs that don't record
+	// a codec were compressed with it.
+	CodecGzip Codec = "gzip"
+
+	// CodecNone leaves tarballs uncompressed.
+	CodecNone Codec = "none"
+
+	// CodecZstd compresses tarballs with zstd.
+	CodecZstd Codec = "zstd"
+)
+
+const (
+	// zstdMinLevel and zstdMaxLevel are the range of zstd's standard
+	// compression levels. They're mapped to the nearest level the encoder
+	// supports.
+	zstdMinLevel = 1
+	zstdMaxLevel = 22
+)
+
+// ValidateCompression returns an error if codec isn't supported, or if level
+// isn't a valid compression level for it. An empty codec is gzip, and a zero
+// level is the codec's default level.
+func ValidateCompression(codec Codec, level int) error {
+	switch codec {
+	case "", CodecGzip:
+		if level != 0 && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+			return errors.Errorf("invalid compression level %d for codec %s: must be between %d and %d", level, CodecGzip, gzip.BestSpeed, gzip.BestCompression)
+		}
+	case CodecNone:
+		if level != 0 {
+			return errors.Errorf("invalid compression level %d for codec %s: must not be set", level, CodecNone)
+		}
+	case CodecZstd:
+		if level != 0 && (level < zstdMinLevel || level > zstdMaxLevel) {
+			return errors.Errorf("invalid compression level %d for codec %s: must be between %d and %d", level, CodecZstd, zstdMinLevel, zstdMaxLevel)
+		}
+	default:
+		return errors.Errorf("unsupported compression codec %q: must be one of %s, %s, %s", codec, CodecGzip, CodecNone, CodecZstd)
+	}
+
+	return nil
+}
+
+// NewDecompressingReader returns a reader that decompresses the data read
+// from r, which was compressed with codec. An empty codec is gzip.
+func NewDecompressingReader(r io.Reader, codec Codec) (io.ReadCloser, error) {
+	switch codec {
+	case "", CodecGzip:
+		gzr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return gzr, nil
+	case CodecNone:
+		return ioutil.NopCloser(r), nil
+	case CodecZstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, errors.Errorf("unsupported compression codec %q", codec)
+	}
+}
+
+// compressor compresses the data written to it, and can be reset to start
+// compressing a new stream.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+func newCompressor(w io.Writer, codec Codec, level int) (compressor, error) {
+	if err := ValidateCompression(codec, level); err != nil {
+		return nil, err
+	}
+
+	switch codec {
+	case CodecNone:
+		return &nopCompressor{w: w}, nil
+	case CodecZstd:
+		encoderLevel := zstd.SpeedDefault
+		if level != 0 {
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(encoderLevel))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return zw, nil
+	}
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gzw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return gzw, nil
+}
+
+// nopCompressor writes data through uncompressed.
+type nopCompressor struct {
+	w io.Writer
+}
+
+func (c *nopCompressor) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+func (c *nopCompressor) Close() error {
+	return nil
+}
+
+func (c *nopCompressor) Reset(w io.Writer) {
+	c.w = w
+}
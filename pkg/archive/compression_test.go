@@ -0,0 +1,79 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCompression(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   Codec
+		level   int
+		wantErr bool
+	}{
+		{name: "empty codec is gzip", codec: "", level: 0},
+		{name: "gzip with default level", codec: CodecGzip, level: 0},
+		{name: "gzip with best compression", codec: CodecGzip, level: 9},
+		{name: "gzip with level too high", codec: CodecGzip, level: 10, wantErr: true},
+		{name: "gzip with negative level", codec: CodecGzip, level: -1, wantErr: true},
+		{name: "none", codec: CodecNone, level: 0},
+		{name: "none with a level", codec: CodecNone, level: 1, wantErr: true},
+		{name: "zstd with default level", codec: CodecZstd, level: 0},
+		{name: "zstd with best compression", codec: CodecZstd, level: 22},
+		{name: "zstd with level too high", codec: CodecZstd, level: 23, wantErr: true},
+		{name: "zstd with negative level", codec: CodecZstd, level: -1, wantErr: true},
+		{name: "unsupported codec", codec: "lz4", level: 0, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCompression(tc.codec, tc.level)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewDecompressingReader(t *testing.T) {
+	for _, codec := range []Codec{"", CodecGzip, CodecNone, CodecZstd} {
+		buf := new(bytes.Buffer)
+		c, err := newCompressor(buf, codec, 0)
+		require.NoError(t, err)
+		_, err = c.Write([]byte("some data"))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+
+		r, err := NewDecompressingReader(buf, codec)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "some data", string(data), "codec %q", codec)
+	}
+
+	_, err := NewDecompressingReader(new(bytes.Buffer), "lz4")
+	assert.Error(t, err)
+}
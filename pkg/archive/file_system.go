@@ -0,0 +1,246 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/heptio/velero/pkg/util/filesystem"
+)
+
+// fileSystem is a read-only filesystem.Interface that serves the files in an
+// indexed tarball. Files are read from the tarball as they're needed, by
+// decompressing the block they're in, so the tarball is never extracted.
+type fileSystem struct {
+	source io.ReaderAt
+	codec  Codec
+	blocks []Block
+	files  map[string]*File
+	// dirs maps each directory to the names of its entries. Tarballs don't
+	// necessarily have entries for directories, so they're inferred from the
+	// paths of the files in them.
+	dirs map[string][]string
+
+	// the most recently read block's uncompressed data is cached, since
+	// files are usually read in the order they're in the tarball.
+	lock        sync.Mutex
+	cachedBlock int
+	cachedData  []byte
+}
+
+// NewFileSystem returns a read-only filesystem.Interface serving the files in
+// the tarball indexed by index, reading from the tarball with source. Paths
+// are relative to the root of the tarball; a leading "/" is ignored.
+func NewFileSystem(index *Index, source io.ReaderAt) filesystem.Interface {
+	fs := &fileSystem{
+		source:      source,
+		codec:       index.Codec,
+		blocks:      index.Blocks,
+		files:       make(map[string]*File),
+		dirs:        map[string][]string{".": nil},
+		cachedBlock: -1,
+	}
+
+	children := map[string]map[string]struct{}{".": {}}
+	for i := range index.Files {
+		file := &index.Files[i]
+		name := cleanPath(file.Name)
+		fs.files[name] = file
+
+		// add the file to its directory, and each directory to its parent
+		for child, dir := name, path.Dir(name); ; child, dir = dir, path.Dir(dir) {
+			if _, ok := children[dir]; !ok {
+				children[dir] = make(map[string]struct{})
+			}
+			children[dir][path.Base(child)] = struct{}{}
+
+			if dir == "." {
+				break
+			}
+		}
+	}
+
+	for dir, entries := range children {
+		for entry := range entries {
+			fs.dirs[dir] = append(fs.dirs[dir], entry)
+		}
+		sort.Strings(fs.dirs[dir])
+	}
+
+	return fs
+}
+
+func cleanPath(name string) string {
+	return path.Clean(strings.TrimPrefix(path.Clean("/"+name), "/"))
+}
+
+func (fs *fileSystem) ReadFile(filename string) ([]byte, error) {
+	file, ok := fs.files[cleanPath(filename)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+	}
+
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	data, err := fs.readBlock(file.Block)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s from backup tarball", filename)
+	}
+	if file.Offset+file.Size > int64(len(data)) {
+		return nil, errors.Errorf("error reading %s from backup tarball: its block is too short", filename)
+	}
+
+	contents := make([]byte, file.Size)
+	copy(contents, data[file.Offset:])
+	return contents, nil
+}
+
+// readBlock returns the uncompressed data of the block at the given index,
+// after checking its compressed data against the digest in the index.
+func (fs *fileSystem) readBlock(index int) ([]byte, error) {
+	if index == fs.cachedBlock {
+		return fs.cachedData, nil
+	}
+	if index < 0 || index >= len(fs.blocks) {
+		return nil, errors.Errorf("block %d is not in the index", index)
+	}
+	block := fs.blocks[index]
+
+	compressed := make([]byte, block.Length)
+	if _, err := fs.source.ReadAt(compressed, block.Offset); err != nil && err != io.EOF {
+		return nil, errors.WithStack(err)
+	}
+
+	digest := sha256.Sum256(compressed)
+	if hex.EncodeToString(digest[:]) != block.Digest {
+		return nil, errors.Errorf("checksum mismatch for block %d", index)
+	}
+
+	r, err := NewDecompressingReader(bytes.NewReader(compressed), fs.codec)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fs.cachedBlock = index
+	fs.cachedData = data
+
+	return data, nil
+}
+
+func (fs *fileSystem) ReadDir(dirname string) ([]os.FileInfo, error) {
+	entries, ok := fs.dirs[cleanPath(dirname)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: dirname, Err: os.ErrNotExist}
+	}
+
+	var infos []os.FileInfo
+	for _, entry := range entries {
+		info, err := fs.Stat(path.Join(cleanPath(dirname), entry))
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+func (fs *fileSystem) DirExists(dirname string) (bool, error) {
+	_, ok := fs.dirs[cleanPath(dirname)]
+	return ok, nil
+}
+
+func (fs *fileSystem) Stat(name string) (os.FileInfo, error) {
+	cleaned := cleanPath(name)
+
+	if file, ok := fs.files[cleaned]; ok {
+		return &fileInfo{
+			name:    path.Base(cleaned),
+			size:    file.Size,
+			mode:    os.FileMode(file.Mode).Perm(),
+			modTime: file.ModTime,
+		}, nil
+	}
+
+	if _, ok := fs.dirs[cleaned]; ok {
+		return &fileInfo{
+			name: path.Base(cleaned),
+			mode: os.ModeDir | 0755,
+		}, nil
+	}
+
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+var errReadOnly = errors.New("backup tarball file system is read-only")
+
+func (fs *fileSystem) TempDir(dir, prefix string) (string, error) {
+	return "", errReadOnly
+}
+
+func (fs *fileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return errReadOnly
+}
+
+func (fs *fileSystem) Create(name string) (io.WriteCloser, error) {
+	return nil, errReadOnly
+}
+
+func (fs *fileSystem) RemoveAll(path string) error {
+	return errReadOnly
+}
+
+func (fs *fileSystem) TempFile(dir, prefix string) (filesystem.NameWriteCloser, error) {
+	return nil, errReadOnly
+}
+
+func (fs *fileSystem) Rename(oldpath, newpath string) error {
+	return errReadOnly
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() os.FileMode  { return i.mode }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fileInfo) Sys() interface{}   { return nil }
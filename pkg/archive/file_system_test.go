@@ -0,0 +1,125 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystem(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, "", files, names)
+
+	fs := NewFileSystem(index, bytes.NewReader(tarball))
+
+	// files can be read in any order
+	for i := len(names) - 1; i >= 0; i-- {
+		data, err := fs.ReadFile(names[i])
+		require.NoError(t, err)
+		assert.Equal(t, files[names[i]], string(data))
+	}
+
+	data, err := fs.ReadFile("/metadata/version")
+	require.NoError(t, err)
+	assert.Equal(t, "1\n", string(data))
+
+	_, err = fs.ReadFile("resources/pods/namespaces/ns-0/missing.json")
+	assert.True(t, os.IsNotExist(err))
+
+	// directories are inferred from the files in them
+	exists, err := fs.DirExists("resources/pods/namespaces")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = fs.DirExists("resources/deployments.apps")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	infos, err := fs.ReadDir("resources/pods/namespaces")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "ns-0", infos[0].Name())
+	assert.True(t, infos[0].IsDir())
+	assert.Equal(t, "ns-1", infos[1].Name())
+
+	infos, err = fs.ReadDir("resources/pods/namespaces/ns-1")
+	require.NoError(t, err)
+	require.Len(t, infos, 10)
+	assert.Equal(t, "pod-1.json", infos[0].Name())
+	assert.False(t, infos[0].IsDir())
+	assert.Equal(t, int64(len(files["resources/pods/namespaces/ns-1/pod-1.json"])), infos[0].Size())
+
+	infos, err = fs.ReadDir("/")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "metadata", infos[0].Name())
+	assert.Equal(t, "resources", infos[1].Name())
+
+	_, err = fs.Stat("resources/pods/namespaces/ns-2")
+	assert.True(t, os.IsNotExist(err))
+
+	// the file system is read-only
+	assert.Error(t, fs.MkdirAll("resources", 0755))
+}
+
+func TestFileSystemDetectsModifiedBlocks(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, "", files, names)
+
+	last := index.Blocks[len(index.Blocks)-1]
+	tarball[last.Offset+last.Length/2] ^= 0xff
+
+	fs := NewFileSystem(index, bytes.NewReader(tarball))
+
+	_, err := fs.ReadFile(names[0])
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(names[len(names)-1])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestFileSystemWithoutCompression(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, CodecNone, files, names)
+
+	fs := NewFileSystem(index, bytes.NewReader(tarball))
+
+	for i := len(names) - 1; i >= 0; i-- {
+		data, err := fs.ReadFile(names[i])
+		require.NoError(t, err)
+		assert.Equal(t, files[names[i]], string(data))
+	}
+}
+
+func TestFileSystemWithZstd(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, CodecZstd, files, names)
+
+	fs := NewFileSystem(index, bytes.NewReader(tarball))
+
+	for i := len(names) - 1; i >= 0; i-- {
+		data, err := fs.ReadFile(names[i])
+		require.NoError(t, err)
+		assert.Equal(t, files[names[i]], string(data))
+	}
+}
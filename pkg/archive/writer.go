@@ -0,0 +1,216 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBlockSize is roughly how much uncompressed data is written to each
+// block of an indexed tarball. Larger blocks compress slightly better, while
+// smaller ones mean less data is read to get any one file.
+const DefaultBlockSize = 1024 * 1024
+
+// Index records where each file in a tarball written by a Writer is, so
+// that files can be read from the tarball without reading all of it.
+type Index struct {
+	// Codec is what the tarball's blocks are compressed with. If empty,
+	// they're compressed with gzip.
+	Codec Codec `json:"codec,omitempty"`
+	// Blocks are the independently-compressed blocks that make up the
+	// tarball, in order.
+	Blocks []Block `json:"blocks"`
+	// Files are the regular files in the tarball, in order.
+	Files []File `json:"files"`
+}
+
+// Block is an independently-compressed part of an indexed tarball.
+type Block struct {
+	// Offset is where the block's compressed data starts in the tarball.
+	Offset int64 `json:"offset"`
+	// Length is the length of the block's compressed data.
+	Length int64 `json:"length"`
+	// Digest is the hex-encoded SHA-256 digest of the block's compressed
+	// data.
+	Digest string `json:"digest"`
+}
+
+// File is a regular file in an indexed tarball.
+type File struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Mode    int64     `json:"mode"`
+	ModTime time.Time `json:"modTime"`
+	// Block is the index of the block that the file is in.
+	Block int `json:"block"`
+	// Offset is where the file's data starts in its block's uncompressed
+	// data.
+	Offset int64 `json:"offset"`
+}
+
+// Writer writes a compressed tarball, and indexes the files written to it.
+// The tarball is made up of blocks, each of which is compressed separately
+// and starts at a file's header. With gzip, each block is a separate gzip
+// member, so it's an ordinary .tar.gz file that can be read from start to
+// finish, but any one file can also be read by decompressing just the block
+// it's in. The same goes for zstd, where each block is a separate zstd frame.
+type Writer struct {
+	tw        *tar.Writer
+	out       *blockOutput
+	cw        compressor
+	blockSize int64
+	// blockBytes is the amount of uncompressed data written to the current
+	// block.
+	blockBytes int64
+	index      Index
+}
+
+// NewWriter returns a Writer that writes an indexed tarball to w, compressed
+// with codec at the given level. An empty codec is gzip, and a zero level is
+// the codec's default level.
+func NewWriter(w io.Writer, codec Codec, level int) (*Writer, error) {
+	return newWriter(w, DefaultBlockSize, codec, level)
+}
+
+func newWriter(w io.Writer, blockSize int64, codec Codec, level int) (*Writer, error) {
+	if codec == "" {
+		codec = CodecGzip
+	}
+
+	aw := &Writer{
+		out:       &blockOutput{w: w, hash: sha256.New()},
+		blockSize: blockSize,
+		index:     Index{Codec: codec},
+	}
+
+	cw, err := newCompressor(aw.out, codec, level)
+	if err != nil {
+		return nil, err
+	}
+	aw.cw = cw
+	aw.tw = tar.NewWriter(blockInput{aw})
+
+	return aw, nil
+}
+
+// WriteHeader writes hdr and prepares to accept the file's contents, as
+// tar.Writer.WriteHeader does. If the current block is full, a new one is
+// started first.
+func (w *Writer) WriteHeader(hdr *tar.Header) error {
+	if w.blockBytes >= w.blockSize {
+		// finish padding the previous file so it's entirely in the
+		// current block
+		if err := w.tw.Flush(); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := w.finishBlock(); err != nil {
+			return err
+		}
+		w.cw.Reset(w.out)
+	}
+
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA {
+		w.index.Files = append(w.index.Files, File{
+			Name:    hdr.Name,
+			Size:    hdr.Size,
+			Mode:    hdr.Mode,
+			ModTime: hdr.ModTime,
+			Block:   len(w.index.Blocks),
+			Offset:  w.blockBytes,
+		})
+	}
+
+	return nil
+}
+
+// Write writes to the current file in the tarball.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.tw.Write(p)
+}
+
+// Close finishes the tarball and its last block. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return w.finishBlock()
+}
+
+// Index returns the tarball's index. It's only complete once the Writer has
+// been closed.
+func (w *Writer) Index() *Index {
+	return &w.index
+}
+
+func (w *Writer) finishBlock() error {
+	if err := w.cw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	w.index.Blocks = append(w.index.Blocks, Block{
+		Offset: w.out.blockStart,
+		Length: w.out.n - w.out.blockStart,
+		Digest: hex.EncodeToString(w.out.hash.Sum(nil)),
+	})
+
+	w.out.blockStart = w.out.n
+	w.out.hash.Reset()
+	w.blockBytes = 0
+
+	return nil
+}
+
+// blockInput is what the tar writer writes to; it compresses the data into
+// the current block.
+type blockInput struct {
+	w *Writer
+}
+
+func (i blockInput) Write(p []byte) (int, error) {
+	n, err := i.w.cw.Write(p)
+	i.w.blockBytes += int64(n)
+	return n, err
+}
+
+// blockOutput is what the current block's compressed data is written to; it
+// keeps track of where blocks start, and the current block's digest.
+type blockOutput struct {
+	w          io.Writer
+	n          int64
+	blockStart int64
+	hash       hash.Hash
+}
+
+func (o *blockOutput) Write(p []byte) (int, error) {
+	n, err := o.w.Write(p)
+	o.n += int64(n)
+	o.hash.Write(p[:n])
+	return n, err
+}
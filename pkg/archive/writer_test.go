@@ -0,0 +1,155 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTarball writes files to an indexed tarball with the given block size
+// and codec, and returns the tarball and its index.
+func writeTarball(t *testing.T, blockSize int64, codec Codec, files map[string]string, names []string) ([]byte, *Index) {
+	buf := new(bytes.Buffer)
+	w, err := newWriter(buf, blockSize, codec, 0)
+	require.NoError(t, err)
+
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:     name,
+			Size:     int64(len(files[name])),
+			Typeflag: tar.TypeReg,
+			Mode:     0755,
+			ModTime:  time.Now(),
+		}
+		require.NoError(t, w.WriteHeader(hdr))
+		_, err := w.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes(), w.Index()
+}
+
+func testFiles() (map[string]string, []string) {
+	files := map[string]string{
+		"metadata/version": "1\n",
+	}
+	names := []string{"metadata/version"}
+
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("resources/pods/namespaces/ns-%d/pod-%d.json", i%2, i)
+		files[name] = fmt.Sprintf(`{"kind":"Pod","metadata":{"name":"pod-%d"}}`, i)
+		names = append(names, name)
+	}
+
+	return files, names
+}
+
+func TestWriterWritesIndexedTarball(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, "", files, names)
+	assert.Equal(t, CodecGzip, index.Codec)
+
+	// the tarball is split into blocks, which together make up all of it
+	require.True(t, len(index.Blocks) > 1)
+	var offset int64
+	for _, block := range index.Blocks {
+		assert.Equal(t, offset, block.Offset)
+		offset += block.Length
+	}
+	assert.Equal(t, int64(len(tarball)), offset)
+
+	// every file is indexed
+	require.Len(t, index.Files, len(names))
+	for i, file := range index.Files {
+		assert.Equal(t, names[i], file.Name)
+		assert.Equal(t, int64(len(files[names[i]])), file.Size)
+	}
+
+	// and it's still an ordinary .tar.gz file
+	gzr, err := gzip.NewReader(bytes.NewReader(tarball))
+	require.NoError(t, err)
+	assertTarballFiles(t, gzr, files, names)
+}
+
+func TestWriterWithoutCompression(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, CodecNone, files, names)
+	assert.Equal(t, CodecNone, index.Codec)
+	require.True(t, len(index.Blocks) > 1)
+
+	// the blocks together are an ordinary, uncompressed tar file
+	assertTarballFiles(t, bytes.NewReader(tarball), files, names)
+}
+
+func TestWriterWithZstd(t *testing.T) {
+	files, names := testFiles()
+	tarball, index := writeTarball(t, 1024, CodecZstd, files, names)
+	assert.Equal(t, CodecZstd, index.Codec)
+	require.True(t, len(index.Blocks) > 1)
+
+	// each block is a separate zstd frame, so the blocks together can be
+	// decompressed as a single stream
+	r, err := NewDecompressingReader(bytes.NewReader(tarball), CodecZstd)
+	require.NoError(t, err)
+	defer r.Close()
+	assertTarballFiles(t, r, files, names)
+}
+
+func TestNewWriterRejectsInvalidCompression(t *testing.T) {
+	_, err := NewWriter(new(bytes.Buffer), "lz4", 0)
+	assert.Error(t, err)
+
+	_, err = NewWriter(new(bytes.Buffer), CodecGzip, 10)
+	assert.Error(t, err)
+
+	_, err = NewWriter(new(bytes.Buffer), CodecZstd, 23)
+	assert.Error(t, err)
+}
+
+// assertTarballFiles asserts that the tar file read from r has exactly the
+// given files, in order.
+func assertTarballFiles(t *testing.T, r io.Reader, files map[string]string, names []string) {
+	t.Helper()
+
+	tr := tar.NewReader(r)
+
+	var read []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		assert.Equal(t, files[hdr.Name], string(data))
+		read = append(read, hdr.Name)
+	}
+	assert.Equal(t, names, read)
+}
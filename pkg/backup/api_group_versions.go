@@ -0,0 +1,144 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// itemFilePath returns the path within the backup tarball of the item with the
+// provided group/resource, namespace and name. If version is not empty, the item
+// is stored under a directory for that API version of the resource.
+func itemFilePath(groupResource schema.GroupResource, version, namespace, name string) string {
+	resourceDir := filepath.Join(api.ResourcesDir, groupResource.String(), version)
+
+	if namespace != "" {
+		return filepath.Join(resourceDir, api.NamespaceScopedDir, namespace, name+".json")
+	}
+	return filepath.Join(resourceDir, api.ClusterScopedDir, name+".json")
+}
+
+// backupNonPreferredVersions writes every item that's been backed up to the tarball
+// again in each of the other versions served for its API group. The items are stored
+// as returned by the API server; hooks and item actions only run for the preferred
+// version.
+func (kb *kubernetesBackupper) backupNonPreferredVersions(log logrus.FieldLogger, backupRequest *Request, tarWriter *itemTarWriter) {
+	preferredVersions := make(map[schema.GroupResource]schema.GroupVersion)
+	for _, resourceList := range kb.discoveryHelper.Resources() {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			log.WithError(errors.WithStack(err)).Errorf("Error parsing GroupVersion %s", resourceList.GroupVersion)
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			preferredVersions[gv.WithResource(resource.Name).GroupResource()] = gv
+		}
+	}
+
+	for _, resourceList := range kb.discoveryHelper.ServedResources() {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			log.WithError(errors.WithStack(err)).Errorf("Error parsing GroupVersion %s", resourceList.GroupVersion)
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			gr := gv.WithResource(resource.Name).GroupResource()
+
+			preferredGV, ok := preferredVersions[gr]
+			if !ok || preferredGV == gv {
+				continue
+			}
+
+			log := log.WithFields(logrus.Fields{
+				"resource": gr.String(),
+				"version":  gv.Version,
+			})
+
+			resourceClient, err := kb.dynamicFactory.ClientForGroupVersionResource(gv, resource, "")
+			if err != nil {
+				log.WithError(err).Error("Error getting dynamic client")
+				continue
+			}
+
+			log.Info("Backing up items in non-preferred version")
+			err = listItems(log, resourceClient, "", backupRequest.listPageSize, func(items []runtime.Object) {
+				for _, item := range items {
+					if err := backupNonPreferredVersionItem(backupRequest, tarWriter, item, gr, preferredGV); err != nil {
+						log.WithError(err).Error("Error backing up item in non-preferred version")
+					}
+				}
+			})
+			if err != nil {
+				log.WithError(err).Error("Error listing items")
+			}
+		}
+	}
+}
+
+// backupNonPreferredVersionItem writes item, which is in a non-preferred version of
+// the resource, to the tarball if it was backed up in the preferred version.
+func backupNonPreferredVersionItem(backupRequest *Request, tarWriter *itemTarWriter, item runtime.Object, groupResource schema.GroupResource, preferredGV schema.GroupVersion) error {
+	unstructured, ok := item.(runtime.Unstructured)
+	if !ok {
+		return errors.Errorf("unexpected type %T", item)
+	}
+
+	metadata, err := meta.Accessor(unstructured)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	gvk := unstructured.GetObjectKind().GroupVersionKind()
+
+	key := itemKey{
+		resource:  fmt.Sprintf("%s/%s", preferredGV.String(), gvk.Kind),
+		namespace: metadata.GetNamespace(),
+		name:      metadata.GetName(),
+	}
+	if !backupRequest.hasBackedUpItem(key) {
+		return nil
+	}
+
+	itemBytes, err := json.Marshal(unstructured.UnstructuredContent())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	hdr := &tar.Header{
+		Name:     itemFilePath(groupResource, gvk.Version, metadata.GetNamespace(), metadata.GetName()),
+		Size:     int64(len(itemBytes)),
+		Typeflag: tar.TypeReg,
+		Mode:     0755,
+		ModTime:  time.Now(),
+	}
+
+	return tarWriter.writeItem(hdr, itemBytes)
+}
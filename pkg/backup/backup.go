@@ -18,11 +18,11 @@ package backup
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -32,32 +32,45 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/credentials"
 	"github.com/heptio/velero/pkg/discovery"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	"github.com/heptio/velero/pkg/plugin/velero"
 	"github.com/heptio/velero/pkg/podexec"
 	"github.com/heptio/velero/pkg/restic"
 	"github.com/heptio/velero/pkg/util/collections"
 )
 
-// BackupVersion is the current backup version for Velero.
-const BackupVersion = 1
+// BackupVersion is the current backup version for Velero. Version 2 backups may
+// store each API group version of a resource in its own directory under the
+// resource's directory.
+const BackupVersion = 2
+
+// progressUpdateInterval is how often a backup's status.progress is updated while
+// the backup is running.
+const progressUpdateInterval = time.Second
 
 // Backupper performs backups.
 type Backupper interface {
 	// Backup takes a backup using the specification in the api.Backup and writes backup and log data
-	// to the given writers.
-	Backup(logger logrus.FieldLogger, backup *Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter VolumeSnapshotterGetter) error
+	// to the given writers. If ctx is canceled, no more items are backed up and an error is returned.
+	Backup(ctx context.Context, logger logrus.FieldLogger, backup *Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter VolumeSnapshotterGetter) error
 }
 
 // kubernetesBackupper implements Backupper.
 type kubernetesBackupper struct {
+	backupClient           velerov1client.BackupsGetter
 	dynamicFactory         client.DynamicFactory
 	discoveryHelper        discovery.Helper
 	podCommandExecutor     podexec.PodCommandExecutor
 	groupBackupperFactory  groupBackupperFactory
 	resticBackupperFactory restic.BackupperFactory
 	resticTimeout          time.Duration
+	itemBackupWorkers      int
+	clientPageSize         int
+	credentialFileStore    credentials.FileStore
 }
 
 type resolvedAction struct {
@@ -84,19 +97,27 @@ func cohabitatingResources() map[string]*cohabitatingResource {
 
 // NewKubernetesBackupper creates a new kubernetesBackupper.
 func NewKubernetesBackupper(
+	backupClient velerov1client.BackupsGetter,
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
 	podCommandExecutor podexec.PodCommandExecutor,
 	resticBackupperFactory restic.BackupperFactory,
 	resticTimeout time.Duration,
+	itemBackupWorkers int,
+	clientPageSize int,
+	credentialFileStore credentials.FileStore,
 ) (Backupper, error) {
 	return &kubernetesBackupper{
+		backupClient:           backupClient,
 		discoveryHelper:        discoveryHelper,
 		dynamicFactory:         dynamicFactory,
 		podCommandExecutor:     podCommandExecutor,
 		groupBackupperFactory:  &defaultGroupBackupperFactory{},
 		resticBackupperFactory: resticBackupperFactory,
 		resticTimeout:          resticTimeout,
+		itemBackupWorkers:      itemBackupWorkers,
+		clientPageSize:         clientPageSize,
+		credentialFileStore:    credentialFileStore,
 	}, nil
 }
 
@@ -198,17 +219,22 @@ type VolumeSnapshotterGetter interface {
 	GetVolumeSnapshotter(name string) (velero.VolumeSnapshotter, error)
 }
 
-// Backup backs up the items specified in the Backup, placing them in a gzip-compressed tar file
-// written to backupFile. The finalized api.Backup is written to metadata. Any error that represents
-// a complete backup failure is returned. Errors that constitute partial failures (i.e. failures to
-// back up individual resources that don't prevent the backup from continuing to be processed) are logged
-// to the backup log.
-func (kb *kubernetesBackupper) Backup(log logrus.FieldLogger, backupRequest *Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter VolumeSnapshotterGetter) error {
-	gzippedData := gzip.NewWriter(backupFile)
-	defer gzippedData.Close()
-
-	tw := tar.NewWriter(gzippedData)
-	defer tw.Close()
+// Backup backs up the items specified in the Backup, placing them in a tar file compressed as
+// specified by backupRequest.Compression and written to backupFile, and records the tarball's
+// index in backupRequest. The finalized api.Backup is written to metadata. Any error that
+// represents a complete backup failure is returned. Errors that constitute partial failures
+// (i.e. failures to back up individual resources that don't prevent the backup from continuing
+// to be processed) are logged to the backup log. If ctx is canceled, no more items are backed
+// up, and an error wrapping ctx's error is returned.
+func (kb *kubernetesBackupper) Backup(ctx context.Context, log logrus.FieldLogger, backupRequest *Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter VolumeSnapshotterGetter) error {
+	tw, err := archive.NewWriter(backupFile, archive.Codec(backupRequest.Compression.Codec), backupRequest.Compression.Level)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tw.Close()
+		backupRequest.TarballIndex = tw.Index()
+	}()
 
 	log.Info("Writing backup version file")
 	if err := kb.writeBackupVersion(tw); err != nil {
@@ -223,7 +249,6 @@ func (kb *kubernetesBackupper) Backup(log logrus.FieldLogger, backupRequest *Req
 	log.Infof("Including resources: %s", backupRequest.ResourceIncludesExcludes.IncludesString())
 	log.Infof("Excluding resources: %s", backupRequest.ResourceIncludesExcludes.ExcludesString())
 
-	var err error
 	backupRequest.ResourceHooks, err = getResourceHooks(backupRequest.Spec.Hooks.Resources, kb.discoveryHelper)
 	if err != nil {
 		return err
@@ -236,6 +261,41 @@ func (kb *kubernetesBackupper) Backup(log logrus.FieldLogger, backupRequest *Req
 
 	backupRequest.BackedUpItems = map[itemKey]struct{}{}
 
+	backupRequest.itemBackupWorkers = kb.itemBackupWorkers
+	if backupRequest.Spec.ItemBackupWorkers > 0 {
+		backupRequest.itemBackupWorkers = backupRequest.Spec.ItemBackupWorkers
+	}
+	if backupRequest.itemBackupWorkers < 1 {
+		backupRequest.itemBackupWorkers = 1
+	}
+	log.Infof("Backing up items using %d worker(s) per resource", backupRequest.itemBackupWorkers)
+
+	backupRequest.listPageSize = kb.clientPageSize
+	backupRequest.credentialFileStore = kb.credentialFileStore
+	backupRequest.ctx = ctx
+
+	backupRequest.progress = new(progressTracker)
+	if kb.backupClient != nil {
+		reporter := &progressReporter{
+			backupClient: kb.backupClient,
+			backup:       backupRequest.Backup,
+			tracker:      backupRequest.progress,
+			interval:     progressUpdateInterval,
+			log:          log,
+		}
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			reporter.run(stop)
+		}()
+		defer func() {
+			close(stop)
+			<-done
+		}()
+	}
+
 	podVolumeTimeout := kb.resticTimeout
 	if val := backupRequest.Annotations[api.PodVolumeOperationTimeoutAnnotation]; val != "" {
 		parsed, err := time.ParseDuration(val)
@@ -246,17 +306,19 @@ func (kb *kubernetesBackupper) Backup(log logrus.FieldLogger, backupRequest *Req
 		}
 	}
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), podVolumeTimeout)
+	resticCtx, cancelFunc := context.WithTimeout(ctx, podVolumeTimeout)
 	defer cancelFunc()
 
 	var resticBackupper restic.Backupper
 	if kb.resticBackupperFactory != nil {
-		resticBackupper, err = kb.resticBackupperFactory.NewBackupper(ctx, backupRequest.Backup)
+		resticBackupper, err = kb.resticBackupperFactory.NewBackupper(resticCtx, backupRequest.Backup)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 	}
 
+	tarWriter := &itemTarWriter{tarWriter: tw}
+
 	gb := kb.groupBackupperFactory.newGroupBackupper(
 		log,
 		backupRequest,
@@ -264,22 +326,41 @@ func (kb *kubernetesBackupper) Backup(log logrus.FieldLogger, backupRequest *Req
 		kb.discoveryHelper,
 		cohabitatingResources(),
 		kb.podCommandExecutor,
-		tw,
+		tarWriter,
 		resticBackupper,
 		newPVCSnapshotTracker(),
 		volumeSnapshotterGetter,
 	)
 
 	for _, group := range kb.discoveryHelper.Resources() {
+		if backupRequest.canceled() {
+			log.Info("Backup was canceled, not backing up any more API groups")
+			break
+		}
+
 		if err := gb.backupGroup(group); err != nil {
 			log.WithError(err).WithField("apiGroup", group.String()).Error("Error backing up API group")
 		}
 	}
 
+	if backupRequest.Spec.IncludeAllAPIGroupVersions && !backupRequest.canceled() {
+		kb.backupNonPreferredVersions(log, backupRequest, tarWriter)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "backup was canceled")
+	}
+
+	// now that the backup's done, the total is known exactly.
+	backupRequest.Status.Progress = &api.BackupProgress{
+		TotalItems:    len(backupRequest.BackedUpItems),
+		ItemsBackedUp: len(backupRequest.BackedUpItems),
+	}
+
 	return nil
 }
 
-func (kb *kubernetesBackupper) writeBackupVersion(tw *tar.Writer) error {
+func (kb *kubernetesBackupper) writeBackupVersion(tw tarWriter) error {
 	versionFile := filepath.Join(api.MetadataDir, "version")
 	versionString := fmt.Sprintf("%d\n", BackupVersion)
 
@@ -304,3 +385,26 @@ type tarWriter interface {
 	Write([]byte) (int, error)
 	WriteHeader(*tar.Header) error
 }
+
+// itemTarWriter serializes writes of backed-up items to a tarWriter, so that
+// items being backed up concurrently aren't interleaved in the tarball.
+type itemTarWriter struct {
+	lock      sync.Mutex
+	tarWriter tarWriter
+}
+
+// writeItem writes an item's header and contents to the tarball as a single unit.
+func (w *itemTarWriter) writeItem(hdr *tar.Header, contents []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.tarWriter.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := w.tarWriter.Write(contents); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
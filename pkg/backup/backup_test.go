@@ -22,6 +22,7 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"sort"
@@ -35,12 +36,15 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
 
 	velerov1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/discovery"
@@ -84,7 +88,7 @@ func TestBackedUpItemsMatchesTarballContents(t *testing.T) {
 		h.addItems(t, resource)
 	}
 
-	h.backupper.Backup(h.log, req, backupFile, nil, nil)
+	h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
 
 	// go through BackedUpItems after the backup to assemble the list of files we
 	// expect to see in the tarball and compare to see if they match
@@ -103,6 +107,324 @@ func TestBackedUpItemsMatchesTarballContents(t *testing.T) {
 	assertTarballContents(t, backupFile, append(expectedFiles, "metadata/version")...)
 }
 
+// TestBackupCanceled verifies that a backup whose context has been canceled
+// doesn't back up any items and returns an error.
+func TestBackupCanceled(t *testing.T) {
+	h := newHarness(t)
+	req := &Request{Backup: defaultBackup().Result()}
+	backupFile := bytes.NewBuffer([]byte{})
+
+	h.addItems(t, test.Pods(
+		builder.ForPod("foo", "bar").Result(),
+		builder.ForPod("zoo", "raz").Result(),
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := h.backupper.Backup(ctx, h.log, req, backupFile, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backup was canceled")
+	assert.Empty(t, req.BackedUpItems)
+}
+
+// TestBackupWithCompression verifies that a backup's tarball is compressed
+// with the codec in its request, and that an unsupported codec fails the
+// backup.
+func TestBackupWithCompression(t *testing.T) {
+	h := newHarness(t)
+	h.addItems(t, test.Pods(
+		builder.ForPod("foo", "bar").Result(),
+	))
+
+	req := &Request{
+		Backup:      defaultBackup().Result(),
+		Compression: velerov1.CompressionConfig{Codec: velerov1.CompressionCodecNone},
+	}
+	backupFile := bytes.NewBuffer([]byte{})
+
+	require.NoError(t, h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil))
+	assert.Equal(t, archive.CodecNone, req.TarballIndex.Codec)
+
+	// the tarball isn't compressed, so it can be read as a tar file directly
+	var files []string
+	tr := tar.NewReader(backupFile)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		files = append(files, hdr.Name)
+	}
+	assert.Equal(t, []string{"metadata/version", "resources/pods/namespaces/foo/bar.json"}, files)
+
+	req = &Request{
+		Backup:      defaultBackup().Result(),
+		Compression: velerov1.CompressionConfig{Codec: "lz4"},
+	}
+	err := h.backupper.Backup(context.Background(), h.log, req, bytes.NewBuffer([]byte{}), nil, nil)
+	assert.Error(t, err)
+}
+
+// TestBackupProgressIsUpdated verifies that after a backup has run, its
+// status.progress field is updated to reflect the total number of items
+// backed up.
+func TestBackupProgressIsUpdated(t *testing.T) {
+	h := newHarness(t)
+	req := &Request{Backup: defaultBackup().Result()}
+	backupFile := bytes.NewBuffer([]byte{})
+
+	apiResources := []*test.APIResource{
+		test.Pods(
+			builder.ForPod("foo", "bar").Result(),
+			builder.ForPod("zoo", "raz").Result(),
+		),
+		test.Deployments(
+			builder.ForDeployment("foo", "bar").Result(),
+			builder.ForDeployment("zoo", "raz").Result(),
+		),
+		test.PVs(
+			builder.ForPersistentVolume("bar").Result(),
+			builder.ForPersistentVolume("baz").Result(),
+		),
+	}
+	for _, resource := range apiResources {
+		h.addItems(t, resource)
+	}
+
+	h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
+
+	require.NotNil(t, req.Status.Progress)
+	assert.Len(t, req.BackedUpItems, req.Status.Progress.TotalItems)
+	assert.Len(t, req.BackedUpItems, req.Status.Progress.ItemsBackedUp)
+}
+
+// TestBackupWithItemBackupWorkers runs backups using multiple item backup workers,
+// configured either on the backupper or on the backup itself, and verifies that
+// every item is written to the tarball and every volume is snapshotted.
+func TestBackupWithItemBackupWorkers(t *testing.T) {
+	tests := []struct {
+		name          string
+		backup        *velerov1.Backup
+		serverWorkers int
+	}{
+		{
+			name:          "server's workers are used when the backup doesn't specify any",
+			backup:        defaultBackup().Result(),
+			serverWorkers: 4,
+		},
+		{
+			name:          "backup's workers override the server's",
+			backup:        defaultBackup().ItemBackupWorkers(3).Result(),
+			serverWorkers: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				h                 = newHarness(t)
+				backupFile        = bytes.NewBuffer([]byte{})
+				pods              []metav1.Object
+				pvs               []metav1.Object
+				volumeSnapshotter = new(fakeVolumeSnapshotter)
+				wantFiles         = []string{"metadata/version"}
+				wantPVs           []string
+			)
+
+			for i := 0; i < 10; i++ {
+				ns, name := fmt.Sprintf("ns-%d", i%2), fmt.Sprintf("pod-%d", i)
+				pods = append(pods, builder.ForPod(ns, name).Result())
+				wantFiles = append(wantFiles, fmt.Sprintf("resources/pods/namespaces/%s/%s.json", ns, name))
+			}
+			for i := 0; i < 5; i++ {
+				name := fmt.Sprintf("pv-%d", i)
+				pvs = append(pvs, builder.ForPersistentVolume(name).Result())
+				volumeSnapshotter.WithVolume(name, fmt.Sprintf("vol-%d", i), "", "type-1", 100, false)
+				wantFiles = append(wantFiles, fmt.Sprintf("resources/persistentvolumes/cluster/%s.json", name))
+				wantPVs = append(wantPVs, name)
+			}
+
+			h.addItems(t, test.Pods(pods...))
+			h.addItems(t, test.PVs(pvs...))
+			h.backupper.itemBackupWorkers = tc.serverWorkers
+
+			req := &Request{
+				Backup:            tc.backup,
+				SnapshotLocations: []*velerov1.VolumeSnapshotLocation{newSnapshotLocation("velero", "default", "default")},
+			}
+
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, volumeSnapshotterGetter{"default": volumeSnapshotter})
+			require.NoError(t, err)
+
+			assertTarballContents(t, backupFile, wantFiles...)
+
+			var snapshottedPVs []string
+			for _, snapshot := range req.VolumeSnapshots {
+				assert.Equal(t, volume.SnapshotPhaseCompleted, snapshot.Status.Phase)
+				snapshottedPVs = append(snapshottedPVs, snapshot.Spec.PersistentVolumeName)
+			}
+			assert.ElementsMatch(t, wantPVs, snapshottedPVs)
+		})
+	}
+}
+
+// TestBackupWithPagination runs backups with different client page sizes
+// against a fake API server that paginates list responses, and verifies
+// that every item is backed up exactly once and that items are listed
+// using the expected options.
+func TestBackupWithPagination(t *testing.T) {
+	const labelSelector = "velero.io/exclude-from-backup!=true"
+
+	tests := []struct {
+		name             string
+		pageSize         int
+		expireAfterPages int
+		wantListCalls    []metav1.ListOptions
+	}{
+		{
+			name:     "items are listed a page at a time",
+			pageSize: 2,
+			wantListCalls: []metav1.ListOptions{
+				{LabelSelector: labelSelector, Limit: 2},
+				{LabelSelector: labelSelector, Limit: 2, Continue: "2"},
+				{LabelSelector: labelSelector, Limit: 2, Continue: "4"},
+			},
+		},
+		{
+			name:     "page size of zero lists all items at once",
+			pageSize: 0,
+			wantListCalls: []metav1.ListOptions{
+				{LabelSelector: labelSelector},
+			},
+		},
+		{
+			name:             "remaining items are listed without pagination when the continue token expires",
+			pageSize:         2,
+			expireAfterPages: 1,
+			wantListCalls: []metav1.ListOptions{
+				{LabelSelector: labelSelector, Limit: 2},
+				{LabelSelector: labelSelector, Limit: 2, Continue: "2"},
+				{LabelSelector: labelSelector},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				h          = newHarness(t)
+				req        = &Request{Backup: defaultBackup().Result()}
+				backupFile = bytes.NewBuffer([]byte{})
+				pods       []metav1.Object
+				wantFiles  = []string{"metadata/version"}
+			)
+
+			for i := 0; i < 5; i++ {
+				name := fmt.Sprintf("pod-%d", i)
+				pods = append(pods, builder.ForPod("ns-1", name).Result())
+				wantFiles = append(wantFiles, fmt.Sprintf("resources/pods/namespaces/ns-1/%s.json", name))
+			}
+			h.addItems(t, test.Pods(pods...))
+
+			dynamicFactory := test.NewPagingDynamicFactory(h.backupper.dynamicFactory)
+			dynamicFactory.ExpireAfterPages = tc.expireAfterPages
+			h.backupper.dynamicFactory = dynamicFactory
+			h.backupper.clientPageSize = tc.pageSize
+
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
+			require.NoError(t, err)
+
+			assertTarballContents(t, backupFile, wantFiles...)
+			assert.Equal(t, tc.wantListCalls, dynamicFactory.ListCalls())
+			assert.Equal(t, &velerov1.BackupProgress{TotalItems: 5, ItemsBackedUp: 5}, req.Status.Progress)
+		})
+	}
+}
+
+// TestBackupWithAllAPIGroupVersions runs backups of resources served in
+// multiple API versions, and verifies that only the preferred version is
+// backed up by default, and that every version of the backed-up items is
+// stored in its own directory when all API group versions are included.
+func TestBackupWithAllAPIGroupVersions(t *testing.T) {
+	deployment := func(version, name string, opts ...builder.ObjectMetaOpt) metav1.Object {
+		obj := builder.ForDeployment("ns-1", name).ObjectMeta(opts...).Result()
+		obj.APIVersion = "apps/" + version
+		return obj
+	}
+
+	deployments := func(version string, items ...metav1.Object) *test.APIResource {
+		res := test.Deployments(items...)
+		res.Version = version
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		backup *velerov1.Backup
+		want   []string
+	}{
+		{
+			name:   "only the preferred version is backed up by default",
+			backup: defaultBackup().Result(),
+			want: []string{
+				"resources/deployments.apps/namespaces/ns-1/deploy-1.json",
+				"resources/deployments.apps/namespaces/ns-1/deploy-2.json",
+				"resources/pods/namespaces/ns-1/pod-1.json",
+			},
+		},
+		{
+			name:   "every version is backed up in its own directory when all API group versions are included",
+			backup: defaultBackup().IncludeAllAPIGroupVersions(true).Result(),
+			want: []string{
+				"resources/deployments.apps/v1/namespaces/ns-1/deploy-1.json",
+				"resources/deployments.apps/v1/namespaces/ns-1/deploy-2.json",
+				"resources/deployments.apps/v1beta1/namespaces/ns-1/deploy-1.json",
+				"resources/deployments.apps/v1beta1/namespaces/ns-1/deploy-2.json",
+				"resources/pods/v1/namespaces/ns-1/pod-1.json",
+			},
+		},
+		{
+			name: "non-preferred versions of items excluded from the backup are not backed up",
+			backup: defaultBackup().
+				IncludeAllAPIGroupVersions(true).
+				LabelSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}).
+				Result(),
+			want: []string{
+				"resources/deployments.apps/v1/namespaces/ns-1/deploy-1.json",
+				"resources/deployments.apps/v1beta1/namespaces/ns-1/deploy-1.json",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				h          = newHarness(t)
+				req        = &Request{Backup: tc.backup}
+				backupFile = bytes.NewBuffer([]byte{})
+			)
+
+			// the first version added for a group is its preferred version
+			h.addItems(t, deployments("v1",
+				deployment("v1", "deploy-1", builder.WithLabels("a", "b")),
+				deployment("v1", "deploy-2"),
+			))
+			h.addItems(t, deployments("v1beta1",
+				deployment("v1beta1", "deploy-1", builder.WithLabels("a", "b")),
+				deployment("v1beta1", "deploy-2"),
+			))
+			h.addItems(t, test.Pods(builder.ForPod("ns-1", "pod-1").Result()))
+
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
+			require.NoError(t, err)
+
+			assertTarballContents(t, backupFile, append(tc.want, "metadata/version")...)
+		})
+	}
+}
+
 // TestBackupResourceFiltering runs backups with different combinations
 // of resource filters (included/excluded resources, included/excluded
 // namespaces, label selectors, "include cluster resources" flag), and
@@ -589,7 +911,7 @@ func TestBackupResourceFiltering(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			h.backupper.Backup(h.log, req, backupFile, nil, nil)
+			h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
 
 			assertTarballContents(t, backupFile, append(tc.want, "metadata/version")...)
 		})
@@ -653,7 +975,7 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			h.backupper.Backup(h.log, req, backupFile, nil, nil)
+			h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
 
 			assertTarballContents(t, backupFile, append(tc.want, "metadata/version")...)
 		})
@@ -676,7 +998,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 	h.addItems(t, test.Deployments(builder.ForDeployment("ns-1", "deploy-1").Result()))
 	h.addItems(t, test.ExtensionsDeployments(builder.ForDeployment("ns-1", "deploy-1").Result()))
 
-	h.backupper.Backup(h.log, backup1, backup1File, nil, nil)
+	h.backupper.Backup(context.Background(), h.log, backup1, backup1File, nil, nil)
 
 	assertTarballContents(t, backup1File, "metadata/version", "resources/deployments.apps/namespaces/ns-1/deploy-1.json")
 
@@ -686,7 +1008,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 	}
 	backup2File := bytes.NewBuffer([]byte{})
 
-	h.backupper.Backup(h.log, backup2, backup2File, nil, nil)
+	h.backupper.Backup(context.Background(), h.log, backup2, backup2File, nil, nil)
 
 	assertTarballContents(t, backup2File, "metadata/version", "resources/deployments.apps/namespaces/ns-1/deploy-1.json")
 }
@@ -738,7 +1060,7 @@ func TestBackupResourceOrdering(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			h.backupper.Backup(h.log, req, backupFile, nil, nil)
+			h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
 
 			assertTarballOrdering(t, backupFile, "pods", "persistentvolumeclaims", "persistentvolumes")
 		})
@@ -961,7 +1283,7 @@ func TestBackupActionsRunForCorrectItems(t *testing.T) {
 				actions = append(actions, action)
 			}
 
-			err := h.backupper.Backup(h.log, req, backupFile, actions, nil)
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, actions, nil)
 			assert.NoError(t, err)
 
 			for action, want := range tc.actions {
@@ -1034,7 +1356,7 @@ func TestBackupWithInvalidActions(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			assert.Error(t, h.backupper.Backup(h.log, req, backupFile, tc.actions, nil))
+			assert.Error(t, h.backupper.Backup(context.Background(), h.log, req, backupFile, tc.actions, nil))
 		})
 	}
 }
@@ -1165,7 +1487,7 @@ func TestBackupActionModifications(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			err := h.backupper.Backup(h.log, req, backupFile, tc.actions, nil)
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, tc.actions, nil)
 			assert.NoError(t, err)
 
 			assertTarballFileContents(t, backupFile, tc.want)
@@ -1371,7 +1693,7 @@ func TestBackupActionAdditionalItems(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			err := h.backupper.Backup(h.log, req, backupFile, tc.actions, nil)
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, tc.actions, nil)
 			assert.NoError(t, err)
 
 			assertTarballContents(t, backupFile, append(tc.want, "metadata/version")...)
@@ -1747,7 +2069,7 @@ func TestBackupWithSnapshots(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			err := h.backupper.Backup(h.log, tc.req, backupFile, nil, tc.snapshotterGetter)
+			err := h.backupper.Backup(context.Background(), h.log, tc.req, backupFile, nil, tc.snapshotterGetter)
 			assert.NoError(t, err)
 
 			assert.Equal(t, tc.want, tc.req.VolumeSnapshots)
@@ -1755,6 +2077,154 @@ func TestBackupWithSnapshots(t *testing.T) {
 	}
 }
 
+// TestBackupWithCSISnapshots runs backups of CSI persistent volumes that no
+// volume snapshotter supports, and verifies that a CSI VolumeSnapshot is taken
+// of each volume's claim and backed up along with its VolumeSnapshotContent.
+func TestBackupWithCSISnapshots(t *testing.T) {
+	snapshotClass := func(name, driver string, opts ...builder.ObjectMetaOpt) metav1.Object {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"driver": driver}}
+		obj.SetAPIVersion("snapshot.storage.k8s.io/v1beta1")
+		obj.SetKind("VolumeSnapshotClass")
+		obj.SetName(name)
+		for _, opt := range opts {
+			opt(obj)
+		}
+		return obj
+	}
+
+	snapshotContent := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"deletionPolicy": "Delete"},
+	}}
+	snapshotContent.SetAPIVersion("snapshot.storage.k8s.io/v1beta1")
+	snapshotContent.SetKind("VolumeSnapshotContent")
+	snapshotContent.SetName("snapcontent-1")
+
+	tests := []struct {
+		name            string
+		backup          *velerov1.Backup
+		pv              *corev1.PersistentVolume
+		snapshotClasses []metav1.Object
+		want            []string
+		wantSnapshot    map[string]interface{}
+	}{
+		{
+			name:   "claimed CSI volume is snapshotted using its driver's VolumeSnapshotClass",
+			backup: defaultBackup().Result(),
+			pv:     builder.ForPersistentVolume("pv-1").CSI("csi.example.com", "vol-1").ClaimRef("ns-1", "pvc-1").Result(),
+			snapshotClasses: []metav1.Object{
+				snapshotClass("class-1", "other.example.com"),
+				snapshotClass("class-2", "csi.example.com"),
+			},
+			want: []string{
+				"resources/persistentvolumes/cluster/pv-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-2.json",
+				"resources/volumesnapshotcontents.snapshot.storage.k8s.io/cluster/snapcontent-1.json",
+				"resources/volumesnapshots.snapshot.storage.k8s.io/namespaces/ns-1/velero-backup-1-pvc-1.json",
+			},
+			wantSnapshot: map[string]interface{}{
+				"volumeSnapshotClassName": "class-2",
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": "pvc-1"},
+			},
+		},
+		{
+			name:   "driver's default VolumeSnapshotClass is used when it has multiple",
+			backup: defaultBackup().Result(),
+			pv:     builder.ForPersistentVolume("pv-1").CSI("csi.example.com", "vol-1").ClaimRef("ns-1", "pvc-1").Result(),
+			snapshotClasses: []metav1.Object{
+				snapshotClass("class-1", "csi.example.com"),
+				snapshotClass("class-2", "csi.example.com", builder.WithAnnotations("snapshot.storage.kubernetes.io/is-default-class", "true")),
+			},
+			want: []string{
+				"resources/persistentvolumes/cluster/pv-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-2.json",
+				"resources/volumesnapshotcontents.snapshot.storage.k8s.io/cluster/snapcontent-1.json",
+				"resources/volumesnapshots.snapshot.storage.k8s.io/namespaces/ns-1/velero-backup-1-pvc-1.json",
+			},
+			wantSnapshot: map[string]interface{}{
+				"volumeSnapshotClassName": "class-2",
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": "pvc-1"},
+			},
+		},
+		{
+			name:            "unclaimed CSI volume is not snapshotted",
+			backup:          defaultBackup().Result(),
+			pv:              builder.ForPersistentVolume("pv-1").CSI("csi.example.com", "vol-1").Result(),
+			snapshotClasses: []metav1.Object{snapshotClass("class-1", "csi.example.com")},
+			want: []string{
+				"resources/persistentvolumes/cluster/pv-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-1.json",
+				"resources/volumesnapshotcontents.snapshot.storage.k8s.io/cluster/snapcontent-1.json",
+			},
+		},
+		{
+			name:            "CSI volume is not snapshotted when the backup has snapshots disabled",
+			backup:          defaultBackup().SnapshotVolumes(false).Result(),
+			pv:              builder.ForPersistentVolume("pv-1").CSI("csi.example.com", "vol-1").ClaimRef("ns-1", "pvc-1").Result(),
+			snapshotClasses: []metav1.Object{snapshotClass("class-1", "csi.example.com")},
+			want: []string{
+				"resources/persistentvolumes/cluster/pv-1.json",
+				"resources/volumesnapshotclasses.snapshot.storage.k8s.io/cluster/class-1.json",
+				"resources/volumesnapshotcontents.snapshot.storage.k8s.io/cluster/snapcontent-1.json",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				h          = newHarness(t)
+				req        = &Request{Backup: tc.backup}
+				backupFile = bytes.NewBuffer([]byte{})
+			)
+
+			h.addItems(t, test.PVs(tc.pv))
+			h.addItems(t, test.VolumeSnapshotClasses(tc.snapshotClasses...))
+			h.addItems(t, test.VolumeSnapshotContents(snapshotContent.DeepCopy()))
+			h.addItems(t, test.VolumeSnapshots())
+
+			// simulate the CSI snapshotter binding the VolumeSnapshot to the
+			// content as soon as it's created.
+			var created *unstructured.Unstructured
+			h.DynamicClient.PrependReactor("create", "volumesnapshots", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				snapshot := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+				created = snapshot.DeepCopy()
+				if err := unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"); err != nil {
+					return true, nil, err
+				}
+				return false, nil, unstructured.SetNestedField(snapshot.Object, "snapcontent-1", "status", "boundVolumeSnapshotContentName")
+			})
+
+			err := h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil)
+			require.NoError(t, err)
+
+			assertTarballContents(t, backupFile, append(tc.want, "metadata/version")...)
+
+			if tc.wantSnapshot == nil {
+				assert.Nil(t, created)
+				assert.Empty(t, req.Status.CSIVolumeSnapshotContents)
+				return
+			}
+
+			require.NotNil(t, created)
+			assert.Equal(t, tc.wantSnapshot, created.Object["spec"])
+			assert.Equal(t, "backup-1", created.GetLabels()[velerov1.BackupNameLabel])
+
+			// the VolumeSnapshot is deleted once it's been backed up, and the
+			// VolumeSnapshotContent is recorded so it can be deleted with the backup
+			_, err = h.DynamicClient.Resource(test.VolumeSnapshots().GVR()).Namespace("ns-1").Get("velero-backup-1-pvc-1", metav1.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err))
+			assert.Equal(t, []string{"snapcontent-1"}, req.Status.CSIVolumeSnapshotContents)
+
+			content, err := h.DynamicClient.Resource(test.VolumeSnapshotContents().GVR()).Get("snapcontent-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			policy, _, _ := unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+			assert.Equal(t, "Retain", policy)
+		})
+	}
+}
+
 // TestBackupWithInvalidHooks runs backups with invalid hook specifications and verifies
 // that an error is returned.
 func TestBackupWithInvalidHooks(t *testing.T) {
@@ -1805,7 +2275,7 @@ func TestBackupWithInvalidHooks(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			assert.EqualError(t, h.backupper.Backup(h.log, req, backupFile, nil, nil), tc.want.Error())
+			assert.EqualError(t, h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil), tc.want.Error())
 		})
 	}
 }
@@ -2062,7 +2532,7 @@ func TestBackupWithHooks(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			require.NoError(t, h.backupper.Backup(h.log, req, backupFile, nil, nil))
+			require.NoError(t, h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, nil))
 
 			assertTarballContents(t, backupFile, append(tc.wantBackedUp, "metadata/version")...)
 		})
@@ -2166,7 +2636,7 @@ func TestBackupWithRestic(t *testing.T) {
 				h.addItems(t, resource)
 			}
 
-			require.NoError(t, h.backupper.Backup(h.log, req, backupFile, nil, tc.snapshotterGetter))
+			require.NoError(t, h.backupper.Backup(context.Background(), h.log, req, backupFile, nil, tc.snapshotterGetter))
 
 			assert.Equal(t, tc.want, req.PodVolumeBackups)
 
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/kuberesource"
+	"github.com/heptio/velero/pkg/label"
+)
+
+const (
+	// csiSnapshotPollInterval is how often a CSI VolumeSnapshot is checked
+	// while waiting for it to become ready to use.
+	csiSnapshotPollInterval = time.Second
+
+	// csiSnapshotTimeout is how long to wait for a CSI VolumeSnapshot to
+	// become ready to use before failing the PV's snapshot.
+	csiSnapshotTimeout = 10 * time.Minute
+
+	// defaultVolumeSnapshotClassAnnotation is the annotation identifying the
+	// VolumeSnapshotClass to use for a CSI driver when it has more than one.
+	defaultVolumeSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+)
+
+// takeCSISnapshot snapshots the CSI volume underlying the PersistentVolume by creating a
+// VolumeSnapshot for the PV's claim and waiting for it to become ready to use. The
+// VolumeSnapshot and its VolumeSnapshotContent are then backed up, so that the claim
+// can be restored from the snapshot. The VolumeSnapshotContent's deletion policy is set
+// to Retain so that the snapshot outlives the VolumeSnapshot, which is deleted once it's
+// been backed up, and the VolumeSnapshotContent's name is recorded in the backup's status
+// so that it and its snapshot can be deleted along with the backup.
+func (ib *defaultItemBackupper) takeCSISnapshot(pv *corev1api.PersistentVolume, log logrus.FieldLogger) error {
+	if pv.Spec.CSI == nil || pv.Spec.ClaimRef == nil {
+		return nil
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"csiDriver":             pv.Spec.CSI.Driver,
+		"persistentVolumeClaim": fmt.Sprintf("%s/%s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name),
+	})
+
+	snapshotGVR, snapshotResource, err := ib.discoveryHelper.ResourceFor(kuberesource.VolumeSnapshots.WithVersion(""))
+	if err != nil {
+		log.WithError(err).Info("CSI VolumeSnapshot resource is not available in the cluster, skipping.")
+		return nil
+	}
+
+	snapshotClass, err := ib.csiSnapshotClass(pv.Spec.CSI.Driver)
+	if err != nil {
+		return err
+	}
+
+	snapshotClient, err := ib.dynamicFactory.ClientForGroupVersionResource(snapshotGVR.GroupVersion(), snapshotResource, pv.Spec.ClaimRef.Namespace)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	snapshot := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"volumeSnapshotClassName": snapshotClass,
+				"source": map[string]interface{}{
+					"persistentVolumeClaimName": pv.Spec.ClaimRef.Name,
+				},
+			},
+		},
+	}
+	snapshot.SetAPIVersion(snapshotGVR.GroupVersion().String())
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetNamespace(pv.Spec.ClaimRef.Namespace)
+	snapshot.SetName(label.GetValidName(fmt.Sprintf("velero-%s-%s", ib.backupRequest.Name, pv.Spec.ClaimRef.Name)))
+	snapshot.SetLabels(map[string]string{
+		api.BackupNameLabel: label.GetValidName(ib.backupRequest.Name),
+	})
+
+	log = log.WithField("volumeSnapshot", snapshot.GetName())
+
+	log.Info("Creating CSI VolumeSnapshot")
+	if snapshot, err = snapshotClient.Create(snapshot); err != nil {
+		return errors.Wrap(err, "error creating CSI VolumeSnapshot")
+	}
+
+	log.Info("Waiting for CSI VolumeSnapshot to be ready to use")
+	var contentName string
+	err = wait.PollImmediate(csiSnapshotPollInterval, csiSnapshotTimeout, func() (bool, error) {
+		res, err := snapshotClient.Get(snapshot.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "error getting CSI VolumeSnapshot")
+		}
+
+		ready, _, _ := unstructured.NestedBool(res.Object, "status", "readyToUse")
+		contentName, _, _ = unstructured.NestedString(res.Object, "status", "boundVolumeSnapshotContentName")
+		if !ready || contentName == "" {
+			return false, nil
+		}
+
+		snapshot = res
+		return true, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "error waiting for CSI VolumeSnapshot to be ready to use")
+	}
+
+	contentGVR, contentResource, err := ib.discoveryHelper.ResourceFor(kuberesource.VolumeSnapshotContents.WithVersion(""))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	contentClient, err := ib.dynamicFactory.ClientForGroupVersionResource(contentGVR.GroupVersion(), contentResource, "")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Info("Retaining CSI VolumeSnapshotContent")
+	content, err := contentClient.Patch(contentName, []byte(`{"spec":{"deletionPolicy":"Retain"}}`))
+	if err != nil {
+		return errors.Wrap(err, "error setting CSI VolumeSnapshotContent's deletion policy")
+	}
+	ib.backupRequest.addCSIVolumeSnapshotContent(contentName)
+
+	if err := ib.additionalItemBackupper.backupItem(log, content, contentGVR.GroupResource()); err != nil {
+		return err
+	}
+	if err := ib.additionalItemBackupper.backupItem(log, snapshot, snapshotGVR.GroupResource()); err != nil {
+		return err
+	}
+
+	// the VolumeSnapshot is only needed until the snapshot has been captured
+	// and backed up; the retained VolumeSnapshotContent keeps the snapshot.
+	log.Info("Deleting CSI VolumeSnapshot")
+	if err := snapshotClient.Delete(snapshot.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		log.WithError(errors.WithStack(err)).Warn("Error deleting CSI VolumeSnapshot")
+	}
+
+	return nil
+}
+
+// csiSnapshotClass returns the name of the VolumeSnapshotClass to use for snapshots of
+// volumes provisioned by the CSI driver. If the driver has multiple VolumeSnapshotClasses,
+// the one annotated as the default is used.
+func (ib *defaultItemBackupper) csiSnapshotClass(driver string) (string, error) {
+	gvr, resource, err := ib.discoveryHelper.ResourceFor(kuberesource.VolumeSnapshotClasses.WithVersion(""))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	classClient, err := ib.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, "")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return findCSISnapshotClass(classClient, driver)
+}
+
+func findCSISnapshotClass(classClient client.Lister, driver string) (string, error) {
+	res, err := classClient.List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "error listing VolumeSnapshotClasses")
+	}
+
+	list, ok := res.(*unstructured.UnstructuredList)
+	if !ok {
+		return "", errors.Errorf("unexpected type %T", res)
+	}
+
+	var candidates []unstructured.Unstructured
+	for _, class := range list.Items {
+		if classDriver, _, _ := unstructured.NestedString(class.Object, "driver"); classDriver == driver {
+			candidates = append(candidates, class)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", errors.Errorf("no VolumeSnapshotClass found for CSI driver %s", driver)
+	case 1:
+		return candidates[0].GetName(), nil
+	}
+
+	for _, class := range candidates {
+		if class.GetAnnotations()[defaultVolumeSnapshotClassAnnotation] == "true" {
+			return class.GetName(), nil
+		}
+	}
+	return "", errors.Errorf("multiple VolumeSnapshotClasses found for CSI driver %s and none is annotated with %s=true", driver, defaultVolumeSnapshotClassAnnotation)
+}
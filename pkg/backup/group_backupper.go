@@ -39,7 +39,7 @@ type groupBackupperFactory interface {
 		discoveryHelper discovery.Helper,
 		cohabitatingResources map[string]*cohabitatingResource,
 		podCommandExecutor podexec.PodCommandExecutor,
-		tarWriter tarWriter,
+		tarWriter *itemTarWriter,
 		resticBackupper restic.Backupper,
 		resticSnapshotTracker *pvcSnapshotTracker,
 		volumeSnapshotterGetter VolumeSnapshotterGetter,
@@ -55,7 +55,7 @@ func (f *defaultGroupBackupperFactory) newGroupBackupper(
 	discoveryHelper discovery.Helper,
 	cohabitatingResources map[string]*cohabitatingResource,
 	podCommandExecutor podexec.PodCommandExecutor,
-	tarWriter tarWriter,
+	tarWriter *itemTarWriter,
 	resticBackupper restic.Backupper,
 	resticSnapshotTracker *pvcSnapshotTracker,
 	volumeSnapshotterGetter VolumeSnapshotterGetter,
@@ -87,7 +87,7 @@ type defaultGroupBackupper struct {
 	discoveryHelper          discovery.Helper
 	cohabitatingResources    map[string]*cohabitatingResource
 	podCommandExecutor       podexec.PodCommandExecutor
-	tarWriter                tarWriter
+	tarWriter                *itemTarWriter
 	resticBackupper          restic.Backupper
 	resticSnapshotTracker    *pvcSnapshotTracker
 	resourceBackupperFactory resourceBackupperFactory
@@ -20,7 +20,7 @@ import (
 	"archive/tar"
 	"encoding/json"
 	"fmt"
-	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +36,7 @@ import (
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
 	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/credentials"
 	"github.com/heptio/velero/pkg/discovery"
 	"github.com/heptio/velero/pkg/kuberesource"
 	"github.com/heptio/velero/pkg/plugin/velero"
@@ -48,7 +49,7 @@ type itemBackupperFactory interface {
 	newItemBackupper(
 		backup *Request,
 		podCommandExecutor podexec.PodCommandExecutor,
-		tarWriter tarWriter,
+		tarWriter *itemTarWriter,
 		dynamicFactory client.DynamicFactory,
 		discoveryHelper discovery.Helper,
 		resticBackupper restic.Backupper,
@@ -62,7 +63,7 @@ type defaultItemBackupperFactory struct{}
 func (f *defaultItemBackupperFactory) newItemBackupper(
 	backupRequest *Request,
 	podCommandExecutor podexec.PodCommandExecutor,
-	tarWriter tarWriter,
+	tarWriter *itemTarWriter,
 	dynamicFactory client.DynamicFactory,
 	discoveryHelper discovery.Helper,
 	resticBackupper restic.Backupper,
@@ -95,7 +96,7 @@ type ItemBackupper interface {
 
 type defaultItemBackupper struct {
 	backupRequest           *Request
-	tarWriter               tarWriter
+	tarWriter               *itemTarWriter
 	dynamicFactory          client.DynamicFactory
 	discoveryHelper         discovery.Helper
 	resticBackupper         restic.Backupper
@@ -105,10 +106,11 @@ type defaultItemBackupper struct {
 	itemHookHandler                    itemHookHandler
 	additionalItemBackupper            ItemBackupper
 	snapshotLocationVolumeSnapshotters map[string]velero.VolumeSnapshotter
+	volumeSnapshottersLock             sync.Mutex
 }
 
 // backupItem backs up an individual item to tarWriter. The item may be excluded based on the
-// namespaces IncludesExcludes list.
+// namespaces IncludesExcludes list. It's safe to call concurrently from multiple goroutines.
 func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtime.Unstructured, groupResource schema.GroupResource) error {
 	metadata, err := meta.Accessor(obj)
 	if err != nil {
@@ -123,6 +125,11 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		log = log.WithField("namespace", namespace)
 	}
 
+	if ib.backupRequest.canceled() {
+		log.Debug("Not backing up item because the backup was canceled")
+		return nil
+	}
+
 	// NOTE: we have to re-check namespace & resource includes/excludes because it's possible that
 	// backupItem can be invoked by a custom action.
 	if namespace != "" && !ib.backupRequest.NamespaceIncludesExcludes.ShouldInclude(namespace) {
@@ -153,11 +160,10 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		name:      name,
 	}
 
-	if _, exists := ib.backupRequest.BackedUpItems[key]; exists {
+	if !ib.backupRequest.addBackedUpItem(key) {
 		log.Info("Skipping item because it's already been backed up.")
 		return nil
 	}
-	ib.backupRequest.BackedUpItems[key] = struct{}{}
 
 	log.Info("Backing up item")
 
@@ -219,7 +225,7 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		// this function will return partial results, so process podVolumeBackups
 		// even if there are errors.
 		podVolumeBackups, errs := ib.backupPodVolumes(log, pod, resticVolumesToBackup)
-		ib.backupRequest.PodVolumeBackups = podVolumeBackups
+		ib.backupRequest.addPodVolumeBackups(podVolumeBackups)
 		backupErrs = append(backupErrs, errs...)
 	}
 
@@ -232,12 +238,13 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		return kubeerrs.NewAggregate(backupErrs)
 	}
 
-	var filePath string
-	if namespace != "" {
-		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.NamespaceScopedDir, namespace, name+".json")
-	} else {
-		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.ClusterScopedDir, name+".json")
+	// when all API group versions are being backed up, each version is stored
+	// in its own directory.
+	var version string
+	if ib.backupRequest.Spec.IncludeAllAPIGroupVersions {
+		version = obj.GetObjectKind().GroupVersionKind().Version
 	}
+	filePath := itemFilePath(groupResource, version, namespace, name)
 
 	itemBytes, err := json.Marshal(obj.UnstructuredContent())
 	if err != nil {
@@ -252,15 +259,7 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		ModTime:  time.Now(),
 	}
 
-	if err := ib.tarWriter.WriteHeader(hdr); err != nil {
-		return errors.WithStack(err)
-	}
-
-	if _, err := ib.tarWriter.Write(itemBytes); err != nil {
-		return errors.WithStack(err)
-	}
-
-	return nil
+	return ib.tarWriter.writeItem(hdr, itemBytes)
 }
 
 // backupPodVolumes triggers restic backups of the specified pod volumes, and returns a list of PodVolumeBackups
@@ -342,6 +341,9 @@ func (ib *defaultItemBackupper) executeActions(
 // volumeSnapshotter instantiates and initializes a VolumeSnapshotter given a VolumeSnapshotLocation,
 // or returns an existing one if one's already been initialized for the location.
 func (ib *defaultItemBackupper) volumeSnapshotter(snapshotLocation *api.VolumeSnapshotLocation) (velero.VolumeSnapshotter, error) {
+	ib.volumeSnapshottersLock.Lock()
+	defer ib.volumeSnapshottersLock.Unlock()
+
 	if bs, ok := ib.snapshotLocationVolumeSnapshotters[snapshotLocation.Name]; ok {
 		return bs, nil
 	}
@@ -351,7 +353,12 @@ func (ib *defaultItemBackupper) volumeSnapshotter(snapshotLocation *api.VolumeSn
 		return nil, err
 	}
 
-	if err := bs.Init(snapshotLocation.Spec.Config); err != nil {
+	config, err := credentials.ConfigWithCredentialsFile(ib.backupRequest.credentialFileStore, snapshotLocation.Namespace, snapshotLocation.Spec.Config, snapshotLocation.Spec.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bs.Init(config); err != nil {
 		return nil, err
 	}
 
@@ -429,6 +436,10 @@ func (ib *defaultItemBackupper) takePVSnapshot(obj runtime.Unstructured, log log
 	}
 
 	if volumeSnapshotter == nil {
+		if pv.Spec.CSI != nil {
+			return ib.takeCSISnapshot(pv, log)
+		}
+
 		log.Info("Persistent volume is not a supported volume type for snapshots, skipping.")
 		return nil
 	}
@@ -458,7 +469,7 @@ func (ib *defaultItemBackupper) takePVSnapshot(obj runtime.Unstructured, log log
 		snapshot.Status.Phase = volume.SnapshotPhaseCompleted
 		snapshot.Status.ProviderSnapshotID = snapshotID
 	}
-	ib.backupRequest.VolumeSnapshots = append(ib.backupRequest.VolumeSnapshots, snapshot)
+	ib.backupRequest.addVolumeSnapshot(snapshot)
 
 	// nil errors are automatically removed
 	return kubeerrs.NewAggregate(errs)
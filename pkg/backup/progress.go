@@ -0,0 +1,139 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+// progressTracker keeps track of how many items a backup is expected to contain
+// and how many have been backed up so far. All methods are safe to call on a nil
+// progressTracker, in which case they're no-ops.
+type progressTracker struct {
+	lock          sync.Mutex
+	totalItems    int
+	itemsBackedUp int
+}
+
+// addTotalItems increases the estimated total number of items by n, e.g. after
+// listing a resource.
+func (p *progressTracker) addTotalItems(n int) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.totalItems += n
+}
+
+// setItemsBackedUp records the number of items that have been backed up so far.
+func (p *progressTracker) setItemsBackedUp(n int) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.itemsBackedUp = n
+	// additional items returned by plugins aren't included in the estimate, so
+	// make sure the total never falls behind what's actually been backed up.
+	if p.itemsBackedUp > p.totalItems {
+		p.totalItems = p.itemsBackedUp
+	}
+}
+
+// progress returns the current state of the tracker.
+func (p *progressTracker) progress() velerov1api.BackupProgress {
+	if p == nil {
+		return velerov1api.BackupProgress{}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return velerov1api.BackupProgress{
+		TotalItems:    p.totalItems,
+		ItemsBackedUp: p.itemsBackedUp,
+	}
+}
+
+// progressReporter periodically patches a backup's status.progress with the
+// state of a progressTracker.
+type progressReporter struct {
+	backupClient velerov1client.BackupsGetter
+	backup       *velerov1api.Backup
+	tracker      *progressTracker
+	interval     time.Duration
+	log          logrus.FieldLogger
+}
+
+// run patches the backup's progress every interval, if it has changed, until
+// stop is closed.
+func (r *progressReporter) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var last velerov1api.BackupProgress
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			current := r.tracker.progress()
+			if current == last {
+				continue
+			}
+
+			if err := r.patch(current); err != nil {
+				r.log.WithError(err).Warn("Error updating backup's progress")
+				continue
+			}
+			last = current
+		}
+	}
+}
+
+func (r *progressReporter) patch(progress velerov1api.BackupProgress) error {
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"progress": progress,
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling backup progress patch")
+	}
+
+	if _, err := r.backupClient.Backups(r.backup.Namespace).Patch(r.backup.Name, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching backup")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var nilTracker *progressTracker
+	nilTracker.addTotalItems(1)
+	nilTracker.setItemsBackedUp(1)
+	assert.Equal(t, velerov1api.BackupProgress{}, nilTracker.progress())
+
+	tracker := new(progressTracker)
+	tracker.addTotalItems(5)
+	tracker.setItemsBackedUp(2)
+	assert.Equal(t, velerov1api.BackupProgress{TotalItems: 5, ItemsBackedUp: 2}, tracker.progress())
+
+	// backing up more items than estimated (e.g. additional items from plugins)
+	// increases the total
+	tracker.setItemsBackedUp(7)
+	assert.Equal(t, velerov1api.BackupProgress{TotalItems: 7, ItemsBackedUp: 7}, tracker.progress())
+}
+
+func TestProgressReporterPatchesBackup(t *testing.T) {
+	backup := builder.ForBackup(velerov1api.DefaultNamespace, "backup-1").Phase(velerov1api.BackupPhaseInProgress).Result()
+	client := fake.NewSimpleClientset(backup)
+
+	tracker := new(progressTracker)
+	tracker.addTotalItems(10)
+	tracker.setItemsBackedUp(3)
+
+	reporter := &progressReporter{
+		backupClient: client.VeleroV1(),
+		backup:       backup,
+		tracker:      tracker,
+		interval:     time.Millisecond,
+		log:          velerotest.NewLogger(),
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reporter.run(stop)
+	}()
+
+	var res *velerov1api.Backup
+	err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		var err error
+		res, err = client.VeleroV1().Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+		return err == nil && res.Status.Progress != nil, nil
+	})
+	require.NoError(t, err)
+
+	close(stop)
+	<-done
+
+	assert.Equal(t, &velerov1api.BackupProgress{TotalItems: 10, ItemsBackedUp: 3}, res.Status.Progress)
+	assert.Equal(t, velerov1api.BackupPhaseInProgress, res.Status.Phase)
+}
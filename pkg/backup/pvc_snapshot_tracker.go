@@ -18,14 +18,16 @@ package backup
 
 import (
 	"fmt"
+	"sync"
 
 	corev1api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // pvcSnapshotTracker keeps track of persistent volume claims that have been snapshotted
-// with restic.
+// with restic. It's safe for concurrent use.
 type pvcSnapshotTracker struct {
+	lock sync.Mutex
 	pvcs sets.String
 }
 
@@ -38,6 +40,9 @@ func newPVCSnapshotTracker() *pvcSnapshotTracker {
 // Track takes a pod and a list of volumes from that pod that were snapshotted, and
 // tracks each snapshotted volume that's a PVC.
 func (t *pvcSnapshotTracker) Track(pod *corev1api.Pod, snapshottedVolumes []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	for _, volumeName := range snapshottedVolumes {
 		// if the volume is a PVC, track it
 		for _, volume := range pod.Spec.Volumes {
@@ -53,6 +58,9 @@ func (t *pvcSnapshotTracker) Track(pod *corev1api.Pod, snapshottedVolumes []stri
 
 // Has returns true if the PVC with the specified namespace and name has been tracked.
 func (t *pvcSnapshotTracker) Has(namespace, name string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	return t.pvcs.Has(key(namespace, name))
 }
 
@@ -17,9 +17,13 @@ limitations under the License.
 package backup
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/credentials"
 	"github.com/heptio/velero/pkg/util/collections"
 	"github.com/heptio/velero/pkg/volume"
 )
@@ -42,9 +46,98 @@ type Request struct {
 	ResourceHooks             []resourceHook
 	ResolvedActions           []resolvedAction
 
+	// Compression is how the backup's tarball is compressed, resolved from
+	// the backup's and its storage location's settings.
+	Compression velerov1api.CompressionConfig
+
 	VolumeSnapshots  []*volume.Snapshot
 	PodVolumeBackups []*velerov1api.PodVolumeBackup
 	BackedUpItems    map[itemKey]struct{}
+
+	// TarballIndex records where each item is in the backup's tarball, so
+	// that items can be restored without extracting the whole tarball.
+	TarballIndex *archive.Index
+
+	progress *progressTracker
+
+	// ctx is canceled if the backup is canceled while it's running.
+	ctx context.Context
+
+	// itemBackupWorkers is the number of items of each resource that are
+	// backed up concurrently.
+	itemBackupWorkers int
+
+	// listPageSize is the maximum number of items to retrieve from the API
+	// server in a single list request. If zero, items are listed without
+	// pagination.
+	listPageSize int
+
+	// credentialFileStore provides the credentials files for snapshot
+	// locations that have a credential.
+	credentialFileStore credentials.FileStore
+
+	// lock guards VolumeSnapshots, PodVolumeBackups, BackedUpItems and
+	// Status.CSIVolumeSnapshotContents while items are being backed up
+	// concurrently.
+	lock sync.Mutex
+}
+
+// canceled returns whether the backup has been canceled.
+func (r *Request) canceled() bool {
+	return r.ctx != nil && r.ctx.Err() != nil
+}
+
+// addBackedUpItem records that the item identified by key is being backed up. It
+// returns false if the item has already been recorded.
+func (r *Request) addBackedUpItem(key itemKey) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.BackedUpItems[key]; exists {
+		return false
+	}
+	r.BackedUpItems[key] = struct{}{}
+	return true
+}
+
+// hasBackedUpItem returns whether the item identified by key has been recorded
+// as backed up.
+func (r *Request) hasBackedUpItem(key itemKey) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, exists := r.BackedUpItems[key]
+	return exists
+}
+
+// numBackedUpItems returns the number of items that have been recorded as
+// backed up so far.
+func (r *Request) numBackedUpItems() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.BackedUpItems)
+}
+
+func (r *Request) addVolumeSnapshot(snapshot *volume.Snapshot) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.VolumeSnapshots = append(r.VolumeSnapshots, snapshot)
+}
+
+func (r *Request) addCSIVolumeSnapshotContent(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.Status.CSIVolumeSnapshotContents = append(r.Status.CSIVolumeSnapshotContents, name)
+}
+
+func (r *Request) addPodVolumeBackups(podVolumeBackups []*velerov1api.PodVolumeBackup) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.PodVolumeBackups = append(r.PodVolumeBackups, podVolumeBackups...)
 }
 
 // BackupResourceList returns the list of backed up resources grouped by the API
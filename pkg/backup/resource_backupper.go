@@ -17,14 +17,18 @@ limitations under the License.
 package backup
 
 import (
+	"sync"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/discovery"
@@ -32,6 +36,7 @@ import (
 	"github.com/heptio/velero/pkg/podexec"
 	"github.com/heptio/velero/pkg/restic"
 	"github.com/heptio/velero/pkg/util/collections"
+	"github.com/heptio/velero/pkg/util/kube"
 )
 
 type resourceBackupperFactory interface {
@@ -42,7 +47,7 @@ type resourceBackupperFactory interface {
 		discoveryHelper discovery.Helper,
 		cohabitatingResources map[string]*cohabitatingResource,
 		podCommandExecutor podexec.PodCommandExecutor,
-		tarWriter tarWriter,
+		tarWriter *itemTarWriter,
 		resticBackupper restic.Backupper,
 		resticSnapshotTracker *pvcSnapshotTracker,
 		volumeSnapshotterGetter VolumeSnapshotterGetter,
@@ -58,7 +63,7 @@ func (f *defaultResourceBackupperFactory) newResourceBackupper(
 	discoveryHelper discovery.Helper,
 	cohabitatingResources map[string]*cohabitatingResource,
 	podCommandExecutor podexec.PodCommandExecutor,
-	tarWriter tarWriter,
+	tarWriter *itemTarWriter,
 	resticBackupper restic.Backupper,
 	resticSnapshotTracker *pvcSnapshotTracker,
 	volumeSnapshotterGetter VolumeSnapshotterGetter,
@@ -90,7 +95,7 @@ type defaultResourceBackupper struct {
 	discoveryHelper         discovery.Helper
 	cohabitatingResources   map[string]*cohabitatingResource
 	podCommandExecutor      podexec.PodCommandExecutor
-	tarWriter               tarWriter
+	tarWriter               *itemTarWriter
 	resticBackupper         restic.Backupper
 	resticSnapshotTracker   *pvcSnapshotTracker
 	itemBackupperFactory    itemBackupperFactory
@@ -161,6 +166,9 @@ func (rb *defaultResourceBackupper) backupResource(group *metav1.APIResourceList
 		rb.volumeSnapshotterGetter,
 	)
 
+	workers := newItemWorkerPool(rb.backupRequest.itemBackupWorkers)
+	defer workers.wait()
+
 	namespacesToList := getNamespacesToList(rb.backupRequest.NamespaceIncludesExcludes)
 
 	// Check if we're backing up namespaces, and only certain ones
@@ -178,6 +186,8 @@ func (rb *defaultResourceBackupper) backupResource(group *metav1.APIResourceList
 				}
 			}
 
+			rb.backupRequest.progress.addTotalItems(len(namespacesToList))
+
 			for _, ns := range namespacesToList {
 				log = log.WithField("namespace", ns)
 				log.Info("Getting namespace")
@@ -193,9 +203,13 @@ func (rb *defaultResourceBackupper) backupResource(group *metav1.APIResourceList
 					continue
 				}
 
-				if err := itemBackupper.backupItem(log, unstructured, gr); err != nil {
-					log.WithError(errors.WithStack(err)).Error("Error backing up namespace")
-				}
+				log := log
+				workers.run(func() {
+					if err := itemBackupper.backupItem(log, unstructured, gr); err != nil {
+						log.WithError(errors.WithStack(err)).Error("Error backing up namespace")
+					}
+					rb.backupRequest.progress.setItemsBackedUp(rb.backupRequest.numBackedUpItems())
+				})
 			}
 
 			return nil
@@ -222,60 +236,176 @@ func (rb *defaultResourceBackupper) backupResource(group *metav1.APIResourceList
 		}
 
 		log.Info("Listing items")
-		unstructuredList, err := resourceClient.List(metav1.ListOptions{LabelSelector: labelSelector})
+		err = listItems(log, resourceClient, labelSelector, rb.backupRequest.listPageSize, func(items []runtime.Object) {
+			log.Infof("Retrieved %d items", len(items))
+			rb.backupRequest.progress.addTotalItems(len(items))
+
+			for _, item := range items {
+				unstructured, ok := item.(runtime.Unstructured)
+				if !ok {
+					log.Errorf("Unexpected type %T", item)
+					continue
+				}
+
+				metadata, err := meta.Accessor(unstructured)
+				if err != nil {
+					log.WithError(errors.WithStack(err)).Error("Error getting a metadata accessor")
+					continue
+				}
+
+				if gr == kuberesource.Namespaces && !rb.backupRequest.NamespaceIncludesExcludes.ShouldInclude(metadata.GetName()) {
+					log.WithField("name", metadata.GetName()).Info("Skipping namespace because it's excluded")
+					continue
+				}
+
+				log := log
+				workers.run(func() {
+					rb.backupItem(log, itemBackupper, unstructured, gr, metadata.GetName())
+				})
+			}
+		})
 		if err != nil {
-			log.WithError(errors.WithStack(err)).Error("Error listing items")
+			log.WithError(err).Error("Error listing items")
 			continue
 		}
+	}
 
-		// do the backup
-		items, err := meta.ExtractList(unstructuredList)
+	return nil
+}
+
+// listItems lists the items returned by resourceClient that match labelSelector, in
+// pages of at most pageSize items, calling processPage with each page's items as soon
+// as it's retrieved. If pageSize is zero, all items are listed in a single request.
+// If the API server expires the continue token before all pages have been retrieved,
+// the remaining items are retrieved with a single unpaginated request, skipping any
+// that have already been processed.
+func listItems(log logrus.FieldLogger, resourceClient client.Dynamic, labelSelector string, pageSize int, processPage func([]runtime.Object)) error {
+	opts := metav1.ListOptions{
+		LabelSelector: labelSelector,
+		Limit:         int64(pageSize),
+	}
+
+	// the namespace/name of every item processed so far, only tracked
+	// when there are multiple pages.
+	processed := sets.NewString()
+
+	for {
+		list, err := resourceClient.List(opts)
+		if apierrors.IsResourceExpired(err) && opts.Continue != "" {
+			log.WithError(err).Info("Continue token expired, listing remaining items without pagination")
+			return listRemainingItems(resourceClient, labelSelector, processed, processPage)
+		}
 		if err != nil {
-			log.WithError(errors.WithStack(err)).Error("Error extracting list")
-			continue
+			return errors.WithStack(err)
 		}
 
-		log.Infof("Retrieved %d items", len(items))
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return errors.Wrap(err, "error extracting list")
+		}
 
-		for _, item := range items {
-			unstructured, ok := item.(runtime.Unstructured)
-			if !ok {
-				log.Errorf("Unexpected type %T", item)
-				continue
-			}
+		processPage(items)
 
-			metadata, err := meta.Accessor(unstructured)
-			if err != nil {
-				log.WithError(errors.WithStack(err)).Error("Error getting a metadata accessor")
-				continue
-			}
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if listMeta.GetContinue() == "" {
+			return nil
+		}
 
-			if gr == kuberesource.Namespaces && !rb.backupRequest.NamespaceIncludesExcludes.ShouldInclude(metadata.GetName()) {
-				log.WithField("name", metadata.GetName()).Info("Skipping namespace because it's excluded")
-				continue
+		for _, item := range items {
+			if metadata, err := meta.Accessor(item); err == nil {
+				processed.Insert(kube.NamespaceAndName(metadata))
 			}
+		}
+		opts.Continue = listMeta.GetContinue()
+	}
+}
 
-			err = itemBackupper.backupItem(log, unstructured, gr)
-			if aggregate, ok := err.(kubeerrs.Aggregate); ok {
-				log.WithField("name", metadata.GetName()).Infof("%d errors encountered backup up item", len(aggregate.Errors()))
-				// log each error separately so we get error location info in the log, and an
-				// accurate count of errors
-				for _, err = range aggregate.Errors() {
-					log.WithError(err).WithField("name", metadata.GetName()).Error("Error backing up item")
-				}
+// listRemainingItems lists all items returned by resourceClient that match labelSelector
+// in a single request, and calls processPage with those that aren't in processed.
+func listRemainingItems(resourceClient client.Dynamic, labelSelector string, processed sets.String, processPage func([]runtime.Object)) error {
+	list, err := resourceClient.List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-				continue
-			}
-			if err != nil {
-				log.WithError(err).WithField("name", metadata.GetName()).Error("Error backing up item")
-				continue
-			}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return errors.Wrap(err, "error extracting list")
+	}
+
+	var remaining []runtime.Object
+	for _, item := range items {
+		metadata, err := meta.Accessor(item)
+		if err == nil && processed.Has(kube.NamespaceAndName(metadata)) {
+			continue
 		}
+		remaining = append(remaining, item)
 	}
 
+	processPage(remaining)
 	return nil
 }
 
+// backupItem backs up a single item using itemBackupper, logging any errors that
+// are encountered and updating the backup's progress.
+func (rb *defaultResourceBackupper) backupItem(log logrus.FieldLogger, itemBackupper ItemBackupper, obj runtime.Unstructured, gr schema.GroupResource, name string) {
+	err := itemBackupper.backupItem(log, obj, gr)
+	rb.backupRequest.progress.setItemsBackedUp(rb.backupRequest.numBackedUpItems())
+	if aggregate, ok := err.(kubeerrs.Aggregate); ok {
+		log.WithField("name", name).Infof("%d errors encountered backup up item", len(aggregate.Errors()))
+		// log each error separately so we get error location info in the log, and an
+		// accurate count of errors
+		for _, err = range aggregate.Errors() {
+			log.WithError(err).WithField("name", name).Error("Error backing up item")
+		}
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("name", name).Error("Error backing up item")
+	}
+}
+
+// itemWorkerPool runs item backups on a bounded number of goroutines.
+type itemWorkerPool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+func newItemWorkerPool(workers int) *itemWorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &itemWorkerPool{
+		sem: make(chan struct{}, workers),
+	}
+}
+
+// run calls fn on a new goroutine once fewer than the pool's number of workers
+// are busy, blocking until then. With a single worker, functions are run one at
+// a time, in the order they're passed to run.
+func (p *itemWorkerPool) run(fn func()) {
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+
+		fn()
+	}()
+}
+
+// wait blocks until all functions passed to run have returned.
+func (p *itemWorkerPool) wait() {
+	p.wg.Wait()
+}
+
 // getNamespacesToList examines ie and resolves the includes and excludes to a full list of
 // namespaces to list. If ie is nil or it includes *, the result is just "" (list across all
 // namespaces). Otherwise, the result is a list of every included namespace minus all excluded ones.
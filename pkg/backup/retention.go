@@ -0,0 +1,117 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// retentionTime returns the time used to place a backup in a retention
+// policy's days, weeks and months.
+func retentionTime(backup *velerov1api.Backup) time.Time {
+	if !backup.Status.StartTimestamp.IsZero() {
+		return backup.Status.StartTimestamp.Time.UTC()
+	}
+	return backup.CreationTimestamp.Time.UTC()
+}
+
+// isRetentionCandidate returns true if the backup counts towards a retention
+// policy. Only backups that have finished with usable contents do; others are
+// never kept by a policy, but are never deleted by one either.
+func isRetentionCandidate(backup *velerov1api.Backup) bool {
+	switch backup.Status.Phase {
+	case velerov1api.BackupPhaseCompleted, velerov1api.BackupPhasePartiallyFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// BackupsToDelete returns the names of the backups that aren't kept by the
+// retention policy. backups should be all of the backups created by the
+// policy's schedule. Backups that haven't finished, or that failed, are never
+// returned, and a nil policy or one that keeps nothing deletes nothing.
+func BackupsToDelete(policy *velerov1api.ScheduleRetentionPolicy, backups []*velerov1api.Backup) sets.String {
+	toDelete := sets.NewString()
+	if policy == nil || *policy == (velerov1api.ScheduleRetentionPolicy{}) {
+		return toDelete
+	}
+
+	var candidates []*velerov1api.Backup
+	for _, backup := range backups {
+		if isRetentionCandidate(backup) {
+			candidates = append(candidates, backup)
+		}
+	}
+
+	// newest first, using the name to break ties so the result is stable
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := retentionTime(candidates[i]), retentionTime(candidates[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return candidates[i].Name > candidates[j].Name
+	})
+
+	keep := sets.NewString()
+	for i := 0; i < policy.KeepLast && i < len(candidates); i++ {
+		keep.Insert(candidates[i].Name)
+	}
+
+	keepPerPeriod(keep, candidates, policy.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPerPeriod(keep, candidates, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	keepPerPeriod(keep, candidates, policy.KeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	for _, backup := range candidates {
+		if !keep.Has(backup.Name) {
+			toDelete.Insert(backup.Name)
+		}
+	}
+
+	return toDelete
+}
+
+// keepPerPeriod adds the newest backup in each of the count most recent
+// periods that have a backup to keep. candidates must be sorted newest first.
+func keepPerPeriod(keep sets.String, candidates []*velerov1api.Backup, count int, period func(time.Time) string) {
+	seen := sets.NewString()
+	for _, backup := range candidates {
+		if seen.Len() >= count {
+			return
+		}
+
+		p := period(retentionTime(backup))
+		if seen.Has(p) {
+			continue
+		}
+		seen.Insert(p)
+		keep.Insert(backup.Name)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+)
+
+func TestBackupsToDelete(t *testing.T) {
+	// 2019-06-30 is a Sunday
+	start := time.Date(2019, 6, 30, 1, 0, 0, 0, time.UTC)
+
+	// one completed backup a day for 70 days, newest first, named by the
+	// number of days before start they were taken
+	var daily []*velerov1api.Backup
+	for i := 0; i < 70; i++ {
+		daily = append(daily, retentionBackup(dayName(i), velerov1api.BackupPhaseCompleted, start.AddDate(0, 0, -i)))
+	}
+
+	tests := []struct {
+		name     string
+		policy   *velerov1api.ScheduleRetentionPolicy
+		backups  []*velerov1api.Backup
+		expected []string
+	}{
+		{
+			name:     "nil policy deletes nothing",
+			policy:   nil,
+			backups:  daily,
+			expected: nil,
+		},
+		{
+			name:     "policy that keeps nothing deletes nothing",
+			policy:   &velerov1api.ScheduleRetentionPolicy{},
+			backups:  daily,
+			expected: nil,
+		},
+		{
+			name:   "keep last",
+			policy: &velerov1api.ScheduleRetentionPolicy{KeepLast: 2},
+			backups: []*velerov1api.Backup{
+				retentionBackup("a", velerov1api.BackupPhaseCompleted, start),
+				retentionBackup("b", velerov1api.BackupPhasePartiallyFailed, start.Add(-time.Hour)),
+				retentionBackup("c", velerov1api.BackupPhaseCompleted, start.Add(-2*time.Hour)),
+				retentionBackup("d", velerov1api.BackupPhaseCompleted, start.Add(-3*time.Hour)),
+			},
+			expected: []string{"c", "d"},
+		},
+		{
+			name:   "unfinished and failed backups are neither kept nor deleted",
+			policy: &velerov1api.ScheduleRetentionPolicy{KeepLast: 1},
+			backups: []*velerov1api.Backup{
+				retentionBackup("new", velerov1api.BackupPhaseNew, start),
+				retentionBackup("in-progress", velerov1api.BackupPhaseInProgress, start),
+				retentionBackup("failed", velerov1api.BackupPhaseFailed, start),
+				retentionBackup("a", velerov1api.BackupPhaseCompleted, start.Add(-time.Hour)),
+				retentionBackup("b", velerov1api.BackupPhaseCompleted, start.Add(-2*time.Hour)),
+			},
+			expected: []string{"b"},
+		},
+		{
+			name:   "keep daily keeps the newest backup of each day",
+			policy: &velerov1api.ScheduleRetentionPolicy{KeepDaily: 2},
+			backups: []*velerov1api.Backup{
+				retentionBackup("today-late", velerov1api.BackupPhaseCompleted, start.Add(12*time.Hour)),
+				retentionBackup("today-early", velerov1api.BackupPhaseCompleted, start),
+				retentionBackup("yesterday", velerov1api.BackupPhaseCompleted, start.AddDate(0, 0, -1)),
+				retentionBackup("two-days-ago", velerov1api.BackupPhaseCompleted, start.AddDate(0, 0, -2)),
+			},
+			expected: []string{"today-early", "two-days-ago"},
+		},
+		{
+			name:    "daily, weekly and monthly rules are combined",
+			policy:  &velerov1api.ScheduleRetentionPolicy{KeepDaily: 3, KeepWeekly: 2, KeepMonthly: 3},
+			backups: daily,
+			// dailies: days 0-2 (Sun 30 Jun, Sat 29 Jun, Fri 28 Jun)
+			// weeklies: day 0 (ISO week 26) and day 7 (Sun 23 Jun, week 25)
+			// monthlies: day 0 (June), day 30 (31 May) and day 61 (30 Apr)
+			expected: exclude(daily, 0, 1, 2, 7, 30, 61),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ElementsMatch(t, test.expected, BackupsToDelete(test.policy, test.backups).List())
+		})
+	}
+}
+
+func retentionBackup(name string, phase velerov1api.BackupPhase, started time.Time) *velerov1api.Backup {
+	return builder.ForBackup(velerov1api.DefaultNamespace, name).Phase(phase).StartTimestamp(started).Result()
+}
+
+func dayName(day int) string {
+	return fmt.Sprintf("day-%d", day)
+}
+
+// exclude returns the names of the backups other than those at the given
+// indices.
+func exclude(backups []*velerov1api.Backup, indices ...int) []string {
+	skip := make(map[int]bool)
+	for _, i := range indices {
+		skip[i] = true
+	}
+
+	var names []string
+	for i, backup := range backups {
+		if !skip[i] {
+			names = append(names, backup.Name)
+		}
+	}
+	return names
+}
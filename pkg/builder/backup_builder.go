@@ -121,6 +121,18 @@ func (b *BackupBuilder) Phase(phase velerov1api.BackupPhase) *BackupBuilder {
 	return b
 }
 
+// CompressionCodec sets the codec the Backup's tarball was compressed with.
+func (b *BackupBuilder) CompressionCodec(codec velerov1api.CompressionCodec) *BackupBuilder {
+	b.object.Status.CompressionCodec = codec
+	return b
+}
+
+// QueuePosition sets the Backup's queue position.
+func (b *BackupBuilder) QueuePosition(val int) *BackupBuilder {
+	b.object.Status.QueuePosition = val
+	return b
+}
+
 // StorageLocation sets the Backup's storage location.
 func (b *BackupBuilder) StorageLocation(location string) *BackupBuilder {
 	b.object.Spec.StorageLocation = location
@@ -133,6 +145,25 @@ func (b *BackupBuilder) VolumeSnapshotLocations(locations ...string) *BackupBuil
 	return b
 }
 
+// ItemBackupWorkers sets the number of items of each resource that the Backup
+// backs up concurrently.
+func (b *BackupBuilder) ItemBackupWorkers(val int) *BackupBuilder {
+	b.object.Spec.ItemBackupWorkers = val
+	return b
+}
+
+// IncludeAllAPIGroupVersions sets the Backup's "include all API group versions" flag.
+func (b *BackupBuilder) IncludeAllAPIGroupVersions(val bool) *BackupBuilder {
+	b.object.Spec.IncludeAllAPIGroupVersions = val
+	return b
+}
+
+// Cancel sets the Backup's cancel flag.
+func (b *BackupBuilder) Cancel(val bool) *BackupBuilder {
+	b.object.Spec.Cancel = val
+	return b
+}
+
 // TTL sets the Backup's TTL.
 func (b *BackupBuilder) TTL(ttl time.Duration) *BackupBuilder {
 	b.object.Spec.TTL.Duration = ttl
@@ -162,3 +193,12 @@ func (b *BackupBuilder) Hooks(hooks velerov1api.BackupHooks) *BackupBuilder {
 	b.object.Spec.Hooks = hooks
 	return b
 }
+
+// Compression sets the Backup's compression codec and level.
+func (b *BackupBuilder) Compression(codec velerov1api.CompressionCodec, level int) *BackupBuilder {
+	b.object.Spec.Compression = &velerov1api.CompressionConfig{
+		Codec: codec,
+		Level: level,
+	}
+	return b
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package builder
 
 import (
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -86,3 +87,50 @@ func (b *BackupStorageLocationBuilder) AccessMode(accessMode velerov1api.BackupS
 	b.object.Spec.AccessMode = accessMode
 	return b
 }
+
+// EncryptionKeySecret sets the key of the secret that holds the BackupStorageLocation's encryption key.
+func (b *BackupStorageLocationBuilder) EncryptionKeySecret(name, key string) *BackupStorageLocationBuilder {
+	b.object.Spec.Encryption = &velerov1api.EncryptionConfig{
+		KeySecret: corev1api.SecretKeySelector{
+			LocalObjectReference: corev1api.LocalObjectReference{Name: name},
+			Key:                  key,
+		},
+	}
+	return b
+}
+
+// Credential sets the key of the secret that holds the BackupStorageLocation's credentials.
+func (b *BackupStorageLocationBuilder) Credential(name, key string) *BackupStorageLocationBuilder {
+	b.object.Spec.Credential = &corev1api.SecretKeySelector{
+		LocalObjectReference: corev1api.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+	return b
+}
+
+// MaxConcurrentBackups sets the maximum number of backups to the BackupStorageLocation that can run at the same time.
+func (b *BackupStorageLocationBuilder) MaxConcurrentBackups(val int) *BackupStorageLocationBuilder {
+	b.object.Spec.MaxConcurrentBackups = val
+	return b
+}
+
+// Compression sets the compression codec and level for the BackupStorageLocation's backups.
+func (b *BackupStorageLocationBuilder) Compression(codec velerov1api.CompressionCodec, level int) *BackupStorageLocationBuilder {
+	b.object.Spec.Compression = &velerov1api.CompressionConfig{
+		Codec: codec,
+		Level: level,
+	}
+	return b
+}
+
+// Phase sets the BackupStorageLocation's phase.
+func (b *BackupStorageLocationBuilder) Phase(phase velerov1api.BackupStorageLocationPhase) *BackupStorageLocationBuilder {
+	b.object.Status.Phase = phase
+	return b
+}
+
+// Message sets the BackupStorageLocation's status message.
+func (b *BackupStorageLocationBuilder) Message(message string) *BackupStorageLocationBuilder {
+	b.object.Status.Message = message
+	return b
+}
@@ -76,3 +76,25 @@ func (b *PodBuilder) InitContainers(containers ...*corev1api.Container) *PodBuil
 	}
 	return b
 }
+
+// Containers appends to the pod's containers
+func (b *PodBuilder) Containers(containers ...*corev1api.Container) *PodBuilder {
+	for _, c := range containers {
+		b.object.Spec.Containers = append(b.object.Spec.Containers, *c)
+	}
+	return b
+}
+
+// Phase sets the pod's phase
+func (b *PodBuilder) Phase(val corev1api.PodPhase) *PodBuilder {
+	b.object.Status.Phase = val
+	return b
+}
+
+// ContainerStatuses appends to the pod's container statuses
+func (b *PodBuilder) ContainerStatuses(statuses ...*corev1api.ContainerStatus) *PodBuilder {
+	for _, s := range statuses {
+		b.object.Status.ContainerStatuses = append(b.object.Status.ContainerStatuses, *s)
+	}
+	return b
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package builder
 
 import (
+	"time"
+
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -93,6 +96,12 @@ func (b *RestoreBuilder) ExcludedResources(resources ...string) *RestoreBuilder
 	return b
 }
 
+// IncludedItems appends to the Restore's included items.
+func (b *RestoreBuilder) IncludedItems(items ...string) *RestoreBuilder {
+	b.object.Spec.IncludedItems = append(b.object.Spec.IncludedItems, items...)
+	return b
+}
+
 // IncludeClusterResources sets the Restore's "include cluster resources" flag.
 func (b *RestoreBuilder) IncludeClusterResources(val bool) *RestoreBuilder {
 	b.object.Spec.IncludeClusterResources = &val
@@ -133,3 +142,39 @@ func (b *RestoreBuilder) RestorePVs(val bool) *RestoreBuilder {
 	b.object.Spec.RestorePVs = &val
 	return b
 }
+
+// ExistingResourcePolicy sets the Restore's existing resource policy.
+func (b *RestoreBuilder) ExistingResourcePolicy(policy velerov1api.ExistingResourcePolicyType) *RestoreBuilder {
+	b.object.Spec.ExistingResourcePolicy = policy
+	return b
+}
+
+// DryRun sets the Restore's dry run flag.
+func (b *RestoreBuilder) DryRun(val bool) *RestoreBuilder {
+	b.object.Spec.DryRun = val
+	return b
+}
+
+// ResourceModifiers sets the name of the ConfigMap holding the Restore's resource modifier rules.
+func (b *RestoreBuilder) ResourceModifiers(configMap string) *RestoreBuilder {
+	b.object.Spec.ResourceModifiers = &corev1api.LocalObjectReference{Name: configMap}
+	return b
+}
+
+// Cancel sets the Restore's cancel flag.
+func (b *RestoreBuilder) Cancel(val bool) *RestoreBuilder {
+	b.object.Spec.Cancel = val
+	return b
+}
+
+// StartTimestamp sets the Restore's start timestamp.
+func (b *RestoreBuilder) StartTimestamp(val time.Time) *RestoreBuilder {
+	b.object.Status.StartTimestamp.Time = val
+	return b
+}
+
+// CompletionTimestamp sets the Restore's completion timestamp.
+func (b *RestoreBuilder) CompletionTimestamp(val time.Time) *RestoreBuilder {
+	b.object.Status.CompletionTimestamp.Time = val
+	return b
+}
@@ -89,3 +89,28 @@ func (b *ScheduleBuilder) Template(spec velerov1api.BackupSpec) *ScheduleBuilder
 	b.object.Spec.Template = spec
 	return b
 }
+
+// Paused sets the Schedule's paused flag.
+func (b *ScheduleBuilder) Paused(paused bool) *ScheduleBuilder {
+	b.object.Spec.Paused = paused
+	return b
+}
+
+// PausedAt sets the time the Schedule was recorded as paused.
+func (b *ScheduleBuilder) PausedAt(val time.Time) *ScheduleBuilder {
+	t := metav1.NewTime(val)
+	b.object.Status.PausedAt = &t
+	return b
+}
+
+// PausedBy sets who the Schedule was recorded as paused by.
+func (b *ScheduleBuilder) PausedBy(val string) *ScheduleBuilder {
+	b.object.Status.PausedBy = val
+	return b
+}
+
+// Retention sets the Schedule's retention policy.
+func (b *ScheduleBuilder) Retention(policy velerov1api.ScheduleRetentionPolicy) *ScheduleBuilder {
+	b.object.Spec.Retention = &policy
+	return b
+}
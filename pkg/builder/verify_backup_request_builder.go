@@ -0,0 +1,78 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// VerifyBackupRequestBuilder builds VerifyBackupRequest objects.
+type VerifyBackupRequestBuilder struct {
+	object *velerov1api.VerifyBackupRequest
+}
+
+// ForVerifyBackupRequest is the constructor for a VerifyBackupRequestBuilder.
+func ForVerifyBackupRequest(ns, name string) *VerifyBackupRequestBuilder {
+	return &VerifyBackupRequestBuilder{
+		object: &velerov1api.VerifyBackupRequest{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: velerov1api.SchemeGroupVersion.String(),
+				Kind:       "VerifyBackupRequest",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns,
+				Name:      name,
+			},
+		},
+	}
+}
+
+// Result returns the built VerifyBackupRequest.
+func (b *VerifyBackupRequestBuilder) Result() *velerov1api.VerifyBackupRequest {
+	return b.object
+}
+
+// ObjectMeta applies functional options to the VerifyBackupRequest's ObjectMeta.
+func (b *VerifyBackupRequestBuilder) ObjectMeta(opts ...ObjectMetaOpt) *VerifyBackupRequestBuilder {
+	for _, opt := range opts {
+		opt(b.object)
+	}
+
+	return b
+}
+
+// BackupName sets the name of the backup to verify.
+func (b *VerifyBackupRequestBuilder) BackupName(name string) *VerifyBackupRequestBuilder {
+	b.object.Spec.BackupName = name
+	return b
+}
+
+// Phase sets the VerifyBackupRequest's phase.
+func (b *VerifyBackupRequestBuilder) Phase(phase velerov1api.VerifyBackupRequestPhase) *VerifyBackupRequestBuilder {
+	b.object.Status.Phase = phase
+	return b
+}
+
+// ProcessedTimestamp sets the VerifyBackupRequest's processed timestamp.
+func (b *VerifyBackupRequestBuilder) ProcessedTimestamp(time time.Time) *VerifyBackupRequestBuilder {
+	b.object.Status.ProcessedTimestamp.Time = time
+	return b
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package builder
 
 import (
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -62,3 +63,12 @@ func (b *VolumeSnapshotLocationBuilder) Provider(name string) *VolumeSnapshotLoc
 	b.object.Spec.Provider = name
 	return b
 }
+
+// Credential sets the key of the secret that holds the VolumeSnapshotLocation's credentials.
+func (b *VolumeSnapshotLocationBuilder) Credential(name, key string) *VolumeSnapshotLocationBuilder {
+	b.object.Spec.Credential = &corev1api.SecretKeySelector{
+		LocalObjectReference: corev1api.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+	return b
+}
@@ -50,6 +50,32 @@ func Config(kubeconfig, kubecontext, baseName string) (*rest.Config, error) {
 	return clientConfig, nil
 }
 
+// ConfigUser returns the name of the kubeconfig user for the given context (or
+// the current context, if none is given). It returns an empty string if there's
+// no such context, for example when using an in-cluster configuration. The name
+// is taken from the client's kubeconfig, so it's not verified by the apiserver.
+func ConfigUser(kubeconfig, kubecontext string) (string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: kubecontext}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	rawConfig, err := kubeConfig.RawConfig()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	contextName := kubecontext
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+
+	context, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		return "", nil
+	}
+	return context.AuthInfo, nil
+}
+
 // buildUserAgent builds a User-Agent string from given args.
 func buildUserAgent(command, version, formattedSha, os, arch string) string {
 	return fmt.Sprintf(
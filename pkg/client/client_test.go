@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -16,9 +16,13 @@ limitations under the License.
 package client
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildUserAgent(t *testing.T) {
@@ -49,3 +53,64 @@ func TestBuildUserAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigUser(t *testing.T) {
+	dir, err := ioutil.TempDir("", "velero-client-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	require.NoError(t, ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: context-1
+clusters:
+- name: cluster-1
+  cluster:
+    server: https://cluster-1
+contexts:
+- name: context-1
+  context:
+    cluster: cluster-1
+    user: user-1
+- name: context-2
+  context:
+    cluster: cluster-1
+    user: user-2
+users:
+- name: user-1
+  user:
+    token: token-1
+- name: user-2
+  user:
+    token: token-2
+`), 0600))
+
+	tests := []struct {
+		name        string
+		kubecontext string
+		expected    string
+	}{
+		{
+			name:     "current context's user is returned when no context is given",
+			expected: "user-1",
+		},
+		{
+			name:        "given context's user is returned",
+			kubecontext: "context-2",
+			expected:    "user-2",
+		},
+		{
+			name:        "empty string is returned for a context that doesn't exist",
+			kubecontext: "context-3",
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := ConfigUser(kubeconfig, test.kubecontext)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, user)
+		})
+	}
+}
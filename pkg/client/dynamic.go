@@ -81,6 +81,12 @@ type Patcher interface {
 	Patch(name string, data []byte) (*unstructured.Unstructured, error)
 }
 
+// Deleter deletes an object.
+type Deleter interface {
+	// Delete deletes the named object.
+	Delete(name string, opts *metav1.DeleteOptions) error
+}
+
 // Dynamic contains client methods that Velero needs for backing up and restoring resources.
 type Dynamic interface {
 	Creator
@@ -88,6 +94,7 @@ type Dynamic interface {
 	Watcher
 	Getter
 	Patcher
+	Deleter
 }
 
 // dynamicResourceClient implements Dynamic.
@@ -116,3 +123,7 @@ func (d *dynamicResourceClient) Get(name string, opts metav1.GetOptions) (*unstr
 func (d *dynamicResourceClient) Patch(name string, data []byte) (*unstructured.Unstructured, error) {
 	return d.resourceClient.Patch(name, types.MergePatchType, data, metav1.PatchOptions{})
 }
+
+func (d *dynamicResourceClient) Delete(name string, opts *metav1.DeleteOptions) error {
+	return d.resourceClient.Delete(name, opts)
+}
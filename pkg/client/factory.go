@@ -42,6 +42,9 @@ type Factory interface {
 	// DynamicClient returns a Kubernetes dynamic client. It uses the following priority to specify the cluster
 	// configuration: --kubeconfig flag, KUBECONFIG environment variable, in-cluster configuration.
 	DynamicClient() (dynamic.Interface, error)
+	// User returns the name of the kubeconfig user for the --kubecontext context, or the
+	// kubeconfig's current context. It returns an empty string if there's no such context.
+	User() (string, error)
 	Namespace() string
 }
 
@@ -120,6 +123,10 @@ func (f *factory) DynamicClient() (dynamic.Interface, error) {
 	return dynamicClient, nil
 }
 
+func (f *factory) User() (string, error) {
+	return ConfigUser(f.kubeconfig, f.kubecontext)
+}
+
 func (f *factory) Namespace() string {
 	return f.namespace
 }
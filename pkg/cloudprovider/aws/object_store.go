@@ -17,6 +17,7 @@ limitations under the License.
 package aws
 
 import (
+	"fmt"
 	"io"
 	"sort"
 	"strconv"
@@ -43,6 +44,12 @@ const (
 	bucketKey            = "bucket"
 	signatureVersionKey  = "signatureVersion"
 	credentialProfileKey = "profile"
+
+	// uploadPartSize is the size of the parts objects are uploaded in.
+	// Backup tarballs are streamed to S3 as they're written, so their size
+	// isn't known up front, and S3 allows at most 10,000 parts per upload;
+	// this allows for tarballs of up to ~160GB.
+	uploadPartSize = 16 * 1024 * 1024
 )
 
 type s3Interface interface {
@@ -95,6 +102,7 @@ func (o *ObjectStore) Init(config map[string]string) error {
 		s3ForcePathStyleVal = config[s3ForcePathStyleKey]
 		signatureVersion    = config[signatureVersionKey]
 		credentialProfile   = config[credentialProfileKey]
+		credentialsFile     = config[cloudprovider.CredentialsFileKey]
 
 		// note that bucket is automatically added to the config map
 		// by the server from the ObjectStorageProviderConfig so
@@ -127,13 +135,15 @@ func (o *ObjectStore) Init(config map[string]string) error {
 		return err
 	}
 
-	serverSession, err := getSession(serverConfig, credentialProfile)
+	serverSession, err := getSession(serverConfig, credentialProfile, credentialsFile)
 	if err != nil {
 		return err
 	}
 
 	o.s3 = s3.New(serverSession)
-	o.s3Uploader = s3manager.NewUploader(serverSession)
+	o.s3Uploader = s3manager.NewUploader(serverSession, func(u *s3manager.Uploader) {
+		u.PartSize = uploadPartSize
+	})
 	o.kmsKeyID = kmsKeyID
 
 	if signatureVersion != "" {
@@ -148,7 +158,7 @@ func (o *ObjectStore) Init(config map[string]string) error {
 		if err != nil {
 			return err
 		}
-		publicSession, err := getSession(publicConfig, credentialProfile)
+		publicSession, err := getSession(publicConfig, credentialProfile, credentialsFile)
 		if err != nil {
 			return err
 		}
@@ -186,6 +196,9 @@ func newAWSConfig(url, region string, forcePathStyle bool) (*aws.Config, error)
 	return awsConfig, nil
 }
 
+// PutObject uploads body to the bucket in parts as it's read. If reading
+// body or uploading a part fails, the multipart upload is aborted so no
+// partial object is left behind.
 func (o *ObjectStore) PutObject(bucket, key string, body io.Reader) error {
 	req := &s3manager.UploadInput{
 		Bucket: &bucket,
@@ -260,6 +273,21 @@ func (o *ObjectStore) GetObject(bucket, key string) (io.ReadCloser, error) {
 	return res.Body, nil
 }
 
+func (o *ObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	req := &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	}
+
+	res, err := o.s3.GetObject(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting range of object %s", key)
+	}
+
+	return res.Body, nil
+}
+
 func (o *ObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	req := &s3.ListObjectsV2Input{
 		Bucket:    &bucket,
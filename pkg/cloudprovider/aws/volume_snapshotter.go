@@ -49,8 +49,15 @@ type VolumeSnapshotter struct {
 }
 
 // takes AWS credential config & a profile to create a new session
-func getSession(config *aws.Config, profile string) (*session.Session, error) {
+func getSession(config *aws.Config, profile, credentialsFile string) (*session.Session, error) {
 	sessionOptions := session.Options{Config: *config, Profile: profile}
+
+	// a location-specific credentials file takes the place of the
+	// server-wide one from AWS_SHARED_CREDENTIALS_FILE.
+	if credentialsFile != "" {
+		sessionOptions.SharedConfigFiles = []string{credentialsFile}
+	}
+
 	sess, err := session.NewSessionWithOptions(sessionOptions)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -74,13 +81,14 @@ func (b *VolumeSnapshotter) Init(config map[string]string) error {
 
 	region := config[regionKey]
 	credentialProfile := config[credentialProfileKey]
+	credentialsFile := config[cloudprovider.CredentialsFileKey]
 	if region == "" {
 		return errors.Errorf("missing %s in aws configuration", regionKey)
 	}
 
 	awsConfig := aws.NewConfig().WithRegion(region)
 
-	sess, err := getSession(awsConfig, credentialProfile)
+	sess, err := getSession(awsConfig, credentialProfile, credentialsFile)
 	if err != nil {
 		return err
 	}
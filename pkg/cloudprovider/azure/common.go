@@ -24,6 +24,8 @@ import (
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
+
+	"github.com/heptio/velero/pkg/cloudprovider"
 )
 
 const (
@@ -63,6 +65,27 @@ func loadEnv() error {
 	return nil
 }
 
+// getCredentialsLookup returns a function for looking up credential values.
+// If config has a location-specific credentials file, values are read from it;
+// otherwise they're read from the environment after loading $AZURE_CREDENTIALS_FILE.
+func getCredentialsLookup(config map[string]string) (func(string) string, error) {
+	if credentialsFile := config[cloudprovider.CredentialsFileKey]; credentialsFile != "" {
+		creds, err := godotenv.Read(credentialsFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error loading credentials file %s", credentialsFile)
+		}
+
+		return mapLookup(creds), nil
+	}
+
+	// load environment vars from $AZURE_CREDENTIALS_FILE, if it exists
+	if err := loadEnv(); err != nil {
+		return nil, err
+	}
+
+	return os.Getenv, nil
+}
+
 func newServicePrincipalToken(tenantID, clientID, clientSecret, scope string) (*adal.ServicePrincipalToken, error) {
 	oauthConfig, err := adal.NewOAuthConfig(azure.PublicCloud.ActiveDirectoryEndpoint, tenantID)
 	if err != nil {
@@ -18,8 +18,9 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"io"
-	"os"
 	"strings"
 	"time"
 
@@ -35,6 +36,12 @@ import (
 
 const (
 	storageAccountConfigKey = "storageAccount"
+
+	// blockSize is the size of the blocks objects are uploaded in. Backup
+	// tarballs are streamed to Azure as they're written, so their size isn't
+	// known up front, and a block blob can have at most 50,000 blocks; this
+	// allows for tarballs of up to ~800GB.
+	blockSize = 16 * 1024 * 1024
 )
 
 type containerGetter interface {
@@ -93,9 +100,11 @@ func (bg *azureBlobGetter) getBlob(bucket, key string) (blob, error) {
 }
 
 type blob interface {
-	CreateBlockBlobFromReader(blob io.Reader, options *storage.PutBlobOptions) error
+	PutBlock(blockID string, chunk []byte, options *storage.PutBlockOptions) error
+	PutBlockList(blocks []storage.Block, options *storage.PutBlockListOptions) error
 	Exists() (bool, error)
 	Get(options *storage.GetBlobOptions) (io.ReadCloser, error)
+	GetRange(options *storage.GetBlobRangeOptions) (io.ReadCloser, error)
 	Delete(options *storage.DeleteBlobOptions) error
 	GetSASURI(options *storage.BlobSASOptions) (string, error)
 }
@@ -104,8 +113,12 @@ type azureBlob struct {
 	blob *storage.Blob
 }
 
-func (b *azureBlob) CreateBlockBlobFromReader(blob io.Reader, options *storage.PutBlobOptions) error {
-	return b.blob.CreateBlockBlobFromReader(blob, options)
+func (b *azureBlob) PutBlock(blockID string, chunk []byte, options *storage.PutBlockOptions) error {
+	return b.blob.PutBlock(blockID, chunk, options)
+}
+
+func (b *azureBlob) PutBlockList(blocks []storage.Block, options *storage.PutBlockListOptions) error {
+	return b.blob.PutBlockList(blocks, options)
 }
 
 func (b *azureBlob) Exists() (bool, error) {
@@ -116,6 +129,10 @@ func (b *azureBlob) Get(options *storage.GetBlobOptions) (io.ReadCloser, error)
 	return b.blob.Get(options)
 }
 
+func (b *azureBlob) GetRange(options *storage.GetBlobRangeOptions) (io.ReadCloser, error) {
+	return b.blob.GetRange(options)
+}
+
 func (b *azureBlob) Delete(options *storage.DeleteBlobOptions) error {
 	return b.blob.Delete(options)
 }
@@ -135,13 +152,13 @@ func NewObjectStore(logger logrus.FieldLogger) *ObjectStore {
 }
 
 func getStorageAccountKey(config map[string]string) (string, error) {
-	// load environment vars from $AZURE_CREDENTIALS_FILE, if it exists
-	if err := loadEnv(); err != nil {
+	getCredential, err := getCredentialsLookup(config)
+	if err != nil {
 		return "", err
 	}
 
 	// 1. we need AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_SUBSCRIPTION_ID
-	envVars, err := getRequiredValues(os.Getenv, tenantIDEnvVar, clientIDEnvVar, clientSecretEnvVar, subscriptionIDEnvVar)
+	envVars, err := getRequiredValues(getCredential, tenantIDEnvVar, clientIDEnvVar, clientSecretEnvVar, subscriptionIDEnvVar)
 	if err != nil {
 		return "", errors.Wrap(err, "unable to get all required environment variables")
 	}
@@ -220,13 +237,37 @@ func (o *ObjectStore) Init(config map[string]string) error {
 	return nil
 }
 
+// PutObject uploads body to the container in blocks as it's read, and
+// commits them as the blob once all of body has been uploaded. If reading
+// body or uploading a block fails, the blocks are never committed, and
+// Azure discards them.
 func (o *ObjectStore) PutObject(bucket, key string, body io.Reader) error {
 	blob, err := o.blobGetter.getBlob(bucket, key)
 	if err != nil {
 		return err
 	}
 
-	return errors.WithStack(blob.CreateBlockBlobFromReader(body, nil))
+	var blocks []storage.Block
+	buf := make([]byte, blockSize)
+	for {
+		n, readErr := io.ReadFull(body, buf)
+		if n > 0 {
+			// block IDs must all be the same length
+			id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%05d", len(blocks))))
+			if err := blob.PutBlock(id, buf[:n], nil); err != nil {
+				return errors.WithStack(err)
+			}
+			blocks = append(blocks, storage.Block{ID: id, Status: storage.BlockStatusUncommitted})
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return errors.Wrapf(readErr, "error reading object %s", key)
+		}
+	}
+
+	return errors.WithStack(blob.PutBlockList(blocks, nil))
 }
 
 func (o *ObjectStore) ObjectExists(bucket, key string) (bool, error) {
@@ -257,6 +298,28 @@ func (o *ObjectStore) GetObject(bucket, key string) (io.ReadCloser, error) {
 	return res, nil
 }
 
+func (o *ObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	blob, err := o.blobGetter.getBlob(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the range's end is inclusive
+	options := &storage.GetBlobRangeOptions{
+		Range: &storage.BlobRange{
+			Start: uint64(offset),
+			End:   uint64(offset + length - 1),
+		},
+	}
+
+	res, err := blob.GetRange(options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return res, nil
+}
+
 func (o *ObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	container, err := o.containerGetter.getContainer(bucket)
 	if err != nil {
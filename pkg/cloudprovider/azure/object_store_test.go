@@ -17,7 +17,11 @@ limitations under the License.
 package azure
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/storage"
@@ -95,6 +99,97 @@ func TestObjectExists(t *testing.T) {
 	}
 }
 
+func TestPutObject(t *testing.T) {
+	blockID := func(i int) string {
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%05d", i)))
+	}
+
+	tests := []struct {
+		name           string
+		body           io.Reader
+		putBlockError  error
+		expectedBlocks []int
+		expectedError  string
+	}{
+		{
+			name:           "empty object is committed with no blocks",
+			body:           strings.NewReader(""),
+			expectedBlocks: []int{},
+		},
+		{
+			name:           "small object is uploaded in one block",
+			body:           strings.NewReader("contents"),
+			expectedBlocks: []int{8},
+		},
+		{
+			name:           "large object is uploaded in multiple blocks",
+			body:           bytes.NewReader(make([]byte, blockSize+1)),
+			expectedBlocks: []int{blockSize, 1},
+		},
+		{
+			name:          "error reading body doesn't commit the blob",
+			body:          io.MultiReader(strings.NewReader("contents"), new(errorReader)),
+			expectedError: "error reading object k: bad read",
+		},
+		{
+			name:          "error uploading a block doesn't commit the blob",
+			body:          strings.NewReader("contents"),
+			putBlockError: errors.New("bad block"),
+			expectedError: "bad block",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			blobGetter := new(mockBlobGetter)
+			defer blobGetter.AssertExpectations(t)
+
+			o := &ObjectStore{
+				blobGetter: blobGetter,
+			}
+
+			blob := new(mockBlob)
+			defer blob.AssertExpectations(t)
+			blobGetter.On("getBlob", "b", "k").Return(blob, nil)
+
+			var blockSizes []int
+			blob.On("PutBlock", mock.Anything, mock.Anything, (*storage.PutBlockOptions)(nil)).Run(func(args mock.Arguments) {
+				assert.Equal(t, blockID(len(blockSizes)), args.String(0))
+				blockSizes = append(blockSizes, len(args.Get(1).([]byte)))
+			}).Return(tc.putBlockError).Maybe()
+
+			if tc.expectedError == "" {
+				var expected []storage.Block
+				for i := range tc.expectedBlocks {
+					expected = append(expected, storage.Block{ID: blockID(i), Status: storage.BlockStatusUncommitted})
+				}
+				blob.On("PutBlockList", expected, (*storage.PutBlockListOptions)(nil)).Return(nil)
+			}
+
+			err := o.PutObject("b", "k", tc.body)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				blob.AssertNotCalled(t, "PutBlockList", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+
+			if len(tc.expectedBlocks) == 0 {
+				assert.Empty(t, blockSizes)
+			} else {
+				assert.Equal(t, tc.expectedBlocks, blockSizes)
+			}
+		})
+	}
+}
+
+type errorReader struct{}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("bad read")
+}
+
 type mockBlobGetter struct {
 	mock.Mock
 }
@@ -108,8 +203,13 @@ type mockBlob struct {
 	mock.Mock
 }
 
-func (m *mockBlob) CreateBlockBlobFromReader(blob io.Reader, options *storage.PutBlobOptions) error {
-	args := m.Called(blob, options)
+func (m *mockBlob) PutBlock(blockID string, chunk []byte, options *storage.PutBlockOptions) error {
+	args := m.Called(blockID, chunk, options)
+	return args.Error(0)
+}
+
+func (m *mockBlob) PutBlockList(blocks []storage.Block, options *storage.PutBlockListOptions) error {
+	args := m.Called(blocks, options)
 	return args.Error(0)
 }
 
@@ -123,6 +223,11 @@ func (m *mockBlob) Get(options *storage.GetBlobOptions) (io.ReadCloser, error) {
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
+func (m *mockBlob) GetRange(options *storage.GetBlobRangeOptions) (io.ReadCloser, error) {
+	args := m.Called(options)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
 func (m *mockBlob) Delete(options *storage.DeleteBlobOptions) error {
 	args := m.Called(options)
 	return args.Error(0)
@@ -21,19 +21,27 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// CredentialsFileKey is the config key under which velero passes the path
+// of a file holding a location's credential to ObjectStore and
+// VolumeSnapshotter plugins. When it's set, plugins should use the
+// credentials in that file instead of the server-wide ones.
+const CredentialsFileKey = "credentialsFile"
+
 // ValidateObjectStoreConfigKeys ensures that an object store's config
 // is valid by making sure each `config` key is in the `validKeys` list.
-// The special key "bucket" is always considered valid.
+// The special keys "bucket" and "credentialsFile" are always considered valid.
 func ValidateObjectStoreConfigKeys(config map[string]string, validKeys ...string) error {
 	// `bucket` is automatically added to all object store config by
-	// velero, so add it as a valid key.
-	return validateConfigKeys(config, append(validKeys, "bucket")...)
+	// velero, as is `credentialsFile` when the location has a credential,
+	// so add them as valid keys.
+	return validateConfigKeys(config, append(validKeys, "bucket", CredentialsFileKey)...)
 }
 
 // ValidateVolumeSnapshotterConfigKeys ensures that a volume snapshotter's
 // config is valid by making sure each `config` key is in the `validKeys` list.
+// The special key "credentialsFile" is always considered valid.
 func ValidateVolumeSnapshotterConfigKeys(config map[string]string, validKeys ...string) error {
-	return validateConfigKeys(config, validKeys...)
+	return validateConfigKeys(config, append(validKeys, CredentialsFileKey)...)
 }
 
 func validateConfigKeys(config map[string]string, validKeys ...string) error {
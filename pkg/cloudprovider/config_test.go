@@ -34,4 +34,7 @@ func TestValidateConfigKeys(t *testing.T) {
 
 	assert.NoError(t, ValidateObjectStoreConfigKeys(map[string]string{"bucket": "foo"}))
 	assert.Error(t, ValidateVolumeSnapshotterConfigKeys(map[string]string{"bucket": "foo"}))
+
+	assert.NoError(t, ValidateObjectStoreConfigKeys(map[string]string{"bucket": "foo", CredentialsFileKey: "/creds"}))
+	assert.NoError(t, ValidateVolumeSnapshotterConfigKeys(map[string]string{CredentialsFileKey: "/creds"}))
 }
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/heptio/velero/pkg/util/signedurl"
+)
+
+const (
+	// SigningKeyEnvVar is the environment variable that holds the hex-encoded
+	// key used to sign and verify file server URLs. The Velero server sets it
+	// before starting any plugin processes so they inherit it.
+	SigningKeyEnvVar = "VELERO_FILE_SERVER_SIGNING_KEY"
+
+	rootParam = "root"
+)
+
+// EnsureSigningKey returns the key from SigningKeyEnvVar, generating a random
+// key and setting the environment variable to it if it's not already set.
+func EnsureSigningKey() ([]byte, error) {
+	if key := signingKeyFromEnv(); len(key) > 0 {
+		return key, nil
+	}
+
+	key, err := signedurl.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Setenv(SigningKeyEnvVar, hex.EncodeToString(key)); err != nil {
+		return nil, errors.Wrapf(err, "error setting %s", SigningKeyEnvVar)
+	}
+
+	return key, nil
+}
+
+func signingKeyFromEnv() []byte {
+	key, err := hex.DecodeString(os.Getenv(SigningKeyEnvVar))
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// signURL returns a URL on the file server at baseURL for the object, which is
+// valid until expires.
+func signURL(key []byte, baseURL, root, bucket, objectKey string, expires time.Time) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "error parsing %s", fileServerURLKey)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/" + objectKey
+
+	query := url.Values{}
+	query.Set(rootParam, root)
+	signedurl.Sign(key, query, expires, root, bucket, objectKey)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// FileServer is an http.Handler that serves objects from filesystem object
+// stores. Every request must be for a URL created by an ObjectStore's
+// CreateSignedURL with the same signing key, and must be made before the URL
+// expires.
+type FileServer struct {
+	key []byte
+	log logrus.FieldLogger
+}
+
+// NewFileServer returns a FileServer that verifies request URLs with key.
+func NewFileServer(key []byte, log logrus.FieldLogger) *FileServer {
+	return &FileServer{key: key, log: log}
+}
+
+func (s *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	bucket, objectKey := parts[0], parts[1]
+
+	query := r.URL.Query()
+	root := query.Get(rootParam)
+
+	switch err := signedurl.Verify(s.key, query, time.Now(), root, bucket, objectKey); err {
+	case nil:
+	case signedurl.ErrExpired:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	default:
+		s.log.WithField("path", r.URL.Path).Warn("Rejecting file server request with an invalid signature")
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	path, err := objectPath(filepath.Clean(root), bucket, objectKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		s.log.WithError(errors.WithStack(err)).WithField("path", path).Error("Error opening file")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+}
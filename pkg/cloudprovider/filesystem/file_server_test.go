@@ -0,0 +1,112 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestEnsureSigningKey(t *testing.T) {
+	defer os.Unsetenv(SigningKeyEnvVar)
+	os.Unsetenv(SigningKeyEnvVar)
+
+	key, err := EnsureSigningKey()
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+	assert.Equal(t, key, signingKeyFromEnv())
+
+	// an existing key is reused
+	again, err := EnsureSigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+}
+
+func TestFileServer(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	require.NoError(t, o.PutObject("bucket-1", "backups/backup-1/backup-1-logs.gz", strings.NewReader("logs")))
+
+	server := httptest.NewServer(NewFileServer([]byte("key"), velerotest.NewLogger()))
+	defer server.Close()
+
+	o.fileServerURL = server.URL
+	o.signingKey = []byte("key")
+
+	get := func(url string) (int, string) {
+		res, err := http.Get(url)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	signed, err := o.CreateSignedURL("bucket-1", "backups/backup-1/backup-1-logs.gz", time.Minute)
+	require.NoError(t, err)
+
+	status, body := get(signed)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "logs", body)
+
+	// a URL for a different object with the same signature is rejected
+	tampered := strings.Replace(signed, "backup-1-logs.gz", "backup-1.tar.gz", 1)
+	status, _ = get(tampered)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// changing the root directory invalidates the signature
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set(rootParam, "/")
+	u.RawQuery = query.Encode()
+	status, _ = get(u.String())
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// URLs signed with a different key are rejected
+	o.signingKey = []byte("other-key")
+	otherKey, err := o.CreateSignedURL("bucket-1", "backups/backup-1/backup-1-logs.gz", time.Minute)
+	require.NoError(t, err)
+	status, _ = get(otherKey)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// expired URLs are rejected
+	o.signingKey = []byte("key")
+	expired, err := o.CreateSignedURL("bucket-1", "backups/backup-1/backup-1-logs.gz", -time.Minute)
+	require.NoError(t, err)
+	status, _ = get(expired)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// objects that don't exist aren't found
+	missing, err := o.CreateSignedURL("bucket-1", "backups/backup-2/backup-2-logs.gz", time.Minute)
+	require.NoError(t, err)
+	status, _ = get(missing)
+	assert.Equal(t, http.StatusNotFound, status)
+}
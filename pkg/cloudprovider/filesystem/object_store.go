@@ -0,0 +1,331 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/heptio/velero/pkg/cloudprovider"
+)
+
+const (
+	rootKey          = "root"
+	fileServerURLKey = "fileServerURL"
+
+	// tempFilePrefix is the prefix of the temporary files that objects are
+	// written to before being renamed into place. Files with this prefix are
+	// never returned as objects.
+	tempFilePrefix = ".velero-tmp-"
+)
+
+// ObjectStore is an object store that maps buckets and keys onto a directory
+// tree, e.g. an NFS mount or a hostPath volume. Each bucket is a directory
+// directly under the configured root directory, and each key is a path
+// relative to its bucket's directory.
+type ObjectStore struct {
+	log           logrus.FieldLogger
+	root          string
+	fileServerURL string
+	signingKey    []byte
+}
+
+func NewObjectStore(logger logrus.FieldLogger) *ObjectStore {
+	return &ObjectStore{log: logger}
+}
+
+func (o *ObjectStore) Init(config map[string]string) error {
+	if err := cloudprovider.ValidateObjectStoreConfigKeys(config, rootKey, fileServerURLKey); err != nil {
+		return err
+	}
+
+	root := config[rootKey]
+	if root == "" {
+		return errors.Errorf("%s is required", rootKey)
+	}
+	if !filepath.IsAbs(root) {
+		return errors.Errorf("%s must be an absolute path", rootKey)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return errors.Wrapf(err, "error checking %s directory", rootKey)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("%s %s is not a directory", rootKey, root)
+	}
+
+	// the file server only serves TLS, since its URLs' signatures are bearer
+	// tokens until they expire
+	if fileServerURL := config[fileServerURLKey]; fileServerURL != "" {
+		u, err := url.Parse(fileServerURL)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing %s", fileServerURLKey)
+		}
+		if u.Scheme != "https" {
+			return errors.Errorf("%s must be an https URL", fileServerURLKey)
+		}
+	}
+
+	o.root = filepath.Clean(root)
+	o.fileServerURL = strings.TrimSuffix(config[fileServerURLKey], "/")
+	o.signingKey = signingKeyFromEnv()
+
+	return nil
+}
+
+// objectPath returns the path of the file that holds the object with the
+// given key, ensuring that it can't escape the bucket's directory.
+func objectPath(root, bucket, key string) (string, error) {
+	bucketDir, err := bucketDir(root, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(bucketDir, filepath.FromSlash(key))
+	if !isWithin(path, bucketDir) || strings.HasPrefix(filepath.Base(path), tempFilePrefix) {
+		return "", errors.Errorf("invalid key %q", key)
+	}
+
+	return path, nil
+}
+
+// PutObject writes body to a temporary file in the object's directory and then
+// renames it into place, so readers never see a partially-written object.
+func (o *ObjectStore) PutObject(bucket, key string, body io.Reader) error {
+	path, err := objectPath(o.root, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "error creating directory for object %s", key)
+	}
+
+	tmp, err := ioutil.TempFile(dir, tempFilePrefix)
+	if err != nil {
+		return errors.Wrapf(err, "error creating temp file for object %s", key)
+	}
+	// this is a no-op once the temp file has been renamed
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "error writing object %s", key)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "error syncing object %s", key)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "error closing object %s", key)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrapf(err, "error setting permissions of object %s", key)
+	}
+
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "error putting object %s", key)
+}
+
+// ObjectExists checks if there is an object with the given key in the object storage bucket.
+func (o *ObjectStore) ObjectExists(bucket, key string) (bool, error) {
+	path, err := objectPath(o.root, bucket, key)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return !info.IsDir(), nil
+}
+
+func (o *ObjectStore) GetObject(bucket, key string) (io.ReadCloser, error) {
+	path, err := objectPath(o.root, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting object %s", key)
+	}
+
+	return file, nil
+}
+
+func (o *ObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	path, err := objectPath(o.root, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting object %s", key)
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "error getting object %s", key)
+	}
+
+	return &rangeReader{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+func (o *ObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
+	keys, err := o.ListObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := sets.NewString()
+	for _, key := range keys {
+		afterPrefix := key[len(prefix):]
+
+		delimiterStart := strings.Index(afterPrefix, delimiter)
+		if delimiterStart == -1 {
+			continue
+		}
+
+		prefixes.Insert(prefix + afterPrefix[0:delimiterStart] + delimiter)
+	}
+
+	return prefixes.List(), nil
+}
+
+func (o *ObjectStore) ListObjects(bucket, prefix string) ([]string, error) {
+	bucketDir, err := bucketDir(o.root, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// only walk the deepest directory that can contain keys with the prefix
+	walkDir := bucketDir
+	if i := strings.LastIndex(prefix, "/"); i != -1 {
+		walkDir = filepath.Join(bucketDir, filepath.FromSlash(prefix[0:i]))
+		if walkDir != bucketDir && !isWithin(walkDir, bucketDir) {
+			return nil, errors.Errorf("invalid prefix %q", prefix)
+		}
+	}
+
+	var ret []string
+	err = filepath.Walk(walkDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the prefix's directory not existing just means there are no matching keys
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if info.IsDir() || strings.HasPrefix(info.Name(), tempFilePrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			ret = append(ret, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// DeleteObject removes the object's file and any directories that are left
+// empty by removing it, up to the bucket's directory.
+func (o *ObjectStore) DeleteObject(bucket, key string) error {
+	path, err := objectPath(o.root, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error deleting object %s", key)
+	}
+
+	bucketDir := filepath.Join(o.root, bucket)
+	for dir := filepath.Dir(path); dir != bucketDir; dir = filepath.Dir(dir) {
+		// os.Remove fails for non-empty directories, which is the signal to stop
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// CreateSignedURL returns a URL on the Velero server's file server that can be
+// used to download the object until ttl has elapsed.
+func (o *ObjectStore) CreateSignedURL(bucket, key string, ttl time.Duration) (string, error) {
+	if o.fileServerURL == "" {
+		return "", errors.Errorf("%s must be configured to create signed URLs", fileServerURLKey)
+	}
+	if len(o.signingKey) == 0 {
+		return "", errors.New("the Velero server's file server is not enabled")
+	}
+
+	if _, err := objectPath(o.root, bucket, key); err != nil {
+		return "", err
+	}
+
+	return signURL(o.signingKey, o.fileServerURL, o.root, bucket, key, time.Now().Add(ttl))
+}
+
+func bucketDir(root, bucket string) (string, error) {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\`) {
+		return "", errors.Errorf("invalid bucket name %q", bucket)
+	}
+
+	return filepath.Join(root, bucket), nil
+}
+
+// isWithin returns true if path is strictly inside dir.
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
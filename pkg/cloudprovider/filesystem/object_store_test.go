@@ -0,0 +1,208 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func newTestObjectStore(t *testing.T) (*ObjectStore, func()) {
+	root, err := ioutil.TempDir("", "velero-filesystem-object-store")
+	require.NoError(t, err)
+
+	o := NewObjectStore(velerotest.NewLogger())
+	require.NoError(t, o.Init(map[string]string{"root": root, "bucket": "bucket-1"}))
+
+	return o, func() { os.RemoveAll(root) }
+}
+
+func TestInit(t *testing.T) {
+	o := NewObjectStore(velerotest.NewLogger())
+
+	assert.Error(t, o.Init(map[string]string{}))
+	assert.Error(t, o.Init(map[string]string{"root": "relative/path"}))
+	assert.Error(t, o.Init(map[string]string{"root": "/does/not/exist"}))
+	assert.Error(t, o.Init(map[string]string{"root": os.TempDir(), "invalid": "key"}))
+	assert.Error(t, o.Init(map[string]string{"root": os.TempDir(), "fileServerURL": "http://velero:8085"}))
+	assert.NoError(t, o.Init(map[string]string{"root": os.TempDir(), "fileServerURL": "https://velero:8085"}))
+}
+
+func TestPutGetAndDeleteObject(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	exists, err := o.ObjectExists("bucket-1", "backups/backup-1/backup-1.tar.gz")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, o.PutObject("bucket-1", "backups/backup-1/backup-1.tar.gz", strings.NewReader("contents")))
+	// putting an existing object replaces it
+	require.NoError(t, o.PutObject("bucket-1", "backups/backup-1/backup-1.tar.gz", strings.NewReader("new contents")))
+
+	exists, err = o.ObjectExists("bucket-1", "backups/backup-1/backup-1.tar.gz")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	rc, err := o.GetObject("bucket-1", "backups/backup-1/backup-1.tar.gz")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	rc.Close()
+	assert.Equal(t, "new contents", string(data))
+
+	// no temp files are left behind
+	files, err := ioutil.ReadDir(filepath.Join(o.root, "bucket-1", "backups", "backup-1"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "backup-1.tar.gz", files[0].Name())
+
+	require.NoError(t, o.DeleteObject("bucket-1", "backups/backup-1/backup-1.tar.gz"))
+	exists, err = o.ObjectExists("bucket-1", "backups/backup-1/backup-1.tar.gz")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// directories left empty are removed, but the bucket's directory is kept
+	_, err = os.Stat(filepath.Join(o.root, "bucket-1", "backups"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(o.root, "bucket-1"))
+	assert.NoError(t, err)
+
+	// deleting an object that doesn't exist isn't an error
+	assert.NoError(t, o.DeleteObject("bucket-1", "backups/backup-1/backup-1.tar.gz"))
+}
+
+func TestGetObjectRange(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	require.NoError(t, o.PutObject("bucket-1", "key", strings.NewReader("0123456789")))
+
+	tests := []struct {
+		offset, length int64
+		expected       string
+	}{
+		{offset: 0, length: 4, expected: "0123"},
+		{offset: 3, length: 5, expected: "34567"},
+		{offset: 8, length: 10, expected: "89"},
+	}
+
+	for _, tc := range tests {
+		rc, err := o.GetObjectRange("bucket-1", "key", tc.offset, tc.length)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		assert.Equal(t, tc.expected, string(data), "offset=%d length=%d", tc.offset, tc.length)
+	}
+}
+
+func TestInvalidBucketsAndKeys(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	tests := []struct {
+		bucket, key string
+	}{
+		{bucket: "", key: "key"},
+		{bucket: "..", key: "key"},
+		{bucket: "bucket/nested", key: "key"},
+		{bucket: "bucket-1", key: ""},
+		{bucket: "bucket-1", key: "../bucket-2/key"},
+		{bucket: "bucket-1", key: "../../etc/passwd"},
+		{bucket: "bucket-1", key: "dir/" + tempFilePrefix + "123"},
+	}
+
+	for _, tc := range tests {
+		assert.Error(t, o.PutObject(tc.bucket, tc.key, bytes.NewReader(nil)), "bucket=%q key=%q", tc.bucket, tc.key)
+		_, err := o.GetObject(tc.bucket, tc.key)
+		assert.Error(t, err, "bucket=%q key=%q", tc.bucket, tc.key)
+	}
+
+	_, err := o.ListObjects("bucket-1", "../")
+	assert.Error(t, err)
+}
+
+func TestListObjectsAndCommonPrefixes(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	for _, key := range []string{
+		"a-prefix/foo-1/bar",
+		"a-prefix/foo-1/baz",
+		"a-prefix/foo-2/baz",
+		"a-prefix/other",
+		"some-other-prefix/foo-3/bar",
+	} {
+		require.NoError(t, o.PutObject("bucket-1", key, strings.NewReader(key)))
+	}
+
+	// an in-progress write isn't an object
+	require.NoError(t, ioutil.WriteFile(filepath.Join(o.root, "bucket-1", "a-prefix", tempFilePrefix+"123"), nil, 0644))
+
+	keys, err := o.ListObjects("bucket-1", "a-prefix/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-prefix/foo-1/bar", "a-prefix/foo-1/baz", "a-prefix/foo-2/baz", "a-prefix/other"}, keys)
+
+	keys, err = o.ListObjects("bucket-1", "a-prefix/foo-1/b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-prefix/foo-1/bar", "a-prefix/foo-1/baz"}, keys)
+
+	keys, err = o.ListObjects("bucket-1", "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	prefixes, err := o.ListCommonPrefixes("bucket-1", "a-prefix/", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-prefix/foo-1/", "a-prefix/foo-2/"}, prefixes)
+
+	prefixes, err = o.ListCommonPrefixes("bucket-1", "", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-prefix/", "some-other-prefix/"}, prefixes)
+
+	// buckets that don't have a directory yet are empty
+	prefixes, err = o.ListCommonPrefixes("bucket-2", "", "/")
+	require.NoError(t, err)
+	assert.Empty(t, prefixes)
+}
+
+func TestCreateSignedURL(t *testing.T) {
+	o, cleanup := newTestObjectStore(t)
+	defer cleanup()
+
+	_, err := o.CreateSignedURL("bucket-1", "key", time.Minute)
+	assert.Error(t, err, "no file server URL")
+
+	o.fileServerURL = "https://velero.velero.svc:8085"
+	_, err = o.CreateSignedURL("bucket-1", "key", time.Minute)
+	assert.Error(t, err, "no signing key")
+
+	o.signingKey = []byte("key")
+	url, err := o.CreateSignedURL("bucket-1", "backups/backup-1/backup-1.tar.gz", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, "https://velero.velero.svc:8085/bucket-1/backups/backup-1/backup-1.tar.gz?"), url)
+}
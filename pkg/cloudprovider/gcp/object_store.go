@@ -37,16 +37,23 @@ const credentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
 
 // bucketWriter wraps the GCP SDK functions for accessing object store so they can be faked for testing.
 type bucketWriter interface {
-	// getWriteCloser returns an io.WriteCloser that can be used to upload data to the specified bucket for the specified key.
-	getWriteCloser(bucket, key string) io.WriteCloser
+	// getWriteCloser returns an objectWriter that can be used to upload data to the specified bucket for the specified key.
+	getWriteCloser(bucket, key string) objectWriter
 	getAttrs(bucket, key string) (*storage.ObjectAttrs, error)
 }
 
+// objectWriter uploads an object in chunks as it's written. Close commits
+// the object, while CloseWithError aborts the upload.
+type objectWriter interface {
+	io.WriteCloser
+	CloseWithError(err error) error
+}
+
 type writer struct {
 	client *storage.Client
 }
 
-func (w *writer) getWriteCloser(bucket, key string) io.WriteCloser {
+func (w *writer) getWriteCloser(bucket, key string) objectWriter {
 	return w.client.Bucket(bucket).Object(key).NewWriter(context.Background())
 }
 
@@ -71,7 +78,7 @@ func (o *ObjectStore) Init(config map[string]string) error {
 		return err
 	}
 
-	credentialsFile := os.Getenv(credentialsEnvVar)
+	credentialsFile := getCredentialsFile(config)
 	if credentialsFile == "" {
 		return errors.Errorf("%s is undefined", credentialsEnvVar)
 	}
@@ -95,7 +102,12 @@ func (o *ObjectStore) Init(config map[string]string) error {
 	o.googleAccessID = jwtConfig.Email
 	o.privateKey = jwtConfig.PrivateKey
 
-	client, err := storage.NewClient(context.Background(), option.WithScopes(storage.ScopeReadWrite))
+	clientOptions := []option.ClientOption{option.WithScopes(storage.ScopeReadWrite)}
+	if config[cloudprovider.CredentialsFileKey] != "" {
+		clientOptions = append(clientOptions, option.WithCredentialsJSON(creds))
+	}
+
+	client, err := storage.NewClient(context.Background(), clientOptions...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -106,20 +118,29 @@ func (o *ObjectStore) Init(config map[string]string) error {
 	return nil
 }
 
+// getCredentialsFile returns the location-specific credentials file from
+// config if there is one, or the server-wide one from $GOOGLE_APPLICATION_CREDENTIALS.
+func getCredentialsFile(config map[string]string) string {
+	if credentialsFile := config[cloudprovider.CredentialsFileKey]; credentialsFile != "" {
+		return credentialsFile
+	}
+
+	return os.Getenv(credentialsEnvVar)
+}
+
 func (o *ObjectStore) PutObject(bucket, key string, body io.Reader) error {
 	w := o.bucketWriter.getWriteCloser(bucket, key)
 
 	// The writer returned by NewWriter is asynchronous, so errors aren't guaranteed
 	// until Close() is called
-	_, copyErr := io.Copy(w, body)
-
-	// Ensure we close w and report errors properly
-	closeErr := w.Close()
-	if copyErr != nil {
-		return copyErr
+	if _, err := io.Copy(w, body); err != nil {
+		// Abort the upload rather than closing w, which would commit
+		// whatever was written so far as the object.
+		w.CloseWithError(err)
+		return err
 	}
 
-	return closeErr
+	return w.Close()
 }
 
 func (o *ObjectStore) ObjectExists(bucket, key string) (bool, error) {
@@ -142,6 +163,15 @@ func (o *ObjectStore) GetObject(bucket, key string) (io.ReadCloser, error) {
 	return r, nil
 }
 
+func (o *ObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	r, err := o.client.Bucket(bucket).Object(key).NewRangeReader(context.Background(), offset, length)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return r, nil
+}
+
 func (o *ObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	q := &storage.Query{
 		Prefix:    prefix,
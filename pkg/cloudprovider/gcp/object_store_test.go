@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -17,7 +17,6 @@ package gcp
 
 import (
 	"errors"
-	"io"
 	"strings"
 	"testing"
 
@@ -31,12 +30,21 @@ import (
 type mockWriteCloser struct {
 	closeErr error
 	writeErr error
+
+	closed  bool
+	aborted bool
 }
 
 func (m *mockWriteCloser) Close() error {
+	m.closed = true
 	return m.closeErr
 }
 
+func (m *mockWriteCloser) CloseWithError(err error) error {
+	m.aborted = true
+	return nil
+}
+
 func (m *mockWriteCloser) Write(b []byte) (int, error) {
 	return len(b), m.writeErr
 }
@@ -55,7 +63,7 @@ func newFakeWriter(wc *mockWriteCloser) *fakeWriter {
 	return &fakeWriter{wc: wc}
 }
 
-func (fw *fakeWriter) getWriteCloser(bucket, name string) io.WriteCloser {
+func (fw *fakeWriter) getWriteCloser(bucket, name string) objectWriter {
 	return fw.wc
 }
 
@@ -102,6 +110,10 @@ func TestPutObject(t *testing.T) {
 
 			err := o.PutObject("bucket", "key", strings.NewReader("contents"))
 			assert.Equal(t, test.expectedErr, err)
+
+			// a failed upload is aborted rather than committed
+			assert.Equal(t, test.writeErr != nil, wc.aborted)
+			assert.Equal(t, test.writeErr == nil, wc.closed)
 		})
 	}
 }
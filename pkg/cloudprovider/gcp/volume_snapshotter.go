@@ -20,7 +20,6 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -62,7 +61,12 @@ func (b *VolumeSnapshotter) Init(config map[string]string) error {
 
 	b.snapshotLocation = config[snapshotLocationKey]
 
-	project, err := extractProjectFromCreds()
+	credsBytes, err := ioutil.ReadFile(getCredentialsFile(config))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	project, err := extractProjectFromCreds(credsBytes)
 	if err != nil {
 		return err
 	}
@@ -75,9 +79,18 @@ func (b *VolumeSnapshotter) Init(config map[string]string) error {
 		b.snapshotProject = b.volumeProject
 	}
 
-	client, err := google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
-	if err != nil {
-		return errors.WithStack(err)
+	var client *http.Client
+	if config[cloudprovider.CredentialsFileKey] != "" {
+		creds, err := google.CredentialsFromJSON(oauth2.NoContext, credsBytes, compute.ComputeScope)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		client = oauth2.NewClient(oauth2.NoContext, creds.TokenSource)
+	} else {
+		client, err = google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
+		if err != nil {
+			return errors.WithStack(err)
+		}
 	}
 
 	gce, err := compute.New(client)
@@ -90,12 +103,7 @@ func (b *VolumeSnapshotter) Init(config map[string]string) error {
 	return nil
 }
 
-func extractProjectFromCreds() (string, error) {
-	credsBytes, err := ioutil.ReadFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
-	if err != nil {
-		return "", errors.WithStack(err)
-	}
-
+func extractProjectFromCreds(credsBytes []byte) (string, error) {
 	type credentials struct {
 		ProjectID string `json:"project_id"`
 	}
@@ -123,15 +131,18 @@ func isMultiZone(volumeAZ string) bool {
 // parseRegion parses a failure-domain tag with multiple zones
 // and returns a single region. Zones are sperated by double underscores (__).
 // For example
-//     input: us-central1-a__us-central1-b
-//     return: us-central1
+//
+//	input: us-central1-a__us-central1-b
+//	return: us-central1
+//
 // When a custom storage class spans multiple geographical zones,
 // such as us-central1 and us-west1 only the zone matching the cluster is used
 // in the failure-domain tag.
 // For example
-//     Cluster nodes in us-central1-c, us-central1-f
-//     Storage class zones us-central1-a, us-central1-f, us-east1-a, us-east1-d
-//     The failure-domain tag would be: us-central1-a__us-central1-f
+//
+//	Cluster nodes in us-central1-c, us-central1-f
+//	Storage class zones us-central1-a, us-central1-f, us-east1-a, us-east1-d
+//	The failure-domain tag would be: us-central1-a__us-central1-f
 func parseRegion(volumeAZ string) (string, error) {
 	zones := strings.Split(volumeAZ, zoneSeparator)
 	zone := zones[0]
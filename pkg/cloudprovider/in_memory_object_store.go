@@ -94,6 +94,24 @@ func (o *InMemoryObjectStore) GetObject(bucket, key string) (io.ReadCloser, erro
 	return ioutil.NopCloser(bytes.NewReader(obj)), nil
 }
 
+func (o *InMemoryObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	bucketData, ok := o.Data[bucket]
+	if !ok {
+		return nil, errors.New("bucket not found")
+	}
+
+	obj, ok := bucketData[key]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+
+	if offset > int64(len(obj)) {
+		return nil, errors.New("offset is past the end of the object")
+	}
+
+	return ioutil.NopCloser(io.LimitReader(bytes.NewReader(obj[offset:]), length)), nil
+}
+
 func (o *InMemoryObjectStore) ListCommonPrefixes(bucket, prefix, delimiter string) ([]string, error) {
 	keys, err := o.ListObjects(bucket, prefix)
 	if err != nil {
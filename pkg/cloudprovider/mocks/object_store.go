@@ -84,6 +84,29 @@ func (_m *ObjectStore) GetObject(bucket string, key string) (io.ReadCloser, erro
 	return r0, r1
 }
 
+// GetObjectRange provides a mock function with given fields: bucket, key, offset, length
+func (_m *ObjectStore) GetObjectRange(bucket string, key string, offset int64, length int64) (io.ReadCloser, error) {
+	ret := _m.Called(bucket, key, offset, length)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string, int64, int64) io.ReadCloser); ok {
+		r0 = rf(bucket, key, offset, length)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, int64, int64) error); ok {
+		r1 = rf(bucket, key, offset, length)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields: config
 func (_m *ObjectStore) Init(config map[string]string) error {
 	ret := _m.Called(config)
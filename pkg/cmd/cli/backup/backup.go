@@ -36,6 +36,10 @@ func NewCommand(f client.Factory) *cobra.Command {
 		NewDescribeCommand(f, "describe"),
 		NewDownloadCommand(f),
 		NewDeleteCommand(f, "delete"),
+		NewCancelCommand(f, "cancel"),
+		NewVerifyCommand(f),
+		NewDiffCommand(f, "diff"),
+		NewInspectCommand(f, "inspect"),
 	)
 
 	return c
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	"github.com/heptio/velero/pkg/cmd/cli"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+// NewCancelCommand creates and returns a new cobra command for canceling backups.
+func NewCancelCommand(f client.Factory, use string) *cobra.Command {
+	o := cli.NewCancelOptions("backup")
+
+	c := &cobra.Command{
+		Use:   fmt.Sprintf("%s [NAMES]", use),
+		Short: "Cancel unfinished backups",
+		Long:  `Cancel backups that are new or in progress. A canceled backup stops as soon as possible and finishes in the Canceled phase. Restic backups of pod volumes that are running are stopped too.`,
+		Example: `	# cancel a backup named "backup-1"
+	velero backup cancel backup-1
+
+	# cancel backups named "backup-1" and "backup-2"
+	velero backup cancel backup-1 backup-2
+
+	# cancel all unfinished backups labelled with foo=bar
+	velero backup cancel --selector foo=bar
+
+	# cancel all unfinished backups
+	velero backup cancel --all`,
+
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(f, args))
+			cmd.CheckError(o.Validate(c, f, args))
+			cmd.CheckError(cli.RunCancel(o, &backupCanceler{client: o.Client.VeleroV1().Backups(o.Namespace)}))
+		},
+	}
+	o.BindFlags(c.Flags())
+	return c
+}
+
+// backupCanceler cancels backups for cli.RunCancel.
+type backupCanceler struct {
+	client velerov1client.BackupInterface
+}
+
+func (c *backupCanceler) IsUnfinished(name string) (bool, error) {
+	backup, err := c.client.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return isBackupUnfinished(backup), nil
+}
+
+func (c *backupCanceler) ListUnfinished(selector string) ([]string, error) {
+	res, err := c.client.List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var names []string
+	for i := range res.Items {
+		if isBackupUnfinished(&res.Items[i]) {
+			names = append(names, res.Items[i].Name)
+		}
+	}
+	return names, nil
+}
+
+func (c *backupCanceler) Cancel(name string) error {
+	_, err := c.client.Patch(name, types.MergePatchType, cli.CancelPatch)
+	return errors.WithStack(err)
+}
+
+func isBackupUnfinished(backup *velerov1api.Backup) bool {
+	switch backup.Status.Phase {
+	case "", velerov1api.BackupPhaseNew, velerov1api.BackupPhaseQueued, velerov1api.BackupPhaseInProgress:
+		return true
+	default:
+		return false
+	}
+}
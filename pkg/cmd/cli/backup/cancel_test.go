@@ -0,0 +1,66 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/cmd/cli"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+)
+
+func TestRunCancel(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		builder.ForBackup("velero", "new").Result(),
+		builder.ForBackup("velero", "queued").Phase(velerov1api.BackupPhaseQueued).Result(),
+		builder.ForBackup("velero", "in-progress").Phase(velerov1api.BackupPhaseInProgress).Result(),
+		builder.ForBackup("velero", "completed").Phase(velerov1api.BackupPhaseCompleted).Result(),
+	)
+
+	canceled := func() map[string]bool {
+		res := map[string]bool{}
+		backups, err := client.VeleroV1().Backups("velero").List(metav1.ListOptions{})
+		require.NoError(t, err)
+		for _, backup := range backups.Items {
+			res[backup.Name] = backup.Spec.Cancel
+		}
+		return res
+	}
+
+	o := cli.NewCancelOptions("backup")
+	o.Client = client
+	o.Namespace = "velero"
+	canceler := &backupCanceler{client: client.VeleroV1().Backups("velero")}
+
+	// named backups that have finished or don't exist aren't canceled
+	o.Names = []string{"in-progress", "completed", "missing"}
+	err := cli.RunCancel(o, canceler)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"missing" not found`)
+	assert.Equal(t, map[string]bool{"new": false, "queued": false, "in-progress": true, "completed": false}, canceled())
+
+	// all unfinished backups are canceled
+	o.Names = nil
+	require.NoError(t, cli.RunCancel(o, canceler))
+	assert.Equal(t, map[string]bool{"new": true, "queued": true, "in-progress": true, "completed": false}, canceled())
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/cmd/util/downloadrequest"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+// backupContentsItem describes an item's file within a backup tarball.
+type backupContentsItem struct {
+	path          string
+	groupResource string
+	// version is only set for backups that include all API group versions.
+	version   string
+	namespace string
+	name      string
+}
+
+// parseBackupItemPath parses the path of a file within a backup tarball,
+// returning false if it isn't an item's file. Item files are stored at
+// resources/<group-resource>[/<version>]/namespaces/<namespace>/<name>.json, or
+// resources/<group-resource>[/<version>]/cluster/<name>.json for cluster-scoped
+// items.
+func parseBackupItemPath(path string) (backupContentsItem, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 || parts[0] != v1.ResourcesDir || !strings.HasSuffix(path, ".json") {
+		return backupContentsItem{}, false
+	}
+
+	item := backupContentsItem{
+		path:          path,
+		groupResource: parts[1],
+	}
+	rest := parts[2:]
+
+	// a version directory is present if the next directory isn't one of the
+	// scope directories.
+	if rest[0] != v1.NamespaceScopedDir && rest[0] != v1.ClusterScopedDir {
+		item.version = rest[0]
+		rest = rest[1:]
+	}
+
+	switch {
+	case len(rest) == 3 && rest[0] == v1.NamespaceScopedDir:
+		item.namespace = rest[1]
+		item.name = strings.TrimSuffix(rest[2], ".json")
+	case len(rest) == 2 && rest[0] == v1.ClusterScopedDir:
+		item.name = strings.TrimSuffix(rest[1], ".json")
+	default:
+		return backupContentsItem{}, false
+	}
+
+	return item, true
+}
+
+// errStopWalking can be returned by a walkBackupItems callback to stop reading
+// the tarball without an error.
+var errStopWalking = errors.New("stop walking backup items")
+
+// walkBackupItems calls fn with each item in a backup tarball compressed with
+// codec, along with a reader on the item's file.
+func walkBackupItems(r io.Reader, codec archive.Codec, fn func(item backupContentsItem, r io.Reader) error) error {
+	dr, err := archive.NewDecompressingReader(r, codec)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	tr := tar.NewReader(dr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		item, ok := parseBackupItemPath(header.Name)
+		if !ok {
+			continue
+		}
+
+		if err := fn(item, tr); err != nil {
+			if err == errStopWalking {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// streamBackupItems downloads a backup's tarball, which is compressed with
+// codec, and calls fn with each item in it. The tarball is read as it's
+// downloaded rather than being written to disk.
+func streamBackupItems(client velerov1client.DownloadRequestsGetter, namespace, backup string, codec archive.Codec, timeout time.Duration, fn func(item backupContentsItem, r io.Reader) error) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(downloadrequest.Stream(client, namespace, backup, v1.DownloadTargetKindBackupContents, pw, timeout))
+	}()
+
+	err := walkBackupItems(pr, codec, fn)
+	// make sure the download goroutine isn't left blocked writing to the pipe
+	pr.CloseWithError(errors.New("backup contents are no longer being read"))
+	if err != nil {
+		if err == downloadrequest.ErrNotFound || errors.Cause(err) == downloadrequest.ErrNotFound {
+			return errors.Errorf("backup %s has no contents to read", backup)
+		}
+		return errors.Wrapf(err, "error reading contents of backup %s", backup)
+	}
+
+	return nil
+}
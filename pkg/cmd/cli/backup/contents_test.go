@@ -0,0 +1,144 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/velero/pkg/archive"
+)
+
+// newBackupTarball returns a gzipped tarball containing files, which maps
+// paths to file contents.
+func newBackupTarball(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(contents)), Typeflag: tar.TypeReg, Mode: 0644}))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseBackupItemPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected *backupContentsItem
+	}{
+		{
+			path:     "resources/pods/namespaces/ns-1/pod-1.json",
+			expected: &backupContentsItem{groupResource: "pods", namespace: "ns-1", name: "pod-1"},
+		},
+		{
+			path:     "resources/persistentvolumes/cluster/pv-1.json",
+			expected: &backupContentsItem{groupResource: "persistentvolumes", name: "pv-1"},
+		},
+		{
+			path:     "resources/deployments.apps/v1beta2/namespaces/ns-1/deploy-1.json",
+			expected: &backupContentsItem{groupResource: "deployments.apps", version: "v1beta2", namespace: "ns-1", name: "deploy-1"},
+		},
+		{
+			path:     "resources/clusterroles.rbac.authorization.k8s.io/v1/cluster/admin.json",
+			expected: &backupContentsItem{groupResource: "clusterroles.rbac.authorization.k8s.io", version: "v1", name: "admin"},
+		},
+		{
+			path:     "resources/pods/namespaces/cluster/pod-1.json",
+			expected: &backupContentsItem{groupResource: "pods", namespace: "cluster", name: "pod-1"},
+		},
+		{path: "metadata/version"},
+		{path: "resources/pods/namespaces/ns-1"},
+		{path: "resources/pods/namespaces/ns-1/pod-1.txt"},
+		{path: "resources/pods/v1/v2/cluster/pod-1.json"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			item, ok := parseBackupItemPath(tc.path)
+			if tc.expected == nil {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			tc.expected.path = tc.path
+			assert.Equal(t, *tc.expected, item)
+		})
+	}
+}
+
+func TestWalkBackupItems(t *testing.T) {
+	tarball := newBackupTarball(t, map[string]string{
+		"metadata/version":                              "1",
+		"resources/pods/namespaces/ns-1/pod-1.json":     "pod-1",
+		"resources/persistentvolumes/cluster/pv-1.json": "pv-1",
+	})
+
+	contents := make(map[string]string)
+	err := walkBackupItems(bytes.NewReader(tarball), archive.CodecGzip, func(item backupContentsItem, r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		contents[item.path] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"resources/pods/namespaces/ns-1/pod-1.json":     "pod-1",
+		"resources/persistentvolumes/cluster/pv-1.json": "pv-1",
+	}, contents)
+
+	// returning errStopWalking stops reading the tarball without an error
+	var walked int
+	err = walkBackupItems(bytes.NewReader(tarball), archive.CodecGzip, func(item backupContentsItem, r io.Reader) error {
+		walked++
+		return errStopWalking
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, walked)
+}
+
+func TestWalkBackupItemsWithoutCompression(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "resources/pods/namespaces/ns-1/pod-1.json", Size: 5, Typeflag: tar.TypeReg, Mode: 0644}))
+	_, err := tw.Write([]byte("pod-1"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	contents := make(map[string]string)
+	err = walkBackupItems(buf, archive.CodecNone, func(item backupContentsItem, r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		contents[item.path] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"resources/pods/namespaces/ns-1/pod-1.json": "pod-1"}, contents)
+}
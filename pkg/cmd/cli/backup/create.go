@@ -20,12 +20,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/cmd"
 	"github.com/heptio/velero/pkg/cmd/util/flag"
@@ -84,6 +86,10 @@ type CreateOptions struct {
 	Wait                    bool
 	StorageLocation         string
 	SnapshotLocations       []string
+	ItemBackupWorkers       int
+	AllAPIGroupVersions     bool
+	Compression             string
+	CompressionLevel        int
 
 	client veleroclient.Interface
 }
@@ -108,6 +114,10 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.StorageLocation, "storage-location", "", "location in which to store the backup")
 	flags.StringSliceVar(&o.SnapshotLocations, "volume-snapshot-locations", o.SnapshotLocations, "list of locations (at most one per provider) where volume snapshots should be stored")
 	flags.VarP(&o.Selector, "selector", "l", "only back up resources matching this label selector")
+	flags.IntVar(&o.ItemBackupWorkers, "item-backup-workers", o.ItemBackupWorkers, "number of items of each resource to back up concurrently (0 uses the server's default)")
+	flags.BoolVar(&o.AllAPIGroupVersions, "include-all-api-group-versions", o.AllAPIGroupVersions, "back up every version served for each resource's API group, not only the preferred one")
+	flags.StringVar(&o.Compression, "compression", o.Compression, "codec to compress the backup's tarball with, one of gzip, none or zstd (defaults to the storage location's setting)")
+	flags.IntVar(&o.CompressionLevel, "compression-level", o.CompressionLevel, "compression level for the codec (0 uses the codec's default)")
 	f := flags.VarPF(&o.SnapshotVolumes, "snapshot-volumes", "", "take snapshots of PersistentVolumes as part of the backup")
 	// this allows the user to just specify "--snapshot-volumes" as shorthand for "--snapshot-volumes=true"
 	// like a normal bool flag
@@ -128,6 +138,14 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Facto
 		return err
 	}
 
+	if o.ItemBackupWorkers < 0 {
+		return errors.New("--item-backup-workers must be zero or greater")
+	}
+
+	if err := archive.ValidateCompression(archive.Codec(o.Compression), o.CompressionLevel); err != nil {
+		return err
+	}
+
 	if o.StorageLocation != "" {
 		if _, err := o.client.VeleroV1().BackupStorageLocations(f.Namespace()).Get(o.StorageLocation, metav1.GetOptions{}); err != nil {
 			return err
@@ -143,6 +161,19 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Facto
 	return nil
 }
 
+// CompressionConfig returns the compression configuration set by the
+// --compression and --compression-level flags, or nil if neither is set.
+func (o *CreateOptions) CompressionConfig() *api.CompressionConfig {
+	if o.Compression == "" && o.CompressionLevel == 0 {
+		return nil
+	}
+
+	return &api.CompressionConfig{
+		Codec: api.CompressionCodec(o.Compression),
+		Level: o.CompressionLevel,
+	}
+}
+
 func (o *CreateOptions) Complete(args []string, f client.Factory) error {
 	o.Name = args[0]
 	client, err := f.Client()
@@ -161,16 +192,19 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			Labels:    o.Labels.Data(),
 		},
 		Spec: api.BackupSpec{
-			IncludedNamespaces:      o.IncludeNamespaces,
-			ExcludedNamespaces:      o.ExcludeNamespaces,
-			IncludedResources:       o.IncludeResources,
-			ExcludedResources:       o.ExcludeResources,
-			LabelSelector:           o.Selector.LabelSelector,
-			SnapshotVolumes:         o.SnapshotVolumes.Value,
-			TTL:                     metav1.Duration{Duration: o.TTL},
-			IncludeClusterResources: o.IncludeClusterResources.Value,
-			StorageLocation:         o.StorageLocation,
-			VolumeSnapshotLocations: o.SnapshotLocations,
+			IncludedNamespaces:         o.IncludeNamespaces,
+			ExcludedNamespaces:         o.ExcludeNamespaces,
+			IncludedResources:          o.IncludeResources,
+			ExcludedResources:          o.ExcludeResources,
+			LabelSelector:              o.Selector.LabelSelector,
+			SnapshotVolumes:            o.SnapshotVolumes.Value,
+			TTL:                        metav1.Duration{Duration: o.TTL},
+			IncludeClusterResources:    o.IncludeClusterResources.Value,
+			StorageLocation:            o.StorageLocation,
+			VolumeSnapshotLocations:    o.SnapshotLocations,
+			ItemBackupWorkers:          o.ItemBackupWorkers,
+			IncludeAllAPIGroupVersions: o.AllAPIGroupVersions,
+			Compression:                o.CompressionConfig(),
 		},
 	}
 
@@ -239,7 +273,7 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 					return nil
 				}
 
-				if backup.Status.Phase != api.BackupPhaseNew && backup.Status.Phase != api.BackupPhaseInProgress {
+				if backup.Status.Phase != api.BackupPhaseNew && backup.Status.Phase != api.BackupPhaseQueued && backup.Status.Phase != api.BackupPhaseInProgress {
 					fmt.Printf("\nBackup completed with status: %s. You may check for more information using the commands `velero backup describe %s` and `velero backup logs %s`.\n", backup.Status.Phase, backup.Name, backup.Name)
 					return nil
 				}
@@ -0,0 +1,418 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	"github.com/heptio/velero/pkg/cmd/util/downloadrequest"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	"github.com/heptio/velero/pkg/util/collections"
+)
+
+func NewDiffCommand(f client.Factory, use string) *cobra.Command {
+	o := NewDiffOptions()
+
+	c := &cobra.Command{
+		Use:   use + " BACKUP_A BACKUP_B",
+		Short: "Compare the resources in two backups",
+		Long: `Compare the resources in two backups.
+
+The resource lists of both backups are downloaded and the resources that were added or removed
+between BACKUP_A and BACKUP_B are reported. With --contents, both backup tarballs are downloaded
+as well, and field-level differences are reported for each resource present in both backups.`,
+		Example: `  # show resources added or removed between two backups
+  velero backup diff nightly-20191001 nightly-20191002
+
+  # also show the field-level changes to resources in namespace "web"
+  velero backup diff nightly-20191001 nightly-20191002 --contents --include-namespaces web`,
+		Args: cobra.ExactArgs(2),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type DiffOptions struct {
+	BackupA           string
+	BackupB           string
+	Contents          bool
+	IncludeNamespaces []string
+	Timeout           time.Duration
+}
+
+func NewDiffOptions() *DiffOptions {
+	return &DiffOptions{
+		Timeout: time.Minute,
+	}
+}
+
+func (o *DiffOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Contents, "contents", o.Contents, "download the backup tarballs and show field-level differences for resources present in both backups")
+	flags.StringSliceVar(&o.IncludeNamespaces, "include-namespaces", o.IncludeNamespaces, "namespaces to compare. Cluster-scoped resources are only compared if this is not set (defaults to all namespaces)")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait for each download to complete")
+}
+
+func (o *DiffOptions) Complete(args []string, f client.Factory) error {
+	o.BackupA = args[0]
+	o.BackupB = args[1]
+	return nil
+}
+
+func (o *DiffOptions) Run(c *cobra.Command, f client.Factory) error {
+	veleroClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	codecs := make(map[string]archive.Codec)
+	for _, name := range []string{o.BackupA, o.BackupB} {
+		backup, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		codecs[name] = archive.Codec(backup.Status.CompressionCodec)
+	}
+
+	namespaces := collections.NewIncludesExcludes().Includes(o.IncludeNamespaces...)
+
+	resourcesA, err := downloadResourceList(veleroClient.VeleroV1(), f.Namespace(), o.BackupA, o.Timeout, namespaces)
+	if err != nil {
+		return err
+	}
+	resourcesB, err := downloadResourceList(veleroClient.VeleroV1(), f.Namespace(), o.BackupB, o.Timeout, namespaces)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Comparing backup %s to backup %s\n", o.BackupA, o.BackupB)
+
+	fmt.Printf("\nAdded (in %s but not in %s):\n", o.BackupB, o.BackupA)
+	printResourceList(resourceListDifference(resourcesB, resourcesA))
+
+	fmt.Printf("\nRemoved (in %s but not in %s):\n", o.BackupA, o.BackupB)
+	printResourceList(resourceListDifference(resourcesA, resourcesB))
+
+	if !o.Contents {
+		return nil
+	}
+
+	itemsA, err := downloadBackupItems(veleroClient.VeleroV1(), f.Namespace(), o.BackupA, codecs[o.BackupA], o.Timeout, namespaces)
+	if err != nil {
+		return err
+	}
+	itemsB, err := downloadBackupItems(veleroClient.VeleroV1(), f.Namespace(), o.BackupB, codecs[o.BackupB], o.Timeout, namespaces)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nChanged:")
+	changed := diffBackupItems(itemsA, itemsB)
+	if len(changed) == 0 {
+		fmt.Println("  <none>")
+	}
+	for _, item := range changed {
+		fmt.Printf("  %s %s:\n", item.resource, item.name)
+		for _, field := range item.fields {
+			fmt.Printf("    %s\n", field)
+		}
+	}
+
+	return nil
+}
+
+// downloadResourceList downloads a backup's resource list, keeping only the
+// resources in the included namespaces.
+func downloadResourceList(client velerov1client.DownloadRequestsGetter, namespace, backup string, timeout time.Duration, namespaces *collections.IncludesExcludes) (map[string]sets.String, error) {
+	buf := new(bytes.Buffer)
+	if err := downloadrequest.Stream(client, namespace, backup, v1.DownloadTargetKindBackupResourceList, buf, timeout); err != nil {
+		if err == downloadrequest.ErrNotFound {
+			return nil, errors.Errorf("backup %s has no resource list; it was probably taken by an older version of Velero", backup)
+		}
+		return nil, errors.Wrapf(err, "error downloading resource list for backup %s", backup)
+	}
+
+	var resourceList map[string][]string
+	if err := json.NewDecoder(buf).Decode(&resourceList); err != nil {
+		return nil, errors.Wrapf(err, "error decoding resource list for backup %s", backup)
+	}
+
+	res := make(map[string]sets.String)
+	for resource, names := range resourceList {
+		for _, name := range names {
+			if !includesItem(namespaces, name) {
+				continue
+			}
+			if res[resource] == nil {
+				res[resource] = sets.NewString()
+			}
+			res[resource].Insert(name)
+		}
+	}
+
+	return res, nil
+}
+
+// includesItem returns whether an item named "<namespace>/<name>", or "<name>"
+// for a cluster-scoped item, should be compared.
+func includesItem(namespaces *collections.IncludesExcludes, name string) bool {
+	if len(namespaces.GetIncludes()) == 0 {
+		return true
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) < 2 {
+		return false
+	}
+
+	return namespaces.ShouldInclude(parts[0])
+}
+
+// resourceListDifference returns the items in a that aren't in b.
+func resourceListDifference(a, b map[string]sets.String) map[string][]string {
+	res := make(map[string][]string)
+	for resource, names := range a {
+		others, ok := b[resource]
+		if !ok {
+			others = sets.NewString()
+		}
+		if diff := names.Difference(others); diff.Len() > 0 {
+			res[resource] = diff.List()
+		}
+	}
+	return res
+}
+
+func printResourceList(resourceList map[string][]string) {
+	if len(resourceList) == 0 {
+		fmt.Println("  <none>")
+		return
+	}
+
+	resources := make([]string, 0, len(resourceList))
+	for resource := range resourceList {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		fmt.Printf("  %s:\n", resource)
+		for _, name := range resourceList[resource] {
+			fmt.Printf("    - %s\n", name)
+		}
+	}
+}
+
+// backupItemKey identifies an item in a backup the same way the backup's
+// resource list does.
+type backupItemKey struct {
+	resource string
+	name     string
+}
+
+// downloadBackupItems streams a backup's tarball, which is compressed with codec,
+// and decodes the items in the included namespaces.
+func downloadBackupItems(client velerov1client.DownloadRequestsGetter, namespace, backup string, codec archive.Codec, timeout time.Duration, namespaces *collections.IncludesExcludes) (map[backupItemKey]map[string]interface{}, error) {
+	items := make(map[backupItemKey]map[string]interface{})
+
+	err := streamBackupItems(client, namespace, backup, codec, timeout, func(contentsItem backupContentsItem, r io.Reader) error {
+		return readBackupItem(contentsItem, r, namespaces, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// readBackupItem decodes an item from a backup tarball into items if it's in
+// the included namespaces.
+func readBackupItem(contentsItem backupContentsItem, r io.Reader, namespaces *collections.IncludesExcludes, items map[backupItemKey]map[string]interface{}) error {
+	if contentsItem.namespace == "" && len(namespaces.GetIncludes()) > 0 {
+		return nil
+	}
+	if contentsItem.namespace != "" && !namespaces.ShouldInclude(contentsItem.namespace) {
+		return nil
+	}
+
+	item := make(map[string]interface{})
+	if err := json.NewDecoder(r).Decode(&item); err != nil {
+		return errors.Wrapf(err, "error decoding %s", contentsItem.path)
+	}
+
+	if key := backupItemKeyFor(item); key.resource != "" {
+		items[key] = item
+	}
+
+	return nil
+}
+
+func backupItemKeyFor(item map[string]interface{}) backupItemKey {
+	apiVersion, _ := item["apiVersion"].(string)
+	kind, _ := item["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		return backupItemKey{}
+	}
+
+	metadata, _ := item["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+
+	return backupItemKey{
+		resource: apiVersion + "/" + kind,
+		name:     name,
+	}
+}
+
+// ignoredDiffFields are fields that change on every update to an object, so
+// differences in them aren't interesting.
+var ignoredDiffFields = sets.NewString(
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.selfLink",
+	"metadata.managedFields",
+)
+
+type changedItem struct {
+	resource string
+	name     string
+	fields   []string
+}
+
+// diffBackupItems returns the field-level differences between the items
+// present in both a and b, sorted by resource and name.
+func diffBackupItems(a, b map[backupItemKey]map[string]interface{}) []changedItem {
+	var res []changedItem
+
+	for key, itemA := range a {
+		itemB, ok := b[key]
+		if !ok {
+			continue
+		}
+
+		var fields []string
+		diffFields("", itemA, itemB, &fields)
+		if len(fields) > 0 {
+			res = append(res, changedItem{resource: key.resource, name: key.name, fields: fields})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].resource != res[j].resource {
+			return res[i].resource < res[j].resource
+		}
+		return res[i].name < res[j].name
+	})
+
+	return res
+}
+
+// diffFields appends a description of each difference between a and b to
+// diffs. Objects are compared key by key and lists of the same length are
+// compared element by element; any other difference is reported for the
+// value as a whole.
+func diffFields(path string, a, b interface{}, diffs *[]string) {
+	if ignoredDiffFields.Has(path) {
+		return
+	}
+
+	switch aVal := a.(type) {
+	case map[string]interface{}:
+		bVal, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := sets.NewString()
+		for k := range aVal {
+			keys.Insert(k)
+		}
+		for k := range bVal {
+			keys.Insert(k)
+		}
+
+		for _, k := range keys.List() {
+			fieldPath := k
+			if path != "" {
+				fieldPath = path + "." + k
+			}
+
+			aField, aOK := aVal[k]
+			bField, bOK := bVal[k]
+			switch {
+			case !aOK:
+				if !ignoredDiffFields.Has(fieldPath) {
+					*diffs = append(*diffs, fmt.Sprintf("%s: <none> -> %s", fieldPath, diffValue(bField)))
+				}
+			case !bOK:
+				if !ignoredDiffFields.Has(fieldPath) {
+					*diffs = append(*diffs, fmt.Sprintf("%s: %s -> <none>", fieldPath, diffValue(aField)))
+				}
+			default:
+				diffFields(fieldPath, aField, bField, diffs)
+			}
+		}
+		return
+	case []interface{}:
+		bVal, ok := b.([]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			break
+		}
+
+		for i := range aVal {
+			diffFields(fmt.Sprintf("%s[%d]", path, i), aVal[i], bVal[i], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, diffValue(a), diffValue(b)))
+	}
+}
+
+func diffValue(val interface{}) string {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/util/collections"
+)
+
+func TestResourceListDifference(t *testing.T) {
+	a := map[string]sets.String{
+		"v1/Pod":              sets.NewString("ns-1/pod-1", "ns-1/pod-2"),
+		"v1/PersistentVolume": sets.NewString("pv-1"),
+		"apps/v1/Deployment":  sets.NewString("ns-1/deploy-1"),
+	}
+	b := map[string]sets.String{
+		"v1/Pod":             sets.NewString("ns-1/pod-2", "ns-1/pod-3"),
+		"apps/v1/Deployment": sets.NewString("ns-1/deploy-1"),
+	}
+
+	assert.Equal(t, map[string][]string{
+		"v1/Pod":              {"ns-1/pod-1"},
+		"v1/PersistentVolume": {"pv-1"},
+	}, resourceListDifference(a, b))
+
+	assert.Equal(t, map[string][]string{
+		"v1/Pod": {"ns-1/pod-3"},
+	}, resourceListDifference(b, a))
+}
+
+func TestIncludesItem(t *testing.T) {
+	all := collections.NewIncludesExcludes()
+	assert.True(t, includesItem(all, "ns-1/pod-1"))
+	assert.True(t, includesItem(all, "pv-1"))
+
+	some := collections.NewIncludesExcludes().Includes("ns-1", "web-*")
+	assert.True(t, includesItem(some, "ns-1/pod-1"))
+	assert.True(t, includesItem(some, "web-prod/pod-1"))
+	assert.False(t, includesItem(some, "ns-2/pod-1"))
+	assert.False(t, includesItem(some, "pv-1"))
+}
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "identical objects have no differences",
+			a:    map[string]interface{}{"spec": map[string]interface{}{"replicas": 1.0}},
+			b:    map[string]interface{}{"spec": map[string]interface{}{"replicas": 1.0}},
+		},
+		{
+			name: "changed, added and removed fields are reported",
+			a: map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"a": "1", "b": "2"}},
+				"spec":     map[string]interface{}{"replicas": 1.0},
+			},
+			b: map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"a": "1", "c": "3"}},
+				"spec":     map[string]interface{}{"replicas": 3.0},
+			},
+			expected: []string{
+				`metadata.labels.b: "2" -> <none>`,
+				`metadata.labels.c: <none> -> "3"`,
+				`spec.replicas: 1 -> 3`,
+			},
+		},
+		{
+			name: "lists of the same length are compared element by element",
+			a: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:1"},
+			}},
+			b: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:2"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:1"},
+			}},
+			expected: []string{`containers[0].image: "app:1" -> "app:2"`},
+		},
+		{
+			name:     "lists of different lengths are reported as a whole",
+			a:        map[string]interface{}{"args": []interface{}{"a"}},
+			b:        map[string]interface{}{"args": []interface{}{"a", "b"}},
+			expected: []string{`args: ["a"] -> ["a","b"]`},
+		},
+		{
+			name: "ignored fields are not reported",
+			a:    map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "1", "generation": 1.0}},
+			b:    map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "2", "selfLink": "/foo"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var diffs []string
+			diffFields("", tc.a, tc.b, &diffs)
+			assert.Equal(t, tc.expected, diffs)
+		})
+	}
+}
+
+func TestReadBackupItem(t *testing.T) {
+	tarball := newBackupTarball(t, map[string]string{
+		"metadata/version":                                         "1",
+		"resources/pods/namespaces/ns-1/pod-1.json":                `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-1","name":"pod-1"}}`,
+		"resources/pods/namespaces/ns-2/pod-2.json":                `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-2","name":"pod-2"}}`,
+		"resources/persistentvolumes/cluster/pv-1.json":            `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"}}`,
+		"resources/deployments.apps/namespaces/ns-1/deploy-1.json": `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1"}}`,
+	})
+
+	items := make(map[backupItemKey]map[string]interface{})
+	err := walkBackupItems(bytes.NewReader(tarball), archive.CodecGzip, func(item backupContentsItem, r io.Reader) error {
+		return readBackupItem(item, r, collections.NewIncludesExcludes().Includes("ns-1"), items)
+	})
+	require.NoError(t, err)
+
+	keys := make([]backupItemKey, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []backupItemKey{
+		{resource: "v1/Pod", name: "ns-1/pod-1"},
+		{resource: "apps/v1/Deployment", name: "ns-1/deploy-1"},
+	}, keys)
+}
@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/cmd"
 	"github.com/heptio/velero/pkg/cmd/util/downloadrequest"
@@ -66,7 +67,7 @@ func NewDownloadOptions() *DownloadOptions {
 }
 
 func (o *DownloadOptions) BindFlags(flags *pflag.FlagSet) {
-	flags.StringVarP(&o.Output, "output", "o", o.Output, "path to output file. Defaults to <NAME>-data.tar.gz in the current directory")
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "path to output file. Defaults to <NAME>-data.tar.gz in the current directory, or <NAME>-data.tar if the backup isn't compressed")
 	flags.BoolVar(&o.Force, "force", o.Force, "forces the download and will overwrite file if it exists already")
 	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
 }
@@ -75,13 +76,37 @@ func (o *DownloadOptions) Validate(c *cobra.Command, args []string, f client.Fac
 	veleroClient, err := f.Client()
 	cmd.CheckError(err)
 
-	if _, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(o.Name, metav1.GetOptions{}); err != nil {
+	backup, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(o.Name, metav1.GetOptions{})
+	if err != nil {
 		return err
 	}
 
+	// the default output file's extension depends on how the backup's
+	// tarball is compressed, which isn't known until the backup is fetched.
+	if o.Output == "" {
+		path, err := os.Getwd()
+		if err != nil {
+			return errors.Wrapf(err, "error getting current directory")
+		}
+		o.Output = filepath.Join(path, fmt.Sprintf("%s-data.%s", o.Name, tarballExtension(archive.Codec(backup.Status.CompressionCodec))))
+	}
+
 	return nil
 }
 
+// tarballExtension returns the file extension for a backup tarball that's
+// compressed with codec.
+func tarballExtension(codec archive.Codec) string {
+	switch codec {
+	case archive.CodecNone:
+		return "tar"
+	case archive.CodecZstd:
+		return "tar.zst"
+	default:
+		return "tar.gz"
+	}
+}
+
 func (o *DownloadOptions) Complete(args []string) error {
 	o.Name = args[0]
 
@@ -90,14 +115,6 @@ func (o *DownloadOptions) Complete(args []string) error {
 		o.writeOptions = os.O_RDWR | os.O_CREATE | os.O_TRUNC
 	}
 
-	if o.Output == "" {
-		path, err := os.Getwd()
-		if err != nil {
-			return errors.Wrapf(err, "error getting current directory")
-		}
-		o.Output = filepath.Join(path, fmt.Sprintf("%s-data.tar.gz", o.Name))
-	}
-
 	return nil
 }
 
@@ -0,0 +1,350 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	"github.com/heptio/velero/pkg/cmd/util/flag"
+	"github.com/heptio/velero/pkg/util/collections"
+	"github.com/heptio/velero/pkg/util/encode"
+)
+
+func NewInspectCommand(f client.Factory, use string) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Browse the resources in a backup",
+		Long: `Browse the resources in a backup without downloading it.
+
+The backup's tarball is read as it's downloaded and isn't written to disk.`,
+	}
+
+	c.AddCommand(
+		NewInspectListCommand(f, "list"),
+		NewInspectGetCommand(f, "get"),
+	)
+
+	return c
+}
+
+func NewInspectListCommand(f client.Factory, use string) *cobra.Command {
+	o := NewInspectListOptions()
+
+	c := &cobra.Command{
+		Use:   use + " BACKUP",
+		Short: "List the resources in a backup",
+		Example: `  # list every resource in a backup
+  velero backup inspect list nightly-20191001
+
+  # list the config maps in namespace "web" with the label app=web
+  velero backup inspect list nightly-20191001 --resources configmaps --include-namespaces web -l app=web`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Run(args[0], f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type InspectListOptions struct {
+	Resources         []string
+	IncludeNamespaces []string
+	Selector          flag.LabelSelector
+	Timeout           time.Duration
+}
+
+func NewInspectListOptions() *InspectListOptions {
+	return &InspectListOptions{
+		Timeout: time.Minute,
+	}
+}
+
+func (o *InspectListOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.Resources, "resources", o.Resources, "resources to list, e.g. 'configmaps' or 'deployments.apps' (defaults to all resources)")
+	flags.StringSliceVar(&o.IncludeNamespaces, "include-namespaces", o.IncludeNamespaces, "namespaces to list resources in. Cluster-scoped resources are only listed if this is not set (defaults to all namespaces)")
+	flags.VarP(&o.Selector, "selector", "l", "only list resources matching this label selector")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
+}
+
+func (o *InspectListOptions) Run(backupName string, f client.Factory) error {
+	veleroClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	backup, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(backupName, metav1.GetOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	selector := labels.Everything()
+	if o.Selector.LabelSelector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(o.Selector.LabelSelector); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	filter := &inspectFilter{
+		resources:  o.Resources,
+		namespaces: collections.NewIncludesExcludes().Includes(o.IncludeNamespaces...),
+		selector:   selector,
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	if backup.Spec.IncludeAllAPIGroupVersions {
+		fmt.Fprintln(w, "RESOURCE\tVERSION\tNAMESPACE\tNAME")
+	} else {
+		fmt.Fprintln(w, "RESOURCE\tNAMESPACE\tNAME")
+	}
+
+	err = streamBackupItems(veleroClient.VeleroV1(), f.Namespace(), backupName, archive.Codec(backup.Status.CompressionCodec), o.Timeout, func(item backupContentsItem, r io.Reader) error {
+		matches, err := filter.matches(item, r)
+		if err != nil || !matches {
+			return err
+		}
+
+		if backup.Spec.IncludeAllAPIGroupVersions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.groupResource, item.version, item.namespace, item.name)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", item.groupResource, item.namespace, item.name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// inspectFilter decides which of a backup's items to list.
+type inspectFilter struct {
+	resources  []string
+	namespaces *collections.IncludesExcludes
+	selector   labels.Selector
+}
+
+// matches returns whether item, whose contents can be read from r, passes the
+// filter.
+func (f *inspectFilter) matches(item backupContentsItem, r io.Reader) (bool, error) {
+	if len(f.resources) > 0 {
+		var found bool
+		for _, resource := range f.resources {
+			if matchesResource(item.groupResource, resource) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if len(f.namespaces.GetIncludes()) > 0 && item.namespace == "" {
+		return false, nil
+	}
+	if item.namespace != "" && !f.namespaces.ShouldInclude(item.namespace) {
+		return false, nil
+	}
+
+	if f.selector.Empty() {
+		return true, nil
+	}
+
+	var obj struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return false, errors.Wrapf(err, "error decoding %s", item.path)
+	}
+
+	return f.selector.Matches(labels.Set(obj.Metadata.Labels)), nil
+}
+
+// matchesResource returns whether groupResource, as used in a backup tarball,
+// is the resource named by the user. The group can be left out of the
+// resource's name, so "deployments" matches "deployments.apps".
+func matchesResource(groupResource, resource string) bool {
+	if groupResource == resource {
+		return true
+	}
+	return !strings.Contains(resource, ".") && strings.SplitN(groupResource, ".", 2)[0] == resource
+}
+
+func NewInspectGetCommand(f client.Factory, use string) *cobra.Command {
+	o := NewInspectGetOptions()
+
+	c := &cobra.Command{
+		Use:   use + " BACKUP RESOURCE [NAMESPACE/]NAME",
+		Short: "Print a resource from a backup",
+		Example: `  # print config map "settings" in namespace "web" as it was backed up
+  velero backup inspect get nightly-20191001 configmaps web/settings
+
+  # print cluster-scoped resources by name alone
+  velero backup inspect get nightly-20191001 clusterroles.rbac.authorization.k8s.io admin -o json`,
+		Args: cobra.ExactArgs(3),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Validate())
+			cmd.CheckError(o.Run(args, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type InspectGetOptions struct {
+	APIVersion string
+	Output     string
+	Timeout    time.Duration
+}
+
+func NewInspectGetOptions() *InspectGetOptions {
+	return &InspectGetOptions{
+		Output:  "yaml",
+		Timeout: time.Minute,
+	}
+}
+
+func (o *InspectGetOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.APIVersion, "api-version", o.APIVersion, "API version of the resource to print, for backups that include all API group versions")
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "output format. Valid formats are 'yaml' and 'json'")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
+}
+
+func (o *InspectGetOptions) Validate() error {
+	if o.Output != "yaml" && o.Output != "json" {
+		return errors.Errorf("invalid output format %q - valid values are 'yaml' and 'json'", o.Output)
+	}
+	return nil
+}
+
+func (o *InspectGetOptions) Run(args []string, f client.Factory) error {
+	backupName, resource := args[0], args[1]
+
+	var namespace, name string
+	if parts := strings.SplitN(args[2], "/", 2); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	} else {
+		name = parts[0]
+	}
+
+	// the version directories in backups are named for the version alone
+	version := o.APIVersion
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		version = version[i+1:]
+	}
+
+	veleroClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	backup, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(backupName, metav1.GetOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	found := make(map[string][]byte)
+	err = streamBackupItems(veleroClient.VeleroV1(), f.Namespace(), backupName, archive.Codec(backup.Status.CompressionCodec), o.Timeout, func(item backupContentsItem, r io.Reader) error {
+		if !matchesResource(item.groupResource, resource) || item.namespace != namespace || item.name != name {
+			return nil
+		}
+		if version != "" && item.version != version {
+			return nil
+		}
+
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		found[item.path] = data
+
+		// there can't be another match, so there's no need to read the rest
+		// of the tarball.
+		if item.groupResource == resource && !backup.Spec.IncludeAllAPIGroupVersions {
+			return errStopWalking
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := selectInspectedItem(found)
+	if err != nil {
+		return errors.Wrapf(err, "error getting %s %s from backup %s", resource, args[2], backupName)
+	}
+
+	obj := new(unstructured.Unstructured)
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return errors.WithStack(err)
+	}
+
+	encoded, err := encode.Encode(obj, o.Output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(encoded))
+	return nil
+}
+
+// selectInspectedItem returns the only one of found, which maps the paths of
+// items in a backup tarball to their contents.
+func selectInspectedItem(found map[string][]byte) ([]byte, error) {
+	switch len(found) {
+	case 0:
+		return nil, errors.New("not found")
+	case 1:
+		for _, data := range found {
+			return data, nil
+		}
+	}
+
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return nil, errors.Errorf("found more than one match (%s); include the resource's group in its name, or use --api-version for backups that include all API group versions", strings.Join(paths, ", "))
+}
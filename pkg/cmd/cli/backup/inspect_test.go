@@ -0,0 +1,126 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/heptio/velero/pkg/util/collections"
+	"github.com/heptio/velero/pkg/util/encode"
+)
+
+func TestMatchesResource(t *testing.T) {
+	assert.True(t, matchesResource("configmaps", "configmaps"))
+	assert.True(t, matchesResource("deployments.apps", "deployments.apps"))
+	assert.True(t, matchesResource("deployments.apps", "deployments"))
+	assert.False(t, matchesResource("deployments.apps", "deployments.extensions"))
+	assert.False(t, matchesResource("deployments.apps", "apps"))
+	assert.False(t, matchesResource("configmaps", "secrets"))
+}
+
+func TestInspectFilterMatches(t *testing.T) {
+	pod := `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-1","name":"pod-1","labels":{"app":"web"}}}`
+
+	tests := []struct {
+		name     string
+		filter   *inspectFilter
+		item     backupContentsItem
+		expected bool
+	}{
+		{
+			name:     "an empty filter matches everything",
+			filter:   &inspectFilter{namespaces: collections.NewIncludesExcludes(), selector: labels.Everything()},
+			item:     backupContentsItem{groupResource: "persistentvolumes", name: "pv-1"},
+			expected: true,
+		},
+		{
+			name:     "items of other resources don't match",
+			filter:   &inspectFilter{resources: []string{"configmaps", "deployments"}, namespaces: collections.NewIncludesExcludes(), selector: labels.Everything()},
+			item:     backupContentsItem{groupResource: "pods", namespace: "ns-1", name: "pod-1"},
+			expected: false,
+		},
+		{
+			name:     "items of included resources match",
+			filter:   &inspectFilter{resources: []string{"configmaps", "deployments"}, namespaces: collections.NewIncludesExcludes(), selector: labels.Everything()},
+			item:     backupContentsItem{groupResource: "deployments.apps", namespace: "ns-1", name: "deploy-1"},
+			expected: true,
+		},
+		{
+			name:     "cluster-scoped items don't match when namespaces are included",
+			filter:   &inspectFilter{namespaces: collections.NewIncludesExcludes().Includes("ns-1"), selector: labels.Everything()},
+			item:     backupContentsItem{groupResource: "persistentvolumes", name: "pv-1"},
+			expected: false,
+		},
+		{
+			name:     "items in other namespaces don't match",
+			filter:   &inspectFilter{namespaces: collections.NewIncludesExcludes().Includes("ns-2"), selector: labels.Everything()},
+			item:     backupContentsItem{groupResource: "pods", namespace: "ns-1", name: "pod-1"},
+			expected: false,
+		},
+		{
+			name:     "items matching the label selector match",
+			filter:   &inspectFilter{namespaces: collections.NewIncludesExcludes(), selector: labels.SelectorFromSet(labels.Set{"app": "web"})},
+			item:     backupContentsItem{groupResource: "pods", namespace: "ns-1", name: "pod-1"},
+			expected: true,
+		},
+		{
+			name:     "items not matching the label selector don't match",
+			filter:   &inspectFilter{namespaces: collections.NewIncludesExcludes(), selector: labels.SelectorFromSet(labels.Set{"app": "db"})},
+			item:     backupContentsItem{groupResource: "pods", namespace: "ns-1", name: "pod-1"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := tc.filter.matches(tc.item, strings.NewReader(pod))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+func TestSelectInspectedItem(t *testing.T) {
+	_, err := selectInspectedItem(map[string][]byte{})
+	assert.EqualError(t, err, "not found")
+
+	data, err := selectInspectedItem(map[string][]byte{"resources/pods/namespaces/ns-1/pod-1.json": []byte("pod-1")})
+	require.NoError(t, err)
+	assert.Equal(t, "pod-1", string(data))
+
+	_, err = selectInspectedItem(map[string][]byte{
+		"resources/deployments.extensions/namespaces/ns-1/deploy-1.json": []byte("deploy-1"),
+		"resources/deployments.apps/namespaces/ns-1/deploy-1.json":       []byte("deploy-1"),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resources/deployments.apps/namespaces/ns-1/deploy-1.json, resources/deployments.extensions/namespaces/ns-1/deploy-1.json")
+}
+
+func TestEncodeInspectedItem(t *testing.T) {
+	obj := new(unstructured.Unstructured)
+	require.NoError(t, obj.UnmarshalJSON([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"},"data":{"key":"value"}}`)))
+
+	encoded, err := encode.Encode(obj, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\ndata:\n  key: value\nkind: ConfigMap\nmetadata:\n  name: cm-1\n  namespace: ns-1\n", string(encoded))
+}
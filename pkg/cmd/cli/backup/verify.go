@@ -0,0 +1,176 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+func NewVerifyCommand(f client.Factory) *cobra.Command {
+	o := NewVerifyOptions()
+	c := &cobra.Command{
+		Use:   "verify NAME",
+		Short: "Verify a backup's data without restoring it",
+		Long: `Verify that all of a backup's data is present and intact without restoring it.
+
+Each of the backup's files in backup storage is checked against the checksum recorded when the backup was
+taken, and each of its restic snapshots is checked for in its restic repository.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type VerifyOptions struct {
+	Name    string
+	Timeout time.Duration
+}
+
+func NewVerifyOptions() *VerifyOptions {
+	return &VerifyOptions{
+		Timeout: 10 * time.Minute,
+	}
+}
+
+func (o *VerifyOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait for the backup to be verified")
+}
+
+func (o *VerifyOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	veleroClient, err := f.Client()
+	cmd.CheckError(err)
+
+	if _, err := veleroClient.VeleroV1().Backups(f.Namespace()).Get(o.Name, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *VerifyOptions) Complete(args []string) error {
+	o.Name = args[0]
+	return nil
+}
+
+func (o *VerifyOptions) Run(c *cobra.Command, f client.Factory) error {
+	veleroClient, err := f.Client()
+	cmd.CheckError(err)
+
+	fmt.Printf("Verifying backup %s...\n", o.Name)
+
+	req, err := verifyBackup(veleroClient.VeleroV1(), f.Namespace(), o.Name, o.Timeout)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range req.Status.VerifiedFiles {
+		fmt.Printf("Verified file %s\n", file)
+	}
+	for _, snapshot := range req.Status.VerifiedResticSnapshots {
+		fmt.Printf("Verified restic snapshot %s\n", snapshot)
+	}
+
+	if len(req.Status.Errors) > 0 {
+		fmt.Printf("Found %d problem(s) with backup %s:\n", len(req.Status.Errors), o.Name)
+		for _, err := range req.Status.Errors {
+			fmt.Printf("  * %s\n", err)
+		}
+		return errors.Errorf("backup %s failed verification", o.Name)
+	}
+
+	fmt.Printf("Backup %s has been successfully verified.\n", o.Name)
+	return nil
+}
+
+// verifyBackup creates a VerifyBackupRequest for the backup and waits for the
+// Velero server to process it.
+func verifyBackup(client velerov1client.VerifyBackupRequestsGetter, namespace, backupName string, timeout time.Duration) (*v1.VerifyBackupRequest, error) {
+	req := builder.ForVerifyBackupRequest(namespace, "").
+		ObjectMeta(
+			builder.WithGenerateName(backupName + "-"),
+		).
+		BackupName(backupName).
+		Result()
+
+	created, err := client.VerifyBackupRequests(namespace).Create(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer client.VerifyBackupRequests(namespace).Delete(created.Name, nil)
+
+	listOptions := metav1.ListOptions{
+		// TODO: once the minimum supported Kubernetes version is v1.9.0, uncomment the following line.
+		// See http://issue.k8s.io/51046 for details.
+		//FieldSelector:   "metadata.name=" + req.Name
+		ResourceVersion: created.ResourceVersion,
+	}
+	watcher, err := client.VerifyBackupRequests(namespace).Watch(listOptions)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer watcher.Stop()
+
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-expired.C:
+			return nil, errors.New("timed out waiting for the backup to be verified")
+		case e := <-watcher.ResultChan():
+			updated, ok := e.Object.(*v1.VerifyBackupRequest)
+			if !ok {
+				return nil, errors.Errorf("unexpected type %T", e.Object)
+			}
+
+			// TODO: once the minimum supported Kubernetes version is v1.9.0, remove the following check.
+			// See http://issue.k8s.io/51046 for details.
+			if updated.Name != created.Name {
+				continue
+			}
+
+			switch e.Type {
+			case watch.Deleted:
+				return nil, errors.New("verify backup request was unexpectedly deleted")
+			case watch.Modified:
+				if updated.Status.Phase == v1.VerifyBackupRequestPhaseProcessed {
+					return updated, nil
+				}
+			}
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/cmd"
 	"github.com/heptio/velero/pkg/cmd/util/flag"
@@ -61,6 +62,13 @@ type CreateOptions struct {
 	Config     flag.Map
 	Labels     flag.Map
 	AccessMode *flag.Enum
+
+	EncryptionKeySecret     flag.SecretKeySelector
+	AllowUnencryptedObjects bool
+	MaxConcurrentBackups    int
+	Credential              flag.SecretKeySelector
+	Compression             string
+	CompressionLevel        int
 }
 
 func NewCreateOptions() *CreateOptions {
@@ -85,6 +93,12 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 		"access-mode",
 		fmt.Sprintf("access mode for the backup storage location. Valid values are %s", strings.Join(o.AccessMode.AllowedValues(), ",")),
 	)
+	flags.Var(&o.EncryptionKeySecret, "encryption-key-secret", "secret and key, in the form NAME:KEY, holding the key to encrypt the location's backups with. Optional.")
+	flags.BoolVar(&o.AllowUnencryptedObjects, "allow-unencrypted-objects", o.AllowUnencryptedObjects, "allow unencrypted objects, e.g. ones stored before encryption was enabled, to be read from a location that uses encryption. Optional.")
+	flags.IntVar(&o.MaxConcurrentBackups, "max-concurrent-backups", o.MaxConcurrentBackups, "maximum number of backups to the location that can run at the same time. Optional; if not set, only the server's limit applies.")
+	flags.Var(&o.Credential, "credential", "secret and key, in the form NAME:KEY, holding the credentials for the location. Optional; if not set, the server's credentials are used.")
+	flags.StringVar(&o.Compression, "compression", o.Compression, "codec to compress the location's backup tarballs with, one of gzip, none or zstd. Optional; if not set, gzip is used.")
+	flags.IntVar(&o.CompressionLevel, "compression-level", o.CompressionLevel, "compression level for the codec. Optional; if not set, the codec's default level is used.")
 }
 
 func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -100,6 +114,18 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Facto
 		return errors.New("--bucket is required")
 	}
 
+	if o.AllowUnencryptedObjects && o.EncryptionKeySecret.SecretKeySelector == nil {
+		return errors.New("--allow-unencrypted-objects can only be used with --encryption-key-secret")
+	}
+
+	if o.MaxConcurrentBackups < 0 {
+		return errors.New("--max-concurrent-backups must not be negative")
+	}
+
+	if err := archive.ValidateCompression(archive.Codec(o.Compression), o.CompressionLevel); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -123,11 +149,27 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 					Prefix: o.Prefix,
 				},
 			},
-			Config:     o.Config.Data(),
-			AccessMode: velerov1api.BackupStorageLocationAccessMode(o.AccessMode.String()),
+			Config:               o.Config.Data(),
+			AccessMode:           velerov1api.BackupStorageLocationAccessMode(o.AccessMode.String()),
+			MaxConcurrentBackups: o.MaxConcurrentBackups,
+			Credential:           o.Credential.SecretKeySelector,
 		},
 	}
 
+	if o.EncryptionKeySecret.SecretKeySelector != nil {
+		backupStorageLocation.Spec.Encryption = &velerov1api.EncryptionConfig{
+			KeySecret:               *o.EncryptionKeySecret.SecretKeySelector,
+			AllowUnencryptedObjects: o.AllowUnencryptedObjects,
+		}
+	}
+
+	if o.Compression != "" || o.CompressionLevel != 0 {
+		backupStorageLocation.Spec.Compression = &velerov1api.CompressionConfig{
+			Codec: velerov1api.CompressionCodec(o.Compression),
+			Level: o.CompressionLevel,
+		}
+	}
+
 	if printed, err := output.PrintWithFormat(c, backupStorageLocation); printed || err != nil {
 		return err
 	}
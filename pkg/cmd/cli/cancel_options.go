@@ -0,0 +1,142 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd/util/flag"
+	clientset "github.com/heptio/velero/pkg/generated/clientset/versioned"
+)
+
+// CancelOptions contains parameters used for canceling backups or restores.
+type CancelOptions struct {
+	Names            []string
+	all              bool
+	Selector         flag.LabelSelector
+	Client           clientset.Interface
+	Namespace        string
+	singularTypeName string
+}
+
+func NewCancelOptions(singularTypeName string) *CancelOptions {
+	return &CancelOptions{
+		singularTypeName: singularTypeName,
+	}
+}
+
+// Complete fills in the correct values for all the options.
+func (o *CancelOptions) Complete(f client.Factory, args []string) error {
+	o.Namespace = f.Namespace()
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.Client = client
+	o.Names = args
+	return nil
+}
+
+// Validate validates the fields of the CancelOptions struct.
+func (o *CancelOptions) Validate(c *cobra.Command, f client.Factory, args []string) error {
+	if o.Client == nil {
+		return errors.New("Velero client is not set; unable to proceed")
+	}
+	var (
+		hasNames    = len(o.Names) > 0
+		hasAll      = o.all
+		hasSelector = o.Selector.LabelSelector != nil
+	)
+	if !xor(hasNames, hasAll, hasSelector) {
+		return errors.New("you must specify exactly one of: specific " + o.singularTypeName + " name(s), the --all flag, or the --selector flag")
+	}
+
+	return nil
+}
+
+// BindFlags binds options for this command to flags.
+func (o *CancelOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.all, "all", o.all, "Cancel all unfinished "+o.singularTypeName+"s")
+	flags.VarP(&o.Selector, "selector", "l", "Cancel all unfinished "+o.singularTypeName+"s matching this label selector")
+}
+
+// CancelPatch is the merge patch that cancels a backup or restore.
+var CancelPatch = []byte(`{"spec":{"cancel":true}}`)
+
+// Canceler gets and cancels backups or restores for RunCancel.
+type Canceler interface {
+	// IsUnfinished returns whether the named item is new or in progress.
+	IsUnfinished(name string) (bool, error)
+	// ListUnfinished returns the names of the items matching selector that
+	// are new or in progress.
+	ListUnfinished(selector string) ([]string, error)
+	// Cancel requests cancellation of the named item.
+	Cancel(name string) error
+}
+
+// RunCancel requests cancellation of the backups or restores selected by o,
+// using canceler to get and cancel them.
+func RunCancel(o *CancelOptions, canceler Canceler) error {
+	var (
+		names []string
+		errs  []error
+	)
+
+	switch {
+	case len(o.Names) > 0:
+		for _, name := range o.Names {
+			unfinished, err := canceler.IsUnfinished(name)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !unfinished {
+				fmt.Printf("%s %s has already finished\n", strings.Title(o.singularTypeName), name)
+				continue
+			}
+			names = append(names, name)
+		}
+	default:
+		selector := labels.Everything().String()
+		if o.Selector.LabelSelector != nil {
+			selector = o.Selector.String()
+		}
+
+		var err error
+		if names, err = canceler.ListUnfinished(selector); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := canceler.Cancel(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("Request to cancel %s %q submitted successfully.\n", o.singularTypeName, name)
+	}
+
+	return kubeerrs.NewAggregate(errs)
+}
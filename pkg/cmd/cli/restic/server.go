@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -38,6 +38,7 @@ import (
 	"github.com/heptio/velero/pkg/cmd"
 	"github.com/heptio/velero/pkg/cmd/util/signals"
 	"github.com/heptio/velero/pkg/controller"
+	"github.com/heptio/velero/pkg/credentials"
 	clientset "github.com/heptio/velero/pkg/generated/clientset/versioned"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
 	"github.com/heptio/velero/pkg/restic"
@@ -85,6 +86,7 @@ type resticServer struct {
 	ctx                   context.Context
 	cancelFunc            context.CancelFunc
 	fileSystem            filesystem.Interface
+	credentialFileStore   credentials.FileStore
 }
 
 func newResticServer(logger logrus.FieldLogger, baseName string) (*resticServer, error) {
@@ -144,6 +146,7 @@ func newResticServer(logger logrus.FieldLogger, baseName string) (*resticServer,
 		ctx:                   ctx,
 		cancelFunc:            cancelFunc,
 		fileSystem:            filesystem.NewFileSystem(),
+		credentialFileStore:   credentials.NewFileStore(kubeClient.CoreV1(), credentials.DefaultStoreDirectory, filesystem.NewFileSystem()),
 	}
 
 	if err := s.validatePodVolumesHostPath(); err != nil {
@@ -169,6 +172,7 @@ func (s *resticServer) run() {
 		s.kubeInformerFactory.Core().V1().PersistentVolumeClaims(),
 		s.kubeInformerFactory.Core().V1().PersistentVolumes(),
 		s.veleroInformerFactory.Velero().V1().BackupStorageLocations(),
+		s.credentialFileStore,
 		os.Getenv("NODE_NAME"),
 	)
 	wg.Add(1)
@@ -186,6 +190,7 @@ func (s *resticServer) run() {
 		s.kubeInformerFactory.Core().V1().PersistentVolumeClaims(),
 		s.kubeInformerFactory.Core().V1().PersistentVolumes(),
 		s.veleroInformerFactory.Velero().V1().BackupStorageLocations(),
+		s.credentialFileStore,
 		os.Getenv("NODE_NAME"),
 	)
 	wg.Add(1)
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	"github.com/heptio/velero/pkg/cmd/cli"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+// NewCancelCommand creates and returns a new cobra command for canceling restores.
+func NewCancelCommand(f client.Factory, use string) *cobra.Command {
+	o := cli.NewCancelOptions("restore")
+
+	c := &cobra.Command{
+		Use:   fmt.Sprintf("%s [NAMES]", use),
+		Short: "Cancel unfinished restores",
+		Long:  `Cancel restores that are new or in progress. A canceled restore stops as soon as possible and finishes in the Canceled phase. Restic restores of pod volumes that are running are stopped too.`,
+		Example: `	# cancel a restore named "restore-1"
+	velero restore cancel restore-1
+
+	# cancel restores named "restore-1" and "restore-2"
+	velero restore cancel restore-1 restore-2
+
+	# cancel all unfinished restores labelled with foo=bar
+	velero restore cancel --selector foo=bar
+
+	# cancel all unfinished restores
+	velero restore cancel --all`,
+
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(f, args))
+			cmd.CheckError(o.Validate(c, f, args))
+			cmd.CheckError(cli.RunCancel(o, &restoreCanceler{client: o.Client.VeleroV1().Restores(o.Namespace)}))
+		},
+	}
+	o.BindFlags(c.Flags())
+	return c
+}
+
+// restoreCanceler cancels restores for cli.RunCancel.
+type restoreCanceler struct {
+	client velerov1client.RestoreInterface
+}
+
+func (c *restoreCanceler) IsUnfinished(name string) (bool, error) {
+	restore, err := c.client.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return isRestoreUnfinished(restore), nil
+}
+
+func (c *restoreCanceler) ListUnfinished(selector string) ([]string, error) {
+	res, err := c.client.List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var names []string
+	for i := range res.Items {
+		if isRestoreUnfinished(&res.Items[i]) {
+			names = append(names, res.Items[i].Name)
+		}
+	}
+	return names, nil
+}
+
+func (c *restoreCanceler) Cancel(name string) error {
+	_, err := c.client.Patch(name, types.MergePatchType, cli.CancelPatch)
+	return errors.WithStack(err)
+}
+
+func isRestoreUnfinished(restore *velerov1api.Restore) bool {
+	switch restore.Status.Phase {
+	case "", velerov1api.RestorePhaseNew, velerov1api.RestorePhaseInProgress:
+		return true
+	default:
+		return false
+	}
+}
@@ -18,11 +18,13 @@ package restore
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 
@@ -52,6 +54,15 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 
   # create a restore for only persistentvolumeclaims and persistentvolumes within a backup
   velero restore create --from-backup backup-2 --include-resources persistentvolumeclaims,persistentvolumes
+
+  # create a restore that updates items that already exist in the cluster to match the backup
+  velero restore create --from-backup backup-1 --existing-resource-policy update
+
+  # see what a restore from backup "backup-1" would do, without changing the cluster
+  velero restore create --from-backup backup-1 --dry-run
+
+  # create a restore that modifies items using the rules in the ConfigMap "migration-rules"
+  velero restore create --from-backup backup-1 --resource-modifiers migration-rules
   `,
 		Args: cobra.MaximumNArgs(1),
 		Run: func(c *cobra.Command, args []string) {
@@ -78,9 +89,13 @@ type CreateOptions struct {
 	ExcludeNamespaces       flag.StringArray
 	IncludeResources        flag.StringArray
 	ExcludeResources        flag.StringArray
+	IncludeItems            flag.StringArray
 	NamespaceMappings       flag.Map
 	Selector                flag.LabelSelector
 	IncludeClusterResources flag.OptionalBool
+	ExistingResourcePolicy  *flag.Enum
+	DryRun                  bool
+	ResourceModifiers       string
 	Wait                    bool
 
 	client veleroclient.Interface
@@ -93,6 +108,12 @@ func NewCreateOptions() *CreateOptions {
 		NamespaceMappings:       flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter(":"),
 		RestoreVolumes:          flag.NewOptionalBool(nil),
 		IncludeClusterResources: flag.NewOptionalBool(nil),
+		ExistingResourcePolicy: flag.NewEnum(
+			string(api.ExistingResourcePolicyNone),
+			string(api.ExistingResourcePolicyNone),
+			string(api.ExistingResourcePolicyUpdate),
+			string(api.ExistingResourcePolicyRecreate),
+		),
 	}
 }
 
@@ -105,6 +126,7 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.Var(&o.Labels, "labels", "labels to apply to the restore")
 	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the restore, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
 	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the restore, formatted as resource.group, such as storageclasses.storage.k8s.io")
+	flags.Var(&o.IncludeItems, "include-items", "individual items to restore, along with the items they depend on, formatted as resource/namespace/name or resource/name for cluster-scoped items, such as deployments.apps/web/frontend")
 	flags.VarP(&o.Selector, "selector", "l", "only restore resources matching this label selector")
 	f := flags.VarPF(&o.RestoreVolumes, "restore-volumes", "", "whether to restore volumes from snapshots")
 	// this allows the user to just specify "--restore-volumes" as shorthand for "--restore-volumes=true"
@@ -114,6 +136,10 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	f = flags.VarPF(&o.IncludeClusterResources, "include-cluster-resources", "", "include cluster-scoped resources in the restore")
 	f.NoOptDefVal = "true"
 
+	flags.Var(o.ExistingResourcePolicy, "existing-resource-policy", fmt.Sprintf("what to do with items that already exist in the cluster and differ from the backup. Valid values are %s.", strings.Join(o.ExistingResourcePolicy.AllowedValues(), ", ")))
+	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "report what the restore would do without changing the cluster")
+	flags.StringVar(&o.ResourceModifiers, "resource-modifiers", o.ResourceModifiers, "name of a ConfigMap in the Velero namespace holding rules for modifying items before they're restored")
+
 	flags.BoolVarP(&o.Wait, "wait", "w", o.Wait, "wait for the operation to complete")
 }
 
@@ -189,13 +215,20 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			ExcludedNamespaces:      o.ExcludeNamespaces,
 			IncludedResources:       o.IncludeResources,
 			ExcludedResources:       o.ExcludeResources,
+			IncludedItems:           o.IncludeItems,
 			NamespaceMapping:        o.NamespaceMappings.Data(),
 			LabelSelector:           o.Selector.LabelSelector,
 			RestorePVs:              o.RestoreVolumes.Value,
 			IncludeClusterResources: o.IncludeClusterResources.Value,
+			ExistingResourcePolicy:  api.ExistingResourcePolicyType(o.ExistingResourcePolicy.String()),
+			DryRun:                  o.DryRun,
 		},
 	}
 
+	if o.ResourceModifiers != "" {
+		restore.Spec.ResourceModifiers = &corev1api.LocalObjectReference{Name: o.ResourceModifiers}
+	}
+
 	if printed, err := output.PrintWithFormat(c, restore); printed || err != nil {
 		return err
 	}
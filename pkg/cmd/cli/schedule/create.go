@@ -62,6 +62,9 @@ example: "@every 2h30m".`,
 
 	# Create a weekly backup, each living for 90 days (2160 hours)
 	velero create schedule NAME --schedule="@every 168h" --ttl 2160h0m0s
+
+	# Create a daily backup, keeping 7 daily, 4 weekly and 6 monthly backups
+	velero create schedule NAME --schedule="0 1 * * *" --ttl 4464h0m0s --keep-daily 7 --keep-weekly 4 --keep-monthly 6
 	`,
 		Args: cobra.ExactArgs(1),
 		Run: func(c *cobra.Command, args []string) {
@@ -81,6 +84,7 @@ example: "@every 2h30m".`,
 type CreateOptions struct {
 	BackupOptions *backup.CreateOptions
 	Schedule      string
+	Retention     api.ScheduleRetentionPolicy
 
 	labelSelector *metav1.LabelSelector
 }
@@ -94,6 +98,10 @@ func NewCreateOptions() *CreateOptions {
 func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	o.BackupOptions.BindFlags(flags)
 	flags.StringVar(&o.Schedule, "schedule", o.Schedule, "a cron expression specifying a recurring schedule for this backup to run")
+	flags.IntVar(&o.Retention.KeepLast, "keep-last", o.Retention.KeepLast, "number of most recent backups to keep, regardless of their TTL")
+	flags.IntVar(&o.Retention.KeepDaily, "keep-daily", o.Retention.KeepDaily, "number of days to keep the latest backup of, regardless of their TTL")
+	flags.IntVar(&o.Retention.KeepWeekly, "keep-weekly", o.Retention.KeepWeekly, "number of weeks to keep the latest backup of, regardless of their TTL")
+	flags.IntVar(&o.Retention.KeepMonthly, "keep-monthly", o.Retention.KeepMonthly, "number of months to keep the latest backup of, regardless of their TTL")
 }
 
 func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -101,6 +109,10 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Facto
 		return errors.New("--schedule is required")
 	}
 
+	if o.Retention.KeepLast < 0 || o.Retention.KeepDaily < 0 || o.Retention.KeepWeekly < 0 || o.Retention.KeepMonthly < 0 {
+		return errors.New("--keep-last, --keep-daily, --keep-weekly and --keep-monthly must not be negative")
+	}
+
 	return o.BackupOptions.Validate(c, args, f)
 }
 
@@ -122,21 +134,29 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 		},
 		Spec: api.ScheduleSpec{
 			Template: api.BackupSpec{
-				IncludedNamespaces:      o.BackupOptions.IncludeNamespaces,
-				ExcludedNamespaces:      o.BackupOptions.ExcludeNamespaces,
-				IncludedResources:       o.BackupOptions.IncludeResources,
-				ExcludedResources:       o.BackupOptions.ExcludeResources,
-				IncludeClusterResources: o.BackupOptions.IncludeClusterResources.Value,
-				LabelSelector:           o.BackupOptions.Selector.LabelSelector,
-				SnapshotVolumes:         o.BackupOptions.SnapshotVolumes.Value,
-				TTL:                     metav1.Duration{Duration: o.BackupOptions.TTL},
-				StorageLocation:         o.BackupOptions.StorageLocation,
-				VolumeSnapshotLocations: o.BackupOptions.SnapshotLocations,
+				IncludedNamespaces:         o.BackupOptions.IncludeNamespaces,
+				ExcludedNamespaces:         o.BackupOptions.ExcludeNamespaces,
+				IncludedResources:          o.BackupOptions.IncludeResources,
+				ExcludedResources:          o.BackupOptions.ExcludeResources,
+				IncludeClusterResources:    o.BackupOptions.IncludeClusterResources.Value,
+				LabelSelector:              o.BackupOptions.Selector.LabelSelector,
+				SnapshotVolumes:            o.BackupOptions.SnapshotVolumes.Value,
+				TTL:                        metav1.Duration{Duration: o.BackupOptions.TTL},
+				StorageLocation:            o.BackupOptions.StorageLocation,
+				VolumeSnapshotLocations:    o.BackupOptions.SnapshotLocations,
+				ItemBackupWorkers:          o.BackupOptions.ItemBackupWorkers,
+				IncludeAllAPIGroupVersions: o.BackupOptions.AllAPIGroupVersions,
+				Compression:                o.BackupOptions.CompressionConfig(),
 			},
 			Schedule: o.Schedule,
 		},
 	}
 
+	if o.Retention != (api.ScheduleRetentionPolicy{}) {
+		retention := o.Retention
+		schedule.Spec.Retention = &retention
+	}
+
 	if printed, err := output.PrintWithFormat(c, schedule); printed || err != nil {
 		return err
 	}
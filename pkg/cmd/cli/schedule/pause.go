@@ -0,0 +1,252 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cmd"
+	"github.com/heptio/velero/pkg/cmd/util/flag"
+	clientset "github.com/heptio/velero/pkg/generated/clientset/versioned"
+)
+
+// NewPauseCommand creates and returns a new cobra command for pausing schedules.
+func NewPauseCommand(f client.Factory, use string) *cobra.Command {
+	o := NewPauseOptions(true)
+
+	c := &cobra.Command{
+		Use:   fmt.Sprintf("%s [NAMES]", use),
+		Short: "Pause schedules",
+		Long: `Pause schedules so that they don't create any backups until they're unpaused.
+
+If a paused schedule misses one or more runs, it creates a single backup as soon
+as it's unpaused, and then carries on running at its usual times.`,
+		Example: `	# pause a schedule named "schedule-1"
+	velero schedule pause schedule-1
+
+	# pause all schedules labelled with foo=bar
+	velero schedule pause --selector foo=bar
+
+	# pause all schedules
+	velero schedule pause --all`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(f, args))
+			cmd.CheckError(o.Validate())
+			cmd.CheckError(o.Run())
+		},
+	}
+
+	o.BindFlags(c.Flags())
+	return c
+}
+
+// NewUnpauseCommand creates and returns a new cobra command for unpausing schedules.
+func NewUnpauseCommand(f client.Factory, use string) *cobra.Command {
+	o := NewPauseOptions(false)
+
+	c := &cobra.Command{
+		Use:   fmt.Sprintf("%s [NAMES]", use),
+		Short: "Unpause schedules",
+		Long: `Unpause schedules so that they start creating backups again.
+
+If a schedule missed one or more runs while it was paused, it creates a single
+backup straight away, and then carries on running at its usual times.`,
+		Example: `	# unpause a schedule named "schedule-1"
+	velero schedule unpause schedule-1
+
+	# unpause all schedules labelled with foo=bar
+	velero schedule unpause --selector foo=bar
+
+	# unpause all schedules
+	velero schedule unpause --all`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(f, args))
+			cmd.CheckError(o.Validate())
+			cmd.CheckError(o.Run())
+		},
+	}
+
+	o.BindFlags(c.Flags())
+	return c
+}
+
+// PauseOptions contains parameters used for pausing or unpausing schedules.
+type PauseOptions struct {
+	Names     []string
+	All       bool
+	Selector  flag.LabelSelector
+	Client    clientset.Interface
+	Namespace string
+	Paused    bool
+	User      string
+}
+
+// NewPauseOptions returns PauseOptions for pausing schedules if paused is
+// true, or for unpausing them otherwise.
+func NewPauseOptions(paused bool) *PauseOptions {
+	return &PauseOptions{Paused: paused}
+}
+
+func (o *PauseOptions) verb() string {
+	if o.Paused {
+		return "Pause"
+	}
+	return "Unpause"
+}
+
+// BindFlags binds options for this command to flags.
+func (o *PauseOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.All, "all", o.All, o.verb()+" all schedules")
+	flags.VarP(&o.Selector, "selector", "l", o.verb()+" all schedules matching this label selector")
+}
+
+// Complete fills in the correct values for all the options.
+func (o *PauseOptions) Complete(f client.Factory, args []string) error {
+	o.Namespace = f.Namespace()
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.Client = client
+	o.Names = args
+
+	user, err := f.User()
+	if err != nil {
+		return err
+	}
+	o.User = user
+	return nil
+}
+
+// Validate validates the fields of the PauseOptions struct.
+func (o *PauseOptions) Validate() error {
+	if o.Client == nil {
+		return errors.New("Velero client is not set; unable to proceed")
+	}
+
+	var count int
+	for _, specified := range []bool{len(o.Names) > 0, o.All, o.Selector.LabelSelector != nil} {
+		if specified {
+			count++
+		}
+	}
+	if count != 1 {
+		return errors.New("you must specify exactly one of: specific schedule name(s), the --all flag, or the --selector flag")
+	}
+
+	return nil
+}
+
+// Run pauses or unpauses the schedules.
+func (o *PauseOptions) Run() error {
+	var (
+		schedules []*velerov1api.Schedule
+		errs      []error
+	)
+	switch {
+	case len(o.Names) > 0:
+		for _, name := range o.Names {
+			schedule, err := o.Client.VeleroV1().Schedules(o.Namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				errs = append(errs, errors.WithStack(err))
+				continue
+			}
+			schedules = append(schedules, schedule)
+		}
+	default:
+		selector := labels.Everything().String()
+		if o.Selector.LabelSelector != nil {
+			selector = o.Selector.String()
+		}
+		res, err := o.Client.VeleroV1().Schedules(o.Namespace).List(metav1.ListOptions{
+			LabelSelector: selector,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		for i := range res.Items {
+			schedules = append(schedules, &res.Items[i])
+		}
+	}
+	if len(schedules) == 0 && len(errs) == 0 {
+		fmt.Println("No schedules found")
+		return nil
+	}
+
+	patch, err := o.patch(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, s := range schedules {
+		if s.Spec.Paused == o.Paused {
+			fmt.Printf("Schedule %s is already %sd\n", s.Name, strings.ToLower(o.verb()))
+			continue
+		}
+
+		if _, err := o.Client.VeleroV1().Schedules(s.Namespace).Patch(s.Name, types.MergePatchType, patch); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error patching schedule %s", s.Name))
+			continue
+		}
+		fmt.Printf("Schedule %sd: %s\n", strings.ToLower(o.verb()), s.Name)
+	}
+
+	return kubeerrs.NewAggregate(errs)
+}
+
+// patch returns the merge patch that pauses or unpauses a schedule, recording
+// when it was paused and by whom.
+func (o *PauseOptions) patch(now time.Time) ([]byte, error) {
+	status := map[string]interface{}{
+		"pausedAt": nil,
+		"pausedBy": nil,
+	}
+	if o.Paused {
+		status["pausedAt"] = metav1.NewTime(now)
+		if o.User != "" {
+			status["pausedBy"] = o.User
+		}
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"paused": o.Paused,
+		},
+		"status": status,
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshalling schedule patch")
+	}
+
+	return patchBytes, nil
+}
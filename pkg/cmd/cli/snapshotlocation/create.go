@@ -53,10 +53,11 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 }
 
 type CreateOptions struct {
-	Name     string
-	Provider string
-	Config   flag.Map
-	Labels   flag.Map
+	Name       string
+	Provider   string
+	Config     flag.Map
+	Labels     flag.Map
+	Credential flag.SecretKeySelector
 }
 
 func NewCreateOptions() *CreateOptions {
@@ -69,6 +70,7 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.Provider, "provider", o.Provider, "name of the volume snapshot provider (e.g. aws, azure, gcp)")
 	flags.Var(&o.Config, "config", "configuration key-value pairs")
 	flags.Var(&o.Labels, "labels", "labels to apply to the volume snapshot location")
+	flags.Var(&o.Credential, "credential", "secret and key, in the form NAME:KEY, holding the credentials for the location. Optional; if not set, the server's credentials are used.")
 }
 
 func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -96,8 +98,9 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			Labels:    o.Labels.Data(),
 		},
 		Spec: api.VolumeSnapshotLocationSpec{
-			Provider: o.Provider,
-			Config:   o.Config.Data(),
+			Provider:   o.Provider,
+			Config:     o.Config.Data(),
+			Credential: o.Credential.SecretKeySelector,
 		},
 	}
 
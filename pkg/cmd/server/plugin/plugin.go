@@ -24,6 +24,7 @@ import (
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/cloudprovider/aws"
 	"github.com/heptio/velero/pkg/cloudprovider/azure"
+	"github.com/heptio/velero/pkg/cloudprovider/filesystem"
 	"github.com/heptio/velero/pkg/cloudprovider/gcp"
 	velerodiscovery "github.com/heptio/velero/pkg/discovery"
 	veleroplugin "github.com/heptio/velero/pkg/plugin/framework"
@@ -41,6 +42,7 @@ func NewCommand(f client.Factory) *cobra.Command {
 				RegisterObjectStore("velero.io/aws", newAwsObjectStore).
 				RegisterObjectStore("velero.io/azure", newAzureObjectStore).
 				RegisterObjectStore("velero.io/gcp", newGcpObjectStore).
+				RegisterObjectStore("velero.io/filesystem", newFilesystemObjectStore).
 				RegisterVolumeSnapshotter("velero.io/aws", newAwsVolumeSnapshotter).
 				RegisterVolumeSnapshotter("velero.io/azure", newAzureVolumeSnapshotter).
 				RegisterVolumeSnapshotter("velero.io/gcp", newGcpVolumeSnapshotter).
@@ -54,7 +56,9 @@ func NewCommand(f client.Factory) *cobra.Command {
 				RegisterRestoreItemAction("velero.io/service-account", newServiceAccountRestoreItemAction).
 				RegisterRestoreItemAction("velero.io/add-pvc-from-pod", newAddPVCFromPodRestoreItemAction).
 				RegisterRestoreItemAction("velero.io/add-pv-from-pvc", newAddPVFromPVCRestoreItemAction).
+				RegisterRestoreItemAction("velero.io/add-pod-dependencies", newAddPodDependenciesRestoreItemAction).
 				RegisterRestoreItemAction("velero.io/change-storage-class", newChangeStorageClassRestoreItemAction(f)).
+				RegisterRestoreItemAction("velero.io/resource-modifier", newResourceModifierRestoreItemAction(f)).
 				Serve()
 		},
 	}
@@ -76,6 +80,10 @@ func newGcpObjectStore(logger logrus.FieldLogger) (interface{}, error) {
 	return gcp.NewObjectStore(logger), nil
 }
 
+func newFilesystemObjectStore(logger logrus.FieldLogger) (interface{}, error) {
+	return filesystem.NewObjectStore(logger), nil
+}
+
 func newAwsVolumeSnapshotter(logger logrus.FieldLogger) (interface{}, error) {
 	return aws.NewVolumeSnapshotter(logger), nil
 }
@@ -156,6 +164,10 @@ func newAddPVFromPVCRestoreItemAction(logger logrus.FieldLogger) (interface{}, e
 	return restore.NewAddPVFromPVCAction(logger), nil
 }
 
+func newAddPodDependenciesRestoreItemAction(logger logrus.FieldLogger) (interface{}, error) {
+	return restore.NewAddPodDependenciesAction(logger), nil
+}
+
 func newChangeStorageClassRestoreItemAction(f client.Factory) veleroplugin.HandlerInitializer {
 	return func(logger logrus.FieldLogger) (interface{}, error) {
 		client, err := f.KubeClient()
@@ -170,3 +182,23 @@ func newChangeStorageClassRestoreItemAction(f client.Factory) veleroplugin.Handl
 		), nil
 	}
 }
+
+func newResourceModifierRestoreItemAction(f client.Factory) veleroplugin.HandlerInitializer {
+	return func(logger logrus.FieldLogger) (interface{}, error) {
+		client, err := f.KubeClient()
+		if err != nil {
+			return nil, err
+		}
+
+		discoveryHelper, err := velerodiscovery.NewHelper(client.Discovery(), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		return restore.NewResourceModifierAction(
+			logger,
+			client.CoreV1().ConfigMaps(f.Namespace()),
+			discoveryHelper,
+		), nil
+	}
+}
@@ -18,6 +18,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -49,10 +50,12 @@ import (
 	"github.com/heptio/velero/pkg/backup"
 	"github.com/heptio/velero/pkg/buildinfo"
 	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/cloudprovider/filesystem"
 	"github.com/heptio/velero/pkg/cmd"
 	"github.com/heptio/velero/pkg/cmd/util/flag"
 	"github.com/heptio/velero/pkg/cmd/util/signals"
 	"github.com/heptio/velero/pkg/controller"
+	"github.com/heptio/velero/pkg/credentials"
 	velerodiscovery "github.com/heptio/velero/pkg/discovery"
 	clientset "github.com/heptio/velero/pkg/generated/clientset/versioned"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
@@ -62,6 +65,8 @@ import (
 	"github.com/heptio/velero/pkg/podexec"
 	"github.com/heptio/velero/pkg/restic"
 	"github.com/heptio/velero/pkg/restore"
+	utilfilesystem "github.com/heptio/velero/pkg/util/filesystem"
+	"github.com/heptio/velero/pkg/util/kube"
 	"github.com/heptio/velero/pkg/util/logging"
 )
 
@@ -70,6 +75,7 @@ const (
 	defaultMetricsAddress = ":8085"
 
 	defaultBackupSyncPeriod           = time.Minute
+	defaultStoreValidationFrequency   = time.Minute
 	defaultPodVolumeOperationTimeout  = 60 * time.Minute
 	defaultResourceTerminatingTimeout = 10 * time.Minute
 
@@ -80,19 +86,27 @@ const (
 	defaultProfilerAddress = "localhost:6060"
 
 	// keys used to map out available controllers with disable-controllers flag
-	BackupControllerKey              = "backup"
-	BackupSyncControllerKey          = "backup-sync"
-	ScheduleControllerKey            = "schedule"
-	GcControllerKey                  = "gc"
-	BackupDeletionControllerKey      = "backup-deletion"
-	RestoreControllerKey             = "restore"
-	DownloadRequestControllerKey     = "download-request"
-	ResticRepoControllerKey          = "restic-repo"
-	ServerStatusRequestControllerKey = "server-status-request"
+	BackupControllerKey                = "backup"
+	BackupSyncControllerKey            = "backup-sync"
+	ScheduleControllerKey              = "schedule"
+	GcControllerKey                    = "gc"
+	BackupDeletionControllerKey        = "backup-deletion"
+	RestoreControllerKey               = "restore"
+	DownloadRequestControllerKey       = "download-request"
+	ResticRepoControllerKey            = "restic-repo"
+	ServerStatusRequestControllerKey   = "server-status-request"
+	VerifyBackupRequestControllerKey   = "verify-backup-request"
+	BackupStorageLocationControllerKey = "backup-storage-location"
 
 	defaultControllerWorkers = 1
+	// the default number of items of each resource to back up concurrently
+	defaultItemBackupWorkers = 1
+	// the default maximum number of items to retrieve in a single list request
+	defaultClientPageSize = 500
 	// the default TTL for a backup
 	defaultBackupTTL = 30 * 24 * time.Hour
+	// the default number of backups to run concurrently
+	defaultMaxConcurrentBackups = 1
 )
 
 // list of available controllers for input validation
@@ -106,6 +120,8 @@ var disableControllerList = []string{
 	DownloadRequestControllerKey,
 	ResticRepoControllerKey,
 	ServerStatusRequestControllerKey,
+	VerifyBackupRequestControllerKey,
+	BackupStorageLocationControllerKey,
 }
 
 type serverConfig struct {
@@ -118,7 +134,13 @@ type serverConfig struct {
 	disabledControllers                                                     []string
 	clientQPS                                                               float32
 	clientBurst                                                             int
+	itemBackupWorkers                                                       int
+	maxConcurrentBackups                                                    int
+	storeValidationFrequency                                                time.Duration
+	clientPageSize                                                          int
 	profilerAddress                                                         string
+	fileServerAddress, fileServerTLSSecret                                  string
+	downloadServerAddress, downloadServerURL, downloadServerTLSSecret       string
 	formatFlag                                                              *logging.FormatFlag
 }
 
@@ -144,6 +166,10 @@ func NewCommand() *cobra.Command {
 			clientBurst:                    defaultClientBurst,
 			profilerAddress:                defaultProfilerAddress,
 			resourceTerminatingTimeout:     defaultResourceTerminatingTimeout,
+			itemBackupWorkers:              defaultItemBackupWorkers,
+			maxConcurrentBackups:           defaultMaxConcurrentBackups,
+			storeValidationFrequency:       defaultStoreValidationFrequency,
+			clientPageSize:                 defaultClientPageSize,
 			formatFlag:                     logging.NewFormatFlag(),
 		}
 	)
@@ -201,6 +227,7 @@ func NewCommand() *cobra.Command {
 	command.Flags().StringVar(&config.pluginDir, "plugin-dir", config.pluginDir, "directory containing Velero plugins")
 	command.Flags().StringVar(&config.metricsAddress, "metrics-address", config.metricsAddress, "the address to expose prometheus metrics")
 	command.Flags().DurationVar(&config.backupSyncPeriod, "backup-sync-period", config.backupSyncPeriod, "how often to ensure all Velero backups in object storage exist as Backup API objects in the cluster")
+	command.Flags().DurationVar(&config.storeValidationFrequency, "store-validation-frequency", config.storeValidationFrequency, "how often to check that each backup storage location is available")
 	command.Flags().DurationVar(&config.podVolumeOperationTimeout, "restic-timeout", config.podVolumeOperationTimeout, "how long backups/restores of pod volumes should be allowed to run before timing out")
 	command.Flags().BoolVar(&config.restoreOnly, "restore-only", config.restoreOnly, "run in a mode where only restores are allowed; backups, schedules, and garbage-collection are all disabled. DEPRECATED: this flag will be removed in v2.0. Use read-only backup storage locations instead.")
 	command.Flags().StringSliceVar(&config.disabledControllers, "disable-controllers", config.disabledControllers, fmt.Sprintf("list of controllers to disable on startup. Valid values are %s", strings.Join(disableControllerList, ",")))
@@ -212,6 +239,14 @@ func NewCommand() *cobra.Command {
 	command.Flags().StringVar(&config.profilerAddress, "profiler-address", config.profilerAddress, "the address to expose the pprof profiler")
 	command.Flags().DurationVar(&config.resourceTerminatingTimeout, "terminating-resource-timeout", config.resourceTerminatingTimeout, "how long to wait on persistent volumes and namespaces to terminate during a restore before timing out")
 	command.Flags().DurationVar(&config.defaultBackupTTL, "default-backup-ttl", config.defaultBackupTTL, "how long to wait by default before backups can be garbage collected")
+	command.Flags().IntVar(&config.clientPageSize, "client-page-size", config.clientPageSize, "maximum number of items to retrieve from the Kubernetes API in a single list request when backing up a resource; 0 disables pagination")
+	command.Flags().StringVar(&config.fileServerAddress, "file-server-address", config.fileServerAddress, "the address to serve signed download URLs for filesystem backup storage locations on; disabled if empty")
+	command.Flags().StringVar(&config.fileServerTLSSecret, "file-server-tls-secret", config.fileServerTLSSecret, "the name of a kubernetes.io/tls secret in the Velero server's namespace with the certificate and key to serve the file server over TLS with; required if the file server is enabled")
+	command.Flags().StringVar(&config.downloadServerAddress, "download-server-address", config.downloadServerAddress, "the address to serve downloads from backup storage locations that use encryption on; disabled if empty")
+	command.Flags().StringVar(&config.downloadServerURL, "download-server-url", config.downloadServerURL, "the base https URL that velero clients can reach the download server at")
+	command.Flags().StringVar(&config.downloadServerTLSSecret, "download-server-tls-secret", config.downloadServerTLSSecret, "the name of a kubernetes.io/tls secret in the Velero server's namespace with the certificate and key to serve the download server over TLS with; required if the download server is enabled")
+	command.Flags().IntVar(&config.itemBackupWorkers, "item-backup-workers", config.itemBackupWorkers, "number of items of each resource to back up concurrently, unless overridden by a backup's spec.itemBackupWorkers")
+	command.Flags().IntVar(&config.maxConcurrentBackups, "max-concurrent-backups", config.maxConcurrentBackups, "maximum number of backups to run at the same time. Backups over this limit or their storage location's spec.maxConcurrentBackups, or that include namespaces a running backup includes, are queued")
 
 	return command
 }
@@ -247,7 +282,9 @@ type server struct {
 	pluginRegistry        clientmgmt.Registry
 	pluginManager         clientmgmt.Manager
 	resticManager         restic.RepositoryManager
+	credentialFileStore   credentials.FileStore
 	metrics               *metrics.ServerMetrics
+	fileServerKey         []byte
 	config                serverConfig
 }
 
@@ -266,6 +303,26 @@ func newServer(namespace, baseName string, config serverConfig, logger *logrus.L
 	}
 	clientConfig.Burst = config.clientBurst
 
+	if config.clientPageSize < 0 {
+		return nil, errors.New("client-page-size must not be negative")
+	}
+
+	if config.maxConcurrentBackups <= 0 {
+		return nil, errors.New("max-concurrent-backups must be positive")
+	}
+
+	if config.storeValidationFrequency <= 0 {
+		return nil, errors.New("store-validation-frequency must be positive")
+	}
+
+	if config.fileServerAddress != "" && config.fileServerTLSSecret == "" {
+		return nil, errors.New("file-server-tls-secret must be set if file-server-address is")
+	}
+
+	if config.downloadServerAddress != "" && config.downloadServerTLSSecret == "" {
+		return nil, errors.New("download-server-tls-secret must be set if download-server-address is")
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -276,6 +333,15 @@ func newServer(namespace, baseName string, config serverConfig, logger *logrus.L
 		return nil, errors.WithStack(err)
 	}
 
+	// the signing key has to be in the environment before any plugin processes
+	// are started so that filesystem object stores can sign URLs with it.
+	var fileServerKey []byte
+	if config.fileServerAddress != "" {
+		if fileServerKey, err = filesystem.EnsureSigningKey(); err != nil {
+			return nil, err
+		}
+	}
+
 	pluginRegistry := clientmgmt.NewRegistry(config.pluginDir, logger, logger.Level)
 	if err := pluginRegistry.DiscoverPlugins(); err != nil {
 		return nil, err
@@ -307,6 +373,8 @@ func newServer(namespace, baseName string, config serverConfig, logger *logrus.L
 		logLevel:              logger.Level,
 		pluginRegistry:        pluginRegistry,
 		pluginManager:         pluginManager,
+		credentialFileStore:   credentials.NewFileStore(kubeClient.CoreV1(), credentials.DefaultStoreDirectory, utilfilesystem.NewFileSystem()),
+		fileServerKey:         fileServerKey,
 		config:                config,
 	}
 
@@ -322,6 +390,15 @@ func (s *server) run() error {
 		go s.runProfiler()
 	}
 
+	if s.config.fileServerAddress != "" {
+		tlsConfig, err := kube.TLSConfigFromSecret(s.kubeClient.CoreV1(), s.namespace, s.config.fileServerTLSSecret)
+		if err != nil {
+			return err
+		}
+
+		go s.runTLSServer("file server", s.config.fileServerAddress, filesystem.NewFileServer(s.fileServerKey, s.logger), tlsConfig)
+	}
+
 	// Since s.namespace, which specifies where backups/restores/schedules/etc. should live,
 	// *could* be different from the namespace where the Velero server pod runs, check to make
 	// sure it exists, and fail fast if it doesn't.
@@ -445,7 +522,7 @@ func (s *server) validateBackupStorageLocations() error {
 
 	var invalid []string
 	for _, location := range locations.Items {
-		backupStore, err := persistence.NewObjectBackupStore(&location, s.pluginManager, s.logger)
+		backupStore, err := persistence.NewObjectBackupStore(&location, s.pluginManager, s.kubeClient.CoreV1(), s.logger)
 		if err != nil {
 			invalid = append(invalid, errors.Wrapf(err, "error getting backup store for location %q", location.Name).Error())
 			continue
@@ -463,18 +540,18 @@ func (s *server) validateBackupStorageLocations() error {
 	return nil
 }
 
-// - Namespaces go first because all namespaced resources depend on them.
-// - Storage Classes are needed to create PVs and PVCs correctly.
-// - PVs go before PVCs because PVCs depend on them.
-// - PVCs go before pods or controllers so they can be mounted as volumes.
-// - Secrets and config maps go before pods or controllers so they can be mounted
-// 	 as volumes.
-// - Service accounts go before pods or controllers so pods can use them.
-// - Limit ranges go before pods or controllers so pods can use them.
-// - Pods go before controllers so they can be explicitly restored and potentially
-//	 have restic restores run before controllers adopt the pods.
-// - Custom Resource Definitions come before Custom Resource so that they can be
-//   restored with their corresponding CRD.
+//   - Namespaces go first because all namespaced resources depend on them.
+//   - Storage Classes are needed to create PVs and PVCs correctly.
+//   - PVs go before PVCs because PVCs depend on them.
+//   - PVCs go before pods or controllers so they can be mounted as volumes.
+//   - Secrets and config maps go before pods or controllers so they can be mounted
+//     as volumes.
+//   - Service accounts go before pods or controllers so pods can use them.
+//   - Limit ranges go before pods or controllers so pods can use them.
+//   - Pods go before controllers so they can be explicitly restored and potentially
+//     have restic restores run before controllers adopt the pods.
+//   - Custom Resource Definitions come before Custom Resource so that they can be
+//     restored with their corresponding CRD.
 var defaultRestorePriorities = []string{
 	"namespaces",
 	"storageclasses",
@@ -529,6 +606,7 @@ func (s *server) initRestic() error {
 		s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
 		s.kubeClient.CoreV1(),
 		s.kubeClient.CoreV1(),
+		s.credentialFileStore,
 		s.logger,
 	)
 	if err != nil {
@@ -562,6 +640,28 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 		return clientmgmt.NewManager(logger, s.logLevel, s.pluginRegistry)
 	}
 
+	var downloadServer *controller.DownloadServer
+	if s.config.downloadServerAddress != "" {
+		var err error
+		downloadServer, err = controller.NewDownloadServer(
+			s.config.downloadServerURL,
+			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
+			newPluginManager,
+			s.kubeClient.CoreV1(),
+			s.logger,
+		)
+		if err != nil {
+			return err
+		}
+
+		tlsConfig, err := kube.TLSConfigFromSecret(s.kubeClient.CoreV1(), s.namespace, s.config.downloadServerTLSSecret)
+		if err != nil {
+			return err
+		}
+
+		go s.runTLSServer("download server", s.config.downloadServerAddress, downloadServer, tlsConfig)
+	}
+
 	backupSyncControllerRunInfo := func() controllerRunInfo {
 		backupSyncContoller := controller.NewBackupSyncController(
 			s.veleroClient.VeleroV1(),
@@ -574,6 +674,7 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.namespace,
 			s.config.defaultBackupLocation,
 			newPluginManager,
+			s.kubeClient.CoreV1(),
 			s.logger,
 		)
 
@@ -587,11 +688,15 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 
 	backupControllerRunInfo := func() controllerRunInfo {
 		backupper, err := backup.NewKubernetesBackupper(
+			s.veleroClient.VeleroV1(),
 			s.discoveryHelper,
 			client.NewDynamicFactory(s.dynamicClient),
 			podexec.NewPodCommandExecutor(s.kubeClientConfig, s.kubeClient.CoreV1().RESTClient()),
 			s.resticManager,
 			s.config.podVolumeOperationTimeout,
+			s.config.itemBackupWorkers,
+			s.config.clientPageSize,
+			s.credentialFileStore,
 		)
 		cmd.CheckError(err)
 
@@ -602,6 +707,7 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.logger,
 			s.logLevel,
 			newPluginManager,
+			s.kubeClient.CoreV1(),
 			backupTracker,
 			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
 			s.config.defaultBackupLocation,
@@ -610,11 +716,12 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			defaultVolumeSnapshotLocations,
 			s.metrics,
 			s.config.formatFlag.Parse(),
+			s.config.maxConcurrentBackups,
 		)
 
 		return controllerRunInfo{
 			controller: backupController,
-			numWorkers: defaultControllerWorkers,
+			numWorkers: s.config.maxConcurrentBackups,
 		}
 	}
 
@@ -641,6 +748,7 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.sharedInformerFactory.Velero().V1().DeleteBackupRequests(),
 			s.veleroClient.VeleroV1(),
 			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
+			s.sharedInformerFactory.Velero().V1().Schedules(),
 		)
 
 		return controllerRunInfo{
@@ -663,6 +771,10 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
 			s.sharedInformerFactory.Velero().V1().VolumeSnapshotLocations(),
 			newPluginManager,
+			s.kubeClient.CoreV1(),
+			s.credentialFileStore,
+			s.discoveryHelper,
+			client.NewDynamicFactory(s.dynamicClient),
 			s.metrics,
 		)
 
@@ -675,13 +787,16 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 	restoreControllerRunInfo := func() controllerRunInfo {
 
 		restorer, err := restore.NewKubernetesRestorer(
+			s.veleroClient.VeleroV1(),
 			s.discoveryHelper,
 			client.NewDynamicFactory(s.dynamicClient),
 			s.config.restoreResourcePriorities,
 			s.kubeClient.CoreV1().Namespaces(),
+			podexec.NewPodCommandExecutor(s.kubeClientConfig, s.kubeClient.CoreV1().RESTClient()),
 			s.resticManager,
 			s.config.podVolumeOperationTimeout,
 			s.config.resourceTerminatingTimeout,
+			s.credentialFileStore,
 			s.logger,
 		)
 		cmd.CheckError(err)
@@ -698,6 +813,8 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.logger,
 			s.logLevel,
 			newPluginManager,
+			s.kubeClient.CoreV1(),
+			s.kubeClient.CoreV1(),
 			s.config.defaultBackupLocation,
 			s.metrics,
 			s.config.formatFlag.Parse(),
@@ -732,6 +849,8 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
 			s.sharedInformerFactory.Velero().V1().Backups(),
 			newPluginManager,
+			s.kubeClient.CoreV1(),
+			downloadServer,
 			s.logger,
 		)
 
@@ -755,16 +874,53 @@ func (s *server) runControllers(defaultVolumeSnapshotLocations map[string]string
 		}
 	}
 
+	verifyBackupRequestControllerRunInfo := func() controllerRunInfo {
+		verifyBackupRequestController := controller.NewVerifyBackupRequestController(
+			s.logger,
+			s.sharedInformerFactory.Velero().V1().VerifyBackupRequests(),
+			s.veleroClient.VeleroV1(),
+			s.sharedInformerFactory.Velero().V1().Backups(),
+			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
+			s.resticManager,
+			newPluginManager,
+			s.kubeClient.CoreV1(),
+		)
+
+		return controllerRunInfo{
+			controller: verifyBackupRequestController,
+			numWorkers: defaultControllerWorkers,
+		}
+	}
+
+	backupStorageLocationControllerRunInfo := func() controllerRunInfo {
+		backupStorageLocationController := controller.NewBackupStorageLocationController(
+			s.namespace,
+			s.veleroClient.VeleroV1(),
+			s.sharedInformerFactory.Velero().V1().BackupStorageLocations(),
+			s.config.storeValidationFrequency,
+			newPluginManager,
+			s.kubeClient.CoreV1(),
+			s.logger,
+		)
+
+		return controllerRunInfo{
+			controller: backupStorageLocationController,
+			numWorkers: defaultControllerWorkers,
+		}
+	}
+
 	enabledControllers := map[string]func() controllerRunInfo{
-		BackupSyncControllerKey:          backupSyncControllerRunInfo,
-		BackupControllerKey:              backupControllerRunInfo,
-		ScheduleControllerKey:            scheduleControllerRunInfo,
-		GcControllerKey:                  gcControllerRunInfo,
-		BackupDeletionControllerKey:      deletionControllerRunInfo,
-		RestoreControllerKey:             restoreControllerRunInfo,
-		ResticRepoControllerKey:          resticRepoControllerRunInfo,
-		DownloadRequestControllerKey:     downloadrequestControllerRunInfo,
-		ServerStatusRequestControllerKey: serverStatusRequestControllerRunInfo,
+		BackupSyncControllerKey:            backupSyncControllerRunInfo,
+		BackupControllerKey:                backupControllerRunInfo,
+		ScheduleControllerKey:              scheduleControllerRunInfo,
+		GcControllerKey:                    gcControllerRunInfo,
+		BackupDeletionControllerKey:        deletionControllerRunInfo,
+		RestoreControllerKey:               restoreControllerRunInfo,
+		ResticRepoControllerKey:            resticRepoControllerRunInfo,
+		DownloadRequestControllerKey:       downloadrequestControllerRunInfo,
+		ServerStatusRequestControllerKey:   serverStatusRequestControllerRunInfo,
+		VerifyBackupRequestControllerKey:   verifyBackupRequestControllerRunInfo,
+		BackupStorageLocationControllerKey: backupStorageLocationControllerRunInfo,
 	}
 
 	if s.config.restoreOnly {
@@ -821,3 +977,19 @@ func (s *server) runProfiler() {
 		s.logger.WithError(errors.WithStack(err)).Error("error running profiler http server")
 	}
 }
+
+// runTLSServer serves handler over TLS at address. name is used in log
+// messages.
+func (s *server) runTLSServer(name, address string, handler http.Handler, tlsConfig *tls.Config) {
+	s.logger.Infof("Starting %s at address [%s]", name, address)
+
+	httpServer := &http.Server{
+		Addr:      address,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	// the certificate is in tlsConfig, so no files are needed
+	if err := httpServer.ListenAndServeTLS("", ""); err != nil {
+		s.logger.WithError(errors.WithStack(err)).Errorf("error running %s", name)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1api "k8s.io/api/core/v1"
+)
+
+// SecretKeySelector is a Cobra-compatible wrapper for defining
+// a flag that references a key in a Secret, in the form NAME:KEY.
+type SecretKeySelector struct {
+	SecretKeySelector *corev1api.SecretKeySelector
+}
+
+// String returns a string representation of the secret
+// key selector flag.
+func (s *SecretKeySelector) String() string {
+	if s.SecretKeySelector == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", s.SecretKeySelector.Name, s.SecretKeySelector.Key)
+}
+
+// Set parses the provided NAME:KEY string and assigns the
+// result to the secret-key-selector receiver. It returns an
+// error if the string is not in the expected form.
+func (s *SecretKeySelector) Set(val string) error {
+	parts := strings.Split(val, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.Errorf("invalid secret key reference %q, expected NAME:KEY", val)
+	}
+
+	s.SecretKeySelector = &corev1api.SecretKeySelector{
+		LocalObjectReference: corev1api.LocalObjectReference{Name: parts[0]},
+		Key:                  parts[1],
+	}
+	return nil
+}
+
+// Type returns a string representation of the
+// SecretKeySelector type.
+func (s *SecretKeySelector) Type() string {
+	return "secretKeySelector"
+}
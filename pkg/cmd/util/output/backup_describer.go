@@ -55,6 +55,10 @@ func DescribeBackup(
 
 		d.Printf("Phase:\t%s%s\n", phase, logsNote)
 
+		if backup.Status.Phase == velerov1api.BackupPhaseQueued && backup.Status.QueuePosition > 0 {
+			d.Printf("Queue Position:\t%d\n", backup.Status.QueuePosition)
+		}
+
 		status := backup.Status
 		if len(status.ValidationErrors) > 0 {
 			d.Println()
@@ -139,6 +143,16 @@ func DescribeBackupSpec(d *Describer, spec velerov1api.BackupSpec) {
 	d.Println()
 	d.Printf("TTL:\t%s\n", spec.TTL.Duration)
 
+	d.Println()
+	s = "<server default>"
+	if spec.ItemBackupWorkers > 0 {
+		s = fmt.Sprintf("%d", spec.ItemBackupWorkers)
+	}
+	d.Printf("Item backup workers:\t%s\n", s)
+
+	d.Println()
+	d.Printf("Include all API group versions:\t%t\n", spec.IncludeAllAPIGroupVersions)
+
 	d.Println()
 	if len(spec.Hooks.Resources) == 0 {
 		d.Printf("Hooks:\t<none>\n")
@@ -216,6 +230,17 @@ func DescribeBackupStatus(d *Describer, backup *velerov1api.Backup, details bool
 
 	d.Printf("Backup Format Version:\t%d\n", status.Version)
 
+	// backups that were processed before the codec was recorded were
+	// compressed with gzip
+	codec := string(status.CompressionCodec)
+	if codec == "" {
+		codec = "<n/a>"
+		if status.Version > 0 {
+			codec = string(velerov1api.CompressionCodecGzip)
+		}
+	}
+	d.Printf("Compression:\t%s\n", codec)
+
 	d.Println()
 	// "<n/a>" output should only be applicable for backups that failed validation
 	if status.StartTimestamp.Time.IsZero() {
@@ -229,6 +254,17 @@ func DescribeBackupStatus(d *Describer, backup *velerov1api.Backup, details bool
 		d.Printf("Completed:\t%s\n", status.CompletionTimestamp.Time)
 	}
 
+	if status.Progress != nil {
+		d.Println()
+		if status.Phase == velerov1api.BackupPhaseInProgress {
+			d.Printf("Estimated total items to be backed up:\t%d\n", status.Progress.TotalItems)
+			d.Printf("Items backed up so far:\t%d\n", status.Progress.ItemsBackedUp)
+		} else {
+			d.Printf("Total items to be backed up:\t%d\n", status.Progress.TotalItems)
+			d.Printf("Items backed up:\t%d\n", status.Progress.ItemsBackedUp)
+		}
+	}
+
 	d.Println()
 	d.Printf("Expiration:\t%s\n", status.Expiration.Time)
 	d.Println()
@@ -352,6 +388,7 @@ func DescribePodVolumeBackups(d *Describer, backups []velerov1api.PodVolumeBacku
 	for _, phase := range []string{
 		string(velerov1api.PodVolumeBackupPhaseCompleted),
 		string(velerov1api.PodVolumeBackupPhaseFailed),
+		string(velerov1api.PodVolumeBackupPhaseCanceled),
 		"In Progress",
 		string(velerov1api.PodVolumeBackupPhaseNew),
 	} {
@@ -388,6 +425,7 @@ func groupByPhase(backups []velerov1api.PodVolumeBackup) map[string][]velerov1ap
 	phaseToGroup := map[velerov1api.PodVolumeBackupPhase]string{
 		velerov1api.PodVolumeBackupPhaseCompleted:  string(velerov1api.PodVolumeBackupPhaseCompleted),
 		velerov1api.PodVolumeBackupPhaseFailed:     string(velerov1api.PodVolumeBackupPhaseFailed),
+		velerov1api.PodVolumeBackupPhaseCanceled:   string(velerov1api.PodVolumeBackupPhaseCanceled),
 		velerov1api.PodVolumeBackupPhaseInProgress: "In Progress",
 		velerov1api.PodVolumeBackupPhaseNew:        string(velerov1api.PodVolumeBackupPhaseNew),
 		"":                                         string(velerov1api.PodVolumeBackupPhaseNew),
@@ -92,6 +92,15 @@ func printBackup(backup *velerov1api.Backup, w io.Writer, options printers.Print
 	if backup.DeletionTimestamp != nil && !backup.DeletionTimestamp.Time.IsZero() {
 		status = "Deleting"
 	}
+	if status == string(velerov1api.BackupPhaseInProgress) && backup.Status.Progress != nil {
+		status = fmt.Sprintf("%s (%d/%d items)", status, backup.Status.Progress.ItemsBackedUp, backup.Status.Progress.TotalItems)
+	}
+	if status == string(velerov1api.BackupPhaseQueued) && backup.Status.QueuePosition > 0 {
+		status = fmt.Sprintf("%s (position %d)", status, backup.Status.QueuePosition)
+	}
+	if backup.Spec.Cancel && (status == string(velerov1api.BackupPhaseNew) || backup.Status.Phase == velerov1api.BackupPhaseQueued || backup.Status.Phase == velerov1api.BackupPhaseInProgress) {
+		status = fmt.Sprintf("%s (canceling)", status)
+	}
 	if status == string(velerov1api.BackupPhasePartiallyFailed) {
 		if backup.Status.Errors == 1 {
 			status = fmt.Sprintf("%s (1 error)", status)
@@ -26,7 +26,7 @@ import (
 )
 
 var (
-	backupStorageLocationColumns = []string{"NAME", "PROVIDER", "BUCKET/PREFIX", "ACCESS MODE"}
+	backupStorageLocationColumns = []string{"NAME", "PROVIDER", "BUCKET/PREFIX", "PHASE", "LAST VALIDATED", "ACCESS MODE"}
 )
 
 func printBackupStorageLocationList(list *v1.BackupStorageLocationList, w io.Writer, options printers.PrintOptions) error {
@@ -57,12 +57,24 @@ func printBackupStorageLocation(location *v1.BackupStorageLocation, w io.Writer,
 		accessMode = v1.BackupStorageLocationAccessModeReadWrite
 	}
 
+	phase := string(location.Status.Phase)
+	if phase == "" {
+		phase = "Unknown"
+	}
+
+	lastValidated := "<never>"
+	if location.Status.LastValidationTime != nil {
+		lastValidated = humanReadableTimeFromNow(location.Status.LastValidationTime.Time)
+	}
+
 	if _, err := fmt.Fprintf(
 		w,
-		"%s\t%s\t%s\t%s",
+		"%s\t%s\t%s\t%s\t%s\t%s",
 		name,
 		location.Spec.Provider,
 		bucketAndPrefix,
+		phase,
+		lastValidated,
 		accessMode,
 	); err != nil {
 		return err
@@ -48,6 +48,11 @@ func DescribeRestore(restore *v1.Restore, podVolumeRestores []v1.PodVolumeRestor
 
 		d.Printf("Phase:\t%s%s\n", restore.Status.Phase, resultsNote)
 
+		if restore.Spec.DryRun {
+			d.Println()
+			d.Printf("Dry Run:\ttrue\n")
+		}
+
 		if len(restore.Status.ValidationErrors) > 0 {
 			d.Println()
 			d.Printf("Validation errors:")
@@ -56,6 +61,9 @@ func DescribeRestore(restore *v1.Restore, podVolumeRestores []v1.PodVolumeRestor
 			}
 		}
 
+		d.Println()
+		describeRestoreProgress(d, restore.Status)
+
 		describeRestoreResults(d, restore, veleroClient)
 
 		d.Println()
@@ -94,6 +102,14 @@ func DescribeRestore(restore *v1.Restore, podVolumeRestores []v1.PodVolumeRestor
 
 		d.Printf("\tCluster-scoped:\t%s\n", BoolPointerString(restore.Spec.IncludeClusterResources, "excluded", "included", "auto"))
 
+		if len(restore.Spec.IncludedItems) > 0 {
+			d.Println()
+			d.Printf("Included items:\n")
+			for _, item := range restore.Spec.IncludedItems {
+				d.Printf("\t%s\n", item)
+			}
+		}
+
 		d.Println()
 		d.DescribeMap("Namespace mappings", restore.Spec.NamespaceMapping)
 
@@ -107,6 +123,21 @@ func DescribeRestore(restore *v1.Restore, podVolumeRestores []v1.PodVolumeRestor
 		d.Println()
 		d.Printf("Restore PVs:\t%s\n", BoolPointerString(restore.Spec.RestorePVs, "false", "true", "auto"))
 
+		d.Println()
+		policy := restore.Spec.ExistingResourcePolicy
+		if policy == "" {
+			policy = v1.ExistingResourcePolicyNone
+		}
+		d.Printf("Existing Resource Policy:\t%s\n", policy)
+
+		if restore.Spec.ResourceModifiers != nil {
+			d.Println()
+			d.Printf("Resource Modifiers:\t%s\n", restore.Spec.ResourceModifiers.Name)
+		}
+
+		d.Println()
+		describeRestoreHooks(d, restore.Spec.Hooks)
+
 		if len(podVolumeRestores) > 0 {
 			d.Println()
 			describePodVolumeRestores(d, podVolumeRestores, details)
@@ -114,8 +145,130 @@ func DescribeRestore(restore *v1.Restore, podVolumeRestores []v1.PodVolumeRestor
 	})
 }
 
+// describeRestoreProgress describes a restore's start/completion times and the number
+// of items restored, in total and for each resource, in human-readable format.
+func describeRestoreProgress(d *Describer, status v1.RestoreStatus) {
+	if status.StartTimestamp.Time.IsZero() {
+		d.Printf("Started:\t%s\n", "<n/a>")
+	} else {
+		d.Printf("Started:\t%s\n", status.StartTimestamp.Time)
+	}
+	if status.CompletionTimestamp.Time.IsZero() {
+		d.Printf("Completed:\t%s\n", "<n/a>")
+	} else {
+		d.Printf("Completed:\t%s\n", status.CompletionTimestamp.Time)
+	}
+
+	if status.Progress == nil {
+		return
+	}
+
+	d.Println()
+	if status.Phase == v1.RestorePhaseInProgress {
+		d.Printf("Estimated total items to be restored:\t%d\n", status.Progress.TotalItems)
+		d.Printf("Items restored so far:\t%d\n", status.Progress.ItemsRestored)
+	} else {
+		d.Printf("Total items to be restored:\t%d\n", status.Progress.TotalItems)
+		d.Printf("Items restored:\t%d\n", status.Progress.ItemsRestored)
+	}
+
+	var resources []string
+	for resource, progress := range status.Progress.Resources {
+		if progress.TotalItems == 0 {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	if len(resources) == 0 {
+		return
+	}
+	sort.Strings(resources)
+
+	d.Println()
+	d.Printf("Items restored by resource:\n")
+	for _, resource := range resources {
+		progress := status.Progress.Resources[resource]
+		d.Printf("\t%s:\t%d of %d\n", resource, progress.ItemsRestored, progress.TotalItems)
+	}
+}
+
+// describeRestoreHooks describes a restore's hook specs in human-readable format.
+func describeRestoreHooks(d *Describer, hooks v1.RestoreHooks) {
+	if len(hooks.Resources) == 0 {
+		d.Printf("Hooks:\t<none>\n")
+		return
+	}
+
+	d.Printf("Hooks:\n")
+	d.Printf("\tResources:\n")
+	for _, hookSpec := range hooks.Resources {
+		d.Printf("\t\t%s:\n", hookSpec.Name)
+		d.Printf("\t\t\tNamespaces:\n")
+		var s string
+		if len(hookSpec.IncludedNamespaces) == 0 {
+			s = "*"
+		} else {
+			s = strings.Join(hookSpec.IncludedNamespaces, ", ")
+		}
+		d.Printf("\t\t\t\tIncluded:\t%s\n", s)
+		if len(hookSpec.ExcludedNamespaces) == 0 {
+			s = "<none>"
+		} else {
+			s = strings.Join(hookSpec.ExcludedNamespaces, ", ")
+		}
+		d.Printf("\t\t\t\tExcluded:\t%s\n", s)
+
+		d.Println()
+		d.Printf("\t\t\tResources:\n")
+		if len(hookSpec.IncludedResources) == 0 {
+			s = "*"
+		} else {
+			s = strings.Join(hookSpec.IncludedResources, ", ")
+		}
+		d.Printf("\t\t\t\tIncluded:\t%s\n", s)
+		if len(hookSpec.ExcludedResources) == 0 {
+			s = "<none>"
+		} else {
+			s = strings.Join(hookSpec.ExcludedResources, ", ")
+		}
+		d.Printf("\t\t\t\tExcluded:\t%s\n", s)
+
+		d.Println()
+		s = "<none>"
+		if hookSpec.LabelSelector != nil {
+			s = metav1.FormatLabelSelector(hookSpec.LabelSelector)
+		}
+		d.Printf("\t\t\tLabel selector:\t%s\n", s)
+
+		for _, hook := range hookSpec.PostHooks {
+			if hook.Init != nil {
+				d.Println()
+				d.Printf("\t\t\tInit Hook:\n")
+				for _, container := range hook.Init.InitContainers {
+					d.Printf("\t\t\t\tContainer:\t%s\n", container.Name)
+					d.Printf("\t\t\t\t\tImage:\t%s\n", container.Image)
+					d.Printf("\t\t\t\t\tCommand:\t%s\n", strings.Join(container.Command, " "))
+				}
+			}
+			if hook.Exec != nil {
+				d.Println()
+				d.Printf("\t\t\tExec Hook:\n")
+				d.Printf("\t\t\t\tContainer:\t%s\n", hook.Exec.Container)
+				d.Printf("\t\t\t\tCommand:\t%s\n", strings.Join(hook.Exec.Command, " "))
+				d.Printf("\t\t\t\tOn Error:\t%s\n", hook.Exec.OnError)
+				d.Printf("\t\t\t\tExec Timeout:\t%s\n", hook.Exec.ExecTimeout.Duration)
+				d.Printf("\t\t\t\tWait Timeout:\t%s\n", hook.Exec.WaitTimeout.Duration)
+			}
+		}
+	}
+}
+
 func describeRestoreResults(d *Describer, restore *v1.Restore, veleroClient clientset.Interface) {
-	if restore.Status.Warnings == 0 && restore.Status.Errors == 0 {
+	// a dry run's report is stored with its results, so they're needed even
+	// if there are no warnings or errors, once the restore has finished.
+	dryRunReport := restore.Spec.DryRun && !restore.Status.CompletionTimestamp.Time.IsZero()
+
+	if restore.Status.Warnings == 0 && restore.Status.Errors == 0 && !dryRunReport {
 		return
 	}
 
@@ -140,6 +293,17 @@ func describeRestoreResults(d *Describer, restore *v1.Restore, veleroClient clie
 		d.Println()
 		describeRestoreResult(d, "Errors", resultMap["errors"])
 	}
+
+	if dryRunReport {
+		d.Println()
+		describeRestoreResult(d, "Would Create", resultMap[pkgrestore.DryRunCreateResultsKey])
+		d.Println()
+		describeRestoreResult(d, "Unchanged", resultMap[pkgrestore.DryRunUnchangedResultsKey])
+		d.Println()
+		describeRestoreResult(d, "Changed", resultMap[pkgrestore.DryRunChangedResultsKey])
+		d.Println()
+		describeRestoreResult(d, "Skipped", resultMap[pkgrestore.DryRunSkippedResultsKey])
+	}
 }
 
 func describeRestoreResult(d *Describer, name string, result pkgrestore.Result) {
@@ -171,6 +335,7 @@ func describePodVolumeRestores(d *Describer, restores []v1.PodVolumeRestore, det
 	for _, phase := range []string{
 		string(v1.PodVolumeRestorePhaseCompleted),
 		string(v1.PodVolumeRestorePhaseFailed),
+		string(v1.PodVolumeRestorePhaseCanceled),
 		"In Progress",
 		string(v1.PodVolumeRestorePhaseNew),
 	} {
@@ -207,6 +372,7 @@ func groupRestoresByPhase(restores []v1.PodVolumeRestore) map[string][]v1.PodVol
 	phaseToGroup := map[v1.PodVolumeRestorePhase]string{
 		v1.PodVolumeRestorePhaseCompleted:  string(v1.PodVolumeRestorePhaseCompleted),
 		v1.PodVolumeRestorePhaseFailed:     string(v1.PodVolumeRestorePhaseFailed),
+		v1.PodVolumeRestorePhaseCanceled:   string(v1.PodVolumeRestorePhaseCanceled),
 		v1.PodVolumeRestorePhaseInProgress: "In Progress",
 		v1.PodVolumeRestorePhaseNew:        string(v1.PodVolumeRestorePhaseNew),
 		"":                                 string(v1.PodVolumeRestorePhaseNew),
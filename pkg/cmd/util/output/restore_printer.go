@@ -48,9 +48,15 @@ func printRestore(restore *v1.Restore, w io.Writer, options printers.PrintOption
 		}
 	}
 
-	status := restore.Status.Phase
+	status := string(restore.Status.Phase)
 	if status == "" {
-		status = v1.RestorePhaseNew
+		status = string(v1.RestorePhaseNew)
+	}
+	if status == string(v1.RestorePhaseInProgress) && restore.Status.Progress != nil {
+		status = fmt.Sprintf("%s (%d/%d items)", status, restore.Status.Progress.ItemsRestored, restore.Status.Progress.TotalItems)
+	}
+	if restore.Spec.Cancel && (status == string(v1.RestorePhaseNew) || restore.Status.Phase == v1.RestorePhaseInProgress) {
+		status = fmt.Sprintf("%s (canceling)", status)
 	}
 
 	if _, err := fmt.Fprintf(
@@ -32,6 +32,7 @@ func DescribeSchedule(schedule *v1.Schedule) string {
 			phase = v1.SchedulePhaseNew
 		}
 		d.Printf("Phase:\t%s\n", phase)
+		d.Printf("Paused:\t%t\n", schedule.Spec.Paused)
 
 		status := schedule.Status
 		if len(status.ValidationErrors) > 0 {
@@ -53,6 +54,15 @@ func DescribeSchedule(schedule *v1.Schedule) string {
 func DescribeScheduleSpec(d *Describer, spec v1.ScheduleSpec) {
 	d.Printf("Schedule:\t%s\n", spec.Schedule)
 
+	if r := spec.Retention; r != nil {
+		d.Println()
+		d.Println("Retention:")
+		d.Printf("\tKeep Last:\t%d\n", r.KeepLast)
+		d.Printf("\tKeep Daily:\t%d\n", r.KeepDaily)
+		d.Printf("\tKeep Weekly:\t%d\n", r.KeepWeekly)
+		d.Printf("\tKeep Monthly:\t%d\n", r.KeepMonthly)
+	}
+
 	d.Println()
 	d.Println("Backup Template:")
 	d.Prefix = "\t"
@@ -66,4 +76,11 @@ func DescribeScheduleStatus(d *Describer, status v1.ScheduleStatus) {
 		lastBackup = fmt.Sprintf("%v", status.LastBackup.Time)
 	}
 	d.Printf("Last Backup:\t%s\n", lastBackup)
+
+	if status.PausedAt != nil {
+		d.Printf("Paused At:\t%v\n", status.PausedAt.Time)
+	}
+	if status.PausedBy != "" {
+		d.Printf("Paused By:\t%s\n", status.PausedBy)
+	}
 }
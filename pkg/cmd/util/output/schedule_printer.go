@@ -52,6 +52,9 @@ func printSchedule(schedule *v1.Schedule, w io.Writer, options printers.PrintOpt
 	if status == "" {
 		status = v1.SchedulePhaseNew
 	}
+	if schedule.Spec.Paused && status == v1.SchedulePhaseEnabled {
+		status = "Paused"
+	}
 
 	_, err := fmt.Fprintf(
 		w,
@@ -19,11 +19,13 @@ package controller
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -35,9 +37,11 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	pkgbackup "github.com/heptio/velero/pkg/backup"
 	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
@@ -69,8 +73,11 @@ type backupController struct {
 	snapshotLocationLister   listers.VolumeSnapshotLocationLister
 	defaultSnapshotLocations map[string]string
 	metrics                  *metrics.ServerMetrics
-	newBackupStore           func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	secretsGetter            corev1client.SecretsGetter
+	newBackupStore           func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
 	formatFlag               logging.Format
+	running                  *runningOperations
+	limiter                  *backupLimiter
 }
 
 func NewBackupController(
@@ -80,6 +87,7 @@ func NewBackupController(
 	logger logrus.FieldLogger,
 	backupLogLevel logrus.Level,
 	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
 	backupTracker BackupTracker,
 	backupLocationInformer informers.BackupStorageLocationInformer,
 	defaultBackupLocation string,
@@ -88,6 +96,7 @@ func NewBackupController(
 	defaultSnapshotLocations map[string]string,
 	metrics *metrics.ServerMetrics,
 	formatFlag logging.Format,
+	maxConcurrentBackups int,
 ) Interface {
 	c := &backupController{
 		genericController:        newGenericController("backup", logger),
@@ -97,6 +106,7 @@ func NewBackupController(
 		clock:                    &clock.RealClock{},
 		backupLogLevel:           backupLogLevel,
 		newPluginManager:         newPluginManager,
+		secretsGetter:            secretsGetter,
 		backupTracker:            backupTracker,
 		backupLocationLister:     backupLocationInformer.Lister(),
 		defaultBackupLocation:    defaultBackupLocation,
@@ -105,6 +115,8 @@ func NewBackupController(
 		defaultSnapshotLocations: defaultSnapshotLocations,
 		metrics:                  metrics,
 		formatFlag:               formatFlag,
+		running:                  newRunningOperations(),
+		limiter:                  newBackupLimiter(maxConcurrentBackups),
 
 		newBackupStore: persistence.NewObjectBackupStore,
 	}
@@ -124,8 +136,8 @@ func NewBackupController(
 				backup := obj.(*velerov1api.Backup)
 
 				switch backup.Status.Phase {
-				case "", velerov1api.BackupPhaseNew:
-					// only process new backups
+				case "", velerov1api.BackupPhaseNew, velerov1api.BackupPhaseQueued:
+					// only process new and queued backups
 				default:
 					c.logger.WithFields(logrus.Fields{
 						"backup": kubeutil.NamespaceAndName(backup),
@@ -141,6 +153,33 @@ func NewBackupController(
 				}
 				c.queue.Add(key)
 			},
+			UpdateFunc: func(_, obj interface{}) {
+				backup := obj.(*velerov1api.Backup)
+
+				if !backup.Spec.Cancel {
+					return
+				}
+				switch backup.Status.Phase {
+				case "", velerov1api.BackupPhaseNew, velerov1api.BackupPhaseQueued, velerov1api.BackupPhaseInProgress:
+					// only unfinished backups can be canceled
+				default:
+					return
+				}
+
+				key, err := cache.MetaNamespaceKeyFunc(backup)
+				if err != nil {
+					c.logger.WithError(err).WithField("backup", backup).Error("Error creating queue key, item not added to queue")
+					return
+				}
+
+				// a running backup is canceled straight away. Any other is
+				// processed as usual, and marked Canceled then.
+				if c.running.cancel(key) {
+					c.logger.WithField("backup", kubeutil.NamespaceAndName(backup)).Debug("Canceled running backup")
+					return
+				}
+				c.queue.Add(key)
+			},
 		},
 	)
 
@@ -154,6 +193,70 @@ func (c *backupController) resync() {
 	} else {
 		c.metrics.SetBackupTotal(int64(len(backups)))
 	}
+
+	// queued backups are normally retried whenever a running backup
+	// finishes, but are retried here too in case one was missed.
+	c.enqueueQueuedBackups()
+}
+
+// enqueueQueuedBackups adds all Queued backups to the work queue, in the order
+// they were queued, so they're started if they can now run.
+func (c *backupController) enqueueQueuedBackups() {
+	backups, err := c.lister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(err).Error("Error listing backups")
+		return
+	}
+
+	for _, backup := range queuedBackups(backups) {
+		key, err := cache.MetaNamespaceKeyFunc(backup)
+		if err != nil {
+			c.logger.WithError(err).WithField("backup", backup).Error("Error creating queue key, item not added to queue")
+			continue
+		}
+		c.queue.Add(key)
+	}
+}
+
+// queuedBackups returns the Queued backups from the given list, in the order
+// they were created.
+func queuedBackups(backups []*velerov1api.Backup) []*velerov1api.Backup {
+	var queued []*velerov1api.Backup
+	for _, backup := range backups {
+		if backup.Status.Phase == velerov1api.BackupPhaseQueued {
+			queued = append(queued, backup)
+		}
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		return createdBefore(queued[i], queued[j])
+	})
+
+	return queued
+}
+
+func createdBefore(a, b *velerov1api.Backup) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// queuePosition returns the position a backup has, or would have, in the queue
+// of backups waiting to run, starting at 1.
+func (c *backupController) queuePosition(backup *velerov1api.Backup) (int, error) {
+	backups, err := c.lister.Backups(backup.Namespace).List(labels.Everything())
+	if err != nil {
+		return 0, errors.Wrap(err, "error listing backups")
+	}
+
+	position := 1
+	for _, queued := range queuedBackups(backups) {
+		if queued.Name != backup.Name && createdBefore(queued, backup) {
+			position++
+		}
+	}
+	return position, nil
 }
 
 func (c *backupController) processBackup(key string) error {
@@ -185,22 +288,60 @@ func (c *backupController) processBackup(key string) error {
 	// InProgress, we still need this check so we can return nil to indicate we've finished processing
 	// this key (even though it was a no-op).
 	switch original.Status.Phase {
-	case "", velerov1api.BackupPhaseNew:
-		// only process new backups
+	case "", velerov1api.BackupPhaseNew, velerov1api.BackupPhaseQueued:
+		// only process new and queued backups
+	case velerov1api.BackupPhaseInProgress:
+		// backups that are in progress are only queued if they've been
+		// canceled and aren't being run by this server, e.g. because it
+		// restarted while they were running.
+		if original.Spec.Cancel {
+			return c.cancelOrphanedBackup(original, log)
+		}
+		return nil
 	default:
 		return nil
 	}
 
 	log.Debug("Preparing backup request")
 	request := c.prepareBackupRequest(original)
+	request.Status.QueuePosition = 0
 
-	if len(request.Status.ValidationErrors) > 0 {
+	switch {
+	case request.Spec.Cancel:
+		log.Info("Backup was canceled before it started")
+		request.Status.Phase = velerov1api.BackupPhaseCanceled
+		request.Status.CompletionTimestamp.Time = c.clock.Now()
+	case len(request.Status.ValidationErrors) > 0:
 		request.Status.Phase = velerov1api.BackupPhaseFailedValidation
-	} else {
+	default:
+		reason, err := c.startBackup(key, request)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			position, err := c.queuePosition(request.Backup)
+			if err != nil {
+				return err
+			}
+			log.WithField("position", position).Infof("Queuing backup because %s", reason)
+			request.Status.Phase = velerov1api.BackupPhaseQueued
+			request.Status.QueuePosition = position
+			break
+		}
+
+		// once the backup's finished, queued backups may be able to run.
+		defer c.enqueueQueuedBackups()
+		defer c.limiter.finish(key)
+
 		request.Status.Phase = velerov1api.BackupPhaseInProgress
 		request.Status.StartTimestamp.Time = c.clock.Now()
 	}
 
+	// the backup's recorded as running before it's marked InProgress, so
+	// that it can be canceled from then on.
+	ctx, finish := c.running.start(key)
+	defer finish()
+
 	// update status
 	updatedBackup, err := patchBackup(original, request.Backup, c.client)
 	if err != nil {
@@ -210,10 +351,16 @@ func (c *backupController) processBackup(key string) error {
 	original = updatedBackup
 	request.Backup = updatedBackup.DeepCopy()
 
-	if request.Status.Phase == velerov1api.BackupPhaseFailedValidation {
+	if request.Status.Phase != velerov1api.BackupPhaseInProgress {
 		return nil
 	}
 
+	// the backup may have been canceled after it was retrieved, but
+	// before it was recorded as running.
+	if updatedBackup.Spec.Cancel {
+		c.running.cancel(key)
+	}
+
 	c.backupTracker.Add(request.Namespace, request.Name)
 	defer c.backupTracker.Delete(request.Namespace, request.Name)
 
@@ -223,7 +370,7 @@ func (c *backupController) processBackup(key string) error {
 	c.metrics.RegisterBackupAttempt(backupScheduleName)
 
 	// execution & upload of backup
-	if err := c.runBackup(request); err != nil {
+	if err := c.runBackup(ctx, request); err != nil {
 		// even though runBackup sets the backup's phase prior
 		// to uploading artifacts to object storage, we have to
 		// check for an error again here and update the phase if
@@ -251,6 +398,51 @@ func (c *backupController) processBackup(key string) error {
 	return nil
 }
 
+// startBackup records a backup as running if it can run now. If it can't, it
+// returns a description of why the backup has to wait.
+func (c *backupController) startBackup(key string, request *pkgbackup.Request) (string, error) {
+	// new backups go to the back of the queue, if there is one.
+	if request.Status.Phase != velerov1api.BackupPhaseQueued {
+		position, err := c.queuePosition(request.Backup)
+		if err != nil {
+			return "", err
+		}
+		if position > 1 {
+			return "other backups are already queued", nil
+		}
+	}
+
+	return c.limiter.start(key, request.Backup, request.StorageLocation), nil
+}
+
+// cancelOrphanedBackup marks a backup that's in progress, but isn't being run by
+// this server, as Canceled.
+func (c *backupController) cancelOrphanedBackup(backup *velerov1api.Backup, log logrus.FieldLogger) error {
+	// the informer's copy may be out of date, so make sure the backup's
+	// still in progress.
+	original, err := c.client.Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting backup")
+	}
+	if original.Status.Phase != velerov1api.BackupPhaseInProgress {
+		return nil
+	}
+
+	log.Info("Backup isn't being run by this server, marking it as canceled")
+	updated := original.DeepCopy()
+	updated.Status.Phase = velerov1api.BackupPhaseCanceled
+	updated.Status.CompletionTimestamp.Time = c.clock.Now()
+
+	if _, err := patchBackup(original, updated, c.client); err != nil {
+		return errors.Wrap(err, "error updating backup's status to Canceled")
+	}
+
+	return nil
+}
+
 func patchBackup(original, updated *velerov1api.Backup, client velerov1client.BackupsGetter) (*velerov1api.Backup, error) {
 	origBytes, err := json.Marshal(original)
 	if err != nil {
@@ -312,6 +504,10 @@ func (c *backupController) prepareBackupRequest(backup *velerov1api.Backup) *pkg
 		request.Status.ValidationErrors = append(request.Status.ValidationErrors, fmt.Sprintf("Invalid included/excluded namespace lists: %v", err))
 	}
 
+	if request.Spec.ItemBackupWorkers < 0 {
+		request.Status.ValidationErrors = append(request.Status.ValidationErrors, "Invalid itemBackupWorkers: must be zero or greater")
+	}
+
 	// validate the storage location, and store the BackupStorageLocation API obj on the request
 	if storageLocation, err := c.backupLocationLister.BackupStorageLocations(request.Namespace).Get(request.Spec.StorageLocation); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -326,6 +522,23 @@ func (c *backupController) prepareBackupRequest(backup *velerov1api.Backup) *pkg
 			request.Status.ValidationErrors = append(request.Status.ValidationErrors,
 				fmt.Sprintf("backup can't be created because backup storage location %s is currently in read-only mode", request.StorageLocation.Name))
 		}
+
+		if request.StorageLocation.Status.Phase == velerov1api.BackupStorageLocationPhaseUnavailable {
+			msg := fmt.Sprintf("backup can't be created because backup storage location %s is unavailable", request.StorageLocation.Name)
+			if request.StorageLocation.Status.Message != "" {
+				msg += ": " + request.StorageLocation.Status.Message
+			}
+			request.Status.ValidationErrors = append(request.Status.ValidationErrors, msg)
+		}
+	}
+
+	// resolve and validate the tarball's compression, and record the codec
+	// so the tarball can be decompressed later
+	request.Compression = resolveCompression(request.Backup, request.StorageLocation)
+	if err := archive.ValidateCompression(archive.Codec(request.Compression.Codec), request.Compression.Level); err != nil {
+		request.Status.ValidationErrors = append(request.Status.ValidationErrors, fmt.Sprintf("Invalid compression: %v", err))
+	} else {
+		request.Status.CompressionCodec = request.Compression.Codec
 	}
 
 	// validate and get the backup's VolumeSnapshotLocations, and store the
@@ -343,13 +556,32 @@ func (c *backupController) prepareBackupRequest(backup *velerov1api.Backup) *pkg
 	return request
 }
 
+// resolveCompression returns the compression configuration for a backup's
+// tarball: the backup's own, if it has one, otherwise its storage location's,
+// otherwise gzip at its default level.
+func resolveCompression(backup *velerov1api.Backup, location *velerov1api.BackupStorageLocation) velerov1api.CompressionConfig {
+	var config velerov1api.CompressionConfig
+	switch {
+	case backup.Spec.Compression != nil:
+		config = *backup.Spec.Compression
+	case location != nil && location.Spec.Compression != nil:
+		config = *location.Spec.Compression
+	}
+
+	if config.Codec == "" {
+		config.Codec = velerov1api.CompressionCodecGzip
+	}
+
+	return config
+}
+
 // validateAndGetSnapshotLocations gets a collection of VolumeSnapshotLocation objects that
 // this backup will use (returned as a map of provider name -> VSL), and ensures:
-// - each location name in .spec.volumeSnapshotLocations exists as a location
-// - exactly 1 location per provider
-// - a given provider's default location name is added to .spec.volumeSnapshotLocations if one
-//   is not explicitly specified for the provider (if there's only one location for the provider,
-//   it will automatically be used)
+//   - each location name in .spec.volumeSnapshotLocations exists as a location
+//   - exactly 1 location per provider
+//   - a given provider's default location name is added to .spec.volumeSnapshotLocations if one
+//     is not explicitly specified for the provider (if there's only one location for the provider,
+//     it will automatically be used)
 func (c *backupController) validateAndGetSnapshotLocations(backup *velerov1api.Backup) (map[string]*velerov1api.VolumeSnapshotLocation, []string) {
 	errors := []string{}
 	providerLocations := make(map[string]*velerov1api.VolumeSnapshotLocation)
@@ -433,10 +665,13 @@ func (c *backupController) validateAndGetSnapshotLocations(backup *velerov1api.B
 	return providerLocations, nil
 }
 
-// runBackup runs and uploads a validated backup. Any error returned from this function
-// causes the backup to be Failed; if no error is returned, the backup's status's Errors
-// field is checked to see if the backup was a partial failure.
-func (c *backupController) runBackup(backup *pkgbackup.Request) error {
+// runBackup runs and uploads a validated backup. The backup's tarball is streamed to
+// object storage as it's written, and its metadata is only uploaded once that's done.
+// Any error returned from this function causes the backup to be Failed; if no error is
+// returned, the backup's status's Errors field is checked to see if the backup was a
+// partial failure. If ctx is canceled, the backup is Canceled, and the upload of its
+// contents is aborted.
+func (c *backupController) runBackup(ctx context.Context, backup *pkgbackup.Request) error {
 	c.logger.WithField("backup", kubeutil.NamespaceAndName(backup)).Info("Setting up backup log")
 
 	logFile, err := ioutil.TempFile("", "")
@@ -459,13 +694,6 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 
 	backupLog := logger.WithField("backup", kubeutil.NamespaceAndName(backup))
 
-	backupLog.Info("Setting up backup temp file")
-	backupFile, err := ioutil.TempFile("", "")
-	if err != nil {
-		return errors.Wrap(err, "error creating temp file for backup")
-	}
-	defer closeAndRemoveFile(backupFile, backupLog)
-
 	backupLog.Info("Setting up plugin manager")
 	pluginManager := c.newPluginManager(backupLog)
 	defer pluginManager.CleanupClients()
@@ -477,7 +705,7 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 	}
 
 	backupLog.Info("Setting up backup store")
-	backupStore, err := c.newBackupStore(backup.StorageLocation, pluginManager, backupLog)
+	backupStore, err := c.newBackupStore(backup.StorageLocation, pluginManager, c.secretsGetter, backupLog)
 	if err != nil {
 		return err
 	}
@@ -492,9 +720,40 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 		return errors.Errorf("backup already exists in object storage")
 	}
 
+	backupLog.Info("Starting upload of backup contents")
+	contentsReader, contentsWriter := io.Pipe()
+	backupContents := &countingWriter{w: contentsWriter}
+	upload := make(chan contentsUpload, 1)
+	go func() {
+		digest, err := backupStore.PutBackupContents(backup.Name, contentsReader)
+		// if the upload stopped early, unblock the backupper's writes
+		contentsReader.CloseWithError(err)
+		upload <- contentsUpload{digest: digest, err: err}
+	}()
+
 	var fatalErrs []error
-	if err := c.backupper.Backup(backupLog, backup, backupFile, actions, pluginManager); err != nil {
-		fatalErrs = append(fatalErrs, err)
+	backupErr := c.backupper.Backup(ctx, backupLog, backup, backupContents, actions, pluginManager)
+	if backupErr != nil && ctx.Err() == nil {
+		fatalErrs = append(fatalErrs, backupErr)
+	}
+
+	// the partial tarball of a canceled or failed backup isn't uploaded, but
+	// its log and the snapshots it took are, so that they're cleaned up when
+	// the backup is deleted.
+	switch {
+	case ctx.Err() != nil:
+		backupLog.Info("Backup was canceled, aborting upload of its contents")
+		contentsWriter.CloseWithError(errors.Wrap(ctx.Err(), "backup was canceled"))
+	case backupErr != nil:
+		backupLog.Info("Backup failed, aborting upload of its contents")
+		contentsWriter.CloseWithError(errors.Wrap(backupErr, "backup failed"))
+	default:
+		contentsWriter.Close()
+	}
+
+	uploaded := <-upload
+	if uploaded.err != nil && ctx.Err() == nil && backupErr == nil {
+		fatalErrs = append(fatalErrs, errors.Wrap(uploaded.err, "error uploading backup contents"))
 	}
 
 	// Mark completion timestamp before serializing and uploading.
@@ -508,7 +767,7 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 		}
 	}
 
-	recordBackupMetrics(backupLog, backup.Backup, backupFile, c.metrics)
+	recordBackupMetrics(backup.Backup, backupContents.n, c.metrics)
 
 	if err := gzippedLogFile.Close(); err != nil {
 		c.logger.WithError(err).Error("error closing gzippedLogFile")
@@ -522,6 +781,8 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 	// artifacts to object storage so that the JSON representation of the
 	// backup in object storage has the terminal phase set.
 	switch {
+	case ctx.Err() != nil:
+		backup.Status.Phase = velerov1api.BackupPhaseCanceled
 	case len(fatalErrs) > 0:
 		backup.Status.Phase = velerov1api.BackupPhaseFailed
 	case logCounter.GetCount(logrus.ErrorLevel) > 0:
@@ -530,7 +791,7 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 		backup.Status.Phase = velerov1api.BackupPhaseCompleted
 	}
 
-	if errs := persistBackup(backup, backupFile, logFile, backupStore, c.logger); len(errs) > 0 {
+	if errs := persistBackup(backup, uploaded.digest, logFile, backupStore, c.logger); len(errs) > 0 {
 		fatalErrs = append(fatalErrs, errs...)
 	}
 
@@ -541,15 +802,29 @@ func (c *backupController) runBackup(backup *pkgbackup.Request) error {
 	return kerrors.NewAggregate(fatalErrs)
 }
 
-func recordBackupMetrics(log logrus.FieldLogger, backup *velerov1api.Backup, backupFile *os.File, serverMetrics *metrics.ServerMetrics) {
+// contentsUpload is the result of streaming a backup's tarball to object
+// storage.
+type contentsUpload struct {
+	digest string
+	err    error
+}
+
+// countingWriter counts the bytes written through it, so the size of a
+// backup's tarball is known without it being staged on disk.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func recordBackupMetrics(backup *velerov1api.Backup, backupSizeBytes int64, serverMetrics *metrics.ServerMetrics) {
 	backupScheduleName := backup.GetLabels()[velerov1api.ScheduleNameLabel]
 
-	var backupSizeBytes int64
-	if backupFileStat, err := backupFile.Stat(); err != nil {
-		log.WithError(errors.WithStack(err)).Error("Error getting backup file info")
-	} else {
-		backupSizeBytes = backupFileStat.Size()
-	}
 	serverMetrics.SetBackupTarballSizeBytesGauge(backupScheduleName, backupSizeBytes)
 
 	backupDuration := backup.Status.CompletionTimestamp.Time.Sub(backup.Status.StartTimestamp.Time)
@@ -560,7 +835,10 @@ func recordBackupMetrics(log logrus.FieldLogger, backup *velerov1api.Backup, bac
 	serverMetrics.RegisterVolumeSnapshotFailures(backupScheduleName, backup.Status.VolumeSnapshotsAttempted-backup.Status.VolumeSnapshotsCompleted)
 }
 
-func persistBackup(backup *pkgbackup.Request, backupContents, backupLog *os.File, backupStore persistence.BackupStore, log logrus.FieldLogger) []error {
+// persistBackup uploads a backup's metadata, log and other files alongside
+// its contents, which have already been uploaded with the given digest, or
+// not at all if the digest is empty.
+func persistBackup(backup *pkgbackup.Request, contentsDigest string, backupLog *os.File, backupStore persistence.BackupStore, log logrus.FieldLogger) []error {
 	errs := []error{}
 	backupJSON := new(bytes.Buffer)
 
@@ -598,10 +876,26 @@ func persistBackup(backup *pkgbackup.Request, backupContents, backupLog *os.File
 		errs = append(errs, errors.Wrap(err, "error closing gzip writer"))
 	}
 
+	// the tarball index is only used to restore without extracting the
+	// tarball, so failing to encode it doesn't fail the backup.
+	var tarballIndex io.Reader
+	if backup.TarballIndex != nil {
+		buf := new(bytes.Buffer)
+		gzw = gzip.NewWriter(buf)
+
+		if err := json.NewEncoder(gzw).Encode(backup.TarballIndex); err != nil {
+			log.WithError(err).Error("Error encoding tarball index")
+		} else if err := gzw.Close(); err != nil {
+			log.WithError(err).Error("Error closing gzip writer")
+		} else {
+			tarballIndex = buf
+		}
+	}
+
 	if len(errs) > 0 {
-		// Don't upload the JSON files or backup tarball if encoding to json fails.
+		// Don't upload the JSON files if encoding to json fails. This also
+		// removes the backup tarball.
 		backupJSON = nil
-		backupContents = nil
 		volumeSnapshots = nil
 		backupResourceList = nil
 	}
@@ -609,11 +903,12 @@ func persistBackup(backup *pkgbackup.Request, backupContents, backupLog *os.File
 	backupInfo := persistence.BackupInfo{
 		Name:               backup.Name,
 		Metadata:           backupJSON,
-		Contents:           backupContents,
+		ContentsDigest:     contentsDigest,
 		Log:                backupLog,
 		PodVolumeBackups:   podVolumeBackups,
 		VolumeSnapshots:    volumeSnapshots,
 		BackupResourceList: backupResourceList,
+		TarballIndex:       tarballIndex,
 	}
 	if err := backupStore.PutBackup(backupInfo); err != nil {
 		errs = append(errs, err)
@@ -18,8 +18,10 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
 	"strings"
 	"testing"
@@ -32,10 +34,12 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
 	pkgbackup "github.com/heptio/velero/pkg/backup"
 	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/cloudprovider"
 	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
 	"github.com/heptio/velero/pkg/metrics"
@@ -44,6 +48,7 @@ import (
 	"github.com/heptio/velero/pkg/plugin/clientmgmt"
 	pluginmocks "github.com/heptio/velero/pkg/plugin/mocks"
 	"github.com/heptio/velero/pkg/plugin/velero"
+	kubeutil "github.com/heptio/velero/pkg/util/kube"
 	"github.com/heptio/velero/pkg/util/logging"
 )
 
@@ -51,7 +56,7 @@ type fakeBackupper struct {
 	mock.Mock
 }
 
-func (b *fakeBackupper) Backup(logger logrus.FieldLogger, backup *pkgbackup.Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter pkgbackup.VolumeSnapshotterGetter) error {
+func (b *fakeBackupper) Backup(ctx context.Context, logger logrus.FieldLogger, backup *pkgbackup.Request, backupFile io.Writer, actions []velero.BackupItemAction, volumeSnapshotterGetter pkgbackup.VolumeSnapshotterGetter) error {
 	args := b.Called(logger, backup, backupFile, actions, volumeSnapshotterGetter)
 	return args.Error(0)
 }
@@ -157,6 +162,24 @@ func TestProcessBackupValidationFailures(t *testing.T) {
 			backupLocation: builder.ForBackupStorageLocation("velero", "read-only").AccessMode(velerov1api.BackupStorageLocationAccessModeReadOnly).Result(),
 			expectedErrs:   []string{"backup can't be created because backup storage location read-only is currently in read-only mode"},
 		},
+		{
+			name:           "backup for unavailable backup location fails validation",
+			backup:         defaultBackup().StorageLocation("unavailable").Result(),
+			backupLocation: builder.ForBackupStorageLocation("velero", "unavailable").Phase(velerov1api.BackupStorageLocationPhaseUnavailable).Message("access denied").Result(),
+			expectedErrs:   []string{"backup can't be created because backup storage location unavailable is unavailable: access denied"},
+		},
+		{
+			name:           "unsupported compression codec fails validation",
+			backup:         defaultBackup().Compression("lz4", 0).Result(),
+			backupLocation: defaultBackupLocation,
+			expectedErrs:   []string{`Invalid compression: unsupported compression codec "lz4": must be one of gzip, none, zstd`},
+		},
+		{
+			name:           "invalid compression level for backup location fails validation",
+			backup:         defaultBackup().StorageLocation("bad-level").Result(),
+			backupLocation: builder.ForBackupStorageLocation("velero", "bad-level").Compression(velerov1api.CompressionCodecGzip, 10).Result(),
+			expectedErrs:   []string{"Invalid compression: invalid compression level 10 for codec gzip: must be between 1 and 9"},
+		},
 	}
 
 	for _, test := range tests {
@@ -177,6 +200,7 @@ func TestProcessBackupValidationFailures(t *testing.T) {
 				defaultBackupLocation:  defaultBackupLocation.Name,
 				clock:                  &clock.RealClock{},
 				formatFlag:             formatFlag,
+				running:                newRunningOperations(),
 			}
 
 			require.NotNil(t, test.backup)
@@ -205,6 +229,209 @@ func TestProcessBackupValidationFailures(t *testing.T) {
 	}
 }
 
+func TestProcessBackupCancellation(t *testing.T) {
+	now, err := time.Parse(time.RFC1123Z, time.RFC1123Z)
+	require.NoError(t, err)
+	now = now.Local()
+
+	tests := []struct {
+		name   string
+		backup *velerov1api.Backup
+	}{
+		{
+			name:   "new backup that's been canceled is canceled without being run",
+			backup: defaultBackup().Cancel(true).Result(),
+		},
+		{
+			name:   "in-progress backup that's been canceled and isn't running is canceled",
+			backup: defaultBackup().Phase(velerov1api.BackupPhaseInProgress).Cancel(true).Result(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formatFlag := logging.FormatText
+			var (
+				clientset       = fake.NewSimpleClientset(test.backup)
+				sharedInformers = informers.NewSharedInformerFactory(clientset, 0)
+				logger          = logging.DefaultLogger(logrus.DebugLevel, formatFlag)
+				backupper       = new(fakeBackupper)
+				backupLocation  = builder.ForBackupStorageLocation("velero", "loc-1").Result()
+			)
+
+			c := &backupController{
+				genericController:      newGenericController("backup-test", logger),
+				client:                 clientset.VeleroV1(),
+				lister:                 sharedInformers.Velero().V1().Backups().Lister(),
+				backupLocationLister:   sharedInformers.Velero().V1().BackupStorageLocations().Lister(),
+				snapshotLocationLister: sharedInformers.Velero().V1().VolumeSnapshotLocations().Lister(),
+				defaultBackupLocation:  backupLocation.Name,
+				clock:                  clock.NewFakeClock(now),
+				backupper:              backupper,
+				formatFlag:             formatFlag,
+				running:                newRunningOperations(),
+			}
+
+			require.NoError(t, sharedInformers.Velero().V1().Backups().Informer().GetStore().Add(test.backup))
+			require.NoError(t, sharedInformers.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(backupLocation))
+
+			require.NoError(t, c.processBackup(fmt.Sprintf("%s/%s", test.backup.Namespace, test.backup.Name)))
+
+			res, err := clientset.VeleroV1().Backups(test.backup.Namespace).Get(test.backup.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, velerov1api.BackupPhaseCanceled, res.Status.Phase)
+			assert.True(t, now.Equal(res.Status.CompletionTimestamp.Time))
+			backupper.AssertNotCalled(t, "Backup")
+		})
+	}
+}
+
+func TestRunBackupAbortsUploadWhenBackupFails(t *testing.T) {
+	var (
+		formatFlag    = logging.FormatText
+		logger        = logging.DefaultLogger(logrus.DebugLevel, formatFlag)
+		pluginManager = new(pluginmocks.Manager)
+		backupper     = new(fakeBackupper)
+		objectStore   = cloudprovider.NewInMemoryObjectStore("bucket-1")
+		location      = builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").Provider("in-memory").Bucket("bucket-1").Result()
+		backup        = &pkgbackup.Request{Backup: defaultBackup().StorageLocation(location.Name).Result(), StorageLocation: location}
+	)
+
+	c := &backupController{
+		genericController: newGenericController("backup-test", logger),
+		metrics:           metrics.NewServerMetrics(),
+		clock:             clock.NewFakeClock(time.Now()),
+		newPluginManager:  func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+		newBackupStore:    persistence.NewObjectBackupStore,
+		backupper:         backupper,
+		formatFlag:        formatFlag,
+	}
+
+	pluginManager.On("GetBackupItemActions").Return(nil, nil)
+	pluginManager.On("GetObjectStore", "in-memory").Return(objectStore, nil)
+	pluginManager.On("CleanupClients").Return(nil)
+
+	// the backupper writes part of the tarball before failing
+	backupper.On("Backup", mock.Anything, backup, mock.Anything, []velero.BackupItemAction(nil), pluginManager).Run(func(args mock.Arguments) {
+		args.Get(2).(io.Writer).Write([]byte("partial tarball"))
+	}).Return(errors.New("backup failed"))
+
+	err := c.runBackup(context.Background(), backup)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backup failed")
+	assert.NotContains(t, err.Error(), "error uploading backup contents")
+
+	assert.Contains(t, objectStore.Data["bucket-1"], "backups/backup-1/velero-backup.json")
+	assert.Contains(t, objectStore.Data["bucket-1"], "backups/backup-1/backup-1-logs.gz")
+	assert.NotContains(t, objectStore.Data["bucket-1"], "backups/backup-1/backup-1.tar.gz")
+}
+
+func TestProcessBackupQueuing(t *testing.T) {
+	now, err := time.Parse(time.RFC1123Z, time.RFC1123Z)
+	require.NoError(t, err)
+	now = now.Local()
+
+	backupLocation := builder.ForBackupStorageLocation("velero", "loc-1").Result()
+
+	tests := []struct {
+		name             string
+		backup           *velerov1api.Backup
+		running          []*velerov1api.Backup
+		queued           []*velerov1api.Backup
+		expectedPosition int
+	}{
+		{
+			name:   "new backup is queued when the server's running as many backups as it can",
+			backup: defaultBackup().ObjectMeta(builder.WithCreationTimestamp(now)).Result(),
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "running-1").StorageLocation("loc-1").IncludedNamespaces("ns-1").Result(),
+			},
+			expectedPosition: 1,
+		},
+		{
+			name:   "new backup is queued behind backups that are already queued",
+			backup: defaultBackup().ObjectMeta(builder.WithCreationTimestamp(now)).Result(),
+			queued: []*velerov1api.Backup{
+				builder.ForBackup("velero", "queued-1").ObjectMeta(builder.WithCreationTimestamp(now.Add(-2 * time.Minute))).Phase(velerov1api.BackupPhaseQueued).Result(),
+				builder.ForBackup("velero", "queued-2").ObjectMeta(builder.WithCreationTimestamp(now.Add(-time.Minute))).Phase(velerov1api.BackupPhaseQueued).Result(),
+			},
+			expectedPosition: 3,
+		},
+		{
+			name:   "queued backup that still can't run has its position updated",
+			backup: defaultBackup().ObjectMeta(builder.WithCreationTimestamp(now)).Phase(velerov1api.BackupPhaseQueued).QueuePosition(3).Result(),
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "running-1").StorageLocation("loc-1").Result(),
+			},
+			queued: []*velerov1api.Backup{
+				builder.ForBackup("velero", "queued-1").ObjectMeta(builder.WithCreationTimestamp(now.Add(-time.Minute))).Phase(velerov1api.BackupPhaseQueued).Result(),
+				builder.ForBackup("velero", "queued-2").ObjectMeta(builder.WithCreationTimestamp(now.Add(time.Minute))).Phase(velerov1api.BackupPhaseQueued).Result(),
+			},
+			expectedPosition: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formatFlag := logging.FormatText
+			var (
+				clientset       = fake.NewSimpleClientset(test.backup)
+				sharedInformers = informers.NewSharedInformerFactory(clientset, 0)
+				logger          = logging.DefaultLogger(logrus.DebugLevel, formatFlag)
+				backupper       = new(fakeBackupper)
+			)
+
+			c := &backupController{
+				genericController:      newGenericController("backup-test", logger),
+				client:                 clientset.VeleroV1(),
+				lister:                 sharedInformers.Velero().V1().Backups().Lister(),
+				backupLocationLister:   sharedInformers.Velero().V1().BackupStorageLocations().Lister(),
+				snapshotLocationLister: sharedInformers.Velero().V1().VolumeSnapshotLocations().Lister(),
+				defaultBackupLocation:  backupLocation.Name,
+				clock:                  clock.NewFakeClock(now),
+				backupper:              backupper,
+				formatFlag:             formatFlag,
+				running:                newRunningOperations(),
+				limiter:                newBackupLimiter(1),
+			}
+
+			for _, backup := range test.running {
+				c.limiter.running[kubeutil.NamespaceAndName(backup)] = backup
+			}
+			for _, backup := range append(test.queued, test.backup) {
+				require.NoError(t, sharedInformers.Velero().V1().Backups().Informer().GetStore().Add(backup))
+			}
+			require.NoError(t, sharedInformers.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(backupLocation))
+
+			require.NoError(t, c.processBackup(fmt.Sprintf("%s/%s", test.backup.Namespace, test.backup.Name)))
+
+			res, err := clientset.VeleroV1().Backups(test.backup.Namespace).Get(test.backup.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, velerov1api.BackupPhaseQueued, res.Status.Phase)
+			assert.Equal(t, test.expectedPosition, res.Status.QueuePosition)
+			assert.True(t, res.Status.StartTimestamp.IsZero())
+			assert.NotContains(t, c.limiter.running, kubeutil.NamespaceAndName(test.backup))
+			backupper.AssertNotCalled(t, "Backup")
+		})
+	}
+}
+
+func TestRunningOperationsCancel(t *testing.T) {
+	ops := newRunningOperations()
+
+	assert.False(t, ops.cancel("velero/backup-1"))
+
+	ctx, finish := ops.start("velero/backup-1")
+	assert.NoError(t, ctx.Err())
+	assert.True(t, ops.cancel("velero/backup-1"))
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	finish()
+	assert.False(t, ops.cancel("velero/backup-1"))
+}
+
 func TestBackupLocationLabel(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -254,6 +481,45 @@ func TestBackupLocationLabel(t *testing.T) {
 	}
 }
 
+func TestResolveCompression(t *testing.T) {
+	tests := []struct {
+		name     string
+		backup   *velerov1api.Backup
+		location *velerov1api.BackupStorageLocation
+		expected velerov1api.CompressionConfig
+	}{
+		{
+			name:     "neither the backup nor the location set compression, so gzip is used",
+			backup:   defaultBackup().Result(),
+			location: builder.ForBackupStorageLocation("velero", "loc-1").Result(),
+			expected: velerov1api.CompressionConfig{Codec: velerov1api.CompressionCodecGzip},
+		},
+		{
+			name:     "the location's compression is used if the backup doesn't set it",
+			backup:   defaultBackup().Result(),
+			location: builder.ForBackupStorageLocation("velero", "loc-1").Compression(velerov1api.CompressionCodecNone, 0).Result(),
+			expected: velerov1api.CompressionConfig{Codec: velerov1api.CompressionCodecNone},
+		},
+		{
+			name:     "the backup's compression overrides the location's",
+			backup:   defaultBackup().Compression(velerov1api.CompressionCodecGzip, 9).Result(),
+			location: builder.ForBackupStorageLocation("velero", "loc-1").Compression(velerov1api.CompressionCodecNone, 0).Result(),
+			expected: velerov1api.CompressionConfig{Codec: velerov1api.CompressionCodecGzip, Level: 9},
+		},
+		{
+			name:     "a level without a codec is a gzip level",
+			backup:   defaultBackup().Compression("", 1).Result(),
+			expected: velerov1api.CompressionConfig{Codec: velerov1api.CompressionCodecGzip, Level: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, resolveCompression(test.backup, test.location))
+		})
+	}
+}
+
 func TestDefaultBackupTTL(t *testing.T) {
 	var (
 		defaultBackupTTL = metav1.Duration{Duration: 24 * 30 * time.Hour}
@@ -347,7 +613,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseCompleted,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -375,7 +642,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseCompleted,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -406,7 +674,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseCompleted,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -435,7 +704,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseCompleted,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					Expiration:          metav1.NewTime(now.Add(10 * time.Minute)),
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
@@ -464,7 +734,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseCompleted,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -495,7 +766,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseFailed,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -524,7 +796,8 @@ func TestProcessBackupCompletions(t *testing.T) {
 				},
 				Status: velerov1api.BackupStatus{
 					Phase:               velerov1api.BackupPhaseFailed,
-					Version:             1,
+					Version:             pkgbackup.BackupVersion,
+					CompressionCodec:    velerov1api.CompressionCodecGzip,
 					StartTimestamp:      metav1.NewTime(now),
 					CompletionTimestamp: metav1.NewTime(now),
 					Expiration:          metav1.NewTime(now),
@@ -555,8 +828,10 @@ func TestProcessBackupCompletions(t *testing.T) {
 				backupTracker:          NewBackupTracker(),
 				metrics:                metrics.NewServerMetrics(),
 				clock:                  clock.NewFakeClock(now),
+				running:                newRunningOperations(),
+				limiter:                newBackupLimiter(0),
 				newPluginManager:       func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
-				newBackupStore: func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+				newBackupStore: func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
 					return backupStore, nil
 				},
 				backupper:  backupper,
@@ -567,6 +842,9 @@ func TestProcessBackupCompletions(t *testing.T) {
 			pluginManager.On("CleanupClients").Return(nil)
 			backupper.On("Backup", mock.Anything, mock.Anything, mock.Anything, []velero.BackupItemAction(nil), pluginManager).Return(nil)
 			backupStore.On("BackupExists", test.backupLocation.Spec.StorageType.ObjectStorage.Bucket, test.backup.Name).Return(test.backupExists, test.existenceCheckError)
+			backupStore.On("PutBackupContents", test.backup.Name, mock.Anything).Run(func(args mock.Arguments) {
+				ioutil.ReadAll(args.Get(1).(io.Reader))
+			}).Return("digest", nil).Maybe()
 
 			// Ensure we have a CompletionTimestamp when uploading and that the backup name matches the backup in the object store.
 			// Failures will display the bytes in buf.
@@ -31,13 +31,18 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
 	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
 	pkgbackup "github.com/heptio/velero/pkg/backup"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/credentials"
+	"github.com/heptio/velero/pkg/discovery"
 	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
 	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
+	"github.com/heptio/velero/pkg/kuberesource"
 	"github.com/heptio/velero/pkg/label"
 	"github.com/heptio/velero/pkg/metrics"
 	"github.com/heptio/velero/pkg/persistence"
@@ -65,7 +70,11 @@ type backupDeletionController struct {
 	processRequestFunc        func(*v1.DeleteBackupRequest) error
 	clock                     clock.Clock
 	newPluginManager          func(logrus.FieldLogger) clientmgmt.Manager
-	newBackupStore            func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	secretsGetter             corev1client.SecretsGetter
+	credentialFileStore       credentials.FileStore
+	discoveryHelper           discovery.Helper
+	dynamicFactory            client.DynamicFactory
+	newBackupStore            func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
 	metrics                   *metrics.ServerMetrics
 }
 
@@ -83,6 +92,10 @@ func NewBackupDeletionController(
 	backupLocationInformer informers.BackupStorageLocationInformer,
 	snapshotLocationInformer informers.VolumeSnapshotLocationInformer,
 	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+	credentialFileStore credentials.FileStore,
+	discoveryHelper discovery.Helper,
+	dynamicFactory client.DynamicFactory,
 	metrics *metrics.ServerMetrics,
 ) Interface {
 	c := &backupDeletionController{
@@ -100,8 +113,12 @@ func NewBackupDeletionController(
 		metrics:                   metrics,
 		// use variables to refer to these functions so they can be
 		// replaced with fakes for testing.
-		newPluginManager: newPluginManager,
-		newBackupStore:   persistence.NewObjectBackupStore,
+		newPluginManager:    newPluginManager,
+		secretsGetter:       secretsGetter,
+		credentialFileStore: credentialFileStore,
+		discoveryHelper:     discoveryHelper,
+		dynamicFactory:      dynamicFactory,
+		newBackupStore:      persistence.NewObjectBackupStore,
 
 		clock: &clock.RealClock{},
 	}
@@ -274,7 +291,7 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 	pluginManager := c.newPluginManager(log)
 	defer pluginManager.CleanupClients()
 
-	backupStore, err := c.newBackupStore(location, pluginManager, log)
+	backupStore, err := c.newBackupStore(location, pluginManager, c.secretsGetter, log)
 	if err != nil {
 		errs = append(errs, err.Error())
 	}
@@ -292,7 +309,7 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 
 				volumeSnapshotter, ok := volumeSnapshotters[snapshot.Spec.Location]
 				if !ok {
-					if volumeSnapshotter, err = volumeSnapshotterForSnapshotLocation(backup.Namespace, snapshot.Spec.Location, c.snapshotLocationLister, pluginManager); err != nil {
+					if volumeSnapshotter, err = volumeSnapshotterForSnapshotLocation(backup.Namespace, snapshot.Spec.Location, c.snapshotLocationLister, pluginManager, c.credentialFileStore); err != nil {
 						errs = append(errs, err.Error())
 						continue
 					}
@@ -306,6 +323,13 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 		}
 	}
 
+	log.Info("Removing CSI snapshots")
+	if deleteErrs := c.deleteCSISnapshots(backup, log); len(deleteErrs) > 0 {
+		for _, err := range deleteErrs {
+			errs = append(errs, err.Error())
+		}
+	}
+
 	log.Info("Removing restic snapshots")
 	if deleteErrs := c.deleteResticSnapshots(backup); len(deleteErrs) > 0 {
 		for _, err := range deleteErrs {
@@ -385,6 +409,7 @@ func volumeSnapshotterForSnapshotLocation(
 	namespace, snapshotLocationName string,
 	snapshotLocationLister listers.VolumeSnapshotLocationLister,
 	pluginManager clientmgmt.Manager,
+	credentialFileStore credentials.FileStore,
 ) (velero.VolumeSnapshotter, error) {
 	snapshotLocation, err := snapshotLocationLister.VolumeSnapshotLocations(namespace).Get(snapshotLocationName)
 	if err != nil {
@@ -396,7 +421,12 @@ func volumeSnapshotterForSnapshotLocation(
 		return nil, errors.Wrapf(err, "error getting volume snapshotter for provider %s", snapshotLocation.Spec.Provider)
 	}
 
-	if err = volumeSnapshotter.Init(snapshotLocation.Spec.Config); err != nil {
+	config, err := credentials.ConfigWithCredentialsFile(credentialFileStore, namespace, snapshotLocation.Spec.Config, snapshotLocation.Spec.Credential)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting credentials for volume snapshot location %s", snapshotLocationName)
+	}
+
+	if err = volumeSnapshotter.Init(config); err != nil {
 		return nil, errors.Wrapf(err, "error initializing volume snapshotter for volume snapshot location %s", snapshotLocationName)
 	}
 
@@ -450,6 +480,43 @@ func (c *backupDeletionController) deleteResticSnapshots(backup *v1.Backup) []er
 	return errs
 }
 
+// deleteCSISnapshots deletes the CSI VolumeSnapshotContents recorded in the
+// backup's status. Their deletion policy is set to Delete first, so that the
+// CSI driver deletes the underlying storage snapshots too.
+func (c *backupDeletionController) deleteCSISnapshots(backup *v1.Backup, log logrus.FieldLogger) []error {
+	if len(backup.Status.CSIVolumeSnapshotContents) == 0 {
+		return nil
+	}
+
+	gvr, resource, err := c.discoveryHelper.ResourceFor(kuberesource.VolumeSnapshotContents.WithVersion(""))
+	if err != nil {
+		return []error{errors.Wrap(err, "error getting CSI VolumeSnapshotContent resource")}
+	}
+
+	contentClient, err := c.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, "")
+	if err != nil {
+		return []error{errors.WithStack(err)}
+	}
+
+	var errs []error
+	for _, name := range backup.Status.CSIVolumeSnapshotContents {
+		log.WithField("volumeSnapshotContent", name).Info("Removing CSI VolumeSnapshotContent associated with backup")
+
+		if _, err := contentClient.Patch(name, []byte(`{"spec":{"deletionPolicy":"Delete"}}`)); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "error setting deletion policy of CSI VolumeSnapshotContent %s", name))
+			}
+			continue
+		}
+
+		if err := contentClient.Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "error deleting CSI VolumeSnapshotContent %s", name))
+		}
+	}
+
+	return errs
+}
+
 const deleteBackupRequestMaxAge = 24 * time.Hour
 
 func (c *backupDeletionController) deleteExpiredRequests() {
@@ -24,12 +24,16 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	core "k8s.io/client-go/testing"
 
 	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -37,6 +41,7 @@ import (
 	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
+	"github.com/heptio/velero/pkg/kuberesource"
 	"github.com/heptio/velero/pkg/metrics"
 	"github.com/heptio/velero/pkg/persistence"
 	persistencemocks "github.com/heptio/velero/pkg/persistence/mocks"
@@ -63,6 +68,10 @@ func TestBackupDeletionControllerProcessQueueItem(t *testing.T) {
 		sharedInformers.Velero().V1().BackupStorageLocations(),
 		sharedInformers.Velero().V1().VolumeSnapshotLocations(),
 		nil, // new plugin manager func
+		nil, // secrets getter
+		nil, // credential file store
+		nil, // discovery helper
+		nil, // dynamic factory
 		metrics.NewServerMetrics(),
 	).(*backupDeletionController)
 
@@ -153,13 +162,17 @@ func setupBackupDeletionControllerTest(objects ...runtime.Object) *backupDeletio
 			sharedInformers.Velero().V1().BackupStorageLocations(),
 			sharedInformers.Velero().V1().VolumeSnapshotLocations(),
 			func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+			nil, // secrets getter
+			nil, // credential file store
+			nil, // discovery helper
+			nil, // dynamic factory
 			metrics.NewServerMetrics(),
 		).(*backupDeletionController),
 
 		req: req,
 	}
 
-	data.controller.newBackupStore = func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+	data.controller.newBackupStore = func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
 		return backupStore, nil
 	}
 
@@ -851,6 +864,10 @@ func TestBackupDeletionControllerDeleteExpiredRequests(t *testing.T) {
 				sharedInformers.Velero().V1().BackupStorageLocations(),
 				sharedInformers.Velero().V1().VolumeSnapshotLocations(),
 				nil, // new plugin manager func
+				nil, // secrets getter
+				nil, // credential file store
+				nil, // discovery helper
+				nil, // dynamic factory
 				metrics.NewServerMetrics(),
 			).(*backupDeletionController)
 
@@ -873,3 +890,41 @@ func TestBackupDeletionControllerDeleteExpiredRequests(t *testing.T) {
 		})
 	}
 }
+
+func TestBackupDeletionControllerDeleteCSISnapshots(t *testing.T) {
+	td := setupBackupDeletionControllerTest()
+
+	var (
+		dynamicFactory = &velerotest.FakeDynamicFactory{}
+		contentClient  = &velerotest.FakeDynamicClient{}
+		gvr            = kuberesource.VolumeSnapshotContents.WithVersion("")
+	)
+	td.controller.discoveryHelper = velerotest.NewFakeDiscoveryHelper(true, nil)
+	td.controller.dynamicFactory = dynamicFactory
+
+	dynamicFactory.On("ClientForGroupVersionResource", gvr.GroupVersion(), metav1.APIResource{Name: gvr.Resource}, "").Return(contentClient, nil)
+
+	deletePolicy := []byte(`{"spec":{"deletionPolicy":"Delete"}}`)
+	contentClient.On("Patch", "content-1", deletePolicy).Return(&unstructured.Unstructured{}, nil)
+	contentClient.On("Delete", "content-1", &metav1.DeleteOptions{}).Return(nil)
+	// content-2 has already been deleted
+	contentClient.On("Patch", "content-2", deletePolicy).Return((*unstructured.Unstructured)(nil), apierrors.NewNotFound(kuberesource.VolumeSnapshotContents, "content-2"))
+	contentClient.On("Patch", "content-3", deletePolicy).Return(&unstructured.Unstructured{}, nil)
+	contentClient.On("Delete", "content-3", &metav1.DeleteOptions{}).Return(errors.New("oops"))
+
+	backup := builder.ForBackup(v1.DefaultNamespace, "foo").Result()
+	backup.Status.CSIVolumeSnapshotContents = []string{"content-1", "content-2", "content-3"}
+
+	errs := td.controller.deleteCSISnapshots(backup, velerotest.NewLogger())
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "error deleting CSI VolumeSnapshotContent content-3")
+
+	contentClient.AssertExpectations(t)
+	contentClient.AssertNotCalled(t, "Delete", "content-2", mock.Anything)
+
+	// backups without CSI snapshots don't need the discovery helper or
+	// dynamic factory
+	td.controller.discoveryHelper = nil
+	td.controller.dynamicFactory = nil
+	assert.Empty(t, td.controller.deleteCSISnapshots(builder.ForBackup(v1.DefaultNamespace, "bar").Result(), velerotest.NewLogger()))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/util/collections"
+	kubeutil "github.com/heptio/velero/pkg/util/kube"
+)
+
+// backupLimiter keeps track of the backups that are being run by this server,
+// and decides whether another can be started alongside them.
+type backupLimiter struct {
+	lock          sync.Mutex
+	maxConcurrent int
+	running       map[string]*velerov1api.Backup
+}
+
+func newBackupLimiter(maxConcurrent int) *backupLimiter {
+	return &backupLimiter{
+		maxConcurrent: maxConcurrent,
+		running:       make(map[string]*velerov1api.Backup),
+	}
+}
+
+// start records the backup with the given key as running, if it can run
+// now. If it can't, it returns a description of why the backup has to wait.
+func (l *backupLimiter) start(key string, backup *velerov1api.Backup, location *velerov1api.BackupStorageLocation) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.maxConcurrent > 0 && len(l.running) >= l.maxConcurrent {
+		return fmt.Sprintf("the server is already running %d backup(s)", len(l.running))
+	}
+
+	var runningToLocation int
+	for _, running := range l.running {
+		if running.Spec.StorageLocation == backup.Spec.StorageLocation {
+			runningToLocation++
+		}
+	}
+	if max := location.Spec.MaxConcurrentBackups; max > 0 && runningToLocation >= max {
+		return fmt.Sprintf("backup storage location %s is already running %d backup(s)", location.Name, runningToLocation)
+	}
+
+	for _, running := range l.running {
+		if namespacesOverlap(backup, running) {
+			return fmt.Sprintf("running backup %s includes some of the same namespaces", kubeutil.NamespaceAndName(running))
+		}
+	}
+
+	l.running[key] = backup
+	return ""
+}
+
+// finish records that the backup with the given key is no longer running.
+func (l *backupLimiter) finish(key string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.running, key)
+}
+
+// namespacesOverlap returns whether two backups might include some of the same
+// namespaces. Backups that include every namespace, or namespaces matching a
+// wildcard, are assumed to overlap with any other backup that doesn't exclude
+// all of their namespaces.
+func namespacesOverlap(a, b *velerov1api.Backup) bool {
+	if namespaces, ok := literalNamespaces(a); ok {
+		return includesAny(b, namespaces)
+	}
+	if namespaces, ok := literalNamespaces(b); ok {
+		return includesAny(a, namespaces)
+	}
+	return true
+}
+
+// literalNamespaces returns the namespaces that a backup includes, if they're
+// all named explicitly.
+func literalNamespaces(backup *velerov1api.Backup) ([]string, bool) {
+	if len(backup.Spec.IncludedNamespaces) == 0 {
+		return nil, false
+	}
+	for _, ns := range backup.Spec.IncludedNamespaces {
+		if strings.ContainsAny(ns, "*?[{") {
+			return nil, false
+		}
+	}
+	return backup.Spec.IncludedNamespaces, true
+}
+
+func includesAny(backup *velerov1api.Backup, namespaces []string) bool {
+	ie := collections.NewIncludesExcludes().
+		Includes(backup.Spec.IncludedNamespaces...).
+		Excludes(backup.Spec.ExcludedNamespaces...)
+
+	for _, ns := range namespaces {
+		if ie.ShouldInclude(ns) {
+			return true
+		}
+	}
+	return false
+}
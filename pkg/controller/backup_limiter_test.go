@@ -0,0 +1,171 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+)
+
+func TestBackupLimiterStart(t *testing.T) {
+	location := builder.ForBackupStorageLocation("velero", "loc-1").Result()
+
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		location      *velerov1api.BackupStorageLocation
+		running       []*velerov1api.Backup
+		backup        *velerov1api.Backup
+		wantReason    string
+	}{
+		{
+			name:          "backup starts when nothing's running",
+			maxConcurrent: 1,
+			location:      location,
+			backup:        builder.ForBackup("velero", "backup-1").StorageLocation("loc-1").Result(),
+		},
+		{
+			name:          "backup waits when the server limit's been reached",
+			maxConcurrent: 1,
+			location:      location,
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "backup-1").StorageLocation("loc-2").IncludedNamespaces("ns-1").Result(),
+			},
+			backup:     builder.ForBackup("velero", "backup-2").StorageLocation("loc-1").IncludedNamespaces("ns-2").Result(),
+			wantReason: "the server is already running 1 backup(s)",
+		},
+		{
+			name:          "backup waits when its location's limit has been reached",
+			maxConcurrent: 3,
+			location:      builder.ForBackupStorageLocation("velero", "loc-1").MaxConcurrentBackups(1).Result(),
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "backup-1").StorageLocation("loc-1").IncludedNamespaces("ns-1").Result(),
+			},
+			backup:     builder.ForBackup("velero", "backup-2").StorageLocation("loc-1").IncludedNamespaces("ns-2").Result(),
+			wantReason: "backup storage location loc-1 is already running 1 backup(s)",
+		},
+		{
+			name:          "backup starts when other locations have reached the location limit",
+			maxConcurrent: 3,
+			location:      builder.ForBackupStorageLocation("velero", "loc-1").MaxConcurrentBackups(1).Result(),
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "backup-1").StorageLocation("loc-2").IncludedNamespaces("ns-1").Result(),
+			},
+			backup: builder.ForBackup("velero", "backup-2").StorageLocation("loc-1").IncludedNamespaces("ns-2").Result(),
+		},
+		{
+			name:          "backup waits when a running backup includes the same namespaces",
+			maxConcurrent: 3,
+			location:      location,
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "backup-1").StorageLocation("loc-1").IncludedNamespaces("ns-1", "ns-2").Result(),
+			},
+			backup:     builder.ForBackup("velero", "backup-2").StorageLocation("loc-1").IncludedNamespaces("ns-2").Result(),
+			wantReason: "running backup velero/backup-1 includes some of the same namespaces",
+		},
+		{
+			name:          "unlimited server starts any backup that doesn't overlap",
+			maxConcurrent: 0,
+			location:      location,
+			running: []*velerov1api.Backup{
+				builder.ForBackup("velero", "backup-1").StorageLocation("loc-1").IncludedNamespaces("ns-1").Result(),
+				builder.ForBackup("velero", "backup-2").StorageLocation("loc-1").IncludedNamespaces("ns-2").Result(),
+			},
+			backup: builder.ForBackup("velero", "backup-3").StorageLocation("loc-1").IncludedNamespaces("ns-3").Result(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := newBackupLimiter(tc.maxConcurrent)
+			for _, backup := range tc.running {
+				limiter.running[backup.Namespace+"/"+backup.Name] = backup
+			}
+
+			key := tc.backup.Namespace + "/" + tc.backup.Name
+			assert.Equal(t, tc.wantReason, limiter.start(key, tc.backup, tc.location))
+
+			_, running := limiter.running[key]
+			assert.Equal(t, tc.wantReason == "", running)
+
+			limiter.finish(key)
+			assert.NotContains(t, limiter.running, key)
+		})
+	}
+}
+
+func TestNamespacesOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *velerov1api.Backup
+		want bool
+	}{
+		{
+			name: "backups of all namespaces overlap",
+			a:    builder.ForBackup("velero", "a").Result(),
+			b:    builder.ForBackup("velero", "b").Result(),
+			want: true,
+		},
+		{
+			name: "backups of different namespaces don't overlap",
+			a:    builder.ForBackup("velero", "a").IncludedNamespaces("ns-1", "ns-2").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("ns-3").Result(),
+			want: false,
+		},
+		{
+			name: "backups that include a common namespace overlap",
+			a:    builder.ForBackup("velero", "a").IncludedNamespaces("ns-1", "ns-2").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("ns-2", "ns-3").Result(),
+			want: true,
+		},
+		{
+			name: "backup of all namespaces overlaps with a backup of specific namespaces",
+			a:    builder.ForBackup("velero", "a").IncludedNamespaces("*").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("ns-1").Result(),
+			want: true,
+		},
+		{
+			name: "backup of all namespaces doesn't overlap with a backup of namespaces it excludes",
+			a:    builder.ForBackup("velero", "a").ExcludedNamespaces("ns-1").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("ns-1").Result(),
+			want: false,
+		},
+		{
+			name: "backup of wildcard namespaces overlaps with a matching backup",
+			a:    builder.ForBackup("velero", "a").IncludedNamespaces("team-*").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("team-a").Result(),
+			want: true,
+		},
+		{
+			name: "backup of wildcard namespaces doesn't overlap with a non-matching backup",
+			a:    builder.ForBackup("velero", "a").IncludedNamespaces("team-*").Result(),
+			b:    builder.ForBackup("velero", "b").IncludedNamespaces("ops").Result(),
+			want: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, namespacesOverlap(tc.a, tc.b))
+			assert.Equal(t, tc.want, namespacesOverlap(tc.b, tc.a))
+		})
+	}
+}
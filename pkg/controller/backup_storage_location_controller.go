@@ -0,0 +1,181 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
+	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
+	"github.com/heptio/velero/pkg/persistence"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	kubeutil "github.com/heptio/velero/pkg/util/kube"
+)
+
+// backupStorageLocationController periodically validates each backup storage
+// location, and records whether it's available in its status.
+type backupStorageLocationController struct {
+	*genericController
+
+	namespace                   string
+	backupStorageLocationClient velerov1client.BackupStorageLocationsGetter
+	backupStorageLocationLister listers.BackupStorageLocationLister
+	newPluginManager            func(logrus.FieldLogger) clientmgmt.Manager
+	secretsGetter               corev1client.SecretsGetter
+	newBackupStore              func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	clock                       clock.Clock
+}
+
+// NewBackupStorageLocationController constructs a controller that validates
+// each backup storage location every validationFrequency, as well as whenever
+// one is created or its spec changes.
+func NewBackupStorageLocationController(
+	namespace string,
+	backupStorageLocationClient velerov1client.BackupStorageLocationsGetter,
+	backupStorageLocationInformer informers.BackupStorageLocationInformer,
+	validationFrequency time.Duration,
+	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+	logger logrus.FieldLogger,
+) Interface {
+	c := &backupStorageLocationController{
+		genericController:           newGenericController("backup-storage-location", logger),
+		namespace:                   namespace,
+		backupStorageLocationClient: backupStorageLocationClient,
+		backupStorageLocationLister: backupStorageLocationInformer.Lister(),
+
+		// use variables to refer to these functions so they can be
+		// replaced with fakes for testing.
+		newPluginManager: newPluginManager,
+		secretsGetter:    secretsGetter,
+		newBackupStore:   persistence.NewObjectBackupStore,
+		clock:            clock.RealClock{},
+	}
+
+	c.syncHandler = c.processQueueItem
+	c.resyncFunc = c.enqueueAllLocations
+	c.resyncPeriod = validationFrequency
+	c.cacheSyncWaiters = append(c.cacheSyncWaiters, backupStorageLocationInformer.Informer().HasSynced)
+
+	backupStorageLocationInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueue,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				// status updates, including the ones made by this
+				// controller, don't need the location to be validated again.
+				oldLocation := oldObj.(*velerov1api.BackupStorageLocation)
+				newLocation := newObj.(*velerov1api.BackupStorageLocation)
+				if !reflect.DeepEqual(oldLocation.Spec, newLocation.Spec) {
+					c.enqueue(newObj)
+				}
+			},
+		},
+	)
+
+	return c
+}
+
+func (c *backupStorageLocationController) enqueueAllLocations() {
+	locations, err := c.backupStorageLocationLister.BackupStorageLocations(c.namespace).List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error listing backup storage locations")
+		return
+	}
+
+	for _, location := range locations {
+		c.enqueue(location)
+	}
+}
+
+func (c *backupStorageLocationController) processQueueItem(key string) error {
+	log := c.logger.WithField("key", key)
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	location, err := c.backupStorageLocationLister.BackupStorageLocations(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		log.Debug("Unable to find BackupStorageLocation")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting BackupStorageLocation")
+	}
+
+	log = c.logger.WithField("backupLocation", kubeutil.NamespaceAndName(location))
+	log.Debug("Validating backup storage location")
+
+	phase := velerov1api.BackupStorageLocationPhaseAvailable
+	var message string
+	if err := c.validate(location, log); err != nil {
+		log.WithError(err).Warn("Backup storage location is unavailable")
+		phase = velerov1api.BackupStorageLocationPhaseUnavailable
+		message = err.Error()
+	} else if location.Status.Phase != phase {
+		log.Info("Backup storage location is available")
+	}
+
+	// the message is always included in the patch so that it's cleared
+	// once the location's available again.
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase":              phase,
+			"lastValidationTime": c.clock.Now().UTC(),
+			"message":            message,
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling status patch to JSON")
+	}
+
+	if _, err := c.backupStorageLocationClient.BackupStorageLocations(ns).Patch(name, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching BackupStorageLocation's status")
+	}
+
+	return nil
+}
+
+// validate returns an error if the location's backup store can't be reached,
+// or doesn't have a compatible layout.
+func (c *backupStorageLocationController) validate(location *velerov1api.BackupStorageLocation, log logrus.FieldLogger) error {
+	pluginManager := c.newPluginManager(log)
+	defer pluginManager.CleanupClients()
+
+	backupStore, err := c.newBackupStore(location, pluginManager, c.secretsGetter, log)
+	if err != nil {
+		return errors.Wrap(err, "error getting backup store")
+	}
+
+	return backupStore.IsValid()
+}
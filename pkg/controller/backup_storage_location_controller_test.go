@@ -0,0 +1,144 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
+	"github.com/heptio/velero/pkg/persistence"
+	persistencemocks "github.com/heptio/velero/pkg/persistence/mocks"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	pluginmocks "github.com/heptio/velero/pkg/plugin/mocks"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestBackupStorageLocationControllerProcessQueueItem(t *testing.T) {
+	now, err := time.Parse(time.RFC1123Z, time.RFC1123Z)
+	require.NoError(t, err)
+	now = now.Local()
+
+	tests := []struct {
+		name            string
+		location        *velerov1api.BackupStorageLocation
+		newStoreErr     error
+		isValidErr      error
+		expectedPhase   velerov1api.BackupStorageLocationPhase
+		expectedMessage string
+	}{
+		{
+			name:          "valid location is available",
+			location:      builder.ForBackupStorageLocation("velero", "default").Bucket("bucket").Result(),
+			expectedPhase: velerov1api.BackupStorageLocationPhaseAvailable,
+		},
+		{
+			name:            "location whose backup store is invalid is unavailable",
+			location:        builder.ForBackupStorageLocation("velero", "default").Bucket("bucket").Result(),
+			isValidErr:      errors.New("access denied"),
+			expectedPhase:   velerov1api.BackupStorageLocationPhaseUnavailable,
+			expectedMessage: "access denied",
+		},
+		{
+			name:            "location whose backup store can't be created is unavailable",
+			location:        builder.ForBackupStorageLocation("velero", "default").Bucket("bucket").Result(),
+			newStoreErr:     errors.New("no such plugin"),
+			expectedPhase:   velerov1api.BackupStorageLocationPhaseUnavailable,
+			expectedMessage: "error getting backup store: no such plugin",
+		},
+		{
+			name:          "location that becomes available again has its message removed",
+			location:      builder.ForBackupStorageLocation("velero", "default").Bucket("bucket").Phase(velerov1api.BackupStorageLocationPhaseUnavailable).Message("access denied").Result(),
+			expectedPhase: velerov1api.BackupStorageLocationPhaseAvailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset(test.location)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				pluginManager   = &pluginmocks.Manager{}
+				backupStore     = &persistencemocks.BackupStore{}
+			)
+
+			c := NewBackupStorageLocationController(
+				"velero",
+				client.VeleroV1(),
+				sharedInformers.Velero().V1().BackupStorageLocations(),
+				time.Minute,
+				func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+				nil,
+				velerotest.NewLogger(),
+			).(*backupStorageLocationController)
+
+			c.clock = clock.NewFakeClock(now)
+			c.newBackupStore = func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+				if test.newStoreErr != nil {
+					return nil, test.newStoreErr
+				}
+				return backupStore, nil
+			}
+
+			pluginManager.On("CleanupClients").Return(nil)
+			backupStore.On("IsValid").Return(test.isValidErr)
+
+			require.NoError(t, sharedInformers.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(test.location))
+
+			require.NoError(t, c.processQueueItem("velero/default"))
+
+			res, err := client.VeleroV1().BackupStorageLocations("velero").Get("default", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedPhase, res.Status.Phase)
+			assert.Equal(t, test.expectedMessage, res.Status.Message)
+			require.NotNil(t, res.Status.LastValidationTime)
+			assert.True(t, now.Equal(res.Status.LastValidationTime.Time))
+		})
+	}
+}
+
+func TestBackupStorageLocationControllerProcessQueueItemMissingLocation(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+	)
+
+	c := NewBackupStorageLocationController(
+		"velero",
+		client.VeleroV1(),
+		sharedInformers.Velero().V1().BackupStorageLocations(),
+		time.Minute,
+		nil,
+		nil,
+		velerotest.NewLogger(),
+	).(*backupStorageLocationController)
+
+	assert.NoError(t, c.processQueueItem("velero/default"))
+	assert.Empty(t, client.Actions())
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -50,7 +51,8 @@ type backupSyncController struct {
 	namespace                   string
 	defaultBackupLocation       string
 	newPluginManager            func(logrus.FieldLogger) clientmgmt.Manager
-	newBackupStore              func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	secretsGetter               corev1client.SecretsGetter
+	newBackupStore              func(*velerov1api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
 }
 
 func NewBackupSyncController(
@@ -64,6 +66,7 @@ func NewBackupSyncController(
 	namespace string,
 	defaultBackupLocation string,
 	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
 	logger logrus.FieldLogger,
 ) Interface {
 	if syncPeriod < time.Minute {
@@ -85,6 +88,7 @@ func NewBackupSyncController(
 		// use variables to refer to these functions so they can be
 		// replaced with fakes for testing.
 		newPluginManager: newPluginManager,
+		secretsGetter:    secretsGetter,
 		newBackupStore:   persistence.NewObjectBackupStore,
 	}
 
@@ -163,7 +167,7 @@ func (c *backupSyncController) run() {
 	for _, location := range locations {
 		log := c.logger.WithField("backupLocation", location.Name)
 
-		backupStore, err := c.newBackupStore(location, pluginManager, log)
+		backupStore, err := c.newBackupStore(location, pluginManager, c.secretsGetter, log)
 		if err != nil {
 			log.WithError(err).Error("Error getting backup store for this location")
 			continue
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	core "k8s.io/client-go/testing"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -147,6 +148,21 @@ func TestBackupSyncControllerRun(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "synced backups keep the codec their tarballs were compressed with",
+			namespace: "ns-1",
+			locations: defaultLocationsList("ns-1"),
+			cloudBuckets: map[string][]*cloudBackupData{
+				"bucket-1": {
+					&cloudBackupData{
+						backup: builder.ForBackup("ns-1", "backup-1").CompressionCodec(velerov1api.CompressionCodecNone).Result(),
+					},
+					&cloudBackupData{
+						backup: builder.ForBackup("ns-1", "backup-2").CompressionCodec(velerov1api.CompressionCodecGzip).Result(),
+					},
+				},
+			},
+		},
 		{
 			name:      "all synced backups get created in Velero server's namespace",
 			namespace: "velero",
@@ -351,10 +367,11 @@ func TestBackupSyncControllerRun(t *testing.T) {
 				test.namespace,
 				"",
 				func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+				nil,
 				velerotest.NewLogger(),
 			).(*backupSyncController)
 
-			c.newBackupStore = func(loc *velerov1api.BackupStorageLocation, _ persistence.ObjectStoreGetter, _ logrus.FieldLogger) (persistence.BackupStore, error) {
+			c.newBackupStore = func(loc *velerov1api.BackupStorageLocation, _ persistence.ObjectStoreGetter, _ corev1client.SecretsGetter, _ logrus.FieldLogger) (persistence.BackupStore, error) {
 				// this gets populated just below, prior to exercising the method under test
 				return backupStores[loc.Name], nil
 			}
@@ -443,6 +460,10 @@ func TestBackupSyncControllerRun(t *testing.T) {
 						}
 						assert.Equal(t, locationName, obj.Labels[velerov1api.StorageLocationLabel])
 						assert.Equal(t, true, len(obj.Labels[velerov1api.StorageLocationLabel]) <= validation.DNS1035LabelMaxLength)
+
+						// verify that the codec the backup's tarball was compressed with is kept, so
+						// it can be decompressed in this cluster
+						assert.Equal(t, cloudBackupData.backup.Status.CompressionCodec, obj.Status.CompressionCodec)
 					}
 
 					// process the cloud pod volume backups for this backup, if any
@@ -577,6 +598,7 @@ func TestDeleteOrphanedBackups(t *testing.T) {
 				test.namespace,
 				"",
 				nil, // new plugin manager func
+				nil,
 				velerotest.NewLogger(),
 			).(*backupSyncController)
 
@@ -669,6 +691,7 @@ func TestStorageLabelsInDeleteOrphanedBackups(t *testing.T) {
 				test.namespace,
 				"",
 				nil, // new plugin manager func
+				nil,
 				velerotest.NewLogger(),
 			).(*backupSyncController)
 
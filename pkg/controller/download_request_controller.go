@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -49,7 +50,9 @@ type downloadRequestController struct {
 	backupLocationLister  listers.BackupStorageLocationLister
 	backupLister          listers.BackupLister
 	newPluginManager      func(logrus.FieldLogger) clientmgmt.Manager
-	newBackupStore        func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	secretsGetter         corev1client.SecretsGetter
+	downloadServer        *DownloadServer
+	newBackupStore        func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
 }
 
 // NewDownloadRequestController creates a new DownloadRequestController.
@@ -60,6 +63,8 @@ func NewDownloadRequestController(
 	backupLocationInformer informers.BackupStorageLocationInformer,
 	backupInformer informers.BackupInformer,
 	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+	downloadServer *DownloadServer,
 	logger logrus.FieldLogger,
 ) Interface {
 	c := &downloadRequestController{
@@ -73,6 +78,8 @@ func NewDownloadRequestController(
 		// use variables to refer to these functions so they can be
 		// replaced with fakes for testing.
 		newPluginManager: newPluginManager,
+		secretsGetter:    secretsGetter,
+		downloadServer:   downloadServer,
 		newBackupStore:   persistence.NewObjectBackupStore,
 
 		clock: &clock.RealClock{},
@@ -171,20 +178,32 @@ func (c *downloadRequestController) generatePreSignedURL(downloadRequest *v1.Dow
 		return errors.WithStack(err)
 	}
 
-	pluginManager := c.newPluginManager(log)
-	defer pluginManager.CleanupClients()
+	expiration := c.clock.Now().Add(persistence.DownloadURLTTL)
 
-	backupStore, err := c.newBackupStore(backupLocation, pluginManager, log)
-	if err != nil {
-		return errors.WithStack(err)
-	}
+	// objects in locations that use encryption can only be decrypted by the
+	// Velero server, so they're downloaded from it rather than from object
+	// storage.
+	if backupLocation.Spec.Encryption != nil {
+		if c.downloadServer == nil {
+			return errors.New("the Velero server's download server must be enabled to download from backup storage locations that use encryption")
+		}
+		update.Status.DownloadURL = c.downloadServer.signedURL(backupLocation, downloadRequest.Spec.Target, expiration)
+	} else {
+		pluginManager := c.newPluginManager(log)
+		defer pluginManager.CleanupClients()
 
-	if update.Status.DownloadURL, err = backupStore.GetDownloadURL(downloadRequest.Spec.Target); err != nil {
-		return err
+		backupStore, err := c.newBackupStore(backupLocation, pluginManager, c.secretsGetter, log)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if update.Status.DownloadURL, err = backupStore.GetDownloadURL(downloadRequest.Spec.Target); err != nil {
+			return err
+		}
 	}
 
 	update.Status.Phase = v1.DownloadRequestPhaseProcessed
-	update.Status.Expiration = metav1.NewTime(c.clock.Now().Add(persistence.DownloadURLTTL))
+	update.Status.Expiration = metav1.NewTime(expiration)
 
 	_, err = patchDownloadRequest(downloadRequest, update, c.downloadRequestClient)
 	return errors.WithStack(err)
@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
 	"github.com/heptio/velero/pkg/builder"
@@ -61,6 +63,8 @@ func newDownloadRequestTestHarness(t *testing.T) *downloadRequestTestHarness {
 			informerFactory.Velero().V1().BackupStorageLocations(),
 			informerFactory.Velero().V1().Backups(),
 			func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+			nil,
+			nil,
 			velerotest.NewLogger(),
 		).(*downloadRequestController)
 	)
@@ -69,7 +73,7 @@ func newDownloadRequestTestHarness(t *testing.T) *downloadRequestTestHarness {
 	require.NoError(t, err)
 	controller.clock = clock.NewFakeClock(clockTime)
 
-	controller.newBackupStore = func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+	controller.newBackupStore = func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
 		return backupStore, nil
 	}
 
@@ -134,6 +138,7 @@ func TestProcessDownloadRequest(t *testing.T) {
 		expired         bool
 		expectedErr     string
 		expectGetsURL   bool
+		downloadServer  bool
 	}{
 		{
 			name: "empty key returns without error",
@@ -229,6 +234,20 @@ func TestProcessDownloadRequest(t *testing.T) {
 			backupLocation:  newBackupLocation("a-location", "a-provider", "a-bucket"),
 			expectGetsURL:   true,
 		},
+		{
+			name:            "backup contents request for a location that uses encryption gets a download server url",
+			downloadRequest: newDownloadRequest("", v1.DownloadTargetKindBackupContents, "a-backup"),
+			backup:          defaultBackup(),
+			backupLocation:  builder.ForBackupStorageLocation(v1.DefaultNamespace, "a-location").Provider("a-provider").Bucket("a-bucket").EncryptionKeySecret("a-secret", "key").Result(),
+			downloadServer:  true,
+		},
+		{
+			name:            "backup contents request for a location that uses encryption returns an error if the download server isn't enabled",
+			downloadRequest: newDownloadRequest("", v1.DownloadTargetKindBackupContents, "a-backup"),
+			backup:          defaultBackup(),
+			backupLocation:  builder.ForBackupStorageLocation(v1.DefaultNamespace, "a-location").Provider("a-provider").Bucket("a-bucket").EncryptionKeySecret("a-secret", "key").Result(),
+			expectedErr:     "the Velero server's download server must be enabled to download from backup storage locations that use encryption",
+		},
 		{
 			name:            "request with phase 'Processed' is not deleted if not expired",
 			downloadRequest: newDownloadRequest(v1.DownloadRequestPhaseProcessed, v1.DownloadTargetKindBackupLog, "a-backup-20170912150214"),
@@ -281,6 +300,12 @@ func TestProcessDownloadRequest(t *testing.T) {
 				harness.backupStore.On("GetDownloadURL", tc.downloadRequest.Spec.Target).Return("a-url", nil)
 			}
 
+			if tc.downloadServer {
+				downloadServer, err := NewDownloadServer("https://velero.example.com/downloads", harness.informerFactory.Velero().V1().BackupStorageLocations(), nil, nil, velerotest.NewLogger())
+				require.NoError(t, err)
+				harness.controller.downloadServer = downloadServer
+			}
+
 			// exercise method under test
 			key := tc.key
 			if key == "" && tc.downloadRequest != nil {
@@ -305,6 +330,14 @@ func TestProcessDownloadRequest(t *testing.T) {
 				assert.True(t, velerotest.TimesAreEqual(harness.controller.clock.Now().Add(signedURLTTL), output.Status.Expiration.Time), "expiration does not match")
 			}
 
+			if tc.downloadServer {
+				output, err := harness.client.VeleroV1().DownloadRequests(tc.downloadRequest.Namespace).Get(tc.downloadRequest.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+
+				assert.Equal(t, string(v1.DownloadRequestPhaseProcessed), string(output.Status.Phase))
+				assert.True(t, strings.HasPrefix(output.Status.DownloadURL, "https://velero.example.com/downloads/velero/a-location/BackupContents/a-backup?"), output.Status.DownloadURL)
+			}
+
 			if tc.downloadRequest != nil && tc.downloadRequest.Status.Phase == v1.DownloadRequestPhaseProcessed {
 				res, err := harness.client.VeleroV1().DownloadRequests(tc.downloadRequest.Namespace).Get(tc.downloadRequest.Name, metav1.GetOptions{})
 
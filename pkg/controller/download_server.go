@@ -0,0 +1,173 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
+	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
+	"github.com/heptio/velero/pkg/persistence"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	"github.com/heptio/velero/pkg/util/signedurl"
+)
+
+// DownloadServer is an http.Handler that serves the decrypted files of
+// DownloadRequests for backup storage locations that use encryption, so that
+// neither the locations' keys nor the objects' data keys leave the Velero
+// server. Every request must be for a URL created by the DownloadServer, and
+// must be made before the URL expires.
+type DownloadServer struct {
+	key                  []byte
+	baseURL              *url.URL
+	backupLocationLister listers.BackupStorageLocationLister
+	newPluginManager     func(logrus.FieldLogger) clientmgmt.Manager
+	secretsGetter        corev1client.SecretsGetter
+	newBackupStore       func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	clock                clock.Clock
+	log                  logrus.FieldLogger
+}
+
+// NewDownloadServer returns a DownloadServer that creates URLs under baseURL,
+// signed with a randomly generated key.
+func NewDownloadServer(
+	baseURL string,
+	backupLocationInformer informers.BackupStorageLocationInformer,
+	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+	logger logrus.FieldLogger,
+) (*DownloadServer, error) {
+	if baseURL == "" {
+		return nil, errors.New("download server URL must not be empty")
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing download server URL")
+	}
+	// files are served decrypted, and the URLs' signatures are bearer tokens
+	// until they expire, so the server only serves TLS
+	if u.Scheme != "https" {
+		return nil, errors.New("download server URL must be an https URL")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	key, err := signedurl.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DownloadServer{
+		key:                  key,
+		baseURL:              u,
+		backupLocationLister: backupLocationInformer.Lister(),
+		newPluginManager:     newPluginManager,
+		secretsGetter:        secretsGetter,
+		newBackupStore:       persistence.NewObjectBackupStore,
+		clock:                &clock.RealClock{},
+		log:                  logger,
+	}, nil
+}
+
+// signedURL returns a URL that the target's file in location can be
+// downloaded from until expires.
+func (s *DownloadServer) signedURL(location *v1.BackupStorageLocation, target v1.DownloadTarget, expires time.Time) string {
+	u := *s.baseURL
+	u.Path = strings.Join([]string{u.Path, location.Namespace, location.Name, string(target.Kind), target.Name}, "/")
+
+	query := url.Values{}
+	signedurl.Sign(s.key, query, expires, location.Namespace, location.Name, string(target.Kind), target.Name)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (s *DownloadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, s.baseURL.Path), "/"), "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+	namespace, locationName := parts[0], parts[1]
+	target := v1.DownloadTarget{Kind: v1.DownloadTargetKind(parts[2]), Name: parts[3]}
+
+	switch err := signedurl.Verify(s.key, r.URL.Query(), s.clock.Now(), namespace, locationName, string(target.Kind), target.Name); err {
+	case nil:
+	case signedurl.ErrExpired:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	default:
+		s.log.WithField("path", r.URL.Path).Warn("Rejecting download server request with an invalid signature")
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	log := s.log.WithFields(logrus.Fields{
+		"backupLocation": namespace + "/" + locationName,
+		"target":         string(target.Kind) + "/" + target.Name,
+	})
+
+	location, err := s.backupLocationLister.BackupStorageLocations(namespace).Get(locationName)
+	if apierrors.IsNotFound(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error getting backup storage location")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	pluginManager := s.newPluginManager(log)
+	defer pluginManager.CleanupClients()
+
+	backupStore, err := s.newBackupStore(location, pluginManager, s.secretsGetter, log)
+	if err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error getting backup store")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	file, err := backupStore.GetDownload(target)
+	if err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error getting file to download")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err := io.Copy(w, file); err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error writing file to download")
+	}
+}
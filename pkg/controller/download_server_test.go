@@ -0,0 +1,118 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
+	"github.com/heptio/velero/pkg/persistence"
+	persistencemocks "github.com/heptio/velero/pkg/persistence/mocks"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	pluginmocks "github.com/heptio/velero/pkg/plugin/mocks"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestNewDownloadServerRequiresURL(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+
+	_, err := NewDownloadServer("", informerFactory.Velero().V1().BackupStorageLocations(), nil, nil, velerotest.NewLogger())
+	assert.EqualError(t, err, "download server URL must not be empty")
+
+	_, err = NewDownloadServer("http://velero.example.com/downloads", informerFactory.Velero().V1().BackupStorageLocations(), nil, nil, velerotest.NewLogger())
+	assert.EqualError(t, err, "download server URL must be an https URL")
+}
+
+func TestDownloadServer(t *testing.T) {
+	var (
+		informerFactory = informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+		pluginManager   = new(pluginmocks.Manager)
+		backupStore     = new(persistencemocks.BackupStore)
+		location        = builder.ForBackupStorageLocation(v1.DefaultNamespace, "a-location").Provider("a-provider").Bucket("a-bucket").EncryptionKeySecret("a-secret", "key").Result()
+		target          = v1.DownloadTarget{Kind: v1.DownloadTargetKindBackupLog, Name: "a-backup"}
+	)
+
+	require.NoError(t, informerFactory.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(location))
+
+	server, err := NewDownloadServer(
+		"https://velero.example.com/downloads/",
+		informerFactory.Velero().V1().BackupStorageLocations(),
+		func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+		nil,
+		velerotest.NewLogger(),
+	)
+	require.NoError(t, err)
+
+	server.newBackupStore = func(loc *v1.BackupStorageLocation, _ persistence.ObjectStoreGetter, _ corev1client.SecretsGetter, _ logrus.FieldLogger) (persistence.BackupStore, error) {
+		assert.Equal(t, location, loc)
+		return backupStore, nil
+	}
+	pluginManager.On("CleanupClients").Return()
+	backupStore.On("GetDownload", target).Return(ioutil.NopCloser(strings.NewReader("decrypted logs")), nil)
+
+	get := func(url string) (int, string) {
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, httptest.NewRequest(http.MethodGet, url, nil))
+		return res.Code, res.Body.String()
+	}
+
+	signed := server.signedURL(location, target, time.Now().Add(time.Minute))
+	assert.True(t, strings.HasPrefix(signed, "https://velero.example.com/downloads/velero/a-location/BackupLog/a-backup?"), signed)
+
+	status, body := get(signed)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "decrypted logs", body)
+
+	// a URL for a different target with the same signature is rejected
+	status, _ = get(strings.Replace(signed, "/BackupLog/", "/BackupContents/", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// a URL for a different location with the same signature is rejected
+	status, _ = get(strings.Replace(signed, "/a-location/", "/other-location/", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// URLs signed with a different key are rejected
+	other, err := NewDownloadServer("https://velero.example.com/downloads", informerFactory.Velero().V1().BackupStorageLocations(), nil, nil, velerotest.NewLogger())
+	require.NoError(t, err)
+	status, _ = get(other.signedURL(location, target, time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// expired URLs are rejected
+	status, _ = get(server.signedURL(location, target, time.Now().Add(-time.Minute)))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// locations that don't exist aren't found
+	missing := location.DeepCopy()
+	missing.Name = "missing-location"
+	status, _ = get(server.signedURL(missing, target, time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusNotFound, status)
+
+	backupStore.AssertNumberOfCalls(t, "GetDownload", 1)
+}
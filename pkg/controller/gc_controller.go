@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
@@ -38,7 +39,8 @@ const (
 	GCSyncPeriod = 60 * time.Minute
 )
 
-// gcController creates DeleteBackupRequests for expired backups.
+// gcController creates DeleteBackupRequests for expired backups, and for
+// backups that aren't kept by their schedule's retention policy.
 type gcController struct {
 	*genericController
 
@@ -46,6 +48,7 @@ type gcController struct {
 	deleteBackupRequestLister listers.DeleteBackupRequestLister
 	deleteBackupRequestClient velerov1client.DeleteBackupRequestsGetter
 	backupLocationLister      listers.BackupStorageLocationLister
+	scheduleLister            listers.ScheduleLister
 
 	clock clock.Clock
 }
@@ -57,6 +60,7 @@ func NewGCController(
 	deleteBackupRequestInformer informers.DeleteBackupRequestInformer,
 	deleteBackupRequestClient velerov1client.DeleteBackupRequestsGetter,
 	backupLocationInformer informers.BackupStorageLocationInformer,
+	scheduleInformer informers.ScheduleInformer,
 ) Interface {
 	c := &gcController{
 		genericController:         newGenericController("gc-controller", logger),
@@ -65,6 +69,7 @@ func NewGCController(
 		deleteBackupRequestLister: deleteBackupRequestInformer.Lister(),
 		deleteBackupRequestClient: deleteBackupRequestClient,
 		backupLocationLister:      backupLocationInformer.Lister(),
+		scheduleLister:            scheduleInformer.Lister(),
 	}
 
 	c.syncHandler = c.processQueueItem
@@ -72,6 +77,7 @@ func NewGCController(
 		backupInformer.Informer().HasSynced,
 		deleteBackupRequestInformer.Informer().HasSynced,
 		backupLocationInformer.Informer().HasSynced,
+		scheduleInformer.Informer().HasSynced,
 	)
 
 	c.resyncPeriod = GCSyncPeriod
@@ -79,8 +85,30 @@ func NewGCController(
 
 	backupInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc:    c.enqueue,
-			UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+			AddFunc: c.enqueue,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueue(newObj)
+
+				// a scheduled backup finishing may mean that older backups
+				// from its schedule are no longer kept by its retention policy
+				oldBackup := oldObj.(*velerov1api.Backup)
+				newBackup := newObj.(*velerov1api.Backup)
+				if oldBackup.Status.Phase != newBackup.Status.Phase {
+					c.enqueueScheduleBackups(newBackup.Namespace, newBackup.Labels[velerov1api.ScheduleNameLabel])
+				}
+			},
+		},
+	)
+
+	scheduleInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldSchedule := oldObj.(*velerov1api.Schedule)
+				newSchedule := newObj.(*velerov1api.Schedule)
+				if !reflect.DeepEqual(oldSchedule.Spec.Retention, newSchedule.Spec.Retention) {
+					c.enqueueScheduleBackups(newSchedule.Namespace, newSchedule.Name)
+				}
+			},
 		},
 	)
 
@@ -103,6 +131,24 @@ func (c *gcController) enqueueAllBackups() {
 	}
 }
 
+// enqueueScheduleBackups enqueues all of the backups created by the schedule
+// so they can be checked against its retention policy.
+func (c *gcController) enqueueScheduleBackups(namespace, schedule string) {
+	if schedule == "" {
+		return
+	}
+
+	backups, err := c.backupLister.Backups(namespace).List(labels.SelectorFromSet(labels.Set{velerov1api.ScheduleNameLabel: schedule}))
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).WithField("schedule", schedule).Error("error listing schedule's backups")
+		return
+	}
+
+	for _, backup := range backups {
+		c.enqueue(backup)
+	}
+}
+
 func (c *gcController) processQueueItem(key string) error {
 	log := c.logger.WithField("backup", key)
 
@@ -131,11 +177,19 @@ func (c *gcController) processQueueItem(key string) error {
 
 	expiration := backup.Status.Expiration.Time
 	if expiration.IsZero() || expiration.After(now) {
-		log.Debug("Backup has not expired yet, skipping")
-		return nil
-	}
+		pruned, err := c.isPrunedBySchedule(backup)
+		if err != nil {
+			return err
+		}
+		if !pruned {
+			log.Debug("Backup has not expired yet, skipping")
+			return nil
+		}
 
-	log.Info("Backup has expired")
+		log.Info("Backup is not kept by its schedule's retention policy")
+	} else {
+		log.Info("Backup has expired")
+	}
 
 	loc, err := c.backupLocationLister.BackupStorageLocations(ns).Get(backup.Spec.StorageLocation)
 	if apierrors.IsNotFound(err) {
@@ -179,3 +233,39 @@ func (c *gcController) processQueueItem(key string) error {
 
 	return nil
 }
+
+// isPrunedBySchedule returns true if the backup was created by an enabled
+// schedule that has a valid retention policy, and the policy doesn't keep it.
+// Backups whose schedule has been deleted, or whose schedule isn't enabled or
+// has an invalid policy, are only deleted once their TTL expires.
+func (c *gcController) isPrunedBySchedule(backup *velerov1api.Backup) (bool, error) {
+	scheduleName := backup.Labels[velerov1api.ScheduleNameLabel]
+	if scheduleName == "" {
+		return false, nil
+	}
+
+	schedule, err := c.scheduleLister.Schedules(backup.Namespace).Get(scheduleName)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "error getting backup's schedule")
+	}
+
+	if schedule.Spec.Retention == nil {
+		return false, nil
+	}
+
+	// a policy that fails validation, including one that keeps nothing (e.g. because
+	// its keys are misspelled), is treated as if the schedule had no policy
+	if schedule.Status.Phase != velerov1api.SchedulePhaseEnabled || len(validateRetentionPolicy(schedule.Spec.Retention)) > 0 {
+		return false, nil
+	}
+
+	backups, err := c.backupLister.Backups(backup.Namespace).List(labels.SelectorFromSet(labels.Set{velerov1api.ScheduleNameLabel: scheduleName}))
+	if err != nil {
+		return false, errors.Wrap(err, "error listing schedule's backups")
+	}
+
+	return pkgbackup.BackupsToDelete(schedule.Spec.Retention, backups).Has(backup.Name), nil
+}
@@ -51,6 +51,7 @@ func TestGCControllerEnqueueAllBackups(t *testing.T) {
 			sharedInformers.Velero().V1().DeleteBackupRequests(),
 			client.VeleroV1(),
 			sharedInformers.Velero().V1().BackupStorageLocations(),
+			sharedInformers.Velero().V1().Schedules(),
 		).(*gcController)
 	)
 
@@ -115,6 +116,7 @@ func TestGCControllerHasUpdateFunc(t *testing.T) {
 		sharedInformers.Velero().V1().DeleteBackupRequests(),
 		client.VeleroV1(),
 		sharedInformers.Velero().V1().BackupStorageLocations(),
+		sharedInformers.Velero().V1().Schedules(),
 	).(*gcController)
 
 	keys := make(chan string)
@@ -159,6 +161,8 @@ func TestGCControllerProcessQueueItem(t *testing.T) {
 		backup                         *api.Backup
 		deleteBackupRequests           []*api.DeleteBackupRequest
 		backupLocation                 *api.BackupStorageLocation
+		schedule                       *api.Schedule
+		otherBackups                   []*api.Backup
 		expectDeletion                 bool
 		createDeleteBackupRequestError bool
 		expectError                    bool
@@ -232,6 +236,75 @@ func TestGCControllerProcessQueueItem(t *testing.T) {
 			},
 			expectDeletion: true,
 		},
+		{
+			name:           "unexpired scheduled backup that isn't kept by the schedule's retention policy is deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Phase(api.SchedulePhaseEnabled).Retention(api.ScheduleRetentionPolicy{KeepLast: 1}).Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: true,
+		},
+		{
+			name:           "unexpired scheduled backup that's kept by the schedule's retention policy is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Phase(api.SchedulePhaseEnabled).Retention(api.ScheduleRetentionPolicy{KeepLast: 1}).Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-2*time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
+		{
+			name:           "unexpired scheduled backup whose schedule has no retention policy is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
+		{
+			name:           "unexpired scheduled backup whose schedule's retention policy keeps nothing is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Phase(api.SchedulePhaseEnabled).Retention(api.ScheduleRetentionPolicy{}).Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
+		{
+			name:           "unexpired scheduled backup whose schedule's retention policy is invalid is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Phase(api.SchedulePhaseEnabled).Retention(api.ScheduleRetentionPolicy{KeepLast: -1}).Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
+		{
+			name:           "unexpired scheduled backup whose schedule isn't enabled is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			schedule:       builder.ForSchedule(api.DefaultNamespace, "schedule-1").Phase(api.SchedulePhaseFailedValidation).Retention(api.ScheduleRetentionPolicy{KeepLast: 1}).Result(),
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
+		{
+			name:           "unexpired scheduled backup whose schedule doesn't exist is not deleted",
+			backup:         scheduledBackup("backup-1", fakeClock.Now().Add(-2*time.Hour)).Expiration(fakeClock.Now().Add(time.Hour)).Result(),
+			backupLocation: defaultBackupLocation,
+			otherBackups: []*api.Backup{
+				scheduledBackup("backup-2", fakeClock.Now().Add(-time.Hour)).Result(),
+			},
+			expectDeletion: false,
+		},
 		{
 			name:                           "create DeleteBackupRequest error returns an error",
 			backup:                         defaultBackup().Expiration(fakeClock.Now().Add(-time.Second)).StorageLocation("default").Result(),
@@ -255,6 +328,7 @@ func TestGCControllerProcessQueueItem(t *testing.T) {
 				sharedInformers.Velero().V1().DeleteBackupRequests(),
 				client.VeleroV1(),
 				sharedInformers.Velero().V1().BackupStorageLocations(),
+				sharedInformers.Velero().V1().Schedules(),
 			).(*gcController)
 			controller.clock = fakeClock
 
@@ -268,6 +342,14 @@ func TestGCControllerProcessQueueItem(t *testing.T) {
 				sharedInformers.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(test.backupLocation)
 			}
 
+			if test.schedule != nil {
+				sharedInformers.Velero().V1().Schedules().Informer().GetStore().Add(test.schedule)
+			}
+
+			for _, backup := range test.otherBackups {
+				sharedInformers.Velero().V1().Backups().Informer().GetStore().Add(backup)
+			}
+
 			for _, dbr := range test.deleteBackupRequests {
 				sharedInformers.Velero().V1().DeleteBackupRequests().Informer().GetStore().Add(dbr)
 			}
@@ -295,3 +377,11 @@ func TestGCControllerProcessQueueItem(t *testing.T) {
 		})
 	}
 }
+
+func scheduledBackup(name string, started time.Time) *builder.BackupBuilder {
+	return builder.ForBackup(api.DefaultNamespace, name).
+		ObjectMeta(builder.WithLabels(api.ScheduleNameLabel, "schedule-1")).
+		Phase(api.BackupPhaseCompleted).
+		StartTimestamp(started).
+		StorageLocation("default")
+}
@@ -34,6 +34,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/credentials"
 	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
 	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
@@ -53,7 +54,9 @@ type podVolumeBackupController struct {
 	pvcLister             corev1listers.PersistentVolumeClaimLister
 	pvLister              corev1listers.PersistentVolumeLister
 	backupLocationLister  listers.BackupStorageLocationLister
+	credentialFileStore   credentials.FileStore
 	nodeName              string
+	running               *runningOperations
 
 	processBackupFunc func(*velerov1api.PodVolumeBackup) error
 	fileSystem        filesystem.Interface
@@ -70,6 +73,7 @@ func NewPodVolumeBackupController(
 	pvcInformer corev1informers.PersistentVolumeClaimInformer,
 	pvInformer corev1informers.PersistentVolumeInformer,
 	backupLocationInformer informers.BackupStorageLocationInformer,
+	credentialFileStore credentials.FileStore,
 	nodeName string,
 ) Interface {
 	c := &podVolumeBackupController{
@@ -81,7 +85,9 @@ func NewPodVolumeBackupController(
 		pvcLister:             pvcInformer.Lister(),
 		pvLister:              pvInformer.Lister(),
 		backupLocationLister:  backupLocationInformer.Lister(),
+		credentialFileStore:   credentialFileStore,
 		nodeName:              nodeName,
+		running:               newRunningOperations(),
 
 		fileSystem: filesystem.NewFileSystem(),
 		clock:      &clock.RealClock{},
@@ -118,6 +124,13 @@ func (c *podVolumeBackupController) pvbHandler(obj interface{}) {
 
 	log := loggerForPodVolumeBackup(c.logger, req)
 
+	// a running backup is canceled straight away, by killing its restic
+	// process. A new one is processed as usual, and marked Canceled then.
+	if req.Spec.Cancel && c.running.cancel(kube.NamespaceAndName(req)) {
+		log.Info("Canceled running backup")
+		return
+	}
+
 	if req.Status.Phase != "" && req.Status.Phase != velerov1api.PodVolumeBackupPhaseNew {
 		log.Debug("Backup is not new, not enqueuing")
 		return
@@ -174,10 +187,20 @@ func loggerForPodVolumeBackup(baseLogger logrus.FieldLogger, req *velerov1api.Po
 func (c *podVolumeBackupController) processBackup(req *velerov1api.PodVolumeBackup) error {
 	log := loggerForPodVolumeBackup(c.logger, req)
 
+	if req.Spec.Cancel {
+		log.Info("Backup was canceled before it started")
+		return c.markCanceled(req, log)
+	}
+
 	log.Info("Backup starting")
 
 	var err error
 
+	// the backup's recorded as running before it's marked InProgress, so
+	// that it can be canceled from then on.
+	ctx, finish := c.running.start(kube.NamespaceAndName(req))
+	defer finish()
+
 	// update status to InProgress
 	req, err = c.patchPodVolumeBackup(req, func(r *velerov1api.PodVolumeBackup) {
 		r.Status.Phase = velerov1api.PodVolumeBackupPhaseInProgress
@@ -188,6 +211,13 @@ func (c *podVolumeBackupController) processBackup(req *velerov1api.PodVolumeBack
 		return errors.WithStack(err)
 	}
 
+	// the backup may have been canceled after it was retrieved, but
+	// before it was recorded as running.
+	if req.Spec.Cancel {
+		log.Info("Backup was canceled before it started")
+		return c.markCanceled(req, log)
+	}
+
 	pod, err := c.podLister.Pods(req.Spec.Pod.Namespace).Get(req.Spec.Pod.Name)
 	if err != nil {
 		log.WithError(err).Errorf("Error getting pod %s/%s", req.Spec.Pod.Namespace, req.Spec.Pod.Name)
@@ -226,19 +256,21 @@ func (c *podVolumeBackupController) processBackup(req *velerov1api.PodVolumeBack
 		req.Spec.Tags,
 	)
 
-	// if this is azure, set resticCmd.Env appropriately
-	var env []string
-	if strings.HasPrefix(req.Spec.RepoIdentifier, "azure") {
-		if env, err = restic.AzureCmdEnv(c.backupLocationLister, req.Namespace, req.Spec.BackupStorageLocation); err != nil {
-			return c.fail(req, errors.Wrap(err, "error setting restic cmd env").Error(), log)
-		}
-		resticCmd.Env = env
+	env, err := restic.CmdEnv(c.backupLocationLister, c.credentialFileStore, req.Namespace, req.Spec.BackupStorageLocation)
+	if err != nil {
+		return c.fail(req, errors.Wrap(err, "error setting restic cmd env").Error(), log)
 	}
+	resticCmd.Env = env
 
 	var stdout, stderr string
 
 	var emptySnapshot bool
-	if stdout, stderr, err = veleroexec.RunCommand(resticCmd.Cmd()); err != nil {
+	if stdout, stderr, err = veleroexec.RunCommand(resticCmd.CmdContext(ctx)); err != nil {
+		if ctx.Err() != nil {
+			log.Info("Backup was canceled, restic backup was stopped")
+			return c.markCanceled(req, log)
+		}
+
 		if strings.Contains(stderr, "snapshot is empty") {
 			emptySnapshot = true
 		} else {
@@ -318,6 +350,18 @@ func (c *podVolumeBackupController) fail(req *velerov1api.PodVolumeBackup, msg s
 	return nil
 }
 
+func (c *podVolumeBackupController) markCanceled(req *velerov1api.PodVolumeBackup, log logrus.FieldLogger) error {
+	if _, err := c.patchPodVolumeBackup(req, func(r *velerov1api.PodVolumeBackup) {
+		r.Status.Phase = velerov1api.PodVolumeBackupPhaseCanceled
+		r.Status.Message = "backup was canceled"
+		r.Status.CompletionTimestamp.Time = c.clock.Now()
+	}); err != nil {
+		log.WithError(err).Error("Error setting PodVolumeBackup phase to Canceled")
+		return err
+	}
+	return nil
+}
+
 func singlePathMatch(path string) (string, error) {
 	matches, err := filepath.Glob(path)
 	if err != nil {
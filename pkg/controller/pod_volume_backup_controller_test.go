@@ -18,11 +18,17 @@ package controller
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	velerofake "github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	"github.com/heptio/velero/pkg/util/kube"
 	velerotest "github.com/heptio/velero/pkg/util/test"
 )
 
@@ -169,3 +175,46 @@ func TestPVBHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestPVBHandlerCancelsRunningBackup(t *testing.T) {
+	c := &podVolumeBackupController{
+		genericController: newGenericController("pod-volume-backup", velerotest.NewLogger()),
+		nodeName:          "foo",
+		running:           newRunningOperations(),
+	}
+
+	pvb := builder.ForPodVolumeBackup(velerov1api.DefaultNamespace, "pvb-1").Phase(velerov1api.PodVolumeBackupPhaseInProgress).Result()
+	pvb.Spec.Node = "foo"
+	pvb.Spec.Cancel = true
+
+	ctx, finish := c.running.start(kube.NamespaceAndName(pvb))
+	defer finish()
+
+	c.pvbHandler(pvb)
+
+	assert.Error(t, ctx.Err())
+	assert.Equal(t, 0, c.queue.Len())
+}
+
+func TestProcessBackupCanceledBeforeStart(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	pvb := builder.ForPodVolumeBackup(velerov1api.DefaultNamespace, "pvb-1").Phase(velerov1api.PodVolumeBackupPhaseNew).Result()
+	pvb.Spec.Cancel = true
+	client := velerofake.NewSimpleClientset(pvb)
+
+	c := &podVolumeBackupController{
+		genericController:     newGenericController("pod-volume-backup", velerotest.NewLogger()),
+		podVolumeBackupClient: client.VeleroV1(),
+		running:               newRunningOperations(),
+		clock:                 clock.NewFakeClock(now),
+	}
+
+	require.NoError(t, c.processBackup(pvb.DeepCopy()))
+
+	res, err := client.VeleroV1().PodVolumeBackups(pvb.Namespace).Get(pvb.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, velerov1api.PodVolumeBackupPhaseCanceled, res.Status.Phase)
+	assert.True(t, res.Status.StartTimestamp.IsZero())
+	assert.Equal(t, now, res.Status.CompletionTimestamp.Time.UTC())
+}
@@ -17,12 +17,12 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pkg/errors"
@@ -37,6 +37,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/credentials"
 	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
 	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
@@ -57,7 +58,9 @@ type podVolumeRestoreController struct {
 	pvcLister              corev1listers.PersistentVolumeClaimLister
 	pvLister               corev1listers.PersistentVolumeLister
 	backupLocationLister   listers.BackupStorageLocationLister
+	credentialFileStore    credentials.FileStore
 	nodeName               string
+	running                *runningOperations
 
 	processRestoreFunc func(*velerov1api.PodVolumeRestore) error
 	fileSystem         filesystem.Interface
@@ -74,6 +77,7 @@ func NewPodVolumeRestoreController(
 	pvcInformer corev1informers.PersistentVolumeClaimInformer,
 	pvInformer corev1informers.PersistentVolumeInformer,
 	backupLocationInformer informers.BackupStorageLocationInformer,
+	credentialFileStore credentials.FileStore,
 	nodeName string,
 ) Interface {
 	c := &podVolumeRestoreController{
@@ -85,7 +89,9 @@ func NewPodVolumeRestoreController(
 		pvcLister:              pvcInformer.Lister(),
 		pvLister:               pvInformer.Lister(),
 		backupLocationLister:   backupLocationInformer.Lister(),
+		credentialFileStore:    credentialFileStore,
 		nodeName:               nodeName,
+		running:                newRunningOperations(),
 
 		fileSystem: filesystem.NewFileSystem(),
 		clock:      &clock.RealClock{},
@@ -127,6 +133,13 @@ func (c *podVolumeRestoreController) pvrHandler(obj interface{}) {
 	pvr := obj.(*velerov1api.PodVolumeRestore)
 	log := loggerForPodVolumeRestore(c.logger, pvr)
 
+	// a running restore is canceled straight away, by killing its restic
+	// process. A new one is processed as usual, and marked Canceled then.
+	if pvr.Spec.Cancel && c.running.cancel(kube.NamespaceAndName(pvr)) {
+		log.Info("Canceled running restore")
+		return
+	}
+
 	if !isPVRNew(pvr) {
 		log.Debugf("Restore is not new, not enqueuing")
 		return
@@ -259,10 +272,20 @@ func loggerForPodVolumeRestore(baseLogger logrus.FieldLogger, req *velerov1api.P
 func (c *podVolumeRestoreController) processRestore(req *velerov1api.PodVolumeRestore) error {
 	log := loggerForPodVolumeRestore(c.logger, req)
 
+	if req.Spec.Cancel {
+		log.Info("Restore was canceled before it started")
+		return c.markRestoreCanceled(req, log)
+	}
+
 	log.Info("Restore starting")
 
 	var err error
 
+	// the restore's recorded as running before it's marked InProgress, so
+	// that it can be canceled from then on.
+	ctx, finish := c.running.start(kube.NamespaceAndName(req))
+	defer finish()
+
 	// update status to InProgress
 	req, err = c.patchPodVolumeRestore(req, func(r *velerov1api.PodVolumeRestore) {
 		r.Status.Phase = velerov1api.PodVolumeRestorePhaseInProgress
@@ -273,6 +296,13 @@ func (c *podVolumeRestoreController) processRestore(req *velerov1api.PodVolumeRe
 		return errors.WithStack(err)
 	}
 
+	// the restore may have been canceled after it was retrieved, but
+	// before it was recorded as running.
+	if req.Spec.Cancel {
+		log.Info("Restore was canceled before it started")
+		return c.markRestoreCanceled(req, log)
+	}
+
 	pod, err := c.podLister.Pods(req.Spec.Pod.Namespace).Get(req.Spec.Pod.Name)
 	if err != nil {
 		log.WithError(err).Errorf("Error getting pod %s/%s", req.Spec.Pod.Namespace, req.Spec.Pod.Name)
@@ -294,7 +324,12 @@ func (c *podVolumeRestoreController) processRestore(req *velerov1api.PodVolumeRe
 	defer os.Remove(credsFile)
 
 	// execute the restore process
-	if err := c.restorePodVolume(req, credsFile, volumeDir, log); err != nil {
+	if err := c.restorePodVolume(ctx, req, credsFile, volumeDir, log); err != nil {
+		if ctx.Err() != nil {
+			log.Info("Restore was canceled, restic restore was stopped")
+			return c.markRestoreCanceled(req, log)
+		}
+
 		log.WithError(err).Error("Error restoring volume")
 		return c.failRestore(req, errors.Wrap(err, "error restoring volume").Error(), log)
 	}
@@ -313,7 +348,7 @@ func (c *podVolumeRestoreController) processRestore(req *velerov1api.PodVolumeRe
 	return nil
 }
 
-func (c *podVolumeRestoreController) restorePodVolume(req *velerov1api.PodVolumeRestore, credsFile, volumeDir string, log logrus.FieldLogger) error {
+func (c *podVolumeRestoreController) restorePodVolume(ctx context.Context, req *velerov1api.PodVolumeRestore, credsFile, volumeDir string, log logrus.FieldLogger) error {
 	// Get the full path of the new volume's directory as mounted in the daemonset pod, which
 	// will look like: /host_pods/<new-pod-uid>/volumes/<volume-plugin-name>/<volume-dir>
 	volumePath, err := singlePathMatch(fmt.Sprintf("/host_pods/%s/volumes/*/%s", string(req.Spec.Pod.UID), volumeDir))
@@ -328,18 +363,15 @@ func (c *podVolumeRestoreController) restorePodVolume(req *velerov1api.PodVolume
 		volumePath,
 	)
 
-	// if this is azure, set resticCmd.Env appropriately
-	if strings.HasPrefix(req.Spec.RepoIdentifier, "azure") {
-		env, err := restic.AzureCmdEnv(c.backupLocationLister, req.Namespace, req.Spec.BackupStorageLocation)
-		if err != nil {
-			return c.failRestore(req, errors.Wrap(err, "error setting restic cmd env").Error(), log)
-		}
-		resticCmd.Env = env
+	env, err := restic.CmdEnv(c.backupLocationLister, c.credentialFileStore, req.Namespace, req.Spec.BackupStorageLocation)
+	if err != nil {
+		return c.failRestore(req, errors.Wrap(err, "error setting restic cmd env").Error(), log)
 	}
+	resticCmd.Env = env
 
 	var stdout, stderr string
 
-	if stdout, stderr, err = veleroexec.RunCommand(resticCmd.Cmd()); err != nil {
+	if stdout, stderr, err = veleroexec.RunCommand(resticCmd.CmdContext(ctx)); err != nil {
 		return errors.Wrapf(err, "error running restic restore, cmd=%s, stdout=%s, stderr=%s", resticCmd.String(), stdout, stderr)
 	}
 	log.Debugf("Ran command=%s, stdout=%s, stderr=%s", resticCmd.String(), stdout, stderr)
@@ -416,3 +448,15 @@ func (c *podVolumeRestoreController) failRestore(req *velerov1api.PodVolumeResto
 	}
 	return nil
 }
+
+func (c *podVolumeRestoreController) markRestoreCanceled(req *velerov1api.PodVolumeRestore, log logrus.FieldLogger) error {
+	if _, err := c.patchPodVolumeRestore(req, func(pvr *velerov1api.PodVolumeRestore) {
+		pvr.Status.Phase = velerov1api.PodVolumeRestorePhaseCanceled
+		pvr.Status.Message = "restore was canceled"
+		pvr.Status.CompletionTimestamp.Time = c.clock.Now()
+	}); err != nil {
+		log.WithError(err).Error("Error setting PodVolumeRestore phase to Canceled")
+		return err
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -34,6 +35,7 @@ import (
 	veleroinformers "github.com/heptio/velero/pkg/generated/informers/externalversions"
 	velerov1listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
 	"github.com/heptio/velero/pkg/restic"
+	"github.com/heptio/velero/pkg/util/kube"
 	velerotest "github.com/heptio/velero/pkg/util/test"
 )
 
@@ -233,6 +235,53 @@ func TestPVRHandler(t *testing.T) {
 	}
 }
 
+func TestPVRHandlerCancelsRunningRestore(t *testing.T) {
+	c := &podVolumeRestoreController{
+		genericController: newGenericController("pod-volume-restore", velerotest.NewLogger()),
+		nodeName:          "foo",
+		running:           newRunningOperations(),
+	}
+
+	pvr := &velerov1api.PodVolumeRestore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: velerov1api.DefaultNamespace, Name: "pvr-1"},
+		Spec:       velerov1api.PodVolumeRestoreSpec{Cancel: true},
+		Status:     velerov1api.PodVolumeRestoreStatus{Phase: velerov1api.PodVolumeRestorePhaseInProgress},
+	}
+
+	ctx, finish := c.running.start(kube.NamespaceAndName(pvr))
+	defer finish()
+
+	c.pvrHandler(pvr)
+
+	assert.Error(t, ctx.Err())
+	assert.Equal(t, 0, c.queue.Len())
+}
+
+func TestProcessRestoreCanceledBeforeStart(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	pvr := &velerov1api.PodVolumeRestore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: velerov1api.DefaultNamespace, Name: "pvr-1"},
+		Spec:       velerov1api.PodVolumeRestoreSpec{Cancel: true},
+	}
+	client := velerofake.NewSimpleClientset(pvr)
+
+	c := &podVolumeRestoreController{
+		genericController:      newGenericController("pod-volume-restore", velerotest.NewLogger()),
+		podVolumeRestoreClient: client.VeleroV1(),
+		running:                newRunningOperations(),
+		clock:                  clock.NewFakeClock(now),
+	}
+
+	require.NoError(t, c.processRestore(pvr.DeepCopy()))
+
+	res, err := client.VeleroV1().PodVolumeRestores(pvr.Namespace).Get(pvr.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, velerov1api.PodVolumeRestorePhaseCanceled, res.Status.Phase)
+	assert.True(t, res.Status.StartTimestamp.IsZero())
+	assert.Equal(t, now, res.Status.CompletionTimestamp.Time.UTC())
+}
+
 func TestPodHandler(t *testing.T) {
 	controllerNode := "foo"
 
@@ -19,6 +19,7 @@ package controller
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,9 +31,13 @@ import (
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
@@ -85,9 +90,13 @@ type restoreController struct {
 	defaultBackupLocation  string
 	metrics                *metrics.ServerMetrics
 	logFormat              logging.Format
+	clock                  clock.Clock
+	running                *runningOperations
 
 	newPluginManager func(logger logrus.FieldLogger) clientmgmt.Manager
-	newBackupStore   func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+	secretsGetter    corev1client.SecretsGetter
+	configMapsGetter corev1client.ConfigMapsGetter
+	newBackupStore   func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
 }
 
 func NewRestoreController(
@@ -102,6 +111,8 @@ func NewRestoreController(
 	logger logrus.FieldLogger,
 	restoreLogLevel logrus.Level,
 	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+	configMapsGetter corev1client.ConfigMapsGetter,
 	defaultBackupLocation string,
 	metrics *metrics.ServerMetrics,
 	logFormat logging.Format,
@@ -120,10 +131,14 @@ func NewRestoreController(
 		defaultBackupLocation:  defaultBackupLocation,
 		metrics:                metrics,
 		logFormat:              logFormat,
+		clock:                  &clock.RealClock{},
+		running:                newRunningOperations(),
 
 		// use variables to refer to these functions so they can be
 		// replaced with fakes for testing.
 		newPluginManager: newPluginManager,
+		secretsGetter:    secretsGetter,
+		configMapsGetter: configMapsGetter,
 		newBackupStore:   persistence.NewObjectBackupStore,
 	}
 
@@ -160,6 +175,33 @@ func NewRestoreController(
 				}
 				c.queue.Add(key)
 			},
+			UpdateFunc: func(_, obj interface{}) {
+				restore := obj.(*api.Restore)
+
+				if !restore.Spec.Cancel {
+					return
+				}
+				switch restore.Status.Phase {
+				case "", api.RestorePhaseNew, api.RestorePhaseInProgress:
+					// only unfinished restores can be canceled
+				default:
+					return
+				}
+
+				key, err := cache.MetaNamespaceKeyFunc(restore)
+				if err != nil {
+					c.logger.WithError(errors.WithStack(err)).WithField("restore", restore).Error("Error creating queue key, item not added to queue")
+					return
+				}
+
+				// a running restore is canceled straight away. Any other is
+				// processed as usual, and marked Canceled then.
+				if c.running.cancel(key) {
+					c.logger.WithField("restore", kubeutil.NamespaceAndName(restore)).Debug("Canceled running restore")
+					return
+				}
+				c.queue.Add(key)
+			},
 		},
 	)
 
@@ -202,6 +244,14 @@ func (c *restoreController) processQueueItem(key string) error {
 	switch restore.Status.Phase {
 	case "", api.RestorePhaseNew:
 		// only process new restores
+	case api.RestorePhaseInProgress:
+		// restores that are in progress are only queued if they've been
+		// canceled and aren't being run by this server, e.g. because it
+		// restarted while they were running.
+		if restore.Spec.Cancel {
+			return c.cancelOrphanedRestore(restore, log)
+		}
+		return nil
 	default:
 		return nil
 	}
@@ -232,13 +282,25 @@ func (c *restoreController) processRestore(restore *api.Restore) error {
 	backupScheduleName := restore.Spec.ScheduleName
 	c.metrics.RegisterRestoreAttempt(backupScheduleName)
 
-	if len(restore.Status.ValidationErrors) > 0 {
+	switch {
+	case restore.Spec.Cancel:
+		c.logger.WithField("restore", kubeutil.NamespaceAndName(restore)).Info("Restore was canceled before it started")
+		restore.Status.Phase = api.RestorePhaseCanceled
+		restore.Status.CompletionTimestamp.Time = c.clock.Now()
+	case len(restore.Status.ValidationErrors) > 0:
 		restore.Status.Phase = api.RestorePhaseFailedValidation
 		c.metrics.RegisterRestoreValidationFailed(backupScheduleName)
-	} else {
+	default:
 		restore.Status.Phase = api.RestorePhaseInProgress
+		restore.Status.StartTimestamp.Time = c.clock.Now()
 	}
 
+	// the restore's recorded as running before it's marked InProgress, so
+	// that it can be canceled from then on.
+	key := kubeutil.NamespaceAndName(restore)
+	ctx, finish := c.running.start(key)
+	defer finish()
+
 	// patch to update status and persist to API
 	updatedRestore, err := patchRestore(original, restore, c.restoreClient)
 	if err != nil {
@@ -250,15 +312,24 @@ func (c *restoreController) processRestore(restore *api.Restore) error {
 	original = updatedRestore
 	restore = updatedRestore.DeepCopy()
 
-	if restore.Status.Phase == api.RestorePhaseFailedValidation {
+	if restore.Status.Phase != api.RestorePhaseInProgress {
 		return nil
 	}
 
-	if err := c.runValidatedRestore(restore, info); err != nil {
+	// the restore may have been canceled after it was retrieved, but
+	// before it was recorded as running.
+	if updatedRestore.Spec.Cancel {
+		c.running.cancel(key)
+	}
+
+	if err := c.runValidatedRestore(ctx, restore, info); err != nil {
 		c.logger.WithError(err).Debug("Restore failed")
 		restore.Status.Phase = api.RestorePhaseFailed
 		restore.Status.FailureReason = err.Error()
 		c.metrics.RegisterRestoreFailed(backupScheduleName)
+	} else if ctx.Err() != nil {
+		c.logger.Debug("Restore canceled")
+		restore.Status.Phase = api.RestorePhaseCanceled
 	} else if restore.Status.Errors > 0 {
 		c.logger.Debug("Restore partially failed")
 		restore.Status.Phase = api.RestorePhasePartiallyFailed
@@ -268,6 +339,7 @@ func (c *restoreController) processRestore(restore *api.Restore) error {
 		restore.Status.Phase = api.RestorePhaseCompleted
 		c.metrics.RegisterRestoreSuccess(backupScheduleName)
 	}
+	restore.Status.CompletionTimestamp.Time = c.clock.Now()
 
 	c.logger.Debug("Updating restore's final status")
 	if _, err = patchRestore(original, restore, c.restoreClient); err != nil {
@@ -277,6 +349,34 @@ func (c *restoreController) processRestore(restore *api.Restore) error {
 	return nil
 }
 
+// cancelOrphanedRestore marks a restore that's in progress, but isn't being run by
+// this server, as Canceled.
+func (c *restoreController) cancelOrphanedRestore(restore *api.Restore, log logrus.FieldLogger) error {
+	// the informer's copy may be out of date, so make sure the restore's
+	// still in progress.
+	original, err := c.restoreClient.Restores(restore.Namespace).Get(restore.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting restore")
+	}
+	if original.Status.Phase != api.RestorePhaseInProgress {
+		return nil
+	}
+
+	log.Info("Restore isn't being run by this server, marking it as canceled")
+	updated := original.DeepCopy()
+	updated.Status.Phase = api.RestorePhaseCanceled
+	updated.Status.CompletionTimestamp.Time = c.clock.Now()
+
+	if _, err := patchRestore(original, updated, c.restoreClient); err != nil {
+		return errors.Wrap(err, "error updating restore's status to Canceled")
+	}
+
+	return nil
+}
+
 type backupInfo struct {
 	backup      *api.Backup
 	backupStore persistence.BackupStore
@@ -309,6 +409,30 @@ func (c *restoreController) validateAndComplete(restore *api.Restore, pluginMana
 		restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Invalid included/excluded namespace lists: %v", err))
 	}
 
+	// validate the included items
+	for _, item := range restore.Spec.IncludedItems {
+		if _, _, _, err := pkgrestore.ParseIncludedItem(item); err != nil {
+			restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Invalid included item: %v", err))
+		}
+	}
+
+	// validate the existing resource policy
+	switch restore.Spec.ExistingResourcePolicy {
+	case "", api.ExistingResourcePolicyNone, api.ExistingResourcePolicyUpdate, api.ExistingResourcePolicyRecreate:
+	default:
+		restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Invalid existing resource policy %q, must be one of none, update or recreate", restore.Spec.ExistingResourcePolicy))
+	}
+
+	// validate the resource modifier rules
+	if restore.Spec.ResourceModifiers != nil {
+		configMap, err := c.configMapsGetter.ConfigMaps(c.namespace).Get(restore.Spec.ResourceModifiers.Name, metav1.GetOptions{})
+		if err != nil {
+			restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Error getting resource modifiers ConfigMap %s: %v", restore.Spec.ResourceModifiers.Name, err))
+		} else if _, err := pkgrestore.GetResourceModifiers(configMap); err != nil {
+			restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Invalid resource modifiers: %v", err))
+		}
+	}
+
 	// validate that exactly one of BackupName and ScheduleName have been specified
 	if !backupXorScheduleProvided(restore) {
 		restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, "Either a backup or schedule must be specified as a source for the restore, but not both")
@@ -345,6 +469,11 @@ func (c *restoreController) validateAndComplete(restore *api.Restore, pluginMana
 		return backupInfo{}
 	}
 
+	if info.backup.Status.Phase == api.BackupPhaseCanceled {
+		restore.Status.ValidationErrors = append(restore.Status.ValidationErrors, fmt.Sprintf("Backup %s was canceled, so it can't be restored", info.backup.Name))
+		return backupInfo{}
+	}
+
 	// Fill in the ScheduleName so it's easier to consume for metrics.
 	if restore.Spec.ScheduleName == "" {
 		restore.Spec.ScheduleName = info.backup.GetLabels()[velerov1api.ScheduleNameLabel]
@@ -397,7 +526,7 @@ func (c *restoreController) fetchBackupInfo(backupName string, pluginManager cli
 		return backupInfo{}, errors.WithStack(err)
 	}
 
-	backupStore, err := c.newBackupStore(location, pluginManager, c.logger)
+	backupStore, err := c.newBackupStore(location, pluginManager, c.secretsGetter, c.logger)
 	if err != nil {
 		return backupInfo{}, err
 	}
@@ -411,8 +540,9 @@ func (c *restoreController) fetchBackupInfo(backupName string, pluginManager cli
 // runValidatedRestore takes a validated restore API object and executes the restore process.
 // The log and results files are uploaded to backup storage. Any error returned from this function
 // means that the restore failed. This function updates the restore API object with warning and error
-// counts, but *does not* update its phase or patch it via the API.
-func (c *restoreController) runValidatedRestore(restore *api.Restore, info backupInfo) error {
+// counts, but *does not* update its phase or patch it via the API. If ctx is canceled, the restore
+// stops restoring items, and its log and results so far are uploaded.
+func (c *restoreController) runValidatedRestore(ctx context.Context, restore *api.Restore, info backupInfo) error {
 	// instantiate the per-restore logger that will output both to a temp file
 	// (for upload to object storage) and to stdout.
 	restoreLog, err := newRestoreLogger(restore, c.logger, c.restoreLogLevel, c.logFormat)
@@ -429,11 +559,13 @@ func (c *restoreController) runValidatedRestore(restore *api.Restore, info backu
 		return errors.Wrap(err, "error getting restore item actions")
 	}
 
-	backupFile, err := downloadToTempFile(restore.Spec.BackupName, info.backupStore, restoreLog)
+	backupContents, backupFile, err := getBackupContents(restore.Spec.BackupName, info.backupStore, restoreLog)
 	if err != nil {
-		return errors.Wrap(err, "error downloading backup")
+		return err
+	}
+	if backupFile != nil {
+		defer closeAndRemoveFile(backupFile, c.logger)
 	}
-	defer closeAndRemoveFile(backupFile, c.logger)
 
 	volumeSnapshots, err := info.backupStore.GetBackupVolumeSnapshots(restore.Spec.BackupName)
 	if err != nil {
@@ -441,8 +573,12 @@ func (c *restoreController) runValidatedRestore(restore *api.Restore, info backu
 	}
 
 	restoreLog.Info("starting restore")
-	restoreWarnings, restoreErrors := c.restorer.Restore(restoreLog, restore, info.backup, volumeSnapshots, backupFile, actions, c.snapshotLocationLister, pluginManager)
-	restoreLog.Info("restore completed")
+	restoreWarnings, restoreErrors, dryRunReport := c.restorer.Restore(ctx, restoreLog, restore, info.backup, volumeSnapshots, backupContents, actions, c.snapshotLocationLister, pluginManager)
+	if ctx.Err() != nil {
+		restoreLog.Info("restore canceled")
+	} else {
+		restoreLog.Info("restore completed")
+	}
 
 	if logReader, err := restoreLog.done(c.logger); err != nil {
 		restoreErrors.Velero = append(restoreErrors.Velero, fmt.Sprintf("error getting restore log reader: %v", err))
@@ -469,6 +605,9 @@ func (c *restoreController) runValidatedRestore(restore *api.Restore, info backu
 		"warnings": restoreWarnings,
 		"errors":   restoreErrors,
 	}
+	for k, v := range dryRunReport.Results() {
+		m[k] = v
+	}
 
 	if err := putResults(restore, m, info.backupStore, c.logger); err != nil {
 		c.logger.WithError(err).Error("Error uploading restore results to backup storage")
@@ -497,6 +636,39 @@ func putResults(restore *api.Restore, results map[string]pkgrestore.Result, back
 	return nil
 }
 
+// getBackupContents returns the contents of the backup to restore from. If the
+// backup's tarball has an index, and the object store supports ranged reads,
+// items are read from the tarball in object storage as they're needed.
+// Otherwise the tarball is downloaded to a temp file, which is also returned so
+// that it can be removed, and extracted if it has no index.
+func getBackupContents(backupName string, backupStore persistence.BackupStore, logger logrus.FieldLogger) (pkgrestore.BackupContents, *os.File, error) {
+	index, err := backupStore.GetBackupIndex(backupName)
+	if err != nil {
+		return pkgrestore.BackupContents{}, nil, errors.Wrap(err, "error getting backup tarball index")
+	}
+
+	if index != nil {
+		source, err := backupStore.GetBackupContentsReaderAt(backupName)
+		if err != nil {
+			return pkgrestore.BackupContents{}, nil, errors.Wrap(err, "error getting backup contents reader")
+		}
+		if source != nil {
+			logger.WithField("backup", backupName).Info("Reading backup contents from backup storage as they're needed")
+			return pkgrestore.BackupContents{Index: index, Source: source}, nil, nil
+		}
+	}
+
+	backupFile, err := downloadToTempFile(backupName, backupStore, logger)
+	if err != nil {
+		return pkgrestore.BackupContents{}, nil, errors.Wrap(err, "error downloading backup")
+	}
+
+	if index != nil {
+		return pkgrestore.BackupContents{Index: index, Source: backupFile}, backupFile, nil
+	}
+	return pkgrestore.BackupContents{Reader: backupFile}, backupFile, nil
+}
+
 func downloadToTempFile(backupName string, backupStore persistence.BackupStore, logger logrus.FieldLogger) (*os.File, error) {
 	readCloser, err := backupStore.GetBackupContents(backupName)
 	if err != nil {
@@ -504,13 +676,20 @@ func downloadToTempFile(backupName string, backupStore persistence.BackupStore,
 	}
 	defer readCloser.Close()
 
+	checksums, err := backupStore.GetBackupChecksums(backupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting backup checksums")
+	}
+
 	file, err := ioutil.TempFile("", backupName)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating Backup temp file")
 	}
 
-	n, err := io.Copy(file, readCloser)
+	hash := persistence.NewChecksumHash()
+	n, err := io.Copy(io.MultiWriter(file, hash), readCloser)
 	if err != nil {
+		closeAndRemoveFile(file, logger)
 		return nil, errors.Wrap(err, "error copying Backup to temp file")
 	}
 
@@ -521,6 +700,14 @@ func downloadToTempFile(backupName string, backupStore persistence.BackupStore,
 		"bytes":    n,
 	}).Debug("Copied Backup to file")
 
+	if checksums == nil {
+		// backups taken before checksum manifests were introduced don't have one.
+		log.Info("Backup has no checksum manifest, skipping verification of its contents")
+	} else if err := checksums.VerifyContents(hash.Sum(nil)); err != nil {
+		closeAndRemoveFile(file, logger)
+		return nil, errors.Wrap(err, "error verifying Backup contents")
+	}
+
 	if _, err := file.Seek(0, 0); err != nil {
 		return nil, errors.Wrap(err, "error resetting Backup file offset")
 	}
@@ -18,9 +18,13 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,13 +33,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
@@ -114,12 +123,14 @@ func TestFetchBackupInfo(t *testing.T) {
 				logger,
 				logrus.InfoLevel,
 				func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+				nil,
+				nil,
 				"default",
 				metrics.NewServerMetrics(),
 				formatFlag,
 			).(*restoreController)
 
-			c.newBackupStore = func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+			c.newBackupStore = func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
 				return backupStore, nil
 			}
 
@@ -210,6 +221,8 @@ func TestProcessQueueItemSkips(t *testing.T) {
 				logger,
 				logrus.InfoLevel,
 				nil,
+				nil,
+				nil,
 				"default",
 				metrics.NewServerMetrics(),
 				formatFlag,
@@ -227,6 +240,7 @@ func TestProcessQueueItemSkips(t *testing.T) {
 }
 
 func TestProcessQueueItem(t *testing.T) {
+	timestamp := time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
 	defaultStorageLocation := builder.ForBackupStorageLocation("velero", "default").Provider("myCloud").Bucket("bucket").Result()
 
 	tests := []struct {
@@ -264,6 +278,24 @@ func TestProcessQueueItem(t *testing.T) {
 			expectedPhase:            string(api.RestorePhaseFailedValidation),
 			expectedValidationErrors: []string{"Invalid included/excluded resource lists: excludes list cannot contain an item in the includes list: a-resource"},
 		},
+		{
+			name:                     "restore with an invalid existing resource policy fails validation",
+			location:                 defaultStorageLocation,
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).ExistingResourcePolicy("overwrite").Result(),
+			backup:                   defaultBackup().StorageLocation("default").Result(),
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{"Invalid existing resource policy \"overwrite\", must be one of none, update or recreate"},
+		},
+		{
+			name:                     "restore with an invalid included item fails validation",
+			location:                 defaultStorageLocation,
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).IncludedItems("deployments.apps/ns-1/deploy-1", "deploy-2").Result(),
+			backup:                   defaultBackup().StorageLocation("default").Result(),
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{"Invalid included item: \"deploy-2\" must be in the form <resource>/<namespace>/<name> or <resource>/<name>"},
+		},
 		{
 			name:                     "new restore with empty backup and schedule names fails validation",
 			restore:                  NewRestore("foo", "bar", "", "ns-1", "", api.RestorePhaseNew).Result(),
@@ -285,7 +317,7 @@ func TestProcessQueueItem(t *testing.T) {
 			backup:               defaultBackup().StorageLocation("default").ObjectMeta(builder.WithLabels(api.ScheduleNameLabel, "sched-1")).Phase(api.BackupPhaseCompleted).Result(),
 			expectedErr:          false,
 			expectedPhase:        string(api.RestorePhaseInProgress),
-			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Schedule("sched-1").Result(),
+			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Schedule("sched-1").StartTimestamp(timestamp).Result(),
 		},
 		{
 			name:                            "restore with non-existent backup name fails",
@@ -305,7 +337,7 @@ func TestProcessQueueItem(t *testing.T) {
 			expectedPhase:         string(api.RestorePhaseInProgress),
 			expectedFinalPhase:    string(api.RestorePhasePartiallyFailed),
 			expectedRestoreErrors: 1,
-			expectedRestorerCall:  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Result(),
+			expectedRestorerCall:  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).StartTimestamp(timestamp).Result(),
 		},
 		{
 			name:                 "valid restore gets executed",
@@ -314,7 +346,7 @@ func TestProcessQueueItem(t *testing.T) {
 			backup:               defaultBackup().StorageLocation("default").Result(),
 			expectedErr:          false,
 			expectedPhase:        string(api.RestorePhaseInProgress),
-			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Result(),
+			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).StartTimestamp(timestamp).Result(),
 		},
 		{
 			name:          "restoration of nodes is not supported",
@@ -415,12 +447,15 @@ func TestProcessQueueItem(t *testing.T) {
 				logger,
 				logrus.InfoLevel,
 				func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+				nil,
+				nil,
 				"default",
 				metrics.NewServerMetrics(),
 				formatFlag,
 			).(*restoreController)
 
-			c.newBackupStore = func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+			c.clock = clock.NewFakeClock(timestamp)
+			c.newBackupStore = func(*api.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
 				return backupStore, nil
 			}
 
@@ -465,6 +500,14 @@ func TestProcessQueueItem(t *testing.T) {
 
 					res.Status.Phase = api.RestorePhase(phase)
 
+					if startTimestamp, found, _ := unstructured.NestedString(patchMap, "status", "startTimestamp"); found {
+						parsed, err := time.Parse(time.RFC3339, startTimestamp)
+						if err != nil {
+							return false, nil, err
+						}
+						res.Status.StartTimestamp.Time = parsed
+					}
+
 					backupName, found, err := unstructured.NestedString(patchMap, "spec", "backupName")
 					if found {
 						res.Spec.BackupName = backupName
@@ -486,7 +529,9 @@ func TestProcessQueueItem(t *testing.T) {
 				errors.Velero = append(errors.Velero, "error uploading log file to object storage: "+test.putRestoreLogErr.Error())
 			}
 			if test.expectedRestorerCall != nil {
+				backupStore.On("GetBackupIndex", test.backup.Name).Return(nil, nil)
 				backupStore.On("GetBackupContents", test.backup.Name).Return(ioutil.NopCloser(bytes.NewReader([]byte("hello world"))), nil)
+				backupStore.On("GetBackupChecksums", test.backup.Name).Return(nil, nil)
 
 				restorer.On("Restore", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(warnings, errors)
 
@@ -523,6 +568,7 @@ func TestProcessQueueItem(t *testing.T) {
 
 			if test.backupStoreGetBackupContentsErr != nil {
 				// TODO why do I need .Maybe() here?
+				backupStore.On("GetBackupIndex", test.restore.Spec.BackupName).Return(nil, nil).Maybe()
 				backupStore.On("GetBackupContents", test.restore.Spec.BackupName).Return(nil, test.backupStoreGetBackupContentsErr).Maybe()
 			}
 
@@ -547,9 +593,11 @@ func TestProcessQueueItem(t *testing.T) {
 			}
 
 			type StatusPatch struct {
-				Phase            api.RestorePhase `json:"phase"`
-				ValidationErrors []string         `json:"validationErrors"`
-				Errors           int              `json:"errors"`
+				Phase               api.RestorePhase `json:"phase"`
+				ValidationErrors    []string         `json:"validationErrors"`
+				Errors              int              `json:"errors"`
+				StartTimestamp      *metav1.Time     `json:"startTimestamp"`
+				CompletionTimestamp *metav1.Time     `json:"completionTimestamp"`
 			}
 
 			type Patch struct {
@@ -573,6 +621,9 @@ func TestProcessQueueItem(t *testing.T) {
 					ValidationErrors: test.expectedValidationErrors,
 				},
 			}
+			if test.expectedPhase == string(api.RestorePhaseInProgress) {
+				expected.Status.StartTimestamp = &metav1.Time{Time: timestamp}
+			}
 
 			if test.restore.Spec.ScheduleName != "" && test.backup != nil {
 				expected.Spec = SpecPatch{
@@ -594,16 +645,18 @@ func TestProcessQueueItem(t *testing.T) {
 
 			expected = Patch{
 				Status: StatusPatch{
-					Phase:  api.RestorePhaseCompleted,
-					Errors: test.expectedRestoreErrors,
+					Phase:               api.RestorePhaseCompleted,
+					Errors:              test.expectedRestoreErrors,
+					CompletionTimestamp: &metav1.Time{Time: timestamp},
 				},
 			}
 			// Override our default expectations if the case requires it
 			if test.expectedFinalPhase != "" {
 				expected = Patch{
 					Status: StatusPatch{
-						Phase:  api.RestorePhase(test.expectedFinalPhase),
-						Errors: test.expectedRestoreErrors,
+						Phase:               api.RestorePhase(test.expectedFinalPhase),
+						Errors:              test.expectedRestoreErrors,
+						CompletionTimestamp: &metav1.Time{Time: timestamp},
 					},
 				}
 			}
@@ -640,6 +693,8 @@ func TestvalidateAndCompleteWhenScheduleNameSpecified(t *testing.T) {
 		logger,
 		logrus.DebugLevel,
 		nil,
+		nil,
+		nil,
 		"default",
 		nil,
 		formatFlag,
@@ -711,6 +766,77 @@ func TestvalidateAndCompleteWhenScheduleNameSpecified(t *testing.T) {
 	assert.Equal(t, "bar", restore.Spec.BackupName)
 }
 
+func TestValidateAndCompleteResourceModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		configMap *corev1api.ConfigMap
+		wantErr   string
+	}{
+		{
+			name:    "missing ConfigMap fails validation",
+			wantErr: "Error getting resource modifiers ConfigMap modifiers",
+		},
+		{
+			name:      "invalid rules fail validation",
+			configMap: builder.ForConfigMap(api.DefaultNamespace, "modifiers").Data("rules.yaml", "version: v2").Result(),
+			wantErr:   "Invalid resource modifiers",
+		},
+		{
+			name:      "valid rules pass validation",
+			configMap: builder.ForConfigMap(api.DefaultNamespace, "modifiers").Data("rules.yaml", "version: v1").Result(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				kubeClient      = kubefake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+			)
+
+			if test.configMap != nil {
+				_, err := kubeClient.CoreV1().ConfigMaps(test.configMap.Namespace).Create(test.configMap)
+				require.NoError(t, err)
+			}
+
+			c := NewRestoreController(
+				api.DefaultNamespace,
+				sharedInformers.Velero().V1().Restores(),
+				client.VeleroV1(),
+				client.VeleroV1(),
+				nil,
+				sharedInformers.Velero().V1().Backups(),
+				sharedInformers.Velero().V1().BackupStorageLocations(),
+				sharedInformers.Velero().V1().VolumeSnapshotLocations(),
+				velerotest.NewLogger(),
+				logrus.DebugLevel,
+				nil,
+				nil,
+				kubeClient.CoreV1(),
+				"default",
+				nil,
+				logging.FormatText,
+			).(*restoreController)
+
+			restore := builder.ForRestore(api.DefaultNamespace, "restore-1").ResourceModifiers("modifiers").Result()
+
+			c.validateAndComplete(restore, &pluginmocks.Manager{})
+
+			var found bool
+			for _, err := range restore.Status.ValidationErrors {
+				if strings.Contains(err, "resource modifiers") {
+					found = true
+					if assert.NotEmpty(t, test.wantErr) {
+						assert.Contains(t, err, test.wantErr)
+					}
+				}
+			}
+			assert.Equal(t, test.wantErr != "", found)
+		})
+	}
+}
+
 func TestBackupXorScheduleProvided(t *testing.T) {
 	r := &api.Restore{}
 	assert.False(t, backupXorScheduleProvided(r))
@@ -800,6 +926,129 @@ func TestMostRecentCompletedBackup(t *testing.T) {
 	assert.Equal(t, expected, mostRecentCompletedBackup(backups))
 }
 
+func TestDownloadToTempFileVerifiesChecksums(t *testing.T) {
+	digest := sha256.Sum256([]byte("contents"))
+	checksums := &persistence.BackupChecksums{
+		Backup:    "backup-1",
+		Algorithm: persistence.ChecksumAlgorithmSHA256,
+		Files:     map[string]string{"backup-1.tar.gz": hex.EncodeToString(digest[:])},
+	}
+
+	tests := []struct {
+		name        string
+		contents    string
+		checksums   *persistence.BackupChecksums
+		expectedErr string
+	}{
+		{
+			name:      "contents matching the checksum manifest are downloaded",
+			contents:  "contents",
+			checksums: checksums,
+		},
+		{
+			name:     "backups without a checksum manifest are downloaded",
+			contents: "contents",
+		},
+		{
+			name:        "contents not matching the checksum manifest return an error",
+			contents:    "corrupted",
+			checksums:   checksums,
+			expectedErr: "error verifying Backup contents: checksum mismatch for backup-1.tar.gz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backupStore := new(persistencemocks.BackupStore)
+			backupStore.On("GetBackupContents", "backup-1").Return(ioutil.NopCloser(bytes.NewReader([]byte(test.contents))), nil)
+			backupStore.On("GetBackupChecksums", "backup-1").Return(test.checksums, nil)
+
+			file, err := downloadToTempFile("backup-1", backupStore, velerotest.NewLogger())
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer closeAndRemoveFile(file, velerotest.NewLogger())
+
+			data, err := ioutil.ReadAll(file)
+			require.NoError(t, err)
+			assert.Equal(t, test.contents, string(data))
+		})
+	}
+}
+
+func TestGetBackupContents(t *testing.T) {
+	index := &archive.Index{Files: []archive.File{{Name: "metadata/version", Size: 2}}}
+	source := strings.NewReader("tarball")
+
+	tests := []struct {
+		name           string
+		index          *archive.Index
+		source         io.ReaderAt
+		expectIndex    bool
+		expectSource   bool
+		expectDownload bool
+	}{
+		{
+			name:         "indexed backups are read from backup storage when ranged reads are supported",
+			index:        index,
+			source:       source,
+			expectIndex:  true,
+			expectSource: true,
+		},
+		{
+			name:           "indexed backups are downloaded when ranged reads aren't supported",
+			index:          index,
+			expectIndex:    true,
+			expectDownload: true,
+		},
+		{
+			name:           "backups without an index are downloaded",
+			expectDownload: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backupStore := new(persistencemocks.BackupStore)
+			backupStore.On("GetBackupIndex", "backup-1").Return(test.index, nil)
+			backupStore.On("GetBackupContentsReaderAt", "backup-1").Return(test.source, nil).Maybe()
+			backupStore.On("GetBackupContents", "backup-1").Return(ioutil.NopCloser(strings.NewReader("tarball")), nil).Maybe()
+			backupStore.On("GetBackupChecksums", "backup-1").Return(nil, nil).Maybe()
+
+			contents, file, err := getBackupContents("backup-1", backupStore, velerotest.NewLogger())
+			require.NoError(t, err)
+			if file != nil {
+				defer closeAndRemoveFile(file, velerotest.NewLogger())
+			}
+
+			if test.expectIndex {
+				assert.Equal(t, test.index, contents.Index)
+			} else {
+				assert.Nil(t, contents.Index)
+			}
+
+			if test.expectSource {
+				assert.Equal(t, test.source, contents.Source)
+			}
+
+			if test.expectDownload {
+				require.NotNil(t, file)
+				if test.expectIndex {
+					assert.Equal(t, file, contents.Source)
+				} else {
+					assert.Equal(t, file, contents.Reader)
+				}
+			} else {
+				assert.Nil(t, file)
+				backupStore.AssertNotCalled(t, "GetBackupContents", "backup-1")
+			}
+		})
+	}
+}
+
 func NewRestore(ns, name, backup, includeNS, includeResource string, phase api.RestorePhase) *builder.RestoreBuilder {
 	restore := builder.ForRestore(ns, name).Phase(phase).Backup(backup)
 
@@ -822,18 +1071,19 @@ type fakeRestorer struct {
 }
 
 func (r *fakeRestorer) Restore(
+	ctx context.Context,
 	log logrus.FieldLogger,
 	restore *api.Restore,
 	backup *api.Backup,
 	volumeSnapshots []*volume.Snapshot,
-	backupReader io.Reader,
+	backupContents pkgrestore.BackupContents,
 	actions []velero.RestoreItemAction,
 	snapshotLocationLister listers.VolumeSnapshotLocationLister,
 	volumeSnapshotterGetter pkgrestore.VolumeSnapshotterGetter,
-) (pkgrestore.Result, pkgrestore.Result) {
-	res := r.Called(log, restore, backup, backupReader, actions)
+) (pkgrestore.Result, pkgrestore.Result, *pkgrestore.DryRunReport) {
+	res := r.Called(log, restore, backup, backupContents, actions)
 
 	r.calledWithArg = *restore
 
-	return res.Get(0).(pkgrestore.Result), res.Get(1).(pkgrestore.Result)
+	return res.Get(0).(pkgrestore.Result), res.Get(1).(pkgrestore.Result), nil
 }
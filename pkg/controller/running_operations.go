@@ -0,0 +1,67 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+)
+
+// runningOperations keeps track of the backups or restores that are being
+// run by this server, so that they can be canceled.
+type runningOperations struct {
+	lock    sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newRunningOperations() *runningOperations {
+	return &runningOperations{
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// start records that the operation with the given key is running, and returns
+// a context that's canceled if the operation is, and a func that must be
+// called once the operation has finished.
+func (o *runningOperations) start(key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	o.lock.Lock()
+	o.cancels[key] = cancel
+	o.lock.Unlock()
+
+	return ctx, func() {
+		o.lock.Lock()
+		delete(o.cancels, key)
+		o.lock.Unlock()
+
+		cancel()
+	}
+}
+
+// cancel cancels the operation with the given key, returning false if it
+// isn't running.
+func (o *runningOperations) cancel(key string) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	cancel, ok := o.cancels[key]
+	if ok {
+		cancel()
+	}
+	return ok
+}
@@ -171,6 +171,7 @@ func (c *scheduleController) processSchedule(key string) error {
 	currentPhase := schedule.Status.Phase
 
 	cronSchedule, errs := parseCronSchedule(schedule, c.logger)
+	errs = append(errs, validateRetentionPolicy(schedule.Spec.Retention)...)
 	if len(errs) > 0 {
 		schedule.Status.Phase = api.SchedulePhaseFailedValidation
 		schedule.Status.ValidationErrors = errs
@@ -178,8 +179,10 @@ func (c *scheduleController) processSchedule(key string) error {
 		schedule.Status.Phase = api.SchedulePhaseEnabled
 	}
 
+	pauseStatusChanged := updatePauseStatus(schedule, c.clock.Now())
+
 	// update status if it's changed
-	if currentPhase != schedule.Status.Phase {
+	if currentPhase != schedule.Status.Phase || pauseStatusChanged {
 		updatedSchedule, err := patchSchedule(original, schedule, c.schedulesClient)
 		if err != nil {
 			return errors.Wrapf(err, "error updating Schedule phase to %s", schedule.Status.Phase)
@@ -199,6 +202,54 @@ func (c *scheduleController) processSchedule(key string) error {
 	return nil
 }
 
+// updatePauseStatus records when the schedule was paused if it was paused
+// without the time being recorded, e.g. by editing it directly rather than with
+// the CLI, and clears the record once it's unpaused. It returns true if the
+// schedule's status was changed.
+func updatePauseStatus(schedule *api.Schedule, now time.Time) bool {
+	switch {
+	case schedule.Spec.Paused && schedule.Status.PausedAt == nil:
+		pausedAt := metav1.NewTime(now)
+		schedule.Status.PausedAt = &pausedAt
+		return true
+	case !schedule.Spec.Paused && (schedule.Status.PausedAt != nil || schedule.Status.PausedBy != ""):
+		schedule.Status.PausedAt = nil
+		schedule.Status.PausedBy = ""
+		return true
+	default:
+		return false
+	}
+}
+
+func validateRetentionPolicy(policy *api.ScheduleRetentionPolicy) []string {
+	if policy == nil {
+		return nil
+	}
+
+	counts := []struct {
+		name string
+		val  int
+	}{
+		{"keepLast", policy.KeepLast},
+		{"keepDaily", policy.KeepDaily},
+		{"keepWeekly", policy.KeepWeekly},
+		{"keepMonthly", policy.KeepMonthly},
+	}
+
+	var errs []string
+	for _, count := range counts {
+		if count.val < 0 {
+			errs = append(errs, fmt.Sprintf("retention.%s must not be negative", count.name))
+		}
+	}
+
+	if len(errs) == 0 && *policy == (api.ScheduleRetentionPolicy{}) {
+		errs = append(errs, "retention must keep at least one backup")
+	}
+
+	return errs
+}
+
 func parseCronSchedule(itm *api.Schedule, logger logrus.FieldLogger) (cron.Schedule, []string) {
 	var validationErrors []string
 	var schedule cron.Schedule
@@ -246,6 +297,11 @@ func (c *scheduleController) submitBackupIfDue(item *api.Schedule, cronSchedule
 		log                = c.logger.WithField("schedule", kubeutil.NamespaceAndName(item))
 	)
 
+	if item.Spec.Paused {
+		log.Debug("Schedule is paused, skipping")
+		return nil
+	}
+
 	if !isDue {
 		log.WithField("nextRunTime", nextRunTime).Debug("Schedule is not due, skipping")
 		return nil
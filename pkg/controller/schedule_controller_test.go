@@ -91,6 +91,20 @@ func TestProcessSchedule(t *testing.T) {
 			expectedPhase:            string(velerov1api.SchedulePhaseFailedValidation),
 			expectedValidationErrors: []string{"Schedule must be a non-empty valid Cron expression"},
 		},
+		{
+			name:                     "schedule with an invalid retention policy gets validated and failed",
+			schedule:                 newScheduleBuilder(velerov1api.SchedulePhaseNew).CronSchedule("@every 5m").Retention(velerov1api.ScheduleRetentionPolicy{KeepLast: -1, KeepMonthly: -2}).Result(),
+			expectedErr:              false,
+			expectedPhase:            string(velerov1api.SchedulePhaseFailedValidation),
+			expectedValidationErrors: []string{"retention.keepLast must not be negative", "retention.keepMonthly must not be negative"},
+		},
+		{
+			name:                     "schedule with an empty retention policy gets validated and failed",
+			schedule:                 newScheduleBuilder(velerov1api.SchedulePhaseNew).CronSchedule("@every 5m").Retention(velerov1api.ScheduleRetentionPolicy{}).Result(),
+			expectedErr:              false,
+			expectedPhase:            string(velerov1api.SchedulePhaseFailedValidation),
+			expectedValidationErrors: []string{"retention must keep at least one backup"},
+		},
 		{
 			name:                 "schedule with phase New gets validated and triggers a backup",
 			schedule:             newScheduleBuilder(velerov1api.SchedulePhaseNew).CronSchedule("@every 5m").Result(),
@@ -254,6 +268,104 @@ func TestProcessSchedule(t *testing.T) {
 	}
 }
 
+func TestProcessSchedulePaused(t *testing.T) {
+	now := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		schedule         *velerov1api.Schedule
+		expectPatch      bool
+		expectedPausedAt *time.Time
+	}{
+		{
+			name:             "paused schedule without a paused time gets it recorded and no backup",
+			schedule:         builder.ForSchedule("ns", "name").Phase(velerov1api.SchedulePhaseEnabled).CronSchedule("@every 5m").Paused(true).Result(),
+			expectPatch:      true,
+			expectedPausedAt: &now,
+		},
+		{
+			name:             "paused schedule with a paused time is left alone and no backup",
+			schedule:         builder.ForSchedule("ns", "name").Phase(velerov1api.SchedulePhaseEnabled).CronSchedule("@every 5m").Paused(true).PausedAt(now.Add(-time.Hour)).PausedBy("user").Result(),
+			expectPatch:      false,
+			expectedPausedAt: nil,
+		},
+		{
+			name:             "unpaused schedule gets its paused status cleared",
+			schedule:         builder.ForSchedule("ns", "name").Phase(velerov1api.SchedulePhaseEnabled).CronSchedule("@every 5m").PausedAt(now.Add(-time.Hour)).PausedBy("user").Result(),
+			expectPatch:      true,
+			expectedPausedAt: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset(test.schedule)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+			)
+
+			c := NewScheduleController(
+				"namespace",
+				client.VeleroV1(),
+				client.VeleroV1(),
+				sharedInformers.Velero().V1().Schedules(),
+				velerotest.NewLogger(),
+				metrics.NewServerMetrics(),
+			)
+			c.clock = clock.NewFakeClock(now)
+
+			sharedInformers.Velero().V1().Schedules().Informer().GetStore().Add(test.schedule)
+
+			require.NoError(t, c.processSchedule("ns/name"))
+
+			var (
+				backupsCreated int
+				statusPatches  []map[string]interface{}
+			)
+			for _, action := range client.Actions() {
+				switch {
+				case action.Matches("create", "backups"):
+					backupsCreated++
+				case action.Matches("patch", "schedules"):
+					patch := make(map[string]interface{})
+					require.NoError(t, json.Unmarshal(action.(core.PatchAction).GetPatch(), &patch))
+					if _, found, _ := unstructured.NestedFieldNoCopy(patch, "status", "lastBackup"); !found {
+						statusPatches = append(statusPatches, patch)
+					}
+				}
+			}
+
+			if test.schedule.Spec.Paused {
+				assert.Zero(t, backupsCreated)
+			} else {
+				assert.Equal(t, 1, backupsCreated)
+			}
+
+			if !test.expectPatch {
+				assert.Empty(t, statusPatches)
+				return
+			}
+			require.Len(t, statusPatches, 1)
+
+			pausedAt, found, err := unstructured.NestedFieldNoCopy(statusPatches[0], "status", "pausedAt")
+			require.NoError(t, err)
+			require.True(t, found)
+
+			if test.expectedPausedAt != nil {
+				parsed, err := time.Parse(time.RFC3339, pausedAt.(string))
+				require.NoError(t, err)
+				assert.True(t, test.expectedPausedAt.Equal(parsed))
+			} else {
+				// merge patches remove fields by setting them to null
+				assert.Nil(t, pausedAt)
+				pausedBy, found, _ := unstructured.NestedFieldNoCopy(statusPatches[0], "status", "pausedBy")
+				assert.True(t, found)
+				assert.Nil(t, pausedBy)
+			}
+		})
+	}
+}
+
 func parseTime(timeString string) time.Time {
 	res, _ := time.Parse("2006-01-02 15:04:05", timeString)
 	return res
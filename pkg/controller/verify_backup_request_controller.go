@@ -0,0 +1,337 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
+	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
+	"github.com/heptio/velero/pkg/persistence"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	"github.com/heptio/velero/pkg/restic"
+	"github.com/heptio/velero/pkg/util/kube"
+)
+
+// verifyBackupRequestTTL is how long a processed VerifyBackupRequest is kept
+// before it's deleted.
+const verifyBackupRequestTTL = time.Hour
+
+type verifyBackupRequestController struct {
+	*genericController
+
+	verifyBackupRequestClient velerov1client.VerifyBackupRequestsGetter
+	verifyBackupRequestLister listers.VerifyBackupRequestLister
+	backupLister              listers.BackupLister
+	backupLocationLister      listers.BackupStorageLocationLister
+	resticMgr                 restic.RepositoryManager
+	clock                     clock.Clock
+	newPluginManager          func(logrus.FieldLogger) clientmgmt.Manager
+	secretsGetter             corev1client.SecretsGetter
+	newBackupStore            func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error)
+}
+
+// NewVerifyBackupRequestController creates a new VerifyBackupRequestController.
+func NewVerifyBackupRequestController(
+	logger logrus.FieldLogger,
+	verifyBackupRequestInformer informers.VerifyBackupRequestInformer,
+	verifyBackupRequestClient velerov1client.VerifyBackupRequestsGetter,
+	backupInformer informers.BackupInformer,
+	backupLocationInformer informers.BackupStorageLocationInformer,
+	resticMgr restic.RepositoryManager,
+	newPluginManager func(logrus.FieldLogger) clientmgmt.Manager,
+	secretsGetter corev1client.SecretsGetter,
+) Interface {
+	c := &verifyBackupRequestController{
+		genericController:         newGenericController("verify-backup-request", logger),
+		verifyBackupRequestClient: verifyBackupRequestClient,
+		verifyBackupRequestLister: verifyBackupRequestInformer.Lister(),
+		backupLister:              backupInformer.Lister(),
+		backupLocationLister:      backupLocationInformer.Lister(),
+		resticMgr:                 resticMgr,
+
+		// use variables to refer to these functions so they can be
+		// replaced with fakes for testing.
+		newPluginManager: newPluginManager,
+		secretsGetter:    secretsGetter,
+		newBackupStore:   persistence.NewObjectBackupStore,
+
+		clock: &clock.RealClock{},
+	}
+
+	c.syncHandler = c.processQueueItem
+	c.cacheSyncWaiters = append(
+		c.cacheSyncWaiters,
+		verifyBackupRequestInformer.Informer().HasSynced,
+		backupInformer.Informer().HasSynced,
+		backupLocationInformer.Informer().HasSynced,
+	)
+
+	verifyBackupRequestInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueue,
+		},
+	)
+
+	c.resyncPeriod = verifyBackupRequestTTL
+	c.resyncFunc = c.enqueueAllItems
+
+	return c
+}
+
+func (c *verifyBackupRequestController) processQueueItem(key string) error {
+	log := c.logger.WithField("key", key)
+	log.Debug("Running processQueueItem")
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	req, err := c.verifyBackupRequestLister.VerifyBackupRequests(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		log.Debug("Unable to find VerifyBackupRequest")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting VerifyBackupRequest")
+	}
+
+	switch req.Status.Phase {
+	case "", v1.VerifyBackupRequestPhaseNew:
+		// Don't mutate the shared cache
+		return c.processRequest(req.DeepCopy())
+	case v1.VerifyBackupRequestPhaseProcessed:
+		return c.deleteIfExpired(req)
+	}
+
+	return nil
+}
+
+func (c *verifyBackupRequestController) processRequest(req *v1.VerifyBackupRequest) error {
+	log := c.logger.WithFields(logrus.Fields{
+		"verifyBackupRequest": kube.NamespaceAndName(req),
+		"backup":              req.Spec.BackupName,
+	})
+
+	if req.Spec.BackupName == "" {
+		return c.completeRequest(req, nil, nil, []string{"spec.backupName is required"})
+	}
+
+	backup, err := c.backupLister.Backups(req.Namespace).Get(req.Spec.BackupName)
+	if apierrors.IsNotFound(err) {
+		return c.completeRequest(req, nil, nil, []string{"backup not found"})
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting backup")
+	}
+
+	if backup.Status.Phase != v1.BackupPhaseCompleted && backup.Status.Phase != v1.BackupPhasePartiallyFailed {
+		return c.completeRequest(req, nil, nil, []string{fmt.Sprintf("backup can't be verified because its phase is %s", backup.Status.Phase)})
+	}
+
+	location, err := c.backupLocationLister.BackupStorageLocations(backup.Namespace).Get(backup.Spec.StorageLocation)
+	if apierrors.IsNotFound(err) {
+		return c.completeRequest(req, nil, nil, []string{fmt.Sprintf("backup storage location %s not found", backup.Spec.StorageLocation)})
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting backup storage location")
+	}
+
+	req, err = c.patchVerifyBackupRequest(req, func(r *v1.VerifyBackupRequest) {
+		r.Status.Phase = v1.VerifyBackupRequestPhaseInProgress
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Verifying backup")
+
+	pluginManager := c.newPluginManager(log)
+	defer pluginManager.CleanupClients()
+
+	backupStore, err := c.newBackupStore(location, pluginManager, c.secretsGetter, log)
+	if err != nil {
+		return c.completeRequest(req, nil, nil, []string{err.Error()})
+	}
+
+	var errs []string
+
+	verifiedFiles, err := backupStore.VerifyBackup(backup.Name)
+	errs = append(errs, errorMessages(err)...)
+
+	verifiedSnapshots, err := c.verifyResticSnapshots(backup, backupStore)
+	errs = append(errs, errorMessages(err)...)
+
+	log.WithField("errors", len(errs)).Info("Backup verification completed")
+
+	return c.completeRequest(req, verifiedFiles, verifiedSnapshots, errs)
+}
+
+// verifyResticSnapshots checks that each of the backup's restic snapshots
+// exists in its repository, and returns the IDs of the ones that do.
+func (c *verifyBackupRequestController) verifyResticSnapshots(backup *v1.Backup, backupStore persistence.BackupStore) ([]string, error) {
+	podVolumeBackups, err := backupStore.GetPodVolumeBackups(backup.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting backup's pod volume backups")
+	}
+
+	var snapshots []restic.SnapshotIdentifier
+	for _, pvb := range podVolumeBackups {
+		if pvb.Status.SnapshotID == "" {
+			continue
+		}
+
+		snapshots = append(snapshots, restic.SnapshotIdentifier{
+			VolumeNamespace:       pvb.Spec.Pod.Namespace,
+			BackupStorageLocation: backup.Spec.StorageLocation,
+			SnapshotID:            pvb.Status.SnapshotID,
+		})
+	}
+
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	if c.resticMgr == nil {
+		return nil, errors.New("backup has restic snapshots, but restic isn't enabled on the Velero server so they can't be verified")
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), resticTimeout)
+	defer cancelFunc()
+
+	var (
+		verified []string
+		errs     []error
+	)
+	for _, snapshot := range snapshots {
+		exists, err := c.resticMgr.SnapshotExists(ctx, snapshot)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error checking restic snapshot %s", snapshot.SnapshotID))
+			continue
+		}
+		if !exists {
+			errs = append(errs, errors.Errorf("restic snapshot %s for namespace %s is missing from its repository", snapshot.SnapshotID, snapshot.VolumeNamespace))
+			continue
+		}
+
+		verified = append(verified, snapshot.SnapshotID)
+	}
+
+	return verified, kubeerrs.NewAggregate(errs)
+}
+
+// errorMessages returns the messages of each of the errors in err, if it's an
+// aggregate, or err's message otherwise.
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	agg, ok := err.(kubeerrs.Aggregate)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	var res []string
+	for _, err := range agg.Errors() {
+		res = append(res, err.Error())
+	}
+	return res
+}
+
+func (c *verifyBackupRequestController) completeRequest(req *v1.VerifyBackupRequest, verifiedFiles, verifiedSnapshots, errs []string) error {
+	_, err := c.patchVerifyBackupRequest(req, func(r *v1.VerifyBackupRequest) {
+		r.Status.Phase = v1.VerifyBackupRequestPhaseProcessed
+		r.Status.ProcessedTimestamp.Time = c.clock.Now()
+		r.Status.VerifiedFiles = verifiedFiles
+		r.Status.VerifiedResticSnapshots = verifiedSnapshots
+		r.Status.Errors = errs
+	})
+	return err
+}
+
+// deleteIfExpired deletes req if it was processed more than
+// verifyBackupRequestTTL ago.
+func (c *verifyBackupRequestController) deleteIfExpired(req *v1.VerifyBackupRequest) error {
+	if req.Status.ProcessedTimestamp.Add(verifyBackupRequestTTL).After(c.clock.Now()) {
+		return nil
+	}
+
+	c.logger.WithField("verifyBackupRequest", kube.NamespaceAndName(req)).Debug("VerifyBackupRequest has expired, deleting it")
+	err := c.verifyBackupRequestClient.VerifyBackupRequests(req.Namespace).Delete(req.Name, nil)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (c *verifyBackupRequestController) enqueueAllItems() {
+	items, err := c.verifyBackupRequestLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error listing VerifyBackupRequests")
+		return
+	}
+
+	for _, req := range items {
+		c.enqueue(req)
+	}
+}
+
+func (c *verifyBackupRequestController) patchVerifyBackupRequest(req *v1.VerifyBackupRequest, mutate func(*v1.VerifyBackupRequest)) (*v1.VerifyBackupRequest, error) {
+	// Record original json
+	oldData, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshalling original VerifyBackupRequest")
+	}
+
+	// Mutate
+	mutate(req)
+
+	// Record new json
+	newData, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshalling updated VerifyBackupRequest")
+	}
+
+	patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating json merge patch for VerifyBackupRequest")
+	}
+
+	req, err = c.verifyBackupRequestClient.VerifyBackupRequests(req.Namespace).Patch(req.Name, types.MergePatchType, patchBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "error patching VerifyBackupRequest")
+	}
+
+	return req, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubeerrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	v1 "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
+	"github.com/heptio/velero/pkg/persistence"
+	persistencemocks "github.com/heptio/velero/pkg/persistence/mocks"
+	"github.com/heptio/velero/pkg/plugin/clientmgmt"
+	pluginmocks "github.com/heptio/velero/pkg/plugin/mocks"
+	"github.com/heptio/velero/pkg/restic"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+// fakeResticSnapshotChecker is a restic.RepositoryManager whose repositories
+// contain only the given snapshots.
+type fakeResticSnapshotChecker struct {
+	restic.RepositoryManager
+	snapshots sets.String
+}
+
+func (f *fakeResticSnapshotChecker) SnapshotExists(_ context.Context, snapshot restic.SnapshotIdentifier) (bool, error) {
+	return f.snapshots.Has(snapshot.SnapshotID), nil
+}
+
+func TestProcessVerifyBackupRequest(t *testing.T) {
+	clockTime, err := time.Parse(time.RFC1123, time.RFC1123)
+	require.NoError(t, err)
+
+	completedBackup := builder.ForBackup(v1.DefaultNamespace, "backup-1").StorageLocation("location-1").Phase(v1.BackupPhaseCompleted).Result()
+
+	podVolumeBackup := func(namespace, snapshotID string) *v1.PodVolumeBackup {
+		pvb := builder.ForPodVolumeBackup(v1.DefaultNamespace, "pvb-"+snapshotID).Result()
+		pvb.Spec.Pod = corev1api.ObjectReference{Namespace: namespace, Name: "pod-1"}
+		pvb.Status.SnapshotID = snapshotID
+		return pvb
+	}
+
+	tests := []struct {
+		name                      string
+		req                       *v1.VerifyBackupRequest
+		backup                    *v1.Backup
+		resticSnapshots           sets.String
+		podVolumeBackups          []*v1.PodVolumeBackup
+		verifyBackupErr           error
+		expectedVerifiedFiles     []string
+		expectedVerifiedSnapshots []string
+		expectedErrors            []string
+		expectDeleted             bool
+	}{
+		{
+			name:           "request without a backup name is processed with an error",
+			req:            builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").Result(),
+			expectedErrors: []string{"spec.backupName is required"},
+		},
+		{
+			name:           "request for a missing backup is processed with an error",
+			req:            builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").BackupName("backup-1").Result(),
+			expectedErrors: []string{"backup not found"},
+		},
+		{
+			name:           "in-progress backups can't be verified",
+			req:            builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").BackupName("backup-1").Result(),
+			backup:         builder.ForBackup(v1.DefaultNamespace, "backup-1").StorageLocation("location-1").Phase(v1.BackupPhaseInProgress).Result(),
+			expectedErrors: []string{"backup can't be verified because its phase is InProgress"},
+		},
+		{
+			name:                      "backup whose files and restic snapshots are intact has no errors",
+			req:                       builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").BackupName("backup-1").Result(),
+			backup:                    completedBackup,
+			resticSnapshots:           sets.NewString("snap-1", "snap-2"),
+			podVolumeBackups:          []*v1.PodVolumeBackup{podVolumeBackup("ns-1", "snap-1"), podVolumeBackup("ns-2", "snap-2")},
+			expectedVerifiedFiles:     []string{"backup-1.tar.gz", "velero-backup.json"},
+			expectedVerifiedSnapshots: []string{"snap-1", "snap-2"},
+		},
+		{
+			name:                      "corrupted files and missing restic snapshots are reported",
+			req:                       builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").BackupName("backup-1").Result(),
+			backup:                    completedBackup,
+			resticSnapshots:           sets.NewString("snap-1"),
+			podVolumeBackups:          []*v1.PodVolumeBackup{podVolumeBackup("ns-1", "snap-1"), podVolumeBackup("ns-2", "snap-2")},
+			verifyBackupErr:           kubeerrs.NewAggregate([]error{errors.New("checksum mismatch for backup-1.tar.gz")}),
+			expectedVerifiedFiles:     []string{"velero-backup.json"},
+			expectedVerifiedSnapshots: []string{"snap-1"},
+			expectedErrors: []string{
+				"checksum mismatch for backup-1.tar.gz",
+				"restic snapshot snap-2 for namespace ns-2 is missing from its repository",
+			},
+		},
+		{
+			name:          "expired processed request is deleted",
+			req:           builder.ForVerifyBackupRequest(v1.DefaultNamespace, "req-1").BackupName("backup-1").Phase(v1.VerifyBackupRequestPhaseProcessed).ProcessedTimestamp(clockTime.Add(-2 * verifyBackupRequestTTL)).Result(),
+			expectDeleted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset(test.req)
+				informerFactory = informers.NewSharedInformerFactory(client, 0)
+				pluginManager   = new(pluginmocks.Manager)
+				backupStore     = new(persistencemocks.BackupStore)
+			)
+
+			c := NewVerifyBackupRequestController(
+				velerotest.NewLogger(),
+				informerFactory.Velero().V1().VerifyBackupRequests(),
+				client.VeleroV1(),
+				informerFactory.Velero().V1().Backups(),
+				informerFactory.Velero().V1().BackupStorageLocations(),
+				&fakeResticSnapshotChecker{snapshots: test.resticSnapshots},
+				func(logrus.FieldLogger) clientmgmt.Manager { return pluginManager },
+				nil,
+			).(*verifyBackupRequestController)
+			c.clock = clock.NewFakeClock(clockTime)
+			c.newBackupStore = func(*v1.BackupStorageLocation, persistence.ObjectStoreGetter, corev1client.SecretsGetter, logrus.FieldLogger) (persistence.BackupStore, error) {
+				return backupStore, nil
+			}
+
+			pluginManager.On("CleanupClients").Return()
+			backupStore.On("VerifyBackup", "backup-1").Return(test.expectedVerifiedFiles, test.verifyBackupErr)
+			backupStore.On("GetPodVolumeBackups", "backup-1").Return(test.podVolumeBackups, nil)
+
+			require.NoError(t, informerFactory.Velero().V1().VerifyBackupRequests().Informer().GetStore().Add(test.req))
+			require.NoError(t, informerFactory.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(
+				builder.ForBackupStorageLocation(v1.DefaultNamespace, "location-1").Result(),
+			))
+			if test.backup != nil {
+				require.NoError(t, informerFactory.Velero().V1().Backups().Informer().GetStore().Add(test.backup))
+			}
+
+			require.NoError(t, c.processQueueItem(test.req.Namespace+"/"+test.req.Name))
+
+			res, err := client.VeleroV1().VerifyBackupRequests(test.req.Namespace).Get(test.req.Name, metav1.GetOptions{})
+			if test.expectDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, v1.VerifyBackupRequestPhaseProcessed, res.Status.Phase)
+			assert.True(t, clockTime.Equal(res.Status.ProcessedTimestamp.Time))
+			assert.Equal(t, test.expectedVerifiedFiles, res.Status.VerifiedFiles)
+			assert.Equal(t, test.expectedVerifiedSnapshots, res.Status.VerifiedResticSnapshots)
+			assert.Equal(t, test.expectedErrors, res.Status.Errors)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/heptio/velero/pkg/cloudprovider"
+	"github.com/heptio/velero/pkg/util/filesystem"
+)
+
+// DefaultStoreDirectory is the directory the Velero and restic servers keep
+// location credential files in.
+var DefaultStoreDirectory = filepath.Join(os.TempDir(), "credentials")
+
+// FileStore makes the contents of Secret keys available as files on local
+// disk, so they can be handed to plugins and restic, which read credentials
+// from files.
+type FileStore interface {
+	// Path returns the path of a file holding the contents of the Secret key
+	// selected by selector in namespace, writing the file if it doesn't exist
+	// or is out of date.
+	Path(namespace string, selector *corev1api.SecretKeySelector) (string, error)
+}
+
+type fileStore struct {
+	secretsGetter corev1client.SecretsGetter
+	fsRoot        string
+	fs            filesystem.Interface
+}
+
+// NewFileStore returns a FileStore that reads Secrets using secretsGetter
+// and writes their contents to files under fsRoot.
+func NewFileStore(secretsGetter corev1client.SecretsGetter, fsRoot string, fs filesystem.Interface) FileStore {
+	return &fileStore{
+		secretsGetter: secretsGetter,
+		fsRoot:        fsRoot,
+		fs:            fs,
+	}
+}
+
+func (s *fileStore) Path(namespace string, selector *corev1api.SecretKeySelector) (string, error) {
+	if s.secretsGetter == nil {
+		return "", errors.New("unable to get credential secret: no secrets client")
+	}
+
+	secret, err := s.secretsGetter.Secrets(namespace).Get(selector.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting credential secret %s", selector.Name)
+	}
+
+	data, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", errors.Errorf("credential secret %s has no key %q", selector.Name, selector.Key)
+	}
+
+	dir := filepath.Join(s.fsRoot, namespace)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s", selector.Name, selector.Key))
+
+	// plugins may be reading the file, so only rewrite it when the
+	// secret has changed, and replace it in one step by renaming a complete
+	// temp file over it so it's never seen partially written.
+	if existing, err := s.fs.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return path, nil
+	}
+
+	if err := s.fs.MkdirAll(dir, 0700); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	file, err := s.fs.TempFile(dir, filepath.Base(path)+"-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		// nothing we can do about errors cleaning up the temp file here,
+		// and we're already returning an error about the write failing.
+		file.Close()
+		s.fs.RemoveAll(file.Name())
+		return "", errors.WithStack(err)
+	}
+
+	if err := file.Close(); err != nil {
+		s.fs.RemoveAll(file.Name())
+		return "", errors.WithStack(err)
+	}
+
+	if err := s.fs.Rename(file.Name(), path); err != nil {
+		s.fs.RemoveAll(file.Name())
+		return "", errors.WithStack(err)
+	}
+
+	return path, nil
+}
+
+// ConfigWithCredentialsFile returns a copy of config that also holds the path
+// of the file with credential's contents under cloudprovider.CredentialsFileKey,
+// for initializing a location's ObjectStore or VolumeSnapshotter. If credential
+// is nil, config is returned as-is.
+func ConfigWithCredentialsFile(store FileStore, namespace string, config map[string]string, credential *corev1api.SecretKeySelector) (map[string]string, error) {
+	if credential == nil {
+		return config, nil
+	}
+
+	if store == nil {
+		return nil, errors.New("unable to get credentials file: no credential file store")
+	}
+
+	path, err := store.Path(namespace, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string, len(config)+1)
+	for k, v := range config {
+		res[k] = v
+	}
+	res[cloudprovider.CredentialsFileKey] = path
+
+	return res, nil
+}
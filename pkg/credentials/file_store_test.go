@@ -0,0 +1,145 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/cloudprovider"
+	"github.com/heptio/velero/pkg/util/filesystem"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func newSelector(name, key string) *corev1api.SecretKeySelector {
+	return &corev1api.SecretKeySelector{
+		LocalObjectReference: corev1api.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+}
+
+func TestFileStorePath(t *testing.T) {
+	var (
+		client = fake.NewSimpleClientset()
+		fs     = velerotest.NewFakeFileSystem()
+		store  = NewFileStore(client.CoreV1(), "/credentials", fs)
+		secret = builder.ForSecret("velero", "creds").Data(map[string][]byte{"cloud": []byte("account-1")}).Result()
+	)
+
+	// secret doesn't exist: expect an error
+	_, err := store.Path("velero", newSelector("creds", "cloud"))
+	assert.Error(t, err)
+
+	_, err = client.CoreV1().Secrets("velero").Create(secret)
+	require.NoError(t, err)
+
+	// key doesn't exist: expect an error
+	_, err = store.Path("velero", newSelector("creds", "missing"))
+	assert.EqualError(t, err, `credential secret creds has no key "missing"`)
+
+	// secret and key exist: expect a file with the key's contents
+	path, err := store.Path("velero", newSelector("creds", "cloud"))
+	require.NoError(t, err)
+	assert.Equal(t, "/credentials/velero/creds-cloud", path)
+
+	contents, err := fs.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "account-1", string(contents))
+
+	// secret is updated: expect the file to be rewritten
+	secret.Data["cloud"] = []byte("account-2")
+	_, err = client.CoreV1().Secrets("velero").Update(secret)
+	require.NoError(t, err)
+
+	path, err = store.Path("velero", newSelector("creds", "cloud"))
+	require.NoError(t, err)
+
+	contents, err = fs.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "account-2", string(contents))
+
+	// the file is replaced by renaming a temp file over it, which isn't left behind
+	files, err := fs.ReadDir("/credentials/velero")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "creds-cloud", files[0].Name())
+}
+
+// tempFileCountingFileSystem counts the temp files created through it.
+type tempFileCountingFileSystem struct {
+	*velerotest.FakeFileSystem
+	tempFiles int
+}
+
+func (fs *tempFileCountingFileSystem) TempFile(dir, prefix string) (filesystem.NameWriteCloser, error) {
+	fs.tempFiles++
+	return fs.FakeFileSystem.TempFile(dir, prefix)
+}
+
+func TestFileStorePathOnlyWritesChangedContents(t *testing.T) {
+	var (
+		secret = builder.ForSecret("velero", "creds").Data(map[string][]byte{"cloud": []byte("account-1")}).Result()
+		client = fake.NewSimpleClientset(secret)
+		fs     = &tempFileCountingFileSystem{FakeFileSystem: velerotest.NewFakeFileSystem()}
+		store  = NewFileStore(client.CoreV1(), "/credentials", fs)
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := store.Path("velero", newSelector("creds", "cloud"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, fs.tempFiles)
+
+	secret.Data["cloud"] = []byte("account-2")
+	_, err := client.CoreV1().Secrets("velero").Update(secret)
+	require.NoError(t, err)
+
+	_, err = store.Path("velero", newSelector("creds", "cloud"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, fs.tempFiles)
+}
+
+type fakeFileStore map[string]string
+
+func (s fakeFileStore) Path(namespace string, selector *corev1api.SecretKeySelector) (string, error) {
+	return s[namespace+"/"+selector.Name+"/"+selector.Key], nil
+}
+
+func TestConfigWithCredentialsFile(t *testing.T) {
+	store := fakeFileStore{"velero/creds/cloud": "/credentials/velero/creds-cloud"}
+	config := map[string]string{"region": "us-east-1"}
+
+	// no credential: config is returned as-is
+	res, err := ConfigWithCredentialsFile(store, "velero", config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config, res)
+
+	// credential: path is added to a copy of config
+	res, err = ConfigWithCredentialsFile(store, "velero", config, newSelector("creds", "cloud"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "us-east-1", cloudprovider.CredentialsFileKey: "/credentials/velero/creds-cloud"}, res)
+	assert.Equal(t, map[string]string{"region": "us-east-1"}, config)
+
+	// credential but no store: expect an error
+	_, err = ConfigWithCredentialsFile(nil, "velero", config, newSelector("creds", "cloud"))
+	assert.Error(t, err)
+}
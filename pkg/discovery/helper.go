@@ -18,6 +18,7 @@ package discovery
 
 import (
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -38,6 +39,12 @@ type Helper interface {
 	// that are backuppable by Velero.
 	Resources() []*metav1.APIResourceList
 
+	// ServedResources gets the current set of resources retrieved from
+	// discovery that are backuppable by Velero, in every version served
+	// for their group rather than only the preferred one. Within a group,
+	// versions are in the server's order of preference.
+	ServedResources() []*metav1.APIResourceList
+
 	// ResourceFor gets a fully-resolved GroupVersionResource and an
 	// APIResource for the provided partially-specified GroupVersionResource.
 	ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, metav1.APIResource, error)
@@ -59,12 +66,13 @@ type helper struct {
 	discoveryClient discovery.DiscoveryInterface
 	logger          logrus.FieldLogger
 
-	// lock guards mapper, resources and resourcesMap
-	lock         sync.RWMutex
-	mapper       meta.RESTMapper
-	resources    []*metav1.APIResourceList
-	resourcesMap map[schema.GroupVersionResource]metav1.APIResource
-	apiGroups    []metav1.APIGroup
+	// lock guards mapper, resources, servedResources and resourcesMap
+	lock            sync.RWMutex
+	mapper          meta.RESTMapper
+	resources       []*metav1.APIResourceList
+	servedResources []*metav1.APIResourceList
+	resourcesMap    map[schema.GroupVersionResource]metav1.APIResource
+	apiGroups       []metav1.APIGroup
 }
 
 var _ Helper = &helper{}
@@ -118,6 +126,24 @@ func (h *helper) Refresh() error {
 
 	sortResources(h.resources)
 
+	h.servedResources = nil
+	for _, group := range groupResources {
+		for _, version := range group.Group.Versions {
+			h.servedResources = append(h.servedResources, &metav1.APIResourceList{
+				GroupVersion: version.GroupVersion,
+				APIResources: group.VersionedResources[version.Version],
+			})
+		}
+	}
+	h.servedResources = discovery.FilteredBy(
+		discovery.ResourcePredicateFunc(func(groupVersion string, r *metav1.APIResource) bool {
+			// skip subresources, which aren't included in the preferred
+			// resources either.
+			return !strings.Contains(r.Name, "/") && filterByVerbs(groupVersion, r)
+		}),
+		h.servedResources,
+	)
+
 	shortcutExpander, err := kcmdutil.NewShortcutExpander(restmapper.NewDiscoveryRESTMapper(groupResources), h.resources, h.logger)
 	if err != nil {
 		return errors.WithStack(err)
@@ -195,6 +221,12 @@ func (h *helper) Resources() []*metav1.APIResourceList {
 	return h.resources
 }
 
+func (h *helper) ServedResources() []*metav1.APIResourceList {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.servedResources
+}
+
 func (h *helper) APIGroups() []metav1.APIGroup {
 	h.lock.RLock()
 	defer h.lock.RUnlock()
@@ -38,4 +38,6 @@ type ScheduleExpansion interface{}
 
 type ServerStatusRequestExpansion interface{}
 
+type VerifyBackupRequestExpansion interface{}
+
 type VolumeSnapshotLocationExpansion interface{}
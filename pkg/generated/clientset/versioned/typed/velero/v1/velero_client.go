@@ -37,6 +37,7 @@ type VeleroV1Interface interface {
 	RestoresGetter
 	SchedulesGetter
 	ServerStatusRequestsGetter
+	VerifyBackupRequestsGetter
 	VolumeSnapshotLocationsGetter
 }
 
@@ -85,6 +86,10 @@ func (c *VeleroV1Client) ServerStatusRequests(namespace string) ServerStatusRequ
 	return newServerStatusRequests(c, namespace)
 }
 
+func (c *VeleroV1Client) VerifyBackupRequests(namespace string) VerifyBackupRequestInterface {
+	return newVerifyBackupRequests(c, namespace)
+}
+
 func (c *VeleroV1Client) VolumeSnapshotLocations(namespace string) VolumeSnapshotLocationInterface {
 	return newVolumeSnapshotLocations(c, namespace)
 }
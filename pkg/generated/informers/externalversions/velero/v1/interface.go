@@ -44,6 +44,8 @@ type Interface interface {
 	Schedules() ScheduleInformer
 	// ServerStatusRequests returns a ServerStatusRequestInformer.
 	ServerStatusRequests() ServerStatusRequestInformer
+	// VerifyBackupRequests returns a VerifyBackupRequestInformer.
+	VerifyBackupRequests() VerifyBackupRequestInformer
 	// VolumeSnapshotLocations returns a VolumeSnapshotLocationInformer.
 	VolumeSnapshotLocations() VolumeSnapshotLocationInformer
 }
@@ -109,6 +111,11 @@ func (v *version) ServerStatusRequests() ServerStatusRequestInformer {
 	return &serverStatusRequestInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// VerifyBackupRequests returns a VerifyBackupRequestInformer.
+func (v *version) VerifyBackupRequests() VerifyBackupRequestInformer {
+	return &verifyBackupRequestInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VolumeSnapshotLocations returns a VolumeSnapshotLocationInformer.
 func (v *version) VolumeSnapshotLocations() VolumeSnapshotLocationInformer {
 	return &volumeSnapshotLocationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
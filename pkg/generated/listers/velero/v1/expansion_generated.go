@@ -98,6 +98,14 @@ type ServerStatusRequestListerExpansion interface{}
 // ServerStatusRequestNamespaceLister.
 type ServerStatusRequestNamespaceListerExpansion interface{}
 
+// VerifyBackupRequestListerExpansion allows custom methods to be added to
+// VerifyBackupRequestLister.
+type VerifyBackupRequestListerExpansion interface{}
+
+// VerifyBackupRequestNamespaceListerExpansion allows custom methods to be added to
+// VerifyBackupRequestNamespaceLister.
+type VerifyBackupRequestNamespaceListerExpansion interface{}
+
 // VolumeSnapshotLocationListerExpansion allows custom methods to be added to
 // VolumeSnapshotLocationLister.
 type VolumeSnapshotLocationListerExpansion interface{}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ChecksumAlgorithmSHA256 is the algorithm used for the digests in a
+// backup's checksum manifest.
+const ChecksumAlgorithmSHA256 = "sha256"
+
+// BackupChecksums is a manifest of the digests of a backup's files, which is
+// stored alongside them in backup storage. Digests are of each file's contents
+// as written by Velero, i.e. before any encryption.
+type BackupChecksums struct {
+	// Backup is the name of the backup.
+	Backup string `json:"backup"`
+
+	// Algorithm is the hash algorithm used for the digests.
+	Algorithm string `json:"algorithm"`
+
+	// Files maps the name of each of the backup's files, relative to the
+	// backup's directory, to the hex-encoded digest of its contents.
+	Files map[string]string `json:"files"`
+}
+
+func newBackupChecksums(backup string) *BackupChecksums {
+	return &BackupChecksums{
+		Backup:    backup,
+		Algorithm: ChecksumAlgorithmSHA256,
+		Files:     make(map[string]string),
+	}
+}
+
+// NewChecksumHash returns a hash that computes digests for the algorithm
+// used in checksum manifests.
+func NewChecksumHash() hash.Hash {
+	return sha256.New()
+}
+
+func backupContentsFileName(backup string) string {
+	return fmt.Sprintf("%s.tar.gz", backup)
+}
+
+// Verify returns an error if the file isn't in the manifest, or if its
+// recorded digest doesn't match digest.
+func (c *BackupChecksums) Verify(file string, digest []byte) error {
+	if c.Algorithm != ChecksumAlgorithmSHA256 {
+		return errors.Errorf("checksum manifest has unsupported algorithm %q", c.Algorithm)
+	}
+
+	expected, ok := c.Files[file]
+	if !ok {
+		return errors.Errorf("%s is not in the backup's checksum manifest", file)
+	}
+
+	if actual := hex.EncodeToString(digest); actual != expected {
+		return errors.Errorf("checksum mismatch for %s: expected %s %s, got %s", file, c.Algorithm, expected, actual)
+	}
+
+	return nil
+}
+
+// VerifyContents returns an error if digest doesn't match the recorded
+// digest of the backup's contents tarball.
+func (c *BackupChecksums) VerifyContents(digest []byte) error {
+	return c.Verify(backupContentsFileName(c.Backup), digest)
+}
+
+// digestReader computes the digest of the data read through it.
+type digestReader struct {
+	io.Reader
+	hash hash.Hash
+}
+
+func newDigestReader(r io.Reader) *digestReader {
+	h := NewChecksumHash()
+	return &digestReader{Reader: io.TeeReader(r, h), hash: h}
+}
+
+func (r *digestReader) digest() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
@@ -0,0 +1,355 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// Encrypted objects are made up of:
+//
+//   - encryptionMagic
+//   - the object's data key, encrypted with the location's key using AES-GCM
+//     and prefixed with the nonce used to encrypt it
+//   - a sequence of chunks, each of which is a flags byte, the big-endian uint32
+//     length of the chunk's ciphertext, and up to encryptionChunkSize bytes of
+//     the object encrypted with the data key using AES-GCM
+//
+// Each chunk's nonce is derived from its index and whether it's the last chunk
+// of the object, so chunks can't be reordered, and the object can't be
+// truncated, without decryption failing.
+const (
+	encryptionKeySize   = 32
+	encryptionChunkSize = 64 * 1024
+	encryptionFinalFlag = byte(1)
+	gcmNonceSize        = 12
+	gcmTagSize          = 16
+	wrappedDataKeySize  = gcmNonceSize + encryptionKeySize + gcmTagSize
+	chunkHeaderSize     = 5
+)
+
+var encryptionMagic = []byte("velero.io/encrypted/v1\n")
+
+// GetEncryptionKey returns the key that the location's objects are encrypted
+// with, or nil if the location doesn't use encryption. The key is read from
+// the Secret referenced by the location, which must be in the location's
+// namespace, and must be 32 bytes long, either raw or base64-encoded.
+func GetEncryptionKey(location *velerov1api.BackupStorageLocation, secretsGetter corev1client.SecretsGetter) ([]byte, error) {
+	if location.Spec.Encryption == nil {
+		return nil, nil
+	}
+
+	if secretsGetter == nil {
+		return nil, errors.New("unable to get encryption key secret: no secrets client")
+	}
+
+	ref := location.Spec.Encryption.KeySecret
+	secret, err := secretsGetter.Secrets(location.Namespace).Get(ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting encryption key secret %s", ref.Name)
+	}
+
+	return encryptionKeyFromSecret(secret, ref.Key)
+}
+
+func encryptionKeyFromSecret(secret *corev1api.Secret, key string) ([]byte, error) {
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, errors.Errorf("encryption key secret %s has no key %q", secret.Name, key)
+	}
+
+	if len(data) == encryptionKeySize {
+		return data, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err == nil && len(decoded) == encryptionKeySize {
+		return decoded, nil
+	}
+
+	return nil, errors.Errorf("encryption key in secret %s must be %d bytes long, either raw or base64-encoded", secret.Name, encryptionKeySize)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return gcm, nil
+}
+
+func chunkNonce(index uint64, flags byte) []byte {
+	nonce := make([]byte, gcmNonceSize)
+	nonce[0] = flags
+	binary.BigEndian.PutUint64(nonce[4:], index)
+	return nonce
+}
+
+// encryptingReader reads an object's plaintext from an underlying reader and
+// returns its encrypted form.
+type encryptingReader struct {
+	plaintext io.Reader
+	gcm       cipher.AEAD
+	buf       []byte
+	out       bytes.Buffer
+	index     uint64
+	done      bool
+}
+
+// newEncryptingReader returns a reader that encrypts the data read from
+// plaintext with a new random data key, which is itself encrypted with key.
+func newEncryptingReader(key []byte, plaintext io.Reader) (io.Reader, error) {
+	keyGCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, encryptionKeySize)
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Wrap(err, "error generating data key")
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "error generating nonce")
+	}
+
+	dataGCM, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &encryptingReader{
+		plaintext: plaintext,
+		gcm:       dataGCM,
+		buf:       make([]byte, encryptionChunkSize),
+	}
+	r.out.Write(encryptionMagic)
+	r.out.Write(nonce)
+	r.out.Write(keyGCM.Seal(nil, nonce, dataKey, encryptionMagic))
+
+	return r, nil
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.encryptChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.out.Read(p)
+}
+
+func (r *encryptingReader) encryptChunk() error {
+	n, err := io.ReadFull(r.plaintext, r.buf)
+	var flags byte
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		// a short or empty chunk is always the last one
+		flags = encryptionFinalFlag
+		r.done = true
+	default:
+		return errors.WithStack(err)
+	}
+
+	ciphertext := r.gcm.Seal(nil, chunkNonce(r.index, flags), r.buf[:n], nil)
+	r.index++
+
+	header := make([]byte, chunkHeaderSize)
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(ciphertext)))
+	r.out.Write(header)
+	r.out.Write(ciphertext)
+
+	return nil
+}
+
+// isEncrypted returns true if the data in r starts with encryptionMagic. It
+// doesn't consume any of r's data.
+func isEncrypted(r *bufio.Reader) (bool, error) {
+	prefix, err := r.Peek(len(encryptionMagic))
+	if err != nil && err != io.EOF {
+		return false, errors.WithStack(err)
+	}
+
+	return bytes.Equal(prefix, encryptionMagic), nil
+}
+
+// readDataKey reads the header of an encrypted object from r and returns its
+// data key, decrypted with key.
+func readDataKey(key []byte, r io.Reader) ([]byte, error) {
+	header := make([]byte, len(encryptionMagic)+wrappedDataKeySize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "error reading encrypted object header")
+	}
+	wrapped := header[len(encryptionMagic):]
+
+	if len(key) == 0 {
+		return nil, errors.New("object is encrypted but no encryption key is configured for its backup storage location")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := gcm.Open(nil, wrapped[:gcmNonceSize], wrapped[gcmNonceSize:], encryptionMagic)
+	if err != nil {
+		return nil, errors.New("unable to decrypt object's data key; is the backup storage location's encryption key correct?")
+	}
+
+	return dataKey, nil
+}
+
+// decryptingReader reads an encrypted object's chunks from an underlying
+// reader, after its header has been read, and returns its plaintext.
+type decryptingReader struct {
+	ciphertext io.Reader
+	gcm        cipher.AEAD
+	buf        []byte
+	out        []byte
+	index      uint64
+	done       bool
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.decryptChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptingReader) decryptChunk() error {
+	header := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(r.ciphertext, header); err != nil {
+		if err == io.EOF {
+			return errors.New("encrypted object is truncated")
+		}
+		return errors.Wrap(err, "error reading encrypted object")
+	}
+
+	flags := header[0]
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > encryptionChunkSize+gcmTagSize {
+		return errors.Errorf("encrypted object has an invalid chunk length %d", length)
+	}
+
+	if _, err := io.ReadFull(r.ciphertext, r.buf[:length]); err != nil {
+		return errors.Wrap(err, "error reading encrypted object")
+	}
+
+	plaintext, err := r.gcm.Open(r.buf[:0], chunkNonce(r.index, flags), r.buf[:length], nil)
+	if err != nil {
+		return errors.New("unable to decrypt object; it has been modified or corrupted")
+	}
+	r.index++
+
+	if flags&encryptionFinalFlag != 0 {
+		r.done = true
+	}
+	r.out = plaintext
+
+	return nil
+}
+
+func newDecryptingReaderWithDataKey(dataKey []byte, ciphertext io.Reader) (io.Reader, error) {
+	return newChunkDecryptingReader(dataKey, ciphertext, 0)
+}
+
+// newChunkDecryptingReader returns a reader that decrypts an object's chunks
+// read from ciphertext, starting with the chunk at the given index.
+func newChunkDecryptingReader(dataKey []byte, ciphertext io.Reader, index uint64) (*decryptingReader, error) {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingReader{
+		ciphertext: ciphertext,
+		gcm:        gcm,
+		buf:        make([]byte, encryptionChunkSize+gcmTagSize),
+		index:      index,
+	}, nil
+}
+
+// checkUnencrypted returns an error if an object that isn't encrypted must not
+// be read from a location with the given key.
+func checkUnencrypted(key []byte, allowUnencrypted bool) error {
+	if len(key) > 0 && !allowUnencrypted {
+		return errors.New("object isn't encrypted but its backup storage location uses encryption; set the location's encryption.allowUnencryptedObjects to read objects stored before encryption was enabled")
+	}
+
+	return nil
+}
+
+// newDecryptingReader returns a reader that decrypts the object read from r
+// using key. Objects that aren't encrypted are returned as-is if key is empty
+// or allowUnencrypted is true, and rejected otherwise.
+func newDecryptingReader(key []byte, allowUnencrypted bool, r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	encrypted, err := isEncrypted(buffered)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypted {
+		if err := checkUnencrypted(key, allowUnencrypted); err != nil {
+			return nil, err
+		}
+		return buffered, nil
+	}
+
+	dataKey, err := readDataKey(key, buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDecryptingReaderWithDataKey(dataKey, buffered)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistence
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+)
+
+var testEncryptionKey = bytes.Repeat([]byte("k"), encryptionKeySize)
+
+func encrypt(t *testing.T, key, plaintext []byte) []byte {
+	r, err := newEncryptingReader(key, bytes.NewReader(plaintext))
+	require.NoError(t, err)
+
+	ciphertext, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return ciphertext
+}
+
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	r, err := newDecryptingReader(key, false, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(r)
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3*encryptionChunkSize + 17} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		ciphertext := encrypt(t, testEncryptionKey, plaintext)
+		assert.True(t, bytes.HasPrefix(ciphertext, encryptionMagic))
+		// very short plaintexts can appear in the ciphertext by chance
+		if size > encryptionKeySize {
+			assert.False(t, bytes.Contains(ciphertext, plaintext), "size %d", size)
+		}
+
+		res, err := decrypt(testEncryptionKey, ciphertext)
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, plaintext, res, "size %d", size)
+	}
+}
+
+func TestEncryptionUsesNewDataKeyPerObject(t *testing.T) {
+	plaintext := []byte("some data")
+	assert.NotEqual(t, encrypt(t, testEncryptionKey, plaintext), encrypt(t, testEncryptionKey, plaintext))
+}
+
+func TestDecryptionFailures(t *testing.T) {
+	plaintext := make([]byte, 2*encryptionChunkSize+10)
+	rand.Read(plaintext)
+	ciphertext := encrypt(t, testEncryptionKey, plaintext)
+	headerSize := len(encryptionMagic) + wrappedDataKeySize
+	firstChunkSize := chunkHeaderSize + encryptionChunkSize + gcmTagSize
+
+	tests := []struct {
+		name       string
+		key        []byte
+		ciphertext func() []byte
+	}{
+		{
+			name:       "wrong key",
+			key:        bytes.Repeat([]byte("x"), encryptionKeySize),
+			ciphertext: func() []byte { return ciphertext },
+		},
+		{
+			name:       "no key",
+			ciphertext: func() []byte { return ciphertext },
+		},
+		{
+			name: "modified chunk",
+			key:  testEncryptionKey,
+			ciphertext: func() []byte {
+				modified := append([]byte(nil), ciphertext...)
+				modified[headerSize+chunkHeaderSize+100] ^= 1
+				return modified
+			},
+		},
+		{
+			name: "truncated after a chunk",
+			key:  testEncryptionKey,
+			ciphertext: func() []byte {
+				return ciphertext[:headerSize+firstChunkSize]
+			},
+		},
+		{
+			name: "non-final chunk marked as final",
+			key:  testEncryptionKey,
+			ciphertext: func() []byte {
+				modified := append([]byte(nil), ciphertext[:headerSize+firstChunkSize]...)
+				modified[headerSize] = encryptionFinalFlag
+				return modified
+			},
+		},
+		{
+			name: "chunks reordered",
+			key:  testEncryptionKey,
+			ciphertext: func() []byte {
+				first := ciphertext[headerSize : headerSize+firstChunkSize]
+				second := ciphertext[headerSize+firstChunkSize : headerSize+2*firstChunkSize]
+				rest := ciphertext[headerSize+2*firstChunkSize:]
+
+				var reordered []byte
+				reordered = append(reordered, ciphertext[:headerSize]...)
+				reordered = append(reordered, second...)
+				reordered = append(reordered, first...)
+				return append(reordered, rest...)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decrypt(tc.key, tc.ciphertext())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecryptingUnencryptedObjects(t *testing.T) {
+	for _, plaintext := range [][]byte{nil, []byte("a"), []byte(`{"kind":"Backup"}`)} {
+		// unencrypted objects are rejected when there's a key...
+		_, err := decrypt(testEncryptionKey, plaintext)
+		assert.Error(t, err)
+
+		// ...unless they're explicitly allowed
+		r, err := newDecryptingReader(testEncryptionKey, true, bytes.NewReader(plaintext))
+		require.NoError(t, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, string(plaintext), string(res))
+
+		res, err = decrypt(nil, plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, string(plaintext), string(res))
+	}
+}
+
+func TestGetEncryptionKey(t *testing.T) {
+	secret := func(data []byte) *corev1api.Secret {
+		return &corev1api.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: velerov1api.DefaultNamespace, Name: "encryption"},
+			Data:       map[string][]byte{"key": data},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		location *velerov1api.BackupStorageLocation
+		secret   *corev1api.Secret
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "location without encryption has no key",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").Result(),
+		},
+		{
+			name:     "raw key is used as-is",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").EncryptionKeySecret("encryption", "key").Result(),
+			secret:   secret(testEncryptionKey),
+			want:     testEncryptionKey,
+		},
+		{
+			name:     "base64-encoded key is decoded",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").EncryptionKeySecret("encryption", "key").Result(),
+			secret:   secret([]byte(base64.StdEncoding.EncodeToString(testEncryptionKey) + "\n")),
+			want:     testEncryptionKey,
+		},
+		{
+			name:     "key with the wrong length is an error",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").EncryptionKeySecret("encryption", "key").Result(),
+			secret:   secret([]byte("too-short")),
+			wantErr:  true,
+		},
+		{
+			name:     "missing secret key is an error",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").EncryptionKeySecret("encryption", "other-key").Result(),
+			secret:   secret(testEncryptionKey),
+			wantErr:  true,
+		},
+		{
+			name:     "missing secret is an error",
+			location: builder.ForBackupStorageLocation(velerov1api.DefaultNamespace, "loc-1").EncryptionKeySecret("encryption", "key").Result(),
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tc.secret != nil {
+				client = fake.NewSimpleClientset(tc.secret)
+			}
+
+			key, err := GetEncryptionKey(tc.location, client.CoreV1())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, key)
+		})
+	}
+}
@@ -2,6 +2,7 @@
 
 package mocks
 
+import archive "github.com/heptio/velero/pkg/archive"
 import io "io"
 import mock "github.com/stretchr/testify/mock"
 import persistence "github.com/heptio/velero/pkg/persistence"
@@ -62,6 +63,29 @@ func (_m *BackupStore) DeleteRestore(name string) error {
 	return r0
 }
 
+// GetBackupChecksums provides a mock function with given fields: name
+func (_m *BackupStore) GetBackupChecksums(name string) (*persistence.BackupChecksums, error) {
+	ret := _m.Called(name)
+
+	var r0 *persistence.BackupChecksums
+	if rf, ok := ret.Get(0).(func(string) *persistence.BackupChecksums); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*persistence.BackupChecksums)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBackupContents provides a mock function with given fields: name
 func (_m *BackupStore) GetBackupContents(name string) (io.ReadCloser, error) {
 	ret := _m.Called(name)
@@ -85,6 +109,52 @@ func (_m *BackupStore) GetBackupContents(name string) (io.ReadCloser, error) {
 	return r0, r1
 }
 
+// GetBackupContentsReaderAt provides a mock function with given fields: name
+func (_m *BackupStore) GetBackupContentsReaderAt(name string) (io.ReaderAt, error) {
+	ret := _m.Called(name)
+
+	var r0 io.ReaderAt
+	if rf, ok := ret.Get(0).(func(string) io.ReaderAt); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReaderAt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBackupIndex provides a mock function with given fields: name
+func (_m *BackupStore) GetBackupIndex(name string) (*archive.Index, error) {
+	ret := _m.Called(name)
+
+	var r0 *archive.Index
+	if rf, ok := ret.Get(0).(func(string) *archive.Index); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*archive.Index)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBackupMetadata provides a mock function with given fields: name
 func (_m *BackupStore) GetBackupMetadata(name string) (*v1.Backup, error) {
 	ret := _m.Called(name)
@@ -131,6 +201,29 @@ func (_m *BackupStore) GetBackupVolumeSnapshots(name string) ([]*volume.Snapshot
 	return r0, r1
 }
 
+// GetDownload provides a mock function with given fields: target
+func (_m *BackupStore) GetDownload(target v1.DownloadTarget) (io.ReadCloser, error) {
+	ret := _m.Called(target)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(v1.DownloadTarget) io.ReadCloser); ok {
+		r0 = rf(target)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(v1.DownloadTarget) error); ok {
+		r1 = rf(target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDownloadURL provides a mock function with given fields: target
 func (_m *BackupStore) GetDownloadURL(target v1.DownloadTarget) (string, error) {
 	ret := _m.Called(target)
@@ -247,6 +340,27 @@ func (_m *BackupStore) PutBackup(info persistence.BackupInfo) error {
 	return r0
 }
 
+// PutBackupContents provides a mock function with given fields: name, contents
+func (_m *BackupStore) PutBackupContents(name string, contents io.Reader) (string, error) {
+	ret := _m.Called(name, contents)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, io.Reader) string); ok {
+		r0 = rf(name, contents)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, io.Reader) error); ok {
+		r1 = rf(name, contents)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PutRestoreLog provides a mock function with given fields: backup, restore, log
 func (_m *BackupStore) PutRestoreLog(backup string, restore string, log io.Reader) error {
 	ret := _m.Called(backup, restore, log)
@@ -274,3 +388,26 @@ func (_m *BackupStore) PutRestoreResults(backup string, restore string, results
 
 	return r0
 }
+
+// VerifyBackup provides a mock function with given fields: name
+func (_m *BackupStore) VerifyBackup(name string) ([]string, error) {
+	ret := _m.Called(name)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistence
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+
+	"github.com/heptio/velero/pkg/plugin/velero"
+)
+
+// objectReaderAt is an io.ReaderAt that reads parts of an object with ranged
+// gets, so that the whole object doesn't need to be downloaded. Encrypted
+// objects are decrypted a chunk at a time.
+type objectReaderAt struct {
+	rangeGetter velero.ObjectRangeGetter
+	bucket      string
+	key         string
+	// dataKey is the key the object's data is encrypted with, or nil if the
+	// object isn't encrypted.
+	dataKey []byte
+}
+
+// newObjectReaderAt reads the start of the object to find out whether it's
+// encrypted, and if so, to get its data key. Unencrypted objects are rejected
+// if encryptionKey is set, unless allowUnencrypted is true. It returns
+// velero.ErrObjectRangeNotSupported if the object store doesn't support
+// ranged reads.
+func newObjectReaderAt(rangeGetter velero.ObjectRangeGetter, bucket, key string, encryptionKey []byte, allowUnencrypted bool) (*objectReaderAt, error) {
+	r := &objectReaderAt{
+		rangeGetter: rangeGetter,
+		bucket:      bucket,
+		key:         key,
+	}
+
+	header, err := r.getRange(0, encryptedObjectHeaderSize())
+	if err != nil {
+		return nil, err
+	}
+	defer header.Close()
+
+	buffered := bufio.NewReader(header)
+	encrypted, err := isEncrypted(buffered)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypted {
+		if err := checkUnencrypted(encryptionKey, allowUnencrypted); err != nil {
+			return nil, errors.Wrapf(err, "error reading object %s", key)
+		}
+		return r, nil
+	}
+
+	if r.dataKey, err = readDataKey(encryptionKey, buffered); err != nil {
+		return nil, errors.Wrapf(err, "error reading object %s", key)
+	}
+
+	return r, nil
+}
+
+func encryptedObjectHeaderSize() int64 {
+	return int64(len(encryptionMagic) + wrappedDataKeySize)
+}
+
+func (r *objectReaderAt) getRange(offset, length int64) (io.ReadCloser, error) {
+	res, err := r.rangeGetter.GetObjectRange(r.bucket, r.key, offset, length)
+	if err == velero.ErrObjectRangeNotSupported {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting range of object %s", r.key)
+	}
+
+	return res, nil
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	if r.dataKey == nil {
+		res, err := r.getRange(off, int64(len(p)))
+		if err != nil {
+			return 0, err
+		}
+		defer res.Close()
+
+		return readFull(res, p)
+	}
+
+	// each chunk of an encrypted object holds encryptionChunkSize bytes of
+	// plaintext, apart from the last one, so get all of the chunks that the
+	// range overlaps and decrypt them.
+	chunkSize := int64(chunkHeaderSize + encryptionChunkSize + gcmTagSize)
+	first := off / encryptionChunkSize
+	last := (off + int64(len(p)) - 1) / encryptionChunkSize
+
+	res, err := r.getRange(encryptedObjectHeaderSize()+first*chunkSize, (last-first+1)*chunkSize)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Close()
+
+	decrypted, err := newChunkDecryptingReader(r.dataKey, res, uint64(first))
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, decrypted, off-first*encryptionChunkSize); err != nil {
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		return 0, errors.Wrapf(err, "error reading object %s", r.key)
+	}
+
+	return readFull(decrypted, p)
+}
+
+// readFull reads len(p) bytes from r into p, returning io.EOF if there were
+// fewer, as io.ReaderAt requires.
+func readFull(r io.Reader, p []byte) (int, error) {
+	n, err := io.ReadFull(r, p)
+	switch err {
+	case nil:
+		return n, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, io.EOF
+	default:
+		return n, errors.WithStack(err)
+	}
+}
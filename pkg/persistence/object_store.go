@@ -17,10 +17,13 @@ limitations under the License.
 package persistence
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,10 +31,14 @@ import (
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
+	"github.com/heptio/velero/pkg/credentials"
 	"github.com/heptio/velero/pkg/generated/clientset/versioned/scheme"
 	"github.com/heptio/velero/pkg/plugin/velero"
+	"github.com/heptio/velero/pkg/util/filesystem"
 	"github.com/heptio/velero/pkg/volume"
 )
 
@@ -43,6 +50,14 @@ type BackupInfo struct {
 	PodVolumeBackups,
 	VolumeSnapshots,
 	BackupResourceList io.Reader
+
+	// ContentsDigest is the digest of a tarball that was already uploaded
+	// with PutBackupContents. It's only used when Contents is nil.
+	ContentsDigest string
+
+	// TarballIndex is the gzipped JSON index of the files in the backup's
+	// tarball, which lets it be restored without extracting it.
+	TarballIndex io.Reader
 }
 
 // BackupStore defines operations for creating, retrieving, and deleting
@@ -54,10 +69,30 @@ type BackupStore interface {
 	ListBackups() ([]string, error)
 
 	PutBackup(info BackupInfo) error
+	// PutBackupContents uploads a backup's tarball as it's read from
+	// contents, without needing to stage it anywhere first, and returns
+	// the digest of the data. If reading contents or uploading fails, the
+	// partially-uploaded tarball is removed.
+	PutBackupContents(name string, contents io.Reader) (string, error)
 	GetBackupMetadata(name string) (*velerov1api.Backup, error)
 	GetBackupVolumeSnapshots(name string) ([]*volume.Snapshot, error)
 	GetPodVolumeBackups(name string) ([]*velerov1api.PodVolumeBackup, error)
 	GetBackupContents(name string) (io.ReadCloser, error)
+	// GetBackupIndex returns the index of the files in the backup's
+	// tarball, or nil if it doesn't have one.
+	GetBackupIndex(name string) (*archive.Index, error)
+	// GetBackupContentsReaderAt returns an io.ReaderAt that reads parts of
+	// the backup's tarball on demand, or nil if the object store doesn't
+	// support ranged reads.
+	GetBackupContentsReaderAt(name string) (io.ReaderAt, error)
+	// GetBackupChecksums returns the backup's checksum manifest, or nil if
+	// it doesn't have one.
+	GetBackupChecksums(name string) (*BackupChecksums, error)
+	// VerifyBackup checks each of the files in the backup's checksum manifest
+	// against its recorded digest. It returns the names of the files that
+	// were verified, and an error describing any that are missing or don't
+	// match.
+	VerifyBackup(name string) ([]string, error)
 
 	// BackupExists checks if the backup metadata file exists in object storage.
 	BackupExists(bucket, backupName string) (bool, error)
@@ -69,16 +104,22 @@ type BackupStore interface {
 	DeleteRestore(name string) error
 
 	GetDownloadURL(target velerov1api.DownloadTarget) (string, error)
+	// GetDownload returns the target's file, decrypted if it's encrypted.
+	GetDownload(target velerov1api.DownloadTarget) (io.ReadCloser, error)
 }
 
 // DownloadURLTTL is how long a download URL is valid for.
 const DownloadURLTTL = 10 * time.Minute
 
 type objectBackupStore struct {
-	objectStore velero.ObjectStore
-	bucket      string
-	layout      *ObjectStoreLayout
-	logger      logrus.FieldLogger
+	objectStore   velero.ObjectStore
+	bucket        string
+	layout        *ObjectStoreLayout
+	encryptionKey []byte
+	// allowUnencrypted is whether unencrypted objects can be read from a
+	// store that has an encryption key.
+	allowUnencrypted bool
+	logger           logrus.FieldLogger
 }
 
 // ObjectStoreGetter is a type that can get a velero.ObjectStore
@@ -87,7 +128,10 @@ type ObjectStoreGetter interface {
 	GetObjectStore(provider string) (velero.ObjectStore, error)
 }
 
-func NewObjectBackupStore(location *velerov1api.BackupStorageLocation, objectStoreGetter ObjectStoreGetter, logger logrus.FieldLogger) (BackupStore, error) {
+// NewObjectBackupStore returns a BackupStore for the location. The secrets
+// client is used to get the location's encryption key and credential, if it
+// has them.
+func NewObjectBackupStore(location *velerov1api.BackupStorageLocation, objectStoreGetter ObjectStoreGetter, secretsGetter corev1client.SecretsGetter, logger logrus.FieldLogger) (BackupStore, error) {
 	if location.Spec.ObjectStorage == nil {
 		return nil, errors.New("backup storage location does not use object storage")
 	}
@@ -117,12 +161,23 @@ func NewObjectBackupStore(location *velerov1api.BackupStorageLocation, objectSto
 		location.Spec.Config["bucket"] = bucket
 	}
 
+	encryptionKey, err := GetEncryptionKey(location, secretsGetter)
+	if err != nil {
+		return nil, err
+	}
+
 	objectStore, err := objectStoreGetter.GetObjectStore(location.Spec.Provider)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := objectStore.Init(location.Spec.Config); err != nil {
+	credentialFileStore := credentials.NewFileStore(secretsGetter, credentials.DefaultStoreDirectory, filesystem.NewFileSystem())
+	config, err := credentials.ConfigWithCredentialsFile(credentialFileStore, location.Namespace, location.Spec.Config, location.Spec.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := objectStore.Init(config); err != nil {
 		return nil, err
 	}
 
@@ -132,10 +187,12 @@ func NewObjectBackupStore(location *velerov1api.BackupStorageLocation, objectSto
 	}))
 
 	return &objectBackupStore{
-		objectStore: objectStore,
-		bucket:      bucket,
-		layout:      NewObjectStoreLayout(prefix),
-		logger:      log,
+		objectStore:      objectStore,
+		bucket:           bucket,
+		layout:           NewObjectStoreLayout(prefix),
+		encryptionKey:    encryptionKey,
+		allowUnencrypted: location.Spec.Encryption != nil && location.Spec.Encryption.AllowUnencryptedObjects,
+		logger:           log,
 	}, nil
 }
 
@@ -189,7 +246,9 @@ func (s *objectBackupStore) ListBackups() ([]string, error) {
 }
 
 func (s *objectBackupStore) PutBackup(info BackupInfo) error {
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getBackupLogKey(info.Name), info.Log); err != nil {
+	checksums := newBackupChecksums(info.Name)
+
+	if err := s.putBackupFile(checksums, s.layout.getBackupLogKey(info.Name), info.Log); err != nil {
 		// Uploading the log file is best-effort; if it fails, we log the error but it doesn't impact the
 		// backup's status.
 		s.logger.WithError(err).WithField("backup", info.Name).Error("Error uploading log file")
@@ -198,21 +257,42 @@ func (s *objectBackupStore) PutBackup(info BackupInfo) error {
 	if info.Metadata == nil {
 		// If we don't have metadata, something failed, and there's no point in continuing. An object
 		// storage bucket that is missing the metadata file can't be restored, nor can its logs be
-		// viewed.
+		// viewed, so remove any contents that were already uploaded.
+		if info.Contents == nil && info.ContentsDigest != "" {
+			return s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
+		}
 		return nil
 	}
 
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getBackupMetadataKey(info.Name), info.Metadata); err != nil {
+	if err := s.putBackupFile(checksums, s.layout.getBackupMetadataKey(info.Name), info.Metadata); err != nil {
 		// failure to upload metadata file is a hard-stop
+		if info.Contents == nil && info.ContentsDigest != "" {
+			deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
+			return kerrors.NewAggregate([]error{err, deleteErr})
+		}
 		return err
 	}
 
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getBackupContentsKey(info.Name), info.Contents); err != nil {
+	if info.Contents == nil && info.ContentsDigest != "" {
+		checksums.Files[path.Base(s.layout.getBackupContentsKey(info.Name))] = info.ContentsDigest
+	} else if err := s.putBackupFile(checksums, s.layout.getBackupContentsKey(info.Name), info.Contents); err != nil {
 		deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupMetadataKey(info.Name))
 		return kerrors.NewAggregate([]error{err, deleteErr})
 	}
 
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getPodVolumeBackupsKey(info.Name), info.PodVolumeBackups); err != nil {
+	if err := s.putBackupFile(checksums, s.layout.getPodVolumeBackupsKey(info.Name), info.PodVolumeBackups); err != nil {
+		errs := []error{err}
+
+		deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
+		errs = append(errs, deleteErr)
+
+		deleteErr = s.objectStore.DeleteObject(s.bucket, s.layout.getBackupMetadataKey(info.Name))
+		errs = append(errs, deleteErr)
+
+		return kerrors.NewAggregate(errs)
+	}
+
+	if err := s.putBackupFile(checksums, s.layout.getBackupVolumeSnapshotsKey(info.Name), info.VolumeSnapshots); err != nil {
 		errs := []error{err}
 
 		deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
@@ -224,7 +304,7 @@ func (s *objectBackupStore) PutBackup(info BackupInfo) error {
 		return kerrors.NewAggregate(errs)
 	}
 
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getBackupVolumeSnapshotsKey(info.Name), info.VolumeSnapshots); err != nil {
+	if err := s.putBackupFile(checksums, s.layout.getBackupResourceListKey(info.Name), info.BackupResourceList); err != nil {
 		errs := []error{err}
 
 		deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
@@ -236,7 +316,13 @@ func (s *objectBackupStore) PutBackup(info BackupInfo) error {
 		return kerrors.NewAggregate(errs)
 	}
 
-	if err := seekAndPutObject(s.objectStore, s.bucket, s.layout.getBackupResourceListKey(info.Name), info.BackupResourceList); err != nil {
+	if err := s.putBackupFile(checksums, s.layout.getBackupIndexKey(info.Name), info.TarballIndex); err != nil {
+		// The index is only used to restore without extracting the tarball, so uploading it is
+		// best-effort; if it fails, we log the error and restores extract the tarball instead.
+		s.logger.WithError(err).WithField("backup", info.Name).Error("Error uploading tarball index")
+	}
+
+	if err := s.putBackupChecksums(checksums); err != nil {
 		errs := []error{err}
 
 		deleteErr := s.objectStore.DeleteObject(s.bucket, s.layout.getBackupContentsKey(info.Name))
@@ -255,10 +341,61 @@ func (s *objectBackupStore) PutBackup(info BackupInfo) error {
 	return nil
 }
 
+func (s *objectBackupStore) PutBackupContents(name string, contents io.Reader) (string, error) {
+	key := s.layout.getBackupContentsKey(name)
+
+	digestReader := newDigestReader(contents)
+	if err := s.putObject(key, digestReader); err != nil {
+		// Object store plugins abort uploads that fail partway through, but
+		// check for and delete anything that was left behind anyway so a
+		// truncated tarball is never mistaken for a complete one.
+		exists, existsErr := s.objectStore.ObjectExists(s.bucket, key)
+		if existsErr != nil {
+			return "", kerrors.NewAggregate([]error{err, existsErr})
+		}
+		if exists {
+			deleteErr := s.objectStore.DeleteObject(s.bucket, key)
+			return "", kerrors.NewAggregate([]error{err, deleteErr})
+		}
+		return "", err
+	}
+
+	return digestReader.digest(), nil
+}
+
+// putBackupFile stores one of a backup's files under key and records the
+// digest of its contents in checksums.
+func (s *objectBackupStore) putBackupFile(checksums *BackupChecksums, key string, body io.Reader) error {
+	if body == nil {
+		return nil
+	}
+
+	if err := seekToBeginning(body); err != nil {
+		return errors.WithStack(err)
+	}
+
+	digestReader := newDigestReader(body)
+	if err := s.putObject(key, digestReader); err != nil {
+		return err
+	}
+
+	checksums.Files[path.Base(key)] = digestReader.digest()
+	return nil
+}
+
+func (s *objectBackupStore) putBackupChecksums(checksums *BackupChecksums) error {
+	data, err := json.Marshal(checksums)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling checksum manifest")
+	}
+
+	return s.putObject(s.layout.getBackupChecksumsKey(checksums.Backup), bytes.NewReader(data))
+}
+
 func (s *objectBackupStore) GetBackupMetadata(name string) (*velerov1api.Backup, error) {
 	metadataKey := s.layout.getBackupMetadataKey(name)
 
-	res, err := s.objectStore.GetObject(s.bucket, metadataKey)
+	res, err := s.getObject(metadataKey)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +424,7 @@ func (s *objectBackupStore) GetBackupVolumeSnapshots(name string) ([]*volume.Sna
 	// if the volumesnapshots file doesn't exist, we don't want to return an error, since
 	// a legacy backup or a backup with no snapshots would not have this file, so check for
 	// its existence before attempting to get its contents.
-	res, err := tryGet(s.objectStore, s.bucket, s.layout.getBackupVolumeSnapshotsKey(name))
+	res, err := s.tryGet(s.layout.getBackupVolumeSnapshotsKey(name))
 	if err != nil {
 		return nil, err
 	}
@@ -306,8 +443,8 @@ func (s *objectBackupStore) GetBackupVolumeSnapshots(name string) ([]*volume.Sna
 
 // tryGet returns the object with the given key if it exists, nil if it does not exist,
 // or an error if it was unable to check existence or get the object.
-func tryGet(objectStore velero.ObjectStore, bucket, key string) (io.ReadCloser, error) {
-	exists, err := objectStore.ObjectExists(bucket, key)
+func (s *objectBackupStore) tryGet(key string) (io.ReadCloser, error) {
+	exists, err := s.objectStore.ObjectExists(s.bucket, key)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -315,7 +452,7 @@ func tryGet(objectStore velero.ObjectStore, bucket, key string) (io.ReadCloser,
 		return nil, nil
 	}
 
-	return objectStore.GetObject(bucket, key)
+	return s.getObject(key)
 }
 
 // decode extracts a .json.gz file reader into the object pointed to
@@ -338,7 +475,7 @@ func (s *objectBackupStore) GetPodVolumeBackups(name string) ([]*velerov1api.Pod
 	// if the podvolumebackups file doesn't exist, we don't want to return an error, since
 	// a legacy backup or a backup with no pod volume backups would not have this file, so
 	// check for its existence before attempting to get its contents.
-	res, err := tryGet(s.objectStore, s.bucket, s.layout.getPodVolumeBackupsKey(name))
+	res, err := s.tryGet(s.layout.getPodVolumeBackupsKey(name))
 	if err != nil {
 		return nil, err
 	}
@@ -356,7 +493,136 @@ func (s *objectBackupStore) GetPodVolumeBackups(name string) ([]*velerov1api.Pod
 }
 
 func (s *objectBackupStore) GetBackupContents(name string) (io.ReadCloser, error) {
-	return s.objectStore.GetObject(s.bucket, s.layout.getBackupContentsKey(name))
+	return s.getObject(s.layout.getBackupContentsKey(name))
+}
+
+func (s *objectBackupStore) GetBackupIndex(name string) (*archive.Index, error) {
+	// backups taken before tarball indexes were introduced don't have
+	// one, so don't return an error if it doesn't exist.
+	key := s.layout.getBackupIndexKey(name)
+	res, err := s.tryGet(key)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	defer res.Close()
+
+	digestReader := newDigestReader(res)
+	data, err := ioutil.ReadAll(digestReader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// the index's block digests are what protect the tarball's data when
+	// it's read in pieces, so check the index itself against the checksum
+	// manifest.
+	checksums, err := s.GetBackupChecksums(name)
+	if err != nil {
+		return nil, err
+	}
+	if checksums != nil {
+		if err := checksums.Verify(path.Base(key), digestReader.hash.Sum(nil)); err != nil {
+			return nil, err
+		}
+	}
+
+	index := new(archive.Index)
+	if err := decode(bytes.NewReader(data), index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+func (s *objectBackupStore) GetBackupContentsReaderAt(name string) (io.ReaderAt, error) {
+	rangeGetter, ok := s.objectStore.(velero.ObjectRangeGetter)
+	if !ok {
+		return nil, nil
+	}
+
+	r, err := newObjectReaderAt(rangeGetter, s.bucket, s.layout.getBackupContentsKey(name), s.encryptionKey, s.allowUnencrypted)
+	if err == velero.ErrObjectRangeNotSupported {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *objectBackupStore) GetBackupChecksums(name string) (*BackupChecksums, error) {
+	// backups taken before checksum manifests were introduced don't have
+	// one, so check for its existence before attempting to get it.
+	res, err := s.tryGet(s.layout.getBackupChecksumsKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	defer res.Close()
+
+	checksums := new(BackupChecksums)
+	if err := json.NewDecoder(res).Decode(checksums); err != nil {
+		return nil, errors.Wrap(err, "error decoding checksum manifest")
+	}
+
+	return checksums, nil
+}
+
+func (s *objectBackupStore) VerifyBackup(name string) ([]string, error) {
+	checksums, err := s.GetBackupChecksums(name)
+	if err != nil {
+		return nil, err
+	}
+	if checksums == nil {
+		return nil, errors.New("backup has no checksum manifest")
+	}
+
+	files := make([]string, 0, len(checksums.Files))
+	for file := range checksums.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var (
+		verified []string
+		errs     []error
+	)
+	for _, file := range files {
+		if err := s.verifyBackupFile(name, file, checksums); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verified = append(verified, file)
+	}
+
+	return verified, kerrors.NewAggregate(errs)
+}
+
+func (s *objectBackupStore) verifyBackupFile(backup, file string, checksums *BackupChecksums) error {
+	if file == "" || path.Base(file) != file {
+		return errors.Errorf("checksum manifest contains an invalid file name %q", file)
+	}
+
+	res, err := s.tryGet(path.Join(s.layout.getBackupDir(backup), file))
+	if err != nil {
+		return errors.Wrapf(err, "error getting %s", file)
+	}
+	if res == nil {
+		return errors.Errorf("%s is missing from backup storage", file)
+	}
+	defer res.Close()
+
+	hash := NewChecksumHash()
+	if _, err := io.Copy(hash, res); err != nil {
+		return errors.Wrapf(err, "error reading %s", file)
+	}
+
+	return checksums.Verify(file, hash.Sum(nil))
 }
 
 func (s *objectBackupStore) BackupExists(bucket, backupName string) (bool, error) {
@@ -410,32 +676,50 @@ func (s *objectBackupStore) DeleteRestore(name string) error {
 }
 
 func (s *objectBackupStore) PutRestoreLog(backup string, restore string, log io.Reader) error {
-	return s.objectStore.PutObject(s.bucket, s.layout.getRestoreLogKey(restore), log)
+	return s.putObject(s.layout.getRestoreLogKey(restore), log)
 }
 
 func (s *objectBackupStore) PutRestoreResults(backup string, restore string, results io.Reader) error {
-	return s.objectStore.PutObject(s.bucket, s.layout.getRestoreResultsKey(restore), results)
+	return s.putObject(s.layout.getRestoreResultsKey(restore), results)
 }
 
-func (s *objectBackupStore) GetDownloadURL(target velerov1api.DownloadTarget) (string, error) {
+func (s *objectBackupStore) getDownloadTargetKey(target velerov1api.DownloadTarget) (string, error) {
 	switch target.Kind {
 	case velerov1api.DownloadTargetKindBackupContents:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getBackupContentsKey(target.Name), DownloadURLTTL)
+		return s.layout.getBackupContentsKey(target.Name), nil
 	case velerov1api.DownloadTargetKindBackupLog:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getBackupLogKey(target.Name), DownloadURLTTL)
+		return s.layout.getBackupLogKey(target.Name), nil
 	case velerov1api.DownloadTargetKindBackupVolumeSnapshots:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getBackupVolumeSnapshotsKey(target.Name), DownloadURLTTL)
+		return s.layout.getBackupVolumeSnapshotsKey(target.Name), nil
 	case velerov1api.DownloadTargetKindBackupResourceList:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getBackupResourceListKey(target.Name), DownloadURLTTL)
+		return s.layout.getBackupResourceListKey(target.Name), nil
 	case velerov1api.DownloadTargetKindRestoreLog:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getRestoreLogKey(target.Name), DownloadURLTTL)
+		return s.layout.getRestoreLogKey(target.Name), nil
 	case velerov1api.DownloadTargetKindRestoreResults:
-		return s.objectStore.CreateSignedURL(s.bucket, s.layout.getRestoreResultsKey(target.Name), DownloadURLTTL)
+		return s.layout.getRestoreResultsKey(target.Name), nil
 	default:
 		return "", errors.Errorf("unsupported download target kind %q", target.Kind)
 	}
 }
 
+func (s *objectBackupStore) GetDownloadURL(target velerov1api.DownloadTarget) (string, error) {
+	key, err := s.getDownloadTargetKey(target)
+	if err != nil {
+		return "", err
+	}
+
+	return s.objectStore.CreateSignedURL(s.bucket, key, DownloadURLTTL)
+}
+
+func (s *objectBackupStore) GetDownload(target velerov1api.DownloadTarget) (io.ReadCloser, error) {
+	key, err := s.getDownloadTargetKey(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getObject(key)
+}
+
 func (s *objectBackupStore) GetRevision() (string, error) {
 	rdr, err := s.objectStore.GetObject(s.bucket, s.layout.getRevisionKey())
 	if err != nil {
@@ -460,6 +744,51 @@ func (s *objectBackupStore) putRevision() error {
 	return nil
 }
 
+// putObject stores the data in body under key, encrypting it if the store has
+// an encryption key.
+func (s *objectBackupStore) putObject(key string, body io.Reader) error {
+	if body == nil {
+		return nil
+	}
+
+	if err := seekToBeginning(body); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(s.encryptionKey) > 0 {
+		encrypted, err := newEncryptingReader(s.encryptionKey, body)
+		if err != nil {
+			return err
+		}
+		body = encrypted
+	}
+
+	return s.objectStore.PutObject(s.bucket, key, body)
+}
+
+// getObject returns the object with the given key, decrypting it if it's
+// encrypted. Unencrypted objects are rejected if the store has an encryption
+// key, unless it allows them.
+func (s *objectBackupStore) getObject(key string) (io.ReadCloser, error) {
+	res, err := s.objectStore.GetObject(s.bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := newDecryptingReader(s.encryptionKey, s.allowUnencrypted, res)
+	if err != nil {
+		res.Close()
+		return nil, errors.Wrapf(err, "error reading object %s", key)
+	}
+
+	return &readCloser{Reader: decrypted, Closer: res}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 func seekToBeginning(r io.Reader) error {
 	seeker, ok := r.(io.Seeker)
 	if !ok {
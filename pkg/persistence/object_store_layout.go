@@ -76,7 +76,11 @@ func (l *ObjectStoreLayout) getBackupMetadataKey(backup string) string {
 }
 
 func (l *ObjectStoreLayout) getBackupContentsKey(backup string) string {
-	return path.Join(l.subdirs["backups"], backup, fmt.Sprintf("%s.tar.gz", backup))
+	return path.Join(l.subdirs["backups"], backup, backupContentsFileName(backup))
+}
+
+func (l *ObjectStoreLayout) getBackupChecksumsKey(backup string) string {
+	return path.Join(l.subdirs["backups"], backup, fmt.Sprintf("%s-checksums.json", backup))
 }
 
 func (l *ObjectStoreLayout) getBackupLogKey(backup string) string {
@@ -95,6 +99,10 @@ func (l *ObjectStoreLayout) getBackupResourceListKey(backup string) string {
 	return path.Join(l.subdirs["backups"], backup, fmt.Sprintf("%s-resource-list.json.gz", backup))
 }
 
+func (l *ObjectStoreLayout) getBackupIndexKey(backup string) string {
+	return path.Join(l.subdirs["backups"], backup, fmt.Sprintf("%s-index.json.gz", backup))
+}
+
 func (l *ObjectStoreLayout) getRestoreLogKey(restore string) string {
 	return path.Join(l.subdirs["restores"], restore, fmt.Sprintf("restore-%s-logs.gz", restore))
 }
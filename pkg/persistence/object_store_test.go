@@ -19,6 +19,8 @@ package persistence
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
@@ -35,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/cloudprovider"
 	cloudprovidermocks "github.com/heptio/velero/pkg/cloudprovider/mocks"
@@ -237,6 +240,7 @@ func TestPutBackup(t *testing.T) {
 				"backups/backup-1/backup-1-podvolumebackups.json.gz",
 				"backups/backup-1/backup-1-volumesnapshots.json.gz",
 				"backups/backup-1/backup-1-resource-list.json.gz",
+				"backups/backup-1/backup-1-checksums.json",
 				"metadata/revision",
 			},
 		},
@@ -257,6 +261,7 @@ func TestPutBackup(t *testing.T) {
 				"prefix-1/backups/backup-1/backup-1-podvolumebackups.json.gz",
 				"prefix-1/backups/backup-1/backup-1-volumesnapshots.json.gz",
 				"prefix-1/backups/backup-1/backup-1-resource-list.json.gz",
+				"prefix-1/backups/backup-1/backup-1-checksums.json",
 				"prefix-1/metadata/revision",
 			},
 		},
@@ -296,6 +301,7 @@ func TestPutBackup(t *testing.T) {
 				"backups/backup-1/backup-1-podvolumebackups.json.gz",
 				"backups/backup-1/backup-1-volumesnapshots.json.gz",
 				"backups/backup-1/backup-1-resource-list.json.gz",
+				"backups/backup-1/backup-1-checksums.json",
 				"metadata/revision",
 			},
 		},
@@ -336,6 +342,45 @@ func TestPutBackup(t *testing.T) {
 	}
 }
 
+func TestPutBackupContents(t *testing.T) {
+	harness := newObjectBackupStoreTestHarness("test-bucket", "")
+
+	// contents are streamed to object storage, and their digest returned
+	digest, err := harness.PutBackupContents("backup-1", strings.NewReader("contents"))
+	require.NoError(t, err)
+	contentsDigest := sha256.Sum256([]byte("contents"))
+	assert.Equal(t, hex.EncodeToString(contentsDigest[:]), digest)
+	assert.Equal(t, []byte("contents"), harness.objectStore.Data[harness.bucket]["backups/backup-1/backup-1.tar.gz"])
+
+	// the metadata and checksum manifest are written once the contents are uploaded
+	require.NoError(t, harness.PutBackup(BackupInfo{
+		Name:           "backup-1",
+		Metadata:       newStringReadSeeker("metadata"),
+		Log:            newStringReadSeeker("log"),
+		ContentsDigest: digest,
+	}))
+	verified, err := harness.VerifyBackup("backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1-logs.gz", "backup-1.tar.gz", "velero-backup.json"}, verified)
+
+	// an upload that fails doesn't leave anything behind
+	require.NoError(t, harness.objectStore.PutObject(harness.bucket, "backups/backup-2/backup-2.tar.gz", newStringReadSeeker("partial")))
+	_, err = harness.PutBackupContents("backup-2", new(errorReader))
+	velerotest.AssertErrorMatches(t, "error readers return errors", err)
+	assert.NotContains(t, harness.objectStore.Data[harness.bucket], "backups/backup-2/backup-2.tar.gz")
+
+	// uploaded contents are removed if the backup has no metadata
+	digest, err = harness.PutBackupContents("backup-3", strings.NewReader("contents"))
+	require.NoError(t, err)
+	require.NoError(t, harness.PutBackup(BackupInfo{
+		Name:           "backup-3",
+		Log:            newStringReadSeeker("log"),
+		ContentsDigest: digest,
+	}))
+	assert.NotContains(t, harness.objectStore.Data[harness.bucket], "backups/backup-3/backup-3.tar.gz")
+	assert.Contains(t, harness.objectStore.Data[harness.bucket], "backups/backup-3/backup-3-logs.gz")
+}
+
 func TestGetBackupMetadata(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -437,6 +482,231 @@ func TestGetBackupContents(t *testing.T) {
 	assert.Equal(t, "foo", string(data))
 }
 
+func TestGetBackupIndex(t *testing.T) {
+	harness := newObjectBackupStoreTestHarness("test-bucket", "")
+
+	// backups without an index return nil
+	index, err := harness.GetBackupIndex("backup-1")
+	require.NoError(t, err)
+	assert.Nil(t, index)
+
+	expected := &archive.Index{
+		Blocks: []archive.Block{{Offset: 0, Length: 10, Digest: "digest"}},
+		Files:  []archive.File{{Name: "metadata/version", Size: 1, Block: 0, Offset: 512}},
+	}
+	indexData := new(bytes.Buffer)
+	gzw := gzip.NewWriter(indexData)
+	require.NoError(t, json.NewEncoder(gzw).Encode(expected))
+	require.NoError(t, gzw.Close())
+
+	require.NoError(t, harness.PutBackup(BackupInfo{
+		Name:         "backup-1",
+		Metadata:     newStringReadSeeker("metadata"),
+		Contents:     newStringReadSeeker("contents"),
+		TarballIndex: bytes.NewReader(indexData.Bytes()),
+	}))
+
+	index, err = harness.GetBackupIndex("backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, index)
+
+	// an index that doesn't match the checksum manifest is rejected
+	harness.objectStore.Data[harness.bucket]["backups/backup-1/backup-1-index.json.gz"] = []byte("modified")
+	_, err = harness.GetBackupIndex("backup-1")
+	assert.Error(t, err)
+}
+
+func TestGetBackupContentsReaderAt(t *testing.T) {
+	// make the contents span several encryption chunks
+	contents := make([]byte, 3*encryptionChunkSize+100)
+	for i := range contents {
+		contents[i] = byte(i % 251)
+	}
+
+	tests := []struct {
+		name          string
+		encryptionKey []byte
+	}{
+		{
+			name: "unencrypted contents",
+		},
+		{
+			name:          "encrypted contents",
+			encryptionKey: testEncryptionKey,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			harness := newObjectBackupStoreTestHarness("test-bucket", "")
+			harness.encryptionKey = tc.encryptionKey
+
+			_, err := harness.PutBackupContents("backup-1", bytes.NewReader(contents))
+			require.NoError(t, err)
+
+			ra, err := harness.GetBackupContentsReaderAt("backup-1")
+			require.NoError(t, err)
+			require.NotNil(t, ra)
+
+			ranges := []struct {
+				offset, length int64
+			}{
+				{offset: 0, length: 10},
+				{offset: encryptionChunkSize - 5, length: 10},
+				{offset: 100, length: 2*encryptionChunkSize + 50},
+				{offset: int64(len(contents)) - 10, length: 10},
+			}
+			for _, r := range ranges {
+				p := make([]byte, r.length)
+				n, err := ra.ReadAt(p, r.offset)
+				require.NoError(t, err, "offset=%d length=%d", r.offset, r.length)
+				assert.Equal(t, int(r.length), n)
+				assert.Equal(t, contents[r.offset:r.offset+r.length], p, "offset=%d length=%d", r.offset, r.length)
+			}
+
+			// reading past the end returns io.EOF
+			p := make([]byte, 20)
+			n, err := ra.ReadAt(p, int64(len(contents))-10)
+			assert.Equal(t, io.EOF, err)
+			assert.Equal(t, 10, n)
+			assert.Equal(t, contents[len(contents)-10:], p[:n])
+		})
+	}
+}
+
+func TestGetBackupContentsReaderAtWithoutRangeSupport(t *testing.T) {
+	objectStore := new(cloudprovidermocks.ObjectStore)
+	objectStore.On("GetObjectRange", "test-bucket", "backups/backup-1/backup-1.tar.gz", int64(0), mock.Anything).Return(nil, velero.ErrObjectRangeNotSupported)
+
+	store := &objectBackupStore{
+		objectStore: objectStore,
+		bucket:      "test-bucket",
+		layout:      NewObjectStoreLayout(""),
+		logger:      velerotest.NewLogger(),
+	}
+
+	ra, err := store.GetBackupContentsReaderAt("backup-1")
+	require.NoError(t, err)
+	assert.Nil(t, ra)
+}
+
+func TestPutAndGetEncryptedBackup(t *testing.T) {
+	harness := newObjectBackupStoreTestHarness("test-bucket", "")
+	harness.encryptionKey = testEncryptionKey
+
+	metadata, err := json.Marshal(builder.ForBackup(velerov1api.DefaultNamespace, "backup-1").Result())
+	require.NoError(t, err)
+
+	require.NoError(t, harness.PutBackup(BackupInfo{
+		Name:     "backup-1",
+		Metadata: bytes.NewReader(metadata),
+		Contents: newStringReadSeeker("contents"),
+		Log:      newStringReadSeeker("log"),
+	}))
+
+	// objects are encrypted in object storage, but the revision isn't
+	for key, data := range harness.objectStore.Data[harness.bucket] {
+		if key == "metadata/revision" {
+			continue
+		}
+		assert.True(t, bytes.HasPrefix(data, encryptionMagic), key)
+	}
+
+	res, err := harness.GetBackupMetadata("backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, "backup-1", res.Name)
+
+	rc, err := harness.GetBackupContents("backup-1")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(data))
+
+	// downloads are decrypted
+	rc, err = harness.GetDownload(velerov1api.DownloadTarget{Kind: velerov1api.DownloadTargetKindBackupLog, Name: "backup-1"})
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "log", string(data))
+
+	// unencrypted objects, e.g. from before encryption was enabled, are
+	// rejected
+	require.NoError(t, harness.objectStore.PutObject(harness.bucket, "backups/backup-2/backup-2.tar.gz", newStringReadSeeker("plaintext")))
+	_, err = harness.GetBackupContents("backup-2")
+	assert.Error(t, err)
+	_, err = harness.GetDownload(velerov1api.DownloadTarget{Kind: velerov1api.DownloadTargetKindBackupContents, Name: "backup-2"})
+	assert.Error(t, err)
+	_, err = harness.GetBackupContentsReaderAt("backup-2")
+	assert.Error(t, err)
+
+	// unless the location allows them
+	harness.allowUnencrypted = true
+	rc, err = harness.GetBackupContents("backup-2")
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(data))
+
+	rc, err = harness.GetDownload(velerov1api.DownloadTarget{Kind: velerov1api.DownloadTargetKindBackupContents, Name: "backup-2"})
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(data))
+
+	// encrypted objects can't be read without the key
+	harness.encryptionKey = nil
+	harness.allowUnencrypted = false
+	_, err = harness.GetBackupMetadata("backup-1")
+	assert.Error(t, err)
+}
+
+func TestVerifyBackup(t *testing.T) {
+	harness := newObjectBackupStoreTestHarness("test-bucket", "")
+
+	require.NoError(t, harness.PutBackup(BackupInfo{
+		Name:     "backup-1",
+		Metadata: newStringReadSeeker("metadata"),
+		Contents: newStringReadSeeker("contents"),
+		Log:      newStringReadSeeker("log"),
+	}))
+
+	checksums, err := harness.GetBackupChecksums("backup-1")
+	require.NoError(t, err)
+	require.NotNil(t, checksums)
+	assert.Equal(t, "backup-1", checksums.Backup)
+	assert.Equal(t, ChecksumAlgorithmSHA256, checksums.Algorithm)
+
+	contentsDigest := sha256.Sum256([]byte("contents"))
+	assert.NoError(t, checksums.VerifyContents(contentsDigest[:]))
+	otherDigest := sha256.Sum256([]byte("other"))
+	assert.Error(t, checksums.VerifyContents(otherDigest[:]))
+
+	verified, err := harness.VerifyBackup("backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1-logs.gz", "backup-1.tar.gz", "velero-backup.json"}, verified)
+
+	// a modified file fails verification
+	harness.objectStore.Data[harness.bucket]["backups/backup-1/backup-1.tar.gz"] = []byte("modified")
+	verified, err = harness.VerifyBackup("backup-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for backup-1.tar.gz")
+	assert.Equal(t, []string{"backup-1-logs.gz", "velero-backup.json"}, verified)
+
+	// as does a missing one
+	delete(harness.objectStore.Data[harness.bucket], "backups/backup-1/backup-1-logs.gz")
+	_, err = harness.VerifyBackup("backup-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backup-1-logs.gz is missing from backup storage")
+
+	// backups without a checksum manifest can't be verified
+	require.NoError(t, harness.objectStore.PutObject(harness.bucket, "backups/backup-2/velero-backup.json", newStringReadSeeker("metadata")))
+	checksums, err = harness.GetBackupChecksums("backup-2")
+	require.NoError(t, err)
+	assert.Nil(t, checksums)
+	_, err = harness.VerifyBackup("backup-2")
+	velerotest.AssertErrorMatches(t, "backup has no checksum manifest", err)
+}
+
 func TestDeleteBackup(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -661,7 +931,7 @@ func TestNewObjectBackupStore(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			res, err := NewObjectBackupStore(tc.location, tc.objectStoreGetter, velerotest.NewLogger())
+			res, err := NewObjectBackupStore(tc.location, tc.objectStoreGetter, nil, velerotest.NewLogger())
 			if tc.wantErr != "" {
 				require.Equal(t, tc.wantErr, err.Error())
 			} else {
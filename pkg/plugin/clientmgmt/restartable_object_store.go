@@ -138,6 +138,21 @@ func (r *restartableObjectStore) GetObject(bucket string, key string) (io.ReadCl
 	return delegate.GetObject(bucket, key)
 }
 
+// GetObjectRange restarts the plugin's process if needed, then delegates the
+// call if the plugin supports ranged reads.
+func (r *restartableObjectStore) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	delegate, err := r.getDelegate()
+	if err != nil {
+		return nil, err
+	}
+
+	rangeGetter, ok := delegate.(velero.ObjectRangeGetter)
+	if !ok {
+		return nil, velero.ErrObjectRangeNotSupported
+	}
+	return rangeGetter.GetObjectRange(bucket, key, offset, length)
+}
+
 // ListCommonPrefixes restarts the plugin's process if needed, then delegates the call.
 func (r *restartableObjectStore) ListCommonPrefixes(bucket string, prefix string, delimiter string) ([]string, error) {
 	delegate, err := r.getDelegate()
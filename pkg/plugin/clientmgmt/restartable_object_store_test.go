@@ -208,6 +208,12 @@ func TestRestartableObjectStoreDelegatedFunctions(t *testing.T) {
 			expectedErrorOutputs:    []interface{}{nil, errors.Errorf("reset error")},
 			expectedDelegateOutputs: []interface{}{ioutil.NopCloser(strings.NewReader("object")), errors.Errorf("delegate error")},
 		},
+		restartableDelegateTest{
+			function:                "GetObjectRange",
+			inputs:                  []interface{}{"bucket", "key", int64(10), int64(20)},
+			expectedErrorOutputs:    []interface{}{nil, errors.Errorf("reset error")},
+			expectedDelegateOutputs: []interface{}{ioutil.NopCloser(strings.NewReader("object")), errors.Errorf("delegate error")},
+		},
 		restartableDelegateTest{
 			function:                "ListCommonPrefixes",
 			inputs:                  []interface{}{"bucket", "prefix", "delimiter"},
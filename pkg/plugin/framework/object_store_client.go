@@ -23,8 +23,11 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	proto "github.com/heptio/velero/pkg/plugin/generated"
+	"github.com/heptio/velero/pkg/plugin/velero"
 )
 
 const byteChunkSize = 16384
@@ -69,7 +72,10 @@ func (c *ObjectStoreGRPCClient) Init(config map[string]string) error {
 // PutObject creates a new object using the data in body within the specified
 // object storage bucket with the given key.
 func (c *ObjectStoreGRPCClient) PutObject(bucket, key string, body io.Reader) error {
-	stream, err := c.grpcClient.PutObject(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.grpcClient.PutObject(ctx)
 	if err != nil {
 		return fromGRPCError(err)
 	}
@@ -79,6 +85,11 @@ func (c *ObjectStoreGRPCClient) PutObject(bucket, key string, body io.Reader) er
 	chunk := make([]byte, byteChunkSize)
 	for {
 		n, err := body.Read(chunk)
+		if n > 0 {
+			if err := stream.Send(&proto.PutObjectRequest{Plugin: c.plugin, Bucket: bucket, Key: key, Body: chunk[0:n]}); err != nil {
+				return fromGRPCError(err)
+			}
+		}
 		if err == io.EOF {
 			if _, resErr := stream.CloseAndRecv(); resErr != nil {
 				return fromGRPCError(resErr)
@@ -86,13 +97,12 @@ func (c *ObjectStoreGRPCClient) PutObject(bucket, key string, body io.Reader) er
 			return nil
 		}
 		if err != nil {
-			stream.CloseSend()
+			// Cancel the stream rather than closing it, so the plugin sees
+			// the upload fail and aborts it instead of storing what it's
+			// received so far as the whole object.
+			cancel()
 			return errors.WithStack(err)
 		}
-
-		if err := stream.Send(&proto.PutObjectRequest{Plugin: c.plugin, Bucket: bucket, Key: key, Body: chunk[0:n]}); err != nil {
-			return fromGRPCError(err)
-		}
 	}
 }
 
@@ -151,6 +161,64 @@ func (c *ObjectStoreGRPCClient) GetObject(bucket, key string) (io.ReadCloser, er
 	return &StreamReadCloser{receive: receive, close: close}, nil
 }
 
+// GetObjectRange retrieves length bytes of the object with the given key,
+// starting at offset. It returns velero.ErrObjectRangeNotSupported if the
+// plugin doesn't support ranged reads.
+func (c *ObjectStoreGRPCClient) GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	req := &proto.GetObjectRangeRequest{
+		Plugin: c.plugin,
+		Bucket: bucket,
+		Key:    key,
+		Offset: offset,
+		Length: length,
+	}
+
+	stream, err := c.grpcClient.GetObjectRange(context.Background(), req)
+	if err != nil {
+		return nil, fromGRPCError(err)
+	}
+
+	// receive the first chunk up front so that plugins which don't support
+	// ranged reads are reported here rather than on the first read.
+	first, err := stream.Recv()
+	if status.Code(err) == codes.Unimplemented {
+		return nil, velero.ErrObjectRangeNotSupported
+	}
+	if err != nil && err != io.EOF {
+		return nil, fromGRPCError(err)
+	}
+
+	receive := func() ([]byte, error) {
+		if first != nil {
+			data := first.Data
+			first = nil
+			return data, nil
+		}
+
+		data, err := stream.Recv()
+		if err == io.EOF {
+			// we need to return io.EOF errors unwrapped so that
+			// calling code sees them as io.EOF and knows to stop
+			// reading.
+			return nil, err
+		}
+		if err != nil {
+			return nil, fromGRPCError(err)
+		}
+
+		return data.Data, nil
+	}
+
+	close := func() error {
+		if err := stream.CloseSend(); err != nil {
+			return fromGRPCError(err)
+		}
+		return nil
+	}
+
+	return &StreamReadCloser{receive: receive, close: close}, nil
+}
+
 // ListCommonPrefixes gets a list of all object key prefixes that come
 // after the provided prefix and before the provided delimiter (this is
 // often used to simulate a directory hierarchy in object storage).
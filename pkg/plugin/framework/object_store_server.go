@@ -22,6 +22,7 @@ import (
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
 
 	proto "github.com/heptio/velero/pkg/plugin/generated"
 	"github.com/heptio/velero/pkg/plugin/velero"
@@ -185,6 +186,51 @@ func (s *ObjectStoreGRPCServer) GetObject(req *proto.GetObjectRequest, stream pr
 	}
 }
 
+// GetObjectRange retrieves part of the object with the given key from the
+// specified bucket in object storage. If the plugin doesn't support ranged
+// reads, it returns an Unimplemented error.
+func (s *ObjectStoreGRPCServer) GetObjectRange(req *proto.GetObjectRangeRequest, stream proto.ObjectStore_GetObjectRangeServer) (err error) {
+	defer func() {
+		if recoveredErr := handlePanic(recover()); recoveredErr != nil {
+			err = recoveredErr
+		}
+	}()
+
+	impl, err := s.getImpl(req.Plugin)
+	if err != nil {
+		return newGRPCError(err)
+	}
+
+	rangeGetter, ok := impl.(velero.ObjectRangeGetter)
+	if !ok {
+		return newGRPCErrorWithCode(velero.ErrObjectRangeNotSupported, codes.Unimplemented)
+	}
+
+	rdr, err := rangeGetter.GetObjectRange(req.Bucket, req.Key, req.Offset, req.Length)
+	if err == velero.ErrObjectRangeNotSupported {
+		return newGRPCErrorWithCode(err, codes.Unimplemented)
+	}
+	if err != nil {
+		return newGRPCError(err)
+	}
+	defer rdr.Close()
+
+	chunk := make([]byte, byteChunkSize)
+	for {
+		n, err := rdr.Read(chunk)
+		if err != nil && err != io.EOF {
+			return newGRPCError(errors.WithStack(err))
+		}
+		if n == 0 {
+			return nil
+		}
+
+		if err := stream.Send(&proto.Bytes{Data: chunk[0:n]}); err != nil {
+			return newGRPCError(errors.WithStack(err))
+		}
+	}
+}
+
 // ListCommonPrefixes gets a list of all object key prefixes that start with
 // the specified prefix and stop at the next instance of the provided delimiter
 // (this is often used to simulate a directory hierarchy in object storage).
@@ -369,6 +369,54 @@ func (m *ObjectStoreInitRequest) GetConfig() map[string]string {
 	return nil
 }
 
+type GetObjectRangeRequest struct {
+	Plugin string `protobuf:"bytes,1,opt,name=plugin" json:"plugin,omitempty"`
+	Bucket string `protobuf:"bytes,2,opt,name=bucket" json:"bucket,omitempty"`
+	Key    string `protobuf:"bytes,3,opt,name=key" json:"key,omitempty"`
+	Offset int64  `protobuf:"varint,4,opt,name=offset" json:"offset,omitempty"`
+	Length int64  `protobuf:"varint,5,opt,name=length" json:"length,omitempty"`
+}
+
+func (m *GetObjectRangeRequest) Reset()                    { *m = GetObjectRangeRequest{} }
+func (m *GetObjectRangeRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetObjectRangeRequest) ProtoMessage()               {}
+func (*GetObjectRangeRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+
+func (m *GetObjectRangeRequest) GetPlugin() string {
+	if m != nil {
+		return m.Plugin
+	}
+	return ""
+}
+
+func (m *GetObjectRangeRequest) GetBucket() string {
+	if m != nil {
+		return m.Bucket
+	}
+	return ""
+}
+
+func (m *GetObjectRangeRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *GetObjectRangeRequest) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *GetObjectRangeRequest) GetLength() int64 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*PutObjectRequest)(nil), "generated.PutObjectRequest")
 	proto.RegisterType((*ObjectExistsRequest)(nil), "generated.ObjectExistsRequest")
@@ -383,6 +431,7 @@ func init() {
 	proto.RegisterType((*CreateSignedURLRequest)(nil), "generated.CreateSignedURLRequest")
 	proto.RegisterType((*CreateSignedURLResponse)(nil), "generated.CreateSignedURLResponse")
 	proto.RegisterType((*ObjectStoreInitRequest)(nil), "generated.ObjectStoreInitRequest")
+	proto.RegisterType((*GetObjectRangeRequest)(nil), "generated.GetObjectRangeRequest")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -404,6 +453,7 @@ type ObjectStoreClient interface {
 	ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error)
 	DeleteObject(ctx context.Context, in *DeleteObjectRequest, opts ...grpc.CallOption) (*Empty, error)
 	CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*CreateSignedURLResponse, error)
+	GetObjectRange(ctx context.Context, in *GetObjectRangeRequest, opts ...grpc.CallOption) (ObjectStore_GetObjectRangeClient, error)
 }
 
 type objectStoreClient struct {
@@ -534,6 +584,38 @@ func (c *objectStoreClient) CreateSignedURL(ctx context.Context, in *CreateSigne
 	return out, nil
 }
 
+func (c *objectStoreClient) GetObjectRange(ctx context.Context, in *GetObjectRangeRequest, opts ...grpc.CallOption) (ObjectStore_GetObjectRangeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObjectStore_serviceDesc.Streams[2], c.cc, "/generated.ObjectStore/GetObjectRange", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &objectStoreGetObjectRangeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObjectStore_GetObjectRangeClient interface {
+	Recv() (*Bytes, error)
+	grpc.ClientStream
+}
+
+type objectStoreGetObjectRangeClient struct {
+	grpc.ClientStream
+}
+
+func (x *objectStoreGetObjectRangeClient) Recv() (*Bytes, error) {
+	m := new(Bytes)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for ObjectStore service
 
 type ObjectStoreServer interface {
@@ -545,6 +627,7 @@ type ObjectStoreServer interface {
 	ListObjects(context.Context, *ListObjectsRequest) (*ListObjectsResponse, error)
 	DeleteObject(context.Context, *DeleteObjectRequest) (*Empty, error)
 	CreateSignedURL(context.Context, *CreateSignedURLRequest) (*CreateSignedURLResponse, error)
+	GetObjectRange(*GetObjectRangeRequest, ObjectStore_GetObjectRangeServer) error
 }
 
 func RegisterObjectStoreServer(s *grpc.Server, srv ObjectStoreServer) {
@@ -706,6 +789,27 @@ func _ObjectStore_CreateSignedURL_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ObjectStore_GetObjectRange_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetObjectRangeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObjectStoreServer).GetObjectRange(m, &objectStoreGetObjectRangeServer{stream})
+}
+
+type ObjectStore_GetObjectRangeServer interface {
+	Send(*Bytes) error
+	grpc.ServerStream
+}
+
+type objectStoreGetObjectRangeServer struct {
+	grpc.ServerStream
+}
+
+func (x *objectStoreGetObjectRangeServer) Send(m *Bytes) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "generated.ObjectStore",
 	HandlerType: (*ObjectStoreServer)(nil),
@@ -746,6 +850,11 @@ var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 			Handler:       _ObjectStore_GetObject_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GetObjectRange",
+			Handler:       _ObjectStore_GetObjectRange_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "ObjectStore.proto",
 }
@@ -753,42 +862,44 @@ var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("ObjectStore.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 577 bytes of a gzipped FileDescriptorProto
+	// 619 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xd6, 0xc6, 0x69, 0x54, 0x4f, 0x22, 0x61, 0xb6, 0x55, 0x30, 0x2e, 0x94, 0xb0, 0x02, 0x29,
-	0x08, 0x11, 0xa1, 0x72, 0x29, 0xd0, 0x03, 0x22, 0x44, 0x08, 0x29, 0x52, 0x2b, 0x07, 0x04, 0x07,
-	0x2e, 0x4e, 0x3c, 0x4d, 0x4d, 0x1c, 0x3b, 0xd8, 0x6b, 0x54, 0x1f, 0x79, 0x25, 0x9e, 0x89, 0x07,
-	0x41, 0xbb, 0xde, 0x26, 0xde, 0xfc, 0x10, 0xa9, 0xca, 0x6d, 0x66, 0x76, 0xe7, 0x9b, 0x6f, 0x66,
-	0xfd, 0x8d, 0xe1, 0xee, 0xf9, 0xf0, 0x07, 0x8e, 0xf8, 0x80, 0xc7, 0x09, 0x76, 0x66, 0x49, 0xcc,
-	0x63, 0x6a, 0x8e, 0x31, 0xc2, 0xc4, 0xe3, 0xe8, 0x3b, 0x8d, 0xc1, 0x95, 0x97, 0xa0, 0x5f, 0x1c,
-	0xb0, 0x2b, 0xb0, 0x2e, 0x32, 0x5e, 0x24, 0xb8, 0xf8, 0x33, 0xc3, 0x94, 0xd3, 0x26, 0xd4, 0x66,
-	0x61, 0x36, 0x0e, 0x22, 0x9b, 0xb4, 0x48, 0xdb, 0x74, 0x95, 0x27, 0xe2, 0xc3, 0x6c, 0x34, 0x41,
-	0x6e, 0x57, 0x8a, 0x78, 0xe1, 0x51, 0x0b, 0x8c, 0x09, 0xe6, 0xb6, 0x21, 0x83, 0xc2, 0xa4, 0x14,
-	0xaa, 0xc3, 0xd8, 0xcf, 0xed, 0x6a, 0x8b, 0xb4, 0x1b, 0xae, 0xb4, 0xd9, 0x57, 0x38, 0x28, 0xca,
-	0xf4, 0xae, 0x83, 0x94, 0xa7, 0x3b, 0x2b, 0xc6, 0x3a, 0x70, 0xa8, 0x03, 0xa7, 0xb3, 0x38, 0x4a,
-	0x51, 0x20, 0xa0, 0x8c, 0x48, 0xe4, 0x7d, 0x57, 0x79, 0xec, 0x33, 0x58, 0x1f, 0x71, 0xd7, 0x2d,
-	0xb3, 0x23, 0xd8, 0x7b, 0x9f, 0x73, 0x4c, 0x45, 0xef, 0xbe, 0xc7, 0x3d, 0x09, 0xd4, 0x70, 0xa5,
-	0xcd, 0x7e, 0x13, 0xb8, 0xdf, 0x0f, 0x52, 0xde, 0x8d, 0xa7, 0xd3, 0x38, 0xba, 0x48, 0xf0, 0x32,
-	0xb8, 0xc6, 0x5b, 0x8f, 0xe0, 0x01, 0x98, 0x3e, 0x86, 0xc1, 0x34, 0xe0, 0x98, 0x28, 0x0a, 0x8b,
-	0x80, 0x44, 0x93, 0x05, 0xe4, 0xf4, 0x05, 0x9a, 0xf4, 0xd8, 0x29, 0x38, 0xeb, 0x28, 0xa8, 0x61,
-	0x39, 0xb0, 0x3f, 0x53, 0x31, 0x9b, 0xb4, 0x8c, 0xb6, 0xe9, 0xce, 0x7d, 0xf6, 0x1d, 0xa8, 0xc8,
-	0x2c, 0x26, 0x76, 0x6b, 0xd6, 0x0b, 0x5e, 0x86, 0xc6, 0xeb, 0x19, 0x1c, 0x68, 0xe8, 0x8a, 0x10,
-	0x85, 0xea, 0x04, 0xf3, 0x1b, 0x32, 0xd2, 0x16, 0x9f, 0xd0, 0x07, 0x0c, 0x91, 0xe3, 0xae, 0x1f,
-	0x2f, 0x84, 0x66, 0x37, 0x41, 0x8f, 0xe3, 0x20, 0x18, 0x47, 0xe8, 0x7f, 0x71, 0xfb, 0xbb, 0xd3,
-	0x82, 0x05, 0x06, 0xe7, 0xa1, 0x7c, 0x0c, 0xc3, 0x15, 0x26, 0x7b, 0x0e, 0xf7, 0x56, 0xaa, 0xa9,
-	0xae, 0x2d, 0x30, 0xb2, 0x24, 0x54, 0xb5, 0x84, 0xc9, 0xfe, 0x10, 0x68, 0x96, 0xf4, 0xfc, 0x29,
-	0x0a, 0xb6, 0xf6, 0xdd, 0x83, 0xda, 0x28, 0x8e, 0x2e, 0x83, 0xb1, 0x5d, 0x69, 0x19, 0xed, 0xfa,
-	0xc9, 0x8b, 0xce, 0x5c, 0xfd, 0x9d, 0xf5, 0x50, 0x9d, 0xae, 0xbc, 0xdf, 0x8b, 0x78, 0x92, 0xbb,
-	0x2a, 0xd9, 0x79, 0x0d, 0xf5, 0x52, 0xf8, 0xa6, 0x33, 0xb2, 0xe8, 0xec, 0x10, 0xf6, 0x7e, 0x79,
-	0x61, 0x86, 0x6a, 0x04, 0x85, 0xf3, 0xa6, 0x72, 0x4a, 0x4e, 0xfe, 0x56, 0xa1, 0x5e, 0xaa, 0x44,
-	0xdf, 0x42, 0x55, 0x54, 0xa3, 0x8f, 0xb7, 0x32, 0x71, 0xac, 0xd2, 0x95, 0xde, 0x74, 0xc6, 0x73,
-	0x7a, 0x06, 0xe6, 0x7c, 0x45, 0xd1, 0xa3, 0xd2, 0xf1, 0xf2, 0xe2, 0x5a, 0xcd, 0x6d, 0x13, 0x7a,
-	0x0e, 0x8d, 0xf2, 0x76, 0xa0, 0xc7, 0x2b, 0x14, 0xb4, 0x7d, 0xe4, 0x3c, 0xda, 0x78, 0xae, 0x9e,
-	0xe8, 0x0c, 0xcc, 0xf9, 0xfa, 0xd0, 0xe8, 0x2c, 0x2f, 0x15, 0x8d, 0x8e, 0xdc, 0x0d, 0x2f, 0x09,
-	0xf5, 0x0a, 0x2d, 0xe9, 0x2a, 0xa4, 0x4f, 0x4a, 0x37, 0x37, 0xee, 0x09, 0xe7, 0xe9, 0x96, 0x5b,
-	0x8a, 0x60, 0x1f, 0xea, 0x25, 0x41, 0xd1, 0x87, 0x4b, 0x59, 0xba, 0x8c, 0x9d, 0xe3, 0x4d, 0xc7,
-	0x0a, 0xed, 0x1d, 0x34, 0xca, 0x9a, 0xd3, 0xe6, 0xb7, 0x46, 0x8c, 0x6b, 0xde, 0xef, 0x1b, 0xdc,
-	0x59, 0xfa, 0xdc, 0xb5, 0xef, 0x60, 0xbd, 0xf0, 0x1c, 0xf6, 0xbf, 0x2b, 0x05, 0xb7, 0x61, 0x4d,
-	0xfe, 0xc3, 0x5e, 0xfd, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x0b, 0x4e, 0x76, 0xa3, 0xf1, 0x06, 0x00,
-	0x00,
+	0x10, 0xd6, 0xd6, 0x49, 0x54, 0x4f, 0x22, 0x30, 0xdb, 0x12, 0x8c, 0x0b, 0x25, 0xac, 0x40, 0x0a,
+	0x42, 0x44, 0xa8, 0x5c, 0x0a, 0xf4, 0x80, 0x28, 0xa1, 0x42, 0x8a, 0xd4, 0xca, 0x01, 0xc1, 0x81,
+	0x8b, 0x13, 0x4f, 0x1c, 0x13, 0xc7, 0x0e, 0xf6, 0x1a, 0xd5, 0x47, 0x8e, 0xbc, 0x0b, 0x27, 0x9e,
+	0x10, 0x79, 0xbd, 0x4d, 0xec, 0xc4, 0x69, 0xa4, 0x2a, 0xb7, 0x99, 0xf1, 0xfc, 0x7c, 0x33, 0xb3,
+	0xf3, 0x19, 0xee, 0x9c, 0x0f, 0x7e, 0xe0, 0x90, 0xf7, 0x79, 0x10, 0x62, 0x67, 0x16, 0x06, 0x3c,
+	0xa0, 0xaa, 0x83, 0x3e, 0x86, 0x16, 0x47, 0xdb, 0x68, 0xf4, 0xc7, 0x56, 0x88, 0x76, 0xf6, 0x81,
+	0x8d, 0x41, 0xbb, 0x88, 0x79, 0x16, 0x60, 0xe2, 0xcf, 0x18, 0x23, 0x4e, 0x9b, 0x50, 0x9b, 0x79,
+	0xb1, 0xe3, 0xfa, 0x3a, 0x69, 0x91, 0xb6, 0x6a, 0x4a, 0x2d, 0xb5, 0x0f, 0xe2, 0xe1, 0x04, 0xb9,
+	0xbe, 0x93, 0xd9, 0x33, 0x8d, 0x6a, 0xa0, 0x4c, 0x30, 0xd1, 0x15, 0x61, 0x4c, 0x45, 0x4a, 0xa1,
+	0x32, 0x08, 0xec, 0x44, 0xaf, 0xb4, 0x48, 0xbb, 0x61, 0x0a, 0x99, 0x7d, 0x85, 0xbd, 0xac, 0x4c,
+	0xf7, 0xd2, 0x8d, 0x78, 0xb4, 0xb5, 0x62, 0xac, 0x03, 0xfb, 0xc5, 0xc4, 0xd1, 0x2c, 0xf0, 0x23,
+	0x4c, 0x33, 0xa0, 0xb0, 0x88, 0xcc, 0xbb, 0xa6, 0xd4, 0xd8, 0x67, 0xd0, 0xce, 0x70, 0xdb, 0x2d,
+	0xb3, 0x03, 0xa8, 0xbe, 0x4f, 0x38, 0x46, 0x69, 0xef, 0xb6, 0xc5, 0x2d, 0x91, 0xa8, 0x61, 0x0a,
+	0x99, 0xfd, 0x26, 0x70, 0xbf, 0xe7, 0x46, 0xfc, 0x34, 0x98, 0x4e, 0x03, 0xff, 0x22, 0xc4, 0x91,
+	0x7b, 0x89, 0x37, 0x1e, 0xc1, 0x03, 0x50, 0x6d, 0xf4, 0xdc, 0xa9, 0xcb, 0x31, 0x94, 0x10, 0x16,
+	0x06, 0x91, 0x4d, 0x14, 0xd0, 0x2b, 0x32, 0x9b, 0xd0, 0xd8, 0x31, 0x18, 0x65, 0x10, 0xe4, 0xb0,
+	0x0c, 0xd8, 0x9d, 0x49, 0x9b, 0x4e, 0x5a, 0x4a, 0x5b, 0x35, 0xe7, 0x3a, 0xfb, 0x0e, 0x34, 0x8d,
+	0xcc, 0x26, 0x76, 0x63, 0xd4, 0x0b, 0x5c, 0x4a, 0x01, 0xd7, 0x33, 0xd8, 0x2b, 0x64, 0x97, 0x80,
+	0x28, 0x54, 0x26, 0x98, 0x5c, 0x81, 0x11, 0x72, 0xfa, 0x84, 0x3e, 0xa0, 0x87, 0x1c, 0xb7, 0xbd,
+	0x3c, 0x0f, 0x9a, 0xa7, 0x21, 0x5a, 0x1c, 0xfb, 0xae, 0xe3, 0xa3, 0xfd, 0xc5, 0xec, 0x6d, 0xef,
+	0x16, 0x34, 0x50, 0x38, 0xf7, 0xc4, 0x32, 0x14, 0x33, 0x15, 0xd9, 0x73, 0xb8, 0xb7, 0x52, 0x4d,
+	0x76, 0xad, 0x81, 0x12, 0x87, 0x9e, 0xac, 0x95, 0x8a, 0xec, 0x1f, 0x81, 0x66, 0xee, 0x9e, 0x3f,
+	0xf9, 0xee, 0xc6, 0xbe, 0xbb, 0x50, 0x1b, 0x06, 0xfe, 0xc8, 0x75, 0xf4, 0x9d, 0x96, 0xd2, 0xae,
+	0x1f, 0xbd, 0xe8, 0xcc, 0xaf, 0xbf, 0x53, 0x9e, 0xaa, 0x73, 0x2a, 0xfc, 0xbb, 0x3e, 0x0f, 0x13,
+	0x53, 0x06, 0x1b, 0xaf, 0xa1, 0x9e, 0x33, 0x5f, 0x75, 0x46, 0x16, 0x9d, 0xed, 0x43, 0xf5, 0x97,
+	0xe5, 0xc5, 0x28, 0x47, 0x90, 0x29, 0x6f, 0x76, 0x8e, 0x09, 0xfb, 0x43, 0xe0, 0xee, 0xe2, 0xc6,
+	0x2c, 0xdf, 0xc1, 0xed, 0xcd, 0xb3, 0x09, 0xb5, 0x60, 0x34, 0x8a, 0x90, 0xcb, 0x91, 0x4a, 0x2d,
+	0xb5, 0x7b, 0xe8, 0x3b, 0x7c, 0xac, 0x57, 0x33, 0x7b, 0xa6, 0x1d, 0xfd, 0xad, 0x42, 0x3d, 0xd7,
+	0x35, 0x7d, 0x0b, 0x95, 0xb4, 0x73, 0xfa, 0x78, 0xe3, 0x54, 0x0c, 0x2d, 0xe7, 0xd2, 0x9d, 0xce,
+	0x78, 0x42, 0x4f, 0x40, 0x9d, 0xd3, 0x25, 0x3d, 0xc8, 0x7d, 0x5e, 0x26, 0xd1, 0xd5, 0xd8, 0x36,
+	0xa1, 0xe7, 0xd0, 0xc8, 0x33, 0x15, 0x3d, 0x5c, 0x81, 0x50, 0xe0, 0x46, 0xe3, 0xd1, 0xda, 0xef,
+	0xf2, 0xb9, 0x9c, 0x80, 0x7a, 0x86, 0x65, 0x70, 0xce, 0xf0, 0x1a, 0x38, 0x82, 0xa7, 0x5e, 0x12,
+	0x6a, 0x01, 0x5d, 0x65, 0x04, 0xfa, 0x24, 0xe7, 0xb9, 0x96, 0xb3, 0x8c, 0xa7, 0x1b, 0xbc, 0x24,
+	0xc0, 0x1e, 0xd4, 0x73, 0xc7, 0x4d, 0x1f, 0x2e, 0x45, 0x15, 0x29, 0xc5, 0x38, 0x5c, 0xf7, 0x59,
+	0x66, 0x7b, 0x07, 0x8d, 0xfc, 0xfd, 0x17, 0xe6, 0x57, 0x42, 0x0c, 0x25, 0xfb, 0xfb, 0x06, 0xb7,
+	0x97, 0x4e, 0xaf, 0xf0, 0x0e, 0xca, 0x49, 0xc0, 0x60, 0xd7, 0xb9, 0x48, 0x6c, 0x1f, 0xe1, 0x56,
+	0xf1, 0xc5, 0xd3, 0x56, 0xe9, 0x3e, 0x72, 0xc7, 0x50, 0xb6, 0x94, 0x41, 0x4d, 0xfc, 0x97, 0x5f,
+	0xfd, 0x1f, 0x00, 0xa1, 0xe9, 0x6c, 0x44, 0xc5, 0x07, 0x00, 0x00,
 }
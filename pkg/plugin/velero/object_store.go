@@ -19,6 +19,8 @@ package velero
 import (
 	"io"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // ObjectStore exposes basic object-storage operations required
@@ -63,3 +65,17 @@ type ObjectStore interface {
 	// CreateSignedURL creates a pre-signed URL for the given bucket and key that expires after ttl.
 	CreateSignedURL(bucket, key string, ttl time.Duration) (string, error)
 }
+
+// ErrObjectRangeNotSupported is returned by an ObjectStore's GetObjectRange
+// method if the object store doesn't support ranged reads.
+var ErrObjectRangeNotSupported = errors.New("object store does not support ranged reads")
+
+// ObjectRangeGetter is an optional interface that an ObjectStore can implement
+// to let Velero read parts of objects, e.g. to restore some of a backup's items
+// without downloading its whole tarball.
+type ObjectRangeGetter interface {
+	// GetObjectRange retrieves length bytes of the object with the given key,
+	// starting at offset. If the range extends past the end of the object,
+	// only the bytes up to its end are returned.
+	GetObjectRange(bucket, key string, offset, length int64) (io.ReadCloser, error)
+}
@@ -25,10 +25,12 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	"github.com/heptio/velero/pkg/label"
 	"github.com/heptio/velero/pkg/util/boolptr"
 )
@@ -113,7 +115,10 @@ func (b *backupper) BackupPodVolumes(backup *velerov1api.Backup, pod *corev1api.
 	b.repoManager.repoLocker.Lock(repo.Name)
 	defer b.repoManager.repoLocker.Unlock(repo.Name)
 
-	resultsChan := make(chan *velerov1api.PodVolumeBackup)
+	// the channel's buffered so that results that arrive after we've stopped
+	// waiting for them, e.g. because the backup was canceled, don't block the
+	// informer.
+	resultsChan := make(chan *velerov1api.PodVolumeBackup, len(volumesToBackup))
 
 	b.resultsLock.Lock()
 	b.results[resultsKey(pod.Namespace, pod.Name)] = resultsChan
@@ -123,6 +128,8 @@ func (b *backupper) BackupPodVolumes(backup *velerov1api.Backup, pod *corev1api.
 		errs             []error
 		podVolumeBackups []*velerov1api.PodVolumeBackup
 		podVolumes       = make(map[string]corev1api.Volume)
+		// pending holds the PodVolumeBackups that haven't finished yet, by name.
+		pending = make(map[string]*velerov1api.PodVolumeBackup)
 	)
 
 	// put the pod's volumes in a map for efficient lookup below
@@ -156,15 +163,23 @@ func (b *backupper) BackupPodVolumes(backup *velerov1api.Backup, pod *corev1api.
 			errs = append(errs, err)
 			continue
 		}
+		pending[volumeBackup.Name] = volumeBackup
 	}
 
 ForEachVolume:
 	for i, count := 0, numVolumeSnapshots; i < count; i++ {
 		select {
 		case <-b.ctx.Done():
-			errs = append(errs, errors.New("timed out waiting for all PodVolumeBackups to complete"))
+			if b.ctx.Err() == context.Canceled {
+				errs = append(errs, errors.New("backup was canceled while waiting for all PodVolumeBackups to complete"))
+				cancelPodVolumeBackups(b.repoManager.veleroClient.VeleroV1(), pending, log)
+			} else {
+				errs = append(errs, errors.New("timed out waiting for all PodVolumeBackups to complete"))
+			}
 			break ForEachVolume
 		case res := <-resultsChan:
+			delete(pending, res.Name)
+
 			switch res.Status.Phase {
 			case velerov1api.PodVolumeBackupPhaseCompleted:
 				if res.Status.SnapshotID == "" { // when the volume is empty there is no restic snapshot, so best to exclude it
@@ -185,6 +200,16 @@ ForEachVolume:
 	return podVolumeBackups, errs
 }
 
+// cancelPodVolumeBackups marks the given PodVolumeBackups as canceled, so that
+// the restic daemonset stops running them.
+func cancelPodVolumeBackups(client velerov1client.PodVolumeBackupsGetter, podVolumeBackups map[string]*velerov1api.PodVolumeBackup, log logrus.FieldLogger) {
+	for _, pvb := range podVolumeBackups {
+		if _, err := client.PodVolumeBackups(pvb.Namespace).Patch(pvb.Name, types.MergePatchType, cancelPatch); err != nil {
+			log.WithError(errors.WithStack(err)).Errorf("Error canceling pod volume backup %s/%s", pvb.Namespace, pvb.Name)
+		}
+	}
+}
+
 type pvcGetter interface {
 	Get(name string, opts metav1.GetOptions) (*corev1api.PersistentVolumeClaim, error)
 }
@@ -262,7 +287,3 @@ func newPodVolumeBackup(backup *velerov1api.Backup, pod *corev1api.Pod, volumeNa
 		},
 	}
 }
-
-func errorOnly(_ interface{}, err error) error {
-	return err
-}
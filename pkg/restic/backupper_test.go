@@ -21,10 +21,32 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	velerotest "github.com/heptio/velero/pkg/util/test"
 )
 
+func TestCancelPodVolumeBackups(t *testing.T) {
+	pending := builder.ForPodVolumeBackup(velerov1api.DefaultNamespace, "pvb-1").Phase(velerov1api.PodVolumeBackupPhaseInProgress).Result()
+	finished := builder.ForPodVolumeBackup(velerov1api.DefaultNamespace, "pvb-2").Phase(velerov1api.PodVolumeBackupPhaseCompleted).Result()
+	client := fake.NewSimpleClientset(pending, finished)
+
+	cancelPodVolumeBackups(client.VeleroV1(), map[string]*velerov1api.PodVolumeBackup{pending.Name: pending}, velerotest.NewLogger())
+
+	res, err := client.VeleroV1().PodVolumeBackups(pending.Namespace).Get(pending.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.Spec.Cancel)
+
+	res, err = client.VeleroV1().PodVolumeBackups(finished.Namespace).Get(finished.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, res.Spec.Cancel)
+}
+
 func TestIsHostPathVolume(t *testing.T) {
 	// hostPath pod volume
 	vol := &corev1api.Volume{
@@ -17,6 +17,7 @@ limitations under the License.
 package restic
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -72,8 +73,14 @@ func (c *Command) String() string {
 
 // Cmd returns an exec.Cmd for the command.
 func (c *Command) Cmd() *exec.Cmd {
+	return c.CmdContext(context.Background())
+}
+
+// CmdContext returns an exec.Cmd for the command that's killed if ctx is done
+// before it finishes.
+func (c *Command) CmdContext(ctx context.Context) *exec.Cmd {
 	parts := c.StringSlice()
-	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
 	cmd.Dir = c.Dir
 
 	if len(c.Env) > 0 {
@@ -91,6 +91,17 @@ func SnapshotsCommand(repoIdentifier string) *Command {
 	}
 }
 
+// GetSnapshotByIDCommand returns a command that lists the snapshot with the
+// given ID as JSON, or an empty list if it doesn't exist.
+func GetSnapshotByIDCommand(repoIdentifier, snapshotID string) *Command {
+	return &Command{
+		Command:        "snapshots",
+		RepoIdentifier: repoIdentifier,
+		Args:           []string{snapshotID},
+		ExtraFlags:     []string{"--json"},
+	}
+}
+
 func CheckCommand(repoIdentifier string) *Command {
 	return &Command{
 		Command:        "check",
@@ -17,6 +17,7 @@ limitations under the License.
 package restic
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -104,3 +105,19 @@ func TestCmd(t *testing.T) {
 	assert.Equal(t, c.StringSlice(), execCmd.Args)
 	assert.Equal(t, c.Dir, execCmd.Dir)
 }
+
+func TestCmdContext(t *testing.T) {
+	c := &Command{
+		Command: "cmd",
+		Dir:     "/some/pwd",
+		Args:    []string{"arg-1"},
+		Env:     []string{"FOO=bar"},
+	}
+
+	require.NoError(t, os.Unsetenv("VELERO_SCRATCH_DIR"))
+	execCmd := c.CmdContext(context.Background())
+
+	assert.Equal(t, c.StringSlice(), execCmd.Args)
+	assert.Equal(t, c.Dir, execCmd.Dir)
+	assert.Equal(t, c.Env, execCmd.Env)
+}
@@ -28,7 +28,9 @@ import (
 	corev1listers "k8s.io/client-go/listers/core/v1"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/cloudprovider"
 	"github.com/heptio/velero/pkg/cloudprovider/azure"
+	"github.com/heptio/velero/pkg/credentials"
 	velerov1listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
 	"github.com/heptio/velero/pkg/label"
 	"github.com/heptio/velero/pkg/util/filesystem"
@@ -45,6 +47,10 @@ const (
 	volumesToBackupAnnotation = "backup.velero.io/backup-volumes"
 )
 
+// cancelPatch is a merge patch that cancels a PodVolumeBackup or
+// PodVolumeRestore.
+var cancelPatch = []byte(`{"spec":{"cancel":true}}`)
+
 // GetPodSnapshotAnnotations returns a map, of volume name -> snapshot id,
 // of all restic snapshots for this pod.
 // Deprecated: we will stop using pod annotations to record restic snapshot IDs after they're taken.
@@ -178,24 +184,43 @@ func NewPodVolumeRestoreListOptions(name string) metav1.ListOptions {
 	}
 }
 
-// AzureCmdEnv returns a list of environment variables (in the format var=val) that
-// should be used when running a restic command for an Azure backend. This list is
-// the current environment, plus the Azure-specific variables restic needs, namely
-// a storage account name and key.
-func AzureCmdEnv(backupLocationLister velerov1listers.BackupStorageLocationLister, namespace, backupLocation string) ([]string, error) {
+// CmdEnv returns a list of environment variables (in the format var=val) that
+// should be used when running a restic command against a repository in backupLocation.
+// This list is the current environment, plus the path of the location's credentials
+// file if it has a credential, and for an Azure backend, the Azure-specific variables
+// restic needs, namely a storage account name and key.
+func CmdEnv(backupLocationLister velerov1listers.BackupStorageLocationLister, credentialFileStore credentials.FileStore, namespace, backupLocation string) ([]string, error) {
 	loc, err := backupLocationLister.BackupStorageLocations(namespace).Get(backupLocation)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting backup storage location")
 	}
 
-	azureVars, err := azure.GetResticEnvVars(loc.Spec.Config)
+	config, err := credentials.ConfigWithCredentialsFile(credentialFileStore, loc.Namespace, loc.Spec.Config, loc.Spec.Credential)
 	if err != nil {
-		return nil, errors.Wrap(err, "error getting azure restic env vars")
+		return nil, err
 	}
 
 	env := os.Environ()
-	for k, v := range azureVars {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	credentialsFile := config[cloudprovider.CredentialsFileKey]
+
+	switch getBackendType(loc.Spec.Provider) {
+	case AWSBackend:
+		if credentialsFile != "" {
+			env = append(env, fmt.Sprintf("%s=%s", awsCredentialsFileEnvVar, credentialsFile))
+		}
+	case AzureBackend:
+		azureVars, err := azure.GetResticEnvVars(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting azure restic env vars")
+		}
+
+		for k, v := range azureVars {
+			env = append(env, fmt.Sprintf("%s=%s", k, v))
+		}
+	case GCPBackend:
+		if credentialsFile != "" {
+			env = append(env, fmt.Sprintf("%s=%s", gcpCredentialsFileEnvVar, credentialsFile))
+		}
 	}
 
 	return env, nil
@@ -17,6 +17,7 @@ limitations under the License.
 package restic
 
 import (
+	"os"
 	"sort"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/velero/pkg/generated/informers/externalversions"
 	velerotest "github.com/heptio/velero/pkg/util/test"
@@ -328,3 +330,49 @@ func TestTempCredentialsFile(t *testing.T) {
 
 	assert.Equal(t, "passw0rd", string(contents))
 }
+
+type fakeCredentialFileStore map[string]string
+
+func (s fakeCredentialFileStore) Path(namespace string, selector *corev1api.SecretKeySelector) (string, error) {
+	return s[namespace+"/"+selector.Name+"/"+selector.Key], nil
+}
+
+func TestCmdEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *velerov1api.BackupStorageLocation
+		expected []string
+	}{
+		{
+			name:     "location without a credential uses the current environment",
+			location: builder.ForBackupStorageLocation("velero", "default").Provider("aws").Bucket("bucket").Result(),
+		},
+		{
+			name:     "aws location with a credential sets the shared credentials file",
+			location: builder.ForBackupStorageLocation("velero", "default").Provider("aws").Bucket("bucket").Credential("creds", "cloud").Result(),
+			expected: []string{"AWS_SHARED_CREDENTIALS_FILE=/credentials/velero/creds-cloud"},
+		},
+		{
+			name:     "gcp location with a credential sets the application credentials file",
+			location: builder.ForBackupStorageLocation("velero", "default").Provider("velero.io/gcp").Bucket("bucket").Credential("creds", "cloud").Result(),
+			expected: []string{"GOOGLE_APPLICATION_CREDENTIALS=/credentials/velero/creds-cloud"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				store           = fakeCredentialFileStore{"velero/creds/cloud": "/credentials/velero/creds-cloud"}
+			)
+
+			require.NoError(t, sharedInformers.Velero().V1().BackupStorageLocations().Informer().GetStore().Add(test.location))
+
+			env, err := CmdEnv(sharedInformers.Velero().V1().BackupStorageLocations().Lister(), store, "velero", "default")
+			require.NoError(t, err)
+
+			assert.Equal(t, append(os.Environ(), test.expected...), env)
+		})
+	}
+}
@@ -34,6 +34,13 @@ const (
 	GCPBackend   BackendType = "velero.io/gcp"
 )
 
+// the environment variables that point restic at the
+// credentials file for AWS and GCP backends.
+const (
+	awsCredentialsFileEnvVar = "AWS_SHARED_CREDENTIALS_FILE"
+	gcpCredentialsFileEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+)
+
 // this func is assigned to a package-level variable so it can be
 // replaced when unit-testing
 var getAWSBucketRegion = aws.GetBucketRegion
@@ -51,12 +58,7 @@ func getRepoPrefix(location *velerov1api.BackupStorageLocation) string {
 	}
 	bucketAndPrefix = path.Join(bucket, prefix)
 
-	var locationSpecProvider = location.Spec.Provider
-	if !strings.Contains(locationSpecProvider, "/") {
-		locationSpecProvider = "velero.io/" + locationSpecProvider
-	}
-
-	switch BackendType(locationSpecProvider) {
+	switch getBackendType(location.Spec.Provider) {
 	case AWSBackend:
 		var url string
 		switch {
@@ -83,6 +85,16 @@ func getRepoPrefix(location *velerov1api.BackupStorageLocation) string {
 	return fmt.Sprintf("%s:%s:/%s", provider, bucket, prefix)
 }
 
+// getBackendType returns the backend type for a backup storage
+// location's provider, which may omit the "velero.io/" prefix.
+func getBackendType(provider string) BackendType {
+	if !strings.Contains(provider, "/") {
+		provider = "velero.io/" + provider
+	}
+
+	return BackendType(provider)
+}
+
 // GetRepoIdentifier returns the string to be used as the value of the --repo flag in
 // restic commands for the given repository.
 func GetRepoIdentifier(location *velerov1api.BackupStorageLocation, name string) string {
@@ -18,9 +18,9 @@ package restic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -30,6 +30,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/credentials"
 	clientset "github.com/heptio/velero/pkg/generated/clientset/versioned"
 	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	velerov1informers "github.com/heptio/velero/pkg/generated/informers/externalversions/velero/v1"
@@ -62,6 +63,10 @@ type RepositoryManager interface {
 	// available snapshots in a repo.
 	Forget(context.Context, SnapshotIdentifier) error
 
+	// SnapshotExists returns whether a snapshot is in
+	// its repo.
+	SnapshotExists(context.Context, SnapshotIdentifier) (bool, error)
+
 	BackupperFactory
 
 	RestorerFactory
@@ -96,6 +101,7 @@ type repositoryManager struct {
 	ctx                          context.Context
 	pvcClient                    corev1client.PersistentVolumeClaimsGetter
 	pvClient                     corev1client.PersistentVolumesGetter
+	credentialFileStore          credentials.FileStore
 }
 
 // NewRepositoryManager constructs a RepositoryManager.
@@ -109,6 +115,7 @@ func NewRepositoryManager(
 	backupLocationInformer velerov1informers.BackupStorageLocationInformer,
 	pvcClient corev1client.PersistentVolumeClaimsGetter,
 	pvClient corev1client.PersistentVolumesGetter,
+	credentialFileStore credentials.FileStore,
 	log logrus.FieldLogger,
 ) (RepositoryManager, error) {
 	rm := &repositoryManager{
@@ -121,6 +128,7 @@ func NewRepositoryManager(
 		backupLocationInformerSynced: backupLocationInformer.Informer().HasSynced,
 		pvcClient:                    pvcClient,
 		pvClient:                     pvClient,
+		credentialFileStore:          credentialFileStore,
 		log:                          log,
 		ctx:                          ctx,
 
@@ -245,27 +253,60 @@ func (rm *repositoryManager) Forget(ctx context.Context, snapshot SnapshotIdenti
 	return rm.exec(ForgetCommand(repo.Spec.ResticIdentifier, snapshot.SnapshotID), repo.Spec.BackupStorageLocation)
 }
 
+func (rm *repositoryManager) SnapshotExists(ctx context.Context, snapshot SnapshotIdentifier) (bool, error) {
+	// see Forget for why we can't wait for this in the constructor.
+	if !cache.WaitForCacheSync(ctx.Done(), rm.repoInformerSynced) {
+		return false, errors.New("timed out waiting for cache to sync")
+	}
+
+	repo, err := rm.repoEnsurer.EnsureRepo(ctx, rm.namespace, snapshot.VolumeNamespace, snapshot.BackupStorageLocation)
+	if err != nil {
+		return false, err
+	}
+
+	// restic snapshots requires a non-exclusive lock
+	rm.repoLocker.Lock(repo.Name)
+	defer rm.repoLocker.Unlock(repo.Name)
+
+	stdout, err := rm.execWithOutput(GetSnapshotByIDCommand(repo.Spec.ResticIdentifier, snapshot.SnapshotID), repo.Spec.BackupStorageLocation)
+	if err != nil {
+		return false, err
+	}
+
+	// restic ignores IDs that don't match any snapshots, so a snapshot that
+	// doesn't exist results in an empty list.
+	var snapshots []json.RawMessage
+	if err := json.Unmarshal([]byte(stdout), &snapshots); err != nil {
+		return false, errors.Wrap(err, "error unmarshalling restic snapshots result")
+	}
+
+	return len(snapshots) > 0, nil
+}
+
 func (rm *repositoryManager) exec(cmd *Command, backupLocation string) error {
+	_, err := rm.execWithOutput(cmd, backupLocation)
+	return err
+}
+
+func (rm *repositoryManager) execWithOutput(cmd *Command, backupLocation string) (string, error) {
 	file, err := TempCredentialsFile(rm.secretsLister, rm.namespace, cmd.RepoName(), rm.fileSystem)
 	if err != nil {
-		return err
+		return "", err
 	}
 	// ignore error since there's nothing we can do and it's a temp file.
 	defer os.Remove(file)
 
 	cmd.PasswordFile = file
 
-	if strings.HasPrefix(cmd.RepoIdentifier, "azure") {
-		if !cache.WaitForCacheSync(rm.ctx.Done(), rm.backupLocationInformerSynced) {
-			return errors.New("timed out waiting for cache to sync")
-		}
+	if !cache.WaitForCacheSync(rm.ctx.Done(), rm.backupLocationInformerSynced) {
+		return "", errors.New("timed out waiting for cache to sync")
+	}
 
-		env, err := AzureCmdEnv(rm.backupLocationLister, rm.namespace, backupLocation)
-		if err != nil {
-			return err
-		}
-		cmd.Env = env
+	env, err := CmdEnv(rm.backupLocationLister, rm.credentialFileStore, rm.namespace, backupLocation)
+	if err != nil {
+		return "", err
 	}
+	cmd.Env = env
 
 	stdout, stderr, err := veleroexec.RunCommand(cmd.Cmd())
 	rm.log.WithFields(logrus.Fields{
@@ -275,8 +316,8 @@ func (rm *repositoryManager) exec(cmd *Command, backupLocation string) error {
 		"stderr":     stderr,
 	}).Debugf("Ran restic command")
 	if err != nil {
-		return errors.Wrapf(err, "error running command=%s, stdout=%s, stderr=%s", cmd.String(), stdout, stderr)
+		return "", errors.Wrapf(err, "error running command=%s, stdout=%s, stderr=%s", cmd.String(), stdout, stderr)
 	}
 
-	return nil
+	return stdout, nil
 }
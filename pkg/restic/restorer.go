@@ -24,9 +24,11 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	"github.com/heptio/velero/pkg/label"
 	"github.com/heptio/velero/pkg/util/boolptr"
 )
@@ -101,7 +103,10 @@ func (r *restorer) RestorePodVolumes(restore *velerov1api.Restore, pod *corev1ap
 	r.repoManager.repoLocker.Lock(repo.Name)
 	defer r.repoManager.repoLocker.Unlock(repo.Name)
 
-	resultsChan := make(chan *velerov1api.PodVolumeRestore)
+	// the channel's buffered so that results that arrive after we've stopped
+	// waiting for them, e.g. because the restore was canceled, don't block the
+	// informer.
+	resultsChan := make(chan *velerov1api.PodVolumeRestore, len(volumesToRestore))
 
 	r.resultsLock.Lock()
 	r.results[resultsKey(pod.Namespace, pod.Name)] = resultsChan
@@ -110,15 +115,19 @@ func (r *restorer) RestorePodVolumes(restore *velerov1api.Restore, pod *corev1ap
 	var (
 		errs        []error
 		numRestores int
+		// pending holds the PodVolumeRestores that haven't finished yet, by name.
+		pending = make(map[string]*velerov1api.PodVolumeRestore)
 	)
 
 	for volume, snapshot := range volumesToRestore {
 		volumeRestore := newPodVolumeRestore(restore, pod, volume, snapshot, backupLocation, repo.Spec.ResticIdentifier)
 
-		if err := errorOnly(r.repoManager.veleroClient.VeleroV1().PodVolumeRestores(volumeRestore.Namespace).Create(volumeRestore)); err != nil {
+		volumeRestore, err := r.repoManager.veleroClient.VeleroV1().PodVolumeRestores(volumeRestore.Namespace).Create(volumeRestore)
+		if err != nil {
 			errs = append(errs, errors.WithStack(err))
 			continue
 		}
+		pending[volumeRestore.Name] = volumeRestore
 		numRestores++
 	}
 
@@ -126,9 +135,16 @@ ForEachVolume:
 	for i := 0; i < numRestores; i++ {
 		select {
 		case <-r.ctx.Done():
-			errs = append(errs, errors.New("timed out waiting for all PodVolumeRestores to complete"))
+			if r.ctx.Err() == context.Canceled {
+				errs = append(errs, errors.New("restore was canceled while waiting for all PodVolumeRestores to complete"))
+				cancelPodVolumeRestores(r.repoManager.veleroClient.VeleroV1(), pending, log)
+			} else {
+				errs = append(errs, errors.New("timed out waiting for all PodVolumeRestores to complete"))
+			}
 			break ForEachVolume
 		case res := <-resultsChan:
+			delete(pending, res.Name)
+
 			if res.Status.Phase == velerov1api.PodVolumeRestorePhaseFailed {
 				errs = append(errs, errors.Errorf("pod volume restore failed: %s", res.Status.Message))
 			}
@@ -142,6 +158,16 @@ ForEachVolume:
 	return errs
 }
 
+// cancelPodVolumeRestores marks the given PodVolumeRestores as canceled, so that
+// the restic daemonset stops running them.
+func cancelPodVolumeRestores(client velerov1client.PodVolumeRestoresGetter, podVolumeRestores map[string]*velerov1api.PodVolumeRestore, log logrus.FieldLogger) {
+	for _, pvr := range podVolumeRestores {
+		if _, err := client.PodVolumeRestores(pvr.Namespace).Patch(pvr.Name, types.MergePatchType, cancelPatch); err != nil {
+			log.WithError(errors.WithStack(err)).Errorf("Error canceling pod volume restore %s/%s", pvr.Namespace, pvr.Name)
+		}
+	}
+}
+
 func newPodVolumeRestore(restore *velerov1api.Restore, pod *corev1api.Pod, volume, snapshot, backupLocation, repoIdentifier string) *velerov1api.PodVolumeRestore {
 	return &velerov1api.PodVolumeRestore{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestCancelPodVolumeRestores(t *testing.T) {
+	newPVR := func(name string, phase velerov1api.PodVolumeRestorePhase) *velerov1api.PodVolumeRestore {
+		return &velerov1api.PodVolumeRestore{
+			ObjectMeta: metav1.ObjectMeta{Namespace: velerov1api.DefaultNamespace, Name: name},
+			Status:     velerov1api.PodVolumeRestoreStatus{Phase: phase},
+		}
+	}
+
+	pending := newPVR("pvr-1", velerov1api.PodVolumeRestorePhaseInProgress)
+	finished := newPVR("pvr-2", velerov1api.PodVolumeRestorePhaseCompleted)
+	client := fake.NewSimpleClientset(pending, finished)
+
+	cancelPodVolumeRestores(client.VeleroV1(), map[string]*velerov1api.PodVolumeRestore{pending.Name: pending}, velerotest.NewLogger())
+
+	res, err := client.VeleroV1().PodVolumeRestores(pending.Namespace).Get(pending.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.Spec.Cancel)
+
+	res, err = client.VeleroV1().PodVolumeRestores(finished.Namespace).Get(finished.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, res.Spec.Cancel)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/velero/pkg/kuberesource"
+	"github.com/heptio/velero/pkg/plugin/velero"
+)
+
+// AddPodDependenciesAction adds the ServiceAccount, ConfigMaps, Secrets and
+// PersistentVolumeClaims used by a pod, or by the pod template of a workload,
+// as additional items to restore. It only does so for restores that are
+// limited to individual items, so that restoring an item brings back what it
+// needs to run.
+type AddPodDependenciesAction struct {
+	logger logrus.FieldLogger
+}
+
+func NewAddPodDependenciesAction(logger logrus.FieldLogger) *AddPodDependenciesAction {
+	return &AddPodDependenciesAction{logger: logger}
+}
+
+func (a *AddPodDependenciesAction) AppliesTo() (velero.ResourceSelector, error) {
+	return velero.ResourceSelector{
+		IncludedResources: []string{
+			"pods",
+			"replicationcontrollers",
+			"deployments.apps",
+			"replicasets.apps",
+			"statefulsets.apps",
+			"daemonsets.apps",
+			"jobs.batch",
+			"cronjobs.batch",
+		},
+	}, nil
+}
+
+func (a *AddPodDependenciesAction) Execute(input *velero.RestoreItemActionExecuteInput) (*velero.RestoreItemActionExecuteOutput, error) {
+	output := &velero.RestoreItemActionExecuteOutput{
+		UpdatedItem: input.Item,
+	}
+
+	if len(input.Restore.Spec.IncludedItems) == 0 {
+		return output, nil
+	}
+
+	a.logger.Info("Executing AddPodDependenciesAction")
+
+	item, ok := input.Item.(*unstructured.Unstructured)
+	if !ok {
+		return nil, errors.Errorf("unexpected type %T", input.Item)
+	}
+
+	podSpec, err := getPodSpec(item)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := item.GetNamespace()
+	seen := make(map[velero.ResourceIdentifier]bool)
+	add := func(groupResource schema.GroupResource, name string) {
+		id := velero.ResourceIdentifier{GroupResource: groupResource, Namespace: namespace, Name: name}
+		if name == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+
+		a.logger.Infof("Adding %s %s/%s as an additional item to restore", groupResource.String(), namespace, name)
+		output.AdditionalItems = append(output.AdditionalItems, id)
+	}
+
+	add(kuberesource.ServiceAccounts, podSpec.ServiceAccountName)
+
+	for _, secret := range podSpec.ImagePullSecrets {
+		add(kuberesource.Secrets, secret.Name)
+	}
+
+	for _, volume := range podSpec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			add(kuberesource.ConfigMaps, volume.ConfigMap.Name)
+		case volume.Secret != nil:
+			add(kuberesource.Secrets, volume.Secret.SecretName)
+		case volume.PersistentVolumeClaim != nil:
+			add(kuberesource.PersistentVolumeClaims, volume.PersistentVolumeClaim.ClaimName)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add(kuberesource.ConfigMaps, source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					add(kuberesource.Secrets, source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1api.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add(kuberesource.ConfigMaps, envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				add(kuberesource.Secrets, envFrom.SecretRef.Name)
+			}
+		}
+
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add(kuberesource.ConfigMaps, env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add(kuberesource.Secrets, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	// a StatefulSet's PVCs are created from its volume claim templates, and are
+	// named <template>-<statefulset>-<ordinal>.
+	if item.GetKind() == "StatefulSet" {
+		templates, _, err := unstructured.NestedSlice(item.Object, "spec", "volumeClaimTemplates")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		spec, _ := item.Object["spec"].(map[string]interface{})
+		replicas := int64(1)
+		switch val := spec["replicas"].(type) {
+		case int64:
+			replicas = val
+		case float64:
+			replicas = int64(val)
+		}
+
+		for _, template := range templates {
+			templateName, _, _ := unstructured.NestedString(template.(map[string]interface{}), "metadata", "name")
+			if templateName == "" {
+				continue
+			}
+			for i := int64(0); i < replicas; i++ {
+				add(kuberesource.PersistentVolumeClaims, fmt.Sprintf("%s-%s-%d", templateName, item.GetName(), i))
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// getPodSpec returns the spec of a pod, or of the pod template of a workload.
+func getPodSpec(item *unstructured.Unstructured) (*corev1api.PodSpec, error) {
+	var fields []string
+	switch item.GetKind() {
+	case "Pod":
+		fields = []string{"spec"}
+	case "CronJob":
+		fields = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		fields = []string{"spec", "template", "spec"}
+	}
+
+	obj, found, err := unstructured.NestedMap(item.Object, fields...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	podSpec := new(corev1api.PodSpec)
+	if !found {
+		return podSpec, nil
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, podSpec); err != nil {
+		return nil, errors.Wrap(err, "unable to convert pod spec")
+	}
+
+	return podSpec, nil
+}
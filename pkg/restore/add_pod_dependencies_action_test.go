@@ -0,0 +1,165 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1api "k8s.io/api/apps/v1"
+	batchv1api "k8s.io/api/batch/v1"
+	batchv1beta1api "k8s.io/api/batch/v1beta1"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/kuberesource"
+	"github.com/heptio/velero/pkg/plugin/velero"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestAddPodDependenciesActionExecute(t *testing.T) {
+	podSpec := corev1api.PodSpec{
+		ServiceAccountName: "sa-1",
+		ImagePullSecrets:   []corev1api.LocalObjectReference{{Name: "pull-secret"}},
+		Volumes: []corev1api.Volume{
+			{VolumeSource: corev1api.VolumeSource{EmptyDir: new(corev1api.EmptyDirVolumeSource)}},
+			{VolumeSource: corev1api.VolumeSource{ConfigMap: &corev1api.ConfigMapVolumeSource{LocalObjectReference: corev1api.LocalObjectReference{Name: "cm-1"}}}},
+			{VolumeSource: corev1api.VolumeSource{Secret: &corev1api.SecretVolumeSource{SecretName: "secret-1"}}},
+			{VolumeSource: corev1api.VolumeSource{PersistentVolumeClaim: &corev1api.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-1"}}},
+			{VolumeSource: corev1api.VolumeSource{Projected: &corev1api.ProjectedVolumeSource{Sources: []corev1api.VolumeProjection{
+				{ConfigMap: &corev1api.ConfigMapProjection{LocalObjectReference: corev1api.LocalObjectReference{Name: "cm-2"}}},
+				{Secret: &corev1api.SecretProjection{LocalObjectReference: corev1api.LocalObjectReference{Name: "secret-2"}}},
+			}}}},
+		},
+		InitContainers: []corev1api.Container{
+			{EnvFrom: []corev1api.EnvFromSource{{ConfigMapRef: &corev1api.ConfigMapEnvSource{LocalObjectReference: corev1api.LocalObjectReference{Name: "cm-3"}}}}},
+		},
+		Containers: []corev1api.Container{
+			{
+				EnvFrom: []corev1api.EnvFromSource{{SecretRef: &corev1api.SecretEnvSource{LocalObjectReference: corev1api.LocalObjectReference{Name: "secret-3"}}}},
+				Env: []corev1api.EnvVar{
+					{Name: "A", Value: "a"},
+					{Name: "B", ValueFrom: &corev1api.EnvVarSource{ConfigMapKeyRef: &corev1api.ConfigMapKeySelector{LocalObjectReference: corev1api.LocalObjectReference{Name: "cm-1"}, Key: "b"}}},
+					{Name: "C", ValueFrom: &corev1api.EnvVarSource{SecretKeyRef: &corev1api.SecretKeySelector{LocalObjectReference: corev1api.LocalObjectReference{Name: "secret-4"}, Key: "c"}}},
+				},
+			},
+		},
+	}
+
+	podSpecDependencies := []velero.ResourceIdentifier{
+		{GroupResource: kuberesource.ServiceAccounts, Namespace: "ns-1", Name: "sa-1"},
+		{GroupResource: kuberesource.Secrets, Namespace: "ns-1", Name: "pull-secret"},
+		{GroupResource: kuberesource.ConfigMaps, Namespace: "ns-1", Name: "cm-1"},
+		{GroupResource: kuberesource.Secrets, Namespace: "ns-1", Name: "secret-1"},
+		{GroupResource: kuberesource.PersistentVolumeClaims, Namespace: "ns-1", Name: "pvc-1"},
+		{GroupResource: kuberesource.ConfigMaps, Namespace: "ns-1", Name: "cm-2"},
+		{GroupResource: kuberesource.Secrets, Namespace: "ns-1", Name: "secret-2"},
+		{GroupResource: kuberesource.ConfigMaps, Namespace: "ns-1", Name: "cm-3"},
+		{GroupResource: kuberesource.Secrets, Namespace: "ns-1", Name: "secret-3"},
+		{GroupResource: kuberesource.Secrets, Namespace: "ns-1", Name: "secret-4"},
+	}
+
+	objectMeta := metav1.ObjectMeta{Namespace: "ns-1", Name: "item-1"}
+	replicas := int32(2)
+
+	tests := []struct {
+		name    string
+		item    runtime.Object
+		restore *builder.RestoreBuilder
+		want    []velero.ResourceIdentifier
+	}{
+		{
+			name:    "restores that aren't limited to individual items get no additional items",
+			item:    &corev1api.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}, ObjectMeta: objectMeta, Spec: podSpec},
+			restore: builder.ForRestore("velero", "restore-1"),
+			want:    nil,
+		},
+		{
+			name:    "a pod's dependencies are additional items",
+			item:    &corev1api.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}, ObjectMeta: objectMeta, Spec: podSpec},
+			restore: builder.ForRestore("velero", "restore-1").IncludedItems("pods/ns-1/item-1"),
+			want:    podSpecDependencies,
+		},
+		{
+			name: "a deployment's pod template dependencies are additional items",
+			item: &appsv1api.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: objectMeta,
+				Spec:       appsv1api.DeploymentSpec{Template: corev1api.PodTemplateSpec{Spec: podSpec}},
+			},
+			restore: builder.ForRestore("velero", "restore-1").IncludedItems("deployments/ns-1/item-1"),
+			want:    podSpecDependencies,
+		},
+		{
+			name: "a cron job's pod template dependencies are additional items",
+			item: &batchv1beta1api.CronJob{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+				ObjectMeta: objectMeta,
+				Spec: batchv1beta1api.CronJobSpec{JobTemplate: batchv1beta1api.JobTemplateSpec{
+					Spec: batchv1api.JobSpec{Template: corev1api.PodTemplateSpec{Spec: podSpec}},
+				}},
+			},
+			restore: builder.ForRestore("velero", "restore-1").IncludedItems("cronjobs/ns-1/item-1"),
+			want:    podSpecDependencies,
+		},
+		{
+			name: "a stateful set's PVCs are additional items",
+			item: &appsv1api.StatefulSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+				ObjectMeta: objectMeta,
+				Spec: appsv1api.StatefulSetSpec{
+					Replicas: &replicas,
+					VolumeClaimTemplates: []corev1api.PersistentVolumeClaim{
+						{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+					},
+				},
+			},
+			restore: builder.ForRestore("velero", "restore-1").IncludedItems("statefulsets/ns-1/item-1"),
+			want: []velero.ResourceIdentifier{
+				{GroupResource: kuberesource.PersistentVolumeClaims, Namespace: "ns-1", Name: "data-item-1-0"},
+				{GroupResource: kuberesource.PersistentVolumeClaims, Namespace: "ns-1", Name: "data-item-1-1"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tc.item)
+			require.NoError(t, err)
+			// decode from JSON like restores do, so that numbers are int64s
+			item := new(unstructured.Unstructured)
+			data, err := (&unstructured.Unstructured{Object: content}).MarshalJSON()
+			require.NoError(t, err)
+			require.NoError(t, item.UnmarshalJSON(data))
+
+			action := NewAddPodDependenciesAction(velerotest.NewLogger())
+			res, err := action.Execute(&velero.RestoreItemActionExecuteInput{
+				Item:           item,
+				ItemFromBackup: item,
+				Restore:        tc.restore.Result(),
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, res.AdditionalItems)
+			assert.Equal(t, item, res.UpdatedItem)
+		})
+	}
+}
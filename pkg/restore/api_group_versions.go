@@ -0,0 +1,105 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// resolveResourceDir returns the directory containing the cluster-scoped or
+// namespaced items of the resource stored in resourcePath. If the backup contains
+// multiple API versions of the resource, this is the directory of the version
+// that's best supported by the cluster being restored into. Otherwise, it's
+// resourcePath itself.
+func (ctx *context) resolveResourceDir(resource, resourcePath string) (string, error) {
+	entries, err := ctx.fileSystem.ReadDir(resourcePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "error reading %q resource directory", resource)
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// backups that don't include all API group versions, including all
+		// backups older than version 2, store items directly in these directories.
+		if entry.Name() == api.ClusterScopedDir || entry.Name() == api.NamespaceScopedDir {
+			return resourcePath, nil
+		}
+
+		versions = append(versions, entry.Name())
+	}
+
+	if len(versions) == 0 {
+		return resourcePath, nil
+	}
+
+	var apiGroups []metav1.APIGroup
+	if ctx.discoveryHelper != nil {
+		apiGroups = ctx.discoveryHelper.APIGroups()
+	}
+
+	version := chooseAPIVersion(schema.ParseGroupResource(resource).Group, versions, apiGroups)
+	ctx.log.Infof("Restoring resource '%s' from API version %s", resource, version)
+
+	return filepath.Join(resourcePath, version), nil
+}
+
+// chooseAPIVersion returns the version, out of the backed-up versions of a resource
+// in the provided API group, to restore from. The cluster's preferred version for the
+// group is used if it was backed up, followed by the other versions the cluster serves
+// in order of preference. If the cluster serves none of them, the highest-priority
+// backed-up version is used.
+func chooseAPIVersion(group string, backedUpVersions []string, apiGroups []metav1.APIGroup) string {
+	backedUp := make(map[string]bool)
+	for _, v := range backedUpVersions {
+		backedUp[v] = true
+	}
+
+	for _, apiGroup := range apiGroups {
+		if apiGroup.Name != group {
+			continue
+		}
+
+		if backedUp[apiGroup.PreferredVersion.Version] {
+			return apiGroup.PreferredVersion.Version
+		}
+
+		for _, served := range apiGroup.Versions {
+			if backedUp[served.Version] {
+				return served.Version
+			}
+		}
+	}
+
+	versions := append([]string(nil), backedUpVersions...)
+	sort.Slice(versions, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(versions[i], versions[j]) > 0
+	})
+
+	return versions[0]
+}
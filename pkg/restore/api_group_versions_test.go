@@ -0,0 +1,131 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestChooseAPIVersion(t *testing.T) {
+	appsGroup := metav1.APIGroup{
+		Name: "apps",
+		Versions: []metav1.GroupVersionForDiscovery{
+			{GroupVersion: "apps/v1", Version: "v1"},
+			{GroupVersion: "apps/v1beta2", Version: "v1beta2"},
+			{GroupVersion: "apps/v1beta1", Version: "v1beta1"},
+		},
+		PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"},
+	}
+
+	tests := []struct {
+		name             string
+		group            string
+		backedUpVersions []string
+		apiGroups        []metav1.APIGroup
+		want             string
+	}{
+		{
+			name:             "cluster's preferred version is used when it was backed up",
+			group:            "apps",
+			backedUpVersions: []string{"v1beta1", "v1"},
+			apiGroups:        []metav1.APIGroup{appsGroup},
+			want:             "v1",
+		},
+		{
+			name:             "cluster's most preferred served version is used when its preferred version wasn't backed up",
+			group:            "apps",
+			backedUpVersions: []string{"v1beta1", "v1beta2", "v2"},
+			apiGroups:        []metav1.APIGroup{appsGroup},
+			want:             "v1beta2",
+		},
+		{
+			name:             "highest priority backed-up version is used when the cluster doesn't serve any of them",
+			group:            "apps",
+			backedUpVersions: []string{"v2alpha1", "v1beta1", "v1"},
+			apiGroups:        []metav1.APIGroup{{Name: "apps"}},
+			want:             "v1",
+		},
+		{
+			name:             "highest priority backed-up version is used when the cluster doesn't serve the group",
+			group:            "example.com",
+			backedUpVersions: []string{"v1beta1", "v2beta1", "v1"},
+			apiGroups:        []metav1.APIGroup{appsGroup},
+			want:             "v1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, chooseAPIVersion(tc.group, tc.backedUpVersions, tc.apiGroups))
+		})
+	}
+}
+
+func TestResolveResourceDir(t *testing.T) {
+	discoveryHelper := &velerotest.FakeDiscoveryHelper{
+		APIGroupsList: []metav1.APIGroup{
+			{
+				Name:             "apps",
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "apps/v1beta1", Version: "v1beta1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1beta1", Version: "v1beta1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		dirs     []string
+		want     string
+	}{
+		{
+			name:     "backup without API group versions uses the resource directory",
+			resource: "deployments.apps",
+			dirs:     []string{"/backup/resources/deployments.apps/namespaces/ns-1"},
+			want:     "/backup/resources/deployments.apps",
+		},
+		{
+			name:     "backup with API group versions uses the directory of the version the cluster supports",
+			resource: "deployments.apps",
+			dirs: []string{
+				"/backup/resources/deployments.apps/v1/namespaces/ns-1",
+				"/backup/resources/deployments.apps/v1beta1/namespaces/ns-1",
+			},
+			want: "/backup/resources/deployments.apps/v1beta1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &context{
+				fileSystem:      velerotest.NewFakeFileSystem().WithDirectories(tc.dirs...),
+				discoveryHelper: discoveryHelper,
+				log:             velerotest.NewLogger(),
+			}
+
+			res, err := ctx.resolveResourceDir(tc.resource, "/backup/resources/"+tc.resource)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
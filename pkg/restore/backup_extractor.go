@@ -18,12 +18,12 @@ package restore
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"io"
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/util/filesystem"
 )
 
@@ -34,16 +34,17 @@ type backupExtractor struct {
 	fileSystem filesystem.Interface
 }
 
-// unzipAndExtractBackup extracts a reader on a gzipped tarball to a local temp directory
-func (e *backupExtractor) unzipAndExtractBackup(src io.Reader) (string, error) {
-	gzr, err := gzip.NewReader(src)
+// unzipAndExtractBackup extracts a reader on a tarball compressed with codec
+// to a local temp directory
+func (e *backupExtractor) unzipAndExtractBackup(src io.Reader, codec archive.Codec) (string, error) {
+	r, err := archive.NewDecompressingReader(src, codec)
 	if err != nil {
-		e.log.Infof("error creating gzip reader: %v", err)
+		e.log.Infof("error creating decompressing reader: %v", err)
 		return "", err
 	}
-	defer gzr.Close()
+	defer r.Close()
 
-	return e.readBackup(tar.NewReader(gzr))
+	return e.readBackup(tar.NewReader(r))
 }
 
 func (e *backupExtractor) writeFile(target string, tarRdr *tar.Reader) error {
@@ -0,0 +1,195 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/kuberesource"
+	"github.com/heptio/velero/pkg/label"
+)
+
+// loadCSISnapshots records the CSI VolumeSnapshots that were created for the backup's
+// PersistentVolumeClaims, keyed by the claims' namespace/name.
+func (ctx *context) loadCSISnapshots() error {
+	ctx.csiSnapshots = make(map[string]string)
+
+	resourceDir, ok := ctx.resourceDirs[kuberesource.VolumeSnapshots.String()]
+	if !ok {
+		return nil
+	}
+
+	nsSubDir := filepath.Join(resourceDir, api.NamespaceScopedDir)
+	exists, err := ctx.fileSystem.DirExists(nsSubDir)
+	if err != nil || !exists {
+		return err
+	}
+
+	nsDirs, err := ctx.fileSystem.ReadDir(nsSubDir)
+	if err != nil {
+		return errors.Wrap(err, "error reading VolumeSnapshots directory")
+	}
+
+	backupLabel := label.GetValidName(ctx.backup.Name)
+
+	for _, nsDir := range nsDirs {
+		if !nsDir.IsDir() {
+			continue
+		}
+
+		files, err := ctx.fileSystem.ReadDir(filepath.Join(nsSubDir, nsDir.Name()))
+		if err != nil {
+			return errors.Wrap(err, "error reading VolumeSnapshots directory")
+		}
+
+		for _, file := range files {
+			snapshot, err := ctx.unmarshal(filepath.Join(nsSubDir, nsDir.Name(), file.Name()))
+			if err != nil {
+				return err
+			}
+
+			// only VolumeSnapshots created by Velero for this backup are used
+			// to restore claims.
+			if snapshot.GetLabels()[api.BackupNameLabel] != backupLabel {
+				continue
+			}
+
+			claimName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+			if claimName == "" {
+				continue
+			}
+
+			ctx.csiSnapshots[fmt.Sprintf("%s/%s", snapshot.GetNamespace(), claimName)] = snapshot.GetName()
+		}
+	}
+
+	return nil
+}
+
+// csiSnapshotFor returns the name of the CSI VolumeSnapshot taken of the backed-up
+// PersistentVolumeClaim, if any.
+func (ctx *context) csiSnapshotFor(namespace, claimName string) (string, bool) {
+	name, ok := ctx.csiSnapshots[fmt.Sprintf("%s/%s", namespace, claimName)]
+	return name, ok
+}
+
+// restoreCSISnapshot restores the CSI VolumeSnapshot with the provided name from the
+// backup, along with its VolumeSnapshotContent, so that a PersistentVolumeClaim can be
+// provisioned from it.
+func (ctx *context) restoreCSISnapshot(backupNamespace, name, namespace string) (Result, Result) {
+	warnings, errs := Result{}, Result{}
+
+	snapshot, err := ctx.unmarshal(ctx.itemFilePath(kuberesource.VolumeSnapshots.String(), backupNamespace, name))
+	if err != nil {
+		addToResult(&errs, namespace, errors.Wrapf(err, "error reading CSI VolumeSnapshot %s/%s from backup", backupNamespace, name))
+		return warnings, errs
+	}
+
+	contentName, _, _ := unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+	content, err := ctx.unmarshal(ctx.itemFilePath(kuberesource.VolumeSnapshotContents.String(), "", contentName))
+	if err != nil {
+		addToResult(&errs, namespace, errors.Wrapf(err, "error reading CSI VolumeSnapshotContent %s from backup", contentName))
+		return warnings, errs
+	}
+
+	w, e := ctx.restoreItem(content, kuberesource.VolumeSnapshotContents, "")
+	merge(&warnings, &w)
+	merge(&errs, &e)
+
+	w, e = ctx.restoreItem(snapshot, kuberesource.VolumeSnapshots, namespace)
+	merge(&warnings, &w)
+	merge(&errs, &e)
+
+	return warnings, errs
+}
+
+// restoredSnapshotContentName returns the name a backed-up VolumeSnapshotContent is
+// restored with. Contents are renamed so that restoring into the cluster they were
+// backed up from doesn't conflict with the original, retained content.
+func restoredSnapshotContentName(name, restoreName string) string {
+	return label.GetValidName(fmt.Sprintf("%s-%s", name, restoreName))
+}
+
+// prepareVolumeSnapshotContent converts a backed-up VolumeSnapshotContent into a
+// pre-provisioned one that refers to the existing snapshot by its handle, and is bound
+// to the VolumeSnapshot being restored.
+func prepareVolumeSnapshotContent(obj *unstructured.Unstructured, restore *api.Restore) error {
+	handle, _, _ := unstructured.NestedString(obj.Object, "status", "snapshotHandle")
+	if handle == "" {
+		return errors.Errorf("VolumeSnapshotContent %s has no snapshot handle", obj.GetName())
+	}
+
+	if err := unstructured.SetNestedField(obj.Object, map[string]interface{}{"snapshotHandle": handle}, "spec", "source"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the snapshot belongs to the backup, so it must outlive the restored objects.
+	if err := unstructured.SetNestedField(obj.Object, "Retain", "spec", "deletionPolicy"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "spec", "volumeSnapshotRef", "uid")
+	unstructured.RemoveNestedField(obj.Object, "spec", "volumeSnapshotRef", "resourceVersion")
+
+	if ns, _, _ := unstructured.NestedString(obj.Object, "spec", "volumeSnapshotRef", "namespace"); ns != "" {
+		if target, ok := restore.Spec.NamespaceMapping[ns]; ok {
+			if err := unstructured.SetNestedField(obj.Object, target, "spec", "volumeSnapshotRef", "namespace"); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	obj.SetName(restoredSnapshotContentName(obj.GetName(), restore.Name))
+	return nil
+}
+
+// prepareVolumeSnapshot converts a backed-up VolumeSnapshot into one that's bound to its
+// restored, pre-provisioned VolumeSnapshotContent rather than taking a new snapshot.
+func prepareVolumeSnapshot(obj *unstructured.Unstructured, restore *api.Restore) error {
+	contentName, _, _ := unstructured.NestedString(obj.Object, "status", "boundVolumeSnapshotContentName")
+	if contentName == "" {
+		return nil
+	}
+
+	source := map[string]interface{}{
+		"volumeSnapshotContentName": restoredSnapshotContentName(contentName, restore.Name),
+	}
+	return errors.WithStack(unstructured.SetNestedField(obj.Object, source, "spec", "source"))
+}
+
+// setCSISnapshotDataSource resets the PersistentVolumeClaim for dynamic provisioning
+// from the CSI VolumeSnapshot with the provided name.
+func setCSISnapshotDataSource(obj *unstructured.Unstructured, snapshotName string) error {
+	unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, "pv.kubernetes.io/bind-completed")
+	delete(annotations, "pv.kubernetes.io/bound-by-controller")
+	obj.SetAnnotations(annotations)
+
+	dataSource := map[string]interface{}{
+		"apiGroup": kuberesource.VolumeSnapshots.Group,
+		"kind":     "VolumeSnapshot",
+		"name":     snapshotName,
+	}
+	return errors.WithStack(unstructured.SetNestedField(obj.Object, dataSource, "spec", "dataSource"))
+}
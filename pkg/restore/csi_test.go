@@ -0,0 +1,152 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestLoadCSISnapshots(t *testing.T) {
+	dir := "/restore/resources/volumesnapshots.snapshot.storage.k8s.io"
+
+	ctx := &context{
+		backup:     builder.ForBackup(velerov1api.DefaultNamespace, "backup-1").Result(),
+		fileSystem: velerotest.NewFakeFileSystem(),
+		log:        velerotest.NewLogger(),
+		resourceDirs: map[string]string{
+			"volumesnapshots.snapshot.storage.k8s.io": dir,
+		},
+	}
+	ctx.fileSystem.(*velerotest.FakeFileSystem).
+		WithFile(dir+"/namespaces/ns-1/velero-backup-1-pvc-1.json", []byte(`{
+			"apiVersion": "snapshot.storage.k8s.io/v1beta1",
+			"kind": "VolumeSnapshot",
+			"metadata": {"namespace": "ns-1", "name": "velero-backup-1-pvc-1", "labels": {"velero.io/backup-name": "backup-1"}},
+			"spec": {"source": {"persistentVolumeClaimName": "pvc-1"}}
+		}`)).
+		WithFile(dir+"/namespaces/ns-1/user-snapshot.json", []byte(`{
+			"apiVersion": "snapshot.storage.k8s.io/v1beta1",
+			"kind": "VolumeSnapshot",
+			"metadata": {"namespace": "ns-1", "name": "user-snapshot"},
+			"spec": {"source": {"persistentVolumeClaimName": "pvc-2"}}
+		}`))
+
+	require.NoError(t, ctx.loadCSISnapshots())
+	assert.Equal(t, map[string]string{"ns-1/pvc-1": "velero-backup-1-pvc-1"}, ctx.csiSnapshots)
+
+	name, ok := ctx.csiSnapshotFor("ns-1", "pvc-1")
+	assert.True(t, ok)
+	assert.Equal(t, "velero-backup-1-pvc-1", name)
+
+	_, ok = ctx.csiSnapshotFor("ns-1", "pvc-2")
+	assert.False(t, ok)
+}
+
+func TestPrepareVolumeSnapshotContent(t *testing.T) {
+	restore := builder.ForRestore(velerov1api.DefaultNamespace, "restore-1").NamespaceMappings("ns-1", "ns-2").Result()
+
+	content := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1beta1",
+		"kind":       "VolumeSnapshotContent",
+		"metadata":   map[string]interface{}{"name": "snapcontent-1"},
+		"spec": map[string]interface{}{
+			"deletionPolicy": "Delete",
+			"driver":         "csi.example.com",
+			"source":         map[string]interface{}{"volumeHandle": "vol-1"},
+			"volumeSnapshotRef": map[string]interface{}{
+				"kind":            "VolumeSnapshot",
+				"namespace":       "ns-1",
+				"name":            "velero-backup-1-pvc-1",
+				"uid":             "uid-1",
+				"resourceVersion": "1",
+			},
+		},
+		"status": map[string]interface{}{"snapshotHandle": "snap-1"},
+	}}
+
+	require.NoError(t, prepareVolumeSnapshotContent(content, restore))
+
+	assert.Equal(t, "snapcontent-1-restore-1", content.GetName())
+	assert.Equal(t, map[string]interface{}{
+		"deletionPolicy": "Retain",
+		"driver":         "csi.example.com",
+		"source":         map[string]interface{}{"snapshotHandle": "snap-1"},
+		"volumeSnapshotRef": map[string]interface{}{
+			"kind":      "VolumeSnapshot",
+			"namespace": "ns-2",
+			"name":      "velero-backup-1-pvc-1",
+		},
+	}, content.Object["spec"])
+
+	// contents without a snapshot handle can't be restored
+	unstructured.RemoveNestedField(content.Object, "status")
+	assert.Error(t, prepareVolumeSnapshotContent(content, restore))
+}
+
+func TestPrepareVolumeSnapshot(t *testing.T) {
+	restore := builder.ForRestore(velerov1api.DefaultNamespace, "restore-1").Result()
+
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"volumeSnapshotClassName": "class-1",
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": "pvc-1"},
+		},
+		"status": map[string]interface{}{"boundVolumeSnapshotContentName": "snapcontent-1"},
+	}}
+
+	require.NoError(t, prepareVolumeSnapshot(snapshot, restore))
+	assert.Equal(t, map[string]interface{}{
+		"volumeSnapshotClassName": "class-1",
+		"source":                  map[string]interface{}{"volumeSnapshotContentName": "snapcontent-1-restore-1"},
+	}, snapshot.Object["spec"])
+}
+
+func TestSetCSISnapshotDataSource(t *testing.T) {
+	pvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"pv.kubernetes.io/bind-completed":      "yes",
+				"pv.kubernetes.io/bound-by-controller": "yes",
+				"foo":                                  "bar",
+			},
+		},
+		"spec": map[string]interface{}{
+			"volumeName":       "pv-1",
+			"storageClassName": "csi",
+		},
+	}}
+
+	require.NoError(t, setCSISnapshotDataSource(pvc, "velero-backup-1-pvc-1"))
+
+	assert.Equal(t, map[string]string{"foo": "bar"}, pvc.GetAnnotations())
+	assert.Equal(t, map[string]interface{}{
+		"storageClassName": "csi",
+		"dataSource": map[string]interface{}{
+			"apiGroup": "snapshot.storage.k8s.io",
+			"kind":     "VolumeSnapshot",
+			"name":     "velero-backup-1-pvc-1",
+		},
+	}, pvc.Object["spec"])
+}
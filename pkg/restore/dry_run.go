@@ -0,0 +1,143 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/kuberesource"
+)
+
+// The keys that a dry-run restore's report is stored under in its results,
+// alongside its warnings and errors.
+const (
+	DryRunCreateResultsKey    = "dryRunCreate"
+	DryRunUnchangedResultsKey = "dryRunUnchanged"
+	DryRunChangedResultsKey   = "dryRunChanged"
+	DryRunSkippedResultsKey   = "dryRunSkipped"
+)
+
+// DryRunReport records what a restore would do with each of the items in its
+// backup. Each message is an item's resource ID, followed by more detail where
+// there is any. All methods are safe to call on a nil DryRunReport, in which
+// case they're no-ops.
+type DryRunReport struct {
+	// Create has the items that don't exist in the cluster, so would be
+	// created.
+	Create Result
+
+	// Unchanged has the items that already exist in the cluster and match
+	// the backup, so would be left as they are.
+	Unchanged Result
+
+	// Changed has the items that already exist in the cluster and differ
+	// from the backup. What would be done with them depends on the restore's
+	// existing resource policy.
+	Changed Result
+
+	// Skipped has the items that wouldn't be restored, and why.
+	Skipped Result
+}
+
+// Results returns the report's results, keyed so they can be stored with a
+// restore's other results.
+func (r *DryRunReport) Results() map[string]Result {
+	if r == nil {
+		return nil
+	}
+
+	return map[string]Result{
+		DryRunCreateResultsKey:    r.Create,
+		DryRunUnchangedResultsKey: r.Unchanged,
+		DryRunChangedResultsKey:   r.Changed,
+		DryRunSkippedResultsKey:   r.Skipped,
+	}
+}
+
+func (r *DryRunReport) skipped(namespace, resourceID, reason string) {
+	if r == nil {
+		return
+	}
+	addMessage(&r.Skipped, namespace, fmt.Sprintf("%s: %s", resourceID, reason))
+}
+
+// dryRunNamespace records whether the namespace that items are being restored
+// into would have to be created.
+func (ctx *context) dryRunNamespace(name string) error {
+	if _, err := ctx.namespaceClient.Get(name, metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error getting namespace %s", name)
+		}
+
+		addMessage(&ctx.dryRun.Create, "", getResourceID(kuberesource.Namespaces, "", name))
+	}
+
+	return nil
+}
+
+// dryRunItem records what restoring obj would do, by comparing it with its
+// copy in the cluster if there is one, instead of creating it.
+func (ctx *context) dryRunItem(resourceClient client.Dynamic, obj *unstructured.Unstructured, groupResource schema.GroupResource, namespace string) error {
+	resourceID := getResourceID(groupResource, namespace, obj.GetName())
+
+	fromCluster, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		addMessage(&ctx.dryRun.Create, namespace, resourceID)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error getting cluster version of %s", resourceID)
+	}
+
+	if fromCluster, err = resetMetadataAndStatus(fromCluster); err != nil {
+		return err
+	}
+
+	labels := obj.GetLabels()
+	addRestoreLabels(fromCluster, labels[api.RestoreNameLabel], labels[api.BackupNameLabel])
+
+	if equality.Semantic.DeepEqual(fromCluster, obj) {
+		addMessage(&ctx.dryRun.Unchanged, namespace, resourceID)
+		return nil
+	}
+
+	var action string
+	switch {
+	case groupResource == kuberesource.ServiceAccounts:
+		action = "would be merged with the backed up version"
+	case ctx.restore.Spec.ExistingResourcePolicy == api.ExistingResourcePolicyUpdate:
+		action = "would be updated"
+	case ctx.restore.Spec.ExistingResourcePolicy == api.ExistingResourcePolicyRecreate && canRecreate(groupResource):
+		action = "would be recreated"
+	case ctx.restore.Spec.ExistingResourcePolicy == api.ExistingResourcePolicyRecreate:
+		action = "would be updated instead of recreated"
+	default:
+		action = "would not be changed"
+	}
+	addMessage(&ctx.dryRun.Changed, namespace, fmt.Sprintf("%s: %s", resourceID, action))
+
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/kuberesource"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestDryRunItem(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	backedUp := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns","labels":{"velero.io/backup-name":"backup-1","velero.io/restore-name":"restore-1"}},"data":{"a":"1"}}`
+
+	tests := []struct {
+		name          string
+		policy        api.ExistingResourcePolicyType
+		groupResource schema.GroupResource
+		fromCluster   string
+		expected      *DryRunReport
+	}{
+		{
+			name:          "item that isn't in the cluster would be created",
+			groupResource: configMaps,
+			expected: &DryRunReport{
+				Create: Result{Namespaces: map[string][]string{"ns": {"configmaps/ns/cm"}}},
+			},
+		},
+		{
+			name:          "item that matches the cluster's copy is unchanged",
+			groupResource: configMaps,
+			fromCluster:   `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns","uid":"123","resourceVersion":"1"},"data":{"a":"1"}}`,
+			expected: &DryRunReport{
+				Unchanged: Result{Namespaces: map[string][]string{"ns": {"configmaps/ns/cm"}}},
+			},
+		},
+		{
+			name:          "item that differs from the cluster's copy is left alone by default",
+			groupResource: configMaps,
+			fromCluster:   `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"2"}}`,
+			expected: &DryRunReport{
+				Changed: Result{Namespaces: map[string][]string{"ns": {"configmaps/ns/cm: would not be changed"}}},
+			},
+		},
+		{
+			name:          "item that differs from the cluster's copy would be updated with the update policy",
+			policy:        api.ExistingResourcePolicyUpdate,
+			groupResource: configMaps,
+			fromCluster:   `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"2"}}`,
+			expected: &DryRunReport{
+				Changed: Result{Namespaces: map[string][]string{"ns": {"configmaps/ns/cm: would be updated"}}},
+			},
+		},
+		{
+			name:          "item that differs from the cluster's copy would be recreated with the recreate policy",
+			policy:        api.ExistingResourcePolicyRecreate,
+			groupResource: configMaps,
+			fromCluster:   `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"2"}}`,
+			expected: &DryRunReport{
+				Changed: Result{Namespaces: map[string][]string{"ns": {"configmaps/ns/cm: would be recreated"}}},
+			},
+		},
+		{
+			name:          "PVC that differs from the cluster's copy would be updated with the recreate policy",
+			policy:        api.ExistingResourcePolicyRecreate,
+			groupResource: kuberesource.PersistentVolumeClaims,
+			fromCluster:   `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"2"}}`,
+			expected: &DryRunReport{
+				Changed: Result{Namespaces: map[string][]string{"ns": {"persistentvolumeclaims/ns/cm: would be updated instead of recreated"}}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := new(velerotest.FakeDynamicClient)
+			if test.fromCluster == "" {
+				client.On("Get", "cm", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), apierrors.NewNotFound(test.groupResource, "cm"))
+			} else {
+				client.On("Get", "cm", metav1.GetOptions{}).Return(velerotest.UnstructuredOrDie(test.fromCluster), nil)
+			}
+
+			ctx := &context{
+				restore: builder.ForRestore(api.DefaultNamespace, "restore-1").ExistingResourcePolicy(test.policy).DryRun(true).Result(),
+				dryRun:  new(DryRunReport),
+			}
+
+			require.NoError(t, ctx.dryRunItem(client, velerotest.UnstructuredOrDie(backedUp), test.groupResource, "ns"))
+			assert.Equal(t, test.expected, ctx.dryRun)
+			client.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestDryRunReportIsNilSafe(t *testing.T) {
+	var report *DryRunReport
+
+	report.skipped("ns", "pods/ns/pod", "it's a mirror pod")
+	assert.Nil(t, report.Results())
+}
+
+func TestDryRunReportResults(t *testing.T) {
+	report := new(DryRunReport)
+	report.skipped("ns", "pods/ns/pod", "it's a mirror pod")
+
+	expected := map[string]Result{
+		DryRunCreateResultsKey:    {},
+		DryRunUnchangedResultsKey: {},
+		DryRunChangedResultsKey:   {},
+		DryRunSkippedResultsKey:   {Namespaces: map[string][]string{"ns": {"pods/ns/pod: it's a mirror pod"}}},
+	}
+	assert.Equal(t, expected, report.Results())
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/kuberesource"
+)
+
+// canRecreate returns false for resources whose deletion also deletes other
+// data, e.g. a namespace's contents or a persistent volume's storage, so must
+// never be deleted to be recreated by a restore.
+func canRecreate(groupResource schema.GroupResource) bool {
+	switch groupResource {
+	case kuberesource.Namespaces,
+		kuberesource.PersistentVolumes,
+		kuberesource.PersistentVolumeClaims,
+		kuberesource.CustomResourceDefinitions:
+		return false
+	default:
+		return true
+	}
+}
+
+// existingItemPatch returns a JSON merge patch that updates fromCluster, an
+// item as it exists in the cluster, to match desired, the item from the
+// backup. Fields that are set in fromCluster but not in desired are left
+// alone, since they're usually set by the cluster, e.g. a service's cluster IP
+// or a pod's node name. Status is never patched. It returns nil if there's
+// nothing to update.
+func existingItemPatch(fromCluster, desired *unstructured.Unstructured) ([]byte, error) {
+	fromCluster = fromCluster.DeepCopy()
+	desired = desired.DeepCopy()
+	delete(fromCluster.Object, "status")
+	delete(desired.Object, "status")
+
+	if equality.Semantic.DeepEqual(fromCluster, desired) {
+		return nil, nil
+	}
+
+	fromClusterBytes, err := json.Marshal(fromCluster.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal in-cluster object")
+	}
+
+	desiredBytes, err := json.Marshal(desired.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal desired object")
+	}
+
+	patchBytes, err := jsonpatch.CreateMergePatch(fromClusterBytes, desiredBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create merge patch")
+	}
+
+	patch := make(map[string]interface{})
+	if err := json.Unmarshal(patchBytes, &patch); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal merge patch")
+	}
+
+	// merge patches remove fields by setting them to null
+	removeNulls(patch)
+
+	// desired.metadata only has the fields that are restored, so anything
+	// else in the patch's metadata, e.g. a resourceVersion, must be ignored.
+	if metadata, ok := patch["metadata"].(map[string]interface{}); ok {
+		for k := range metadata {
+			switch k {
+			case "labels", "annotations":
+			default:
+				delete(metadata, k)
+			}
+		}
+	}
+	removeEmptyMaps(patch)
+
+	if len(patch) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(patch)
+}
+
+// removeNulls removes all of the null values from the JSON object m.
+func removeNulls(m map[string]interface{}) {
+	for k, v := range m {
+		switch val := v.(type) {
+		case nil:
+			delete(m, k)
+		case map[string]interface{}:
+			removeNulls(val)
+		}
+	}
+}
+
+// removeEmptyMaps removes all of the empty objects from the JSON object m,
+// including those that become empty when their own empty objects are removed.
+func removeEmptyMaps(m map[string]interface{}) {
+	for k, v := range m {
+		if val, ok := v.(map[string]interface{}); ok {
+			removeEmptyMaps(val)
+			if len(val) == 0 {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// recreateItem deletes the existing copy of obj from the cluster, waits up to
+// timeout for it to be gone, and then creates obj.
+func recreateItem(resourceClient client.Dynamic, obj *unstructured.Unstructured, timeout time.Duration) (*unstructured.Unstructured, error) {
+	name := obj.GetName()
+
+	// delete dependents, e.g. a deployment's replica sets, first, so they don't
+	// outlive the item being recreated.
+	propagation := metav1.DeletePropagationForeground
+	if err := resourceClient.Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "error deleting existing item to recreate it")
+	}
+
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		_, err := resourceClient.Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error waiting for existing item to be deleted so it can be recreated")
+	}
+
+	return resourceClient.Create(obj)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/velero/pkg/kuberesource"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestCanRecreate(t *testing.T) {
+	assert.True(t, canRecreate(schema.GroupResource{Group: "apps", Resource: "deployments"}))
+	assert.True(t, canRecreate(kuberesource.Pods))
+	assert.False(t, canRecreate(kuberesource.Namespaces))
+	assert.False(t, canRecreate(kuberesource.PersistentVolumes))
+	assert.False(t, canRecreate(kuberesource.PersistentVolumeClaims))
+	assert.False(t, canRecreate(kuberesource.CustomResourceDefinitions))
+}
+
+func TestExistingItemPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromCluster string
+		desired     string
+		expected    string
+	}{
+		{
+			name:        "identical items don't need a patch",
+			fromCluster: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"1"}}`,
+			desired:     `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"},"data":{"a":"1"}}`,
+			expected:    "",
+		},
+		{
+			name:        "changed and added fields are patched",
+			fromCluster: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns","labels":{"a":"b"}},"data":{"a":"1"}}`,
+			desired:     `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns","labels":{"a":"b","velero.io/restore-name":"r"}},"data":{"a":"2","b":"3"}}`,
+			expected:    `{"data":{"a":"2","b":"3"},"metadata":{"labels":{"velero.io/restore-name":"r"}}}`,
+		},
+		{
+			name:        "fields only set in the cluster are left alone",
+			fromCluster: `{"apiVersion":"v1","kind":"Service","metadata":{"name":"svc","namespace":"ns","annotations":{"live":"true"}},"spec":{"clusterIP":"10.0.0.1","ports":[{"port":80}]}}`,
+			desired:     `{"apiVersion":"v1","kind":"Service","metadata":{"name":"svc","namespace":"ns"},"spec":{"ports":[{"port":8080}]}}`,
+			expected:    `{"spec":{"ports":[{"port":8080}]}}`,
+		},
+		{
+			name:        "status is never patched",
+			fromCluster: `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"ns"},"status":{"phase":"Running"}}`,
+			desired:     `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"ns"},"status":{"phase":"Pending"}}`,
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := existingItemPatch(velerotest.UnstructuredOrDie(test.fromCluster), velerotest.UnstructuredOrDie(test.desired))
+			require.NoError(t, err)
+
+			if test.expected == "" {
+				assert.Nil(t, patch)
+				return
+			}
+			assert.JSONEq(t, test.expected, string(patch))
+		})
+	}
+}
+
+func TestRecreateItem(t *testing.T) {
+	obj := velerotest.UnstructuredOrDie(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"}}`)
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm")
+
+	client := new(velerotest.FakeDynamicClient)
+	client.On("Delete", "cm", mock.MatchedBy(func(opts *metav1.DeleteOptions) bool {
+		return opts.PropagationPolicy != nil && *opts.PropagationPolicy == metav1.DeletePropagationForeground
+	})).Return(nil)
+	// the item is still there the first time it's checked, and gone the second
+	client.On("Get", "cm", metav1.GetOptions{}).Return(obj, nil).Once()
+	client.On("Get", "cm", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), notFound).Once()
+	client.On("Create", obj).Return(obj, nil)
+
+	res, err := recreateItem(client, obj, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, obj, res)
+	client.AssertExpectations(t)
+}
+
+func TestRecreateItemTimesOutWaitingForDeletion(t *testing.T) {
+	obj := velerotest.UnstructuredOrDie(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns"}}`)
+
+	client := new(velerotest.FakeDynamicClient)
+	client.On("Delete", "cm", mock.Anything).Return(nil)
+	client.On("Get", "cm", metav1.GetOptions{}).Return(obj, nil)
+
+	_, err := recreateItem(client, obj, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error waiting for existing item to be deleted")
+	client.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/kuberesource"
+	"github.com/heptio/velero/pkg/podexec"
+	"github.com/heptio/velero/pkg/restic"
+	"github.com/heptio/velero/pkg/util/collections"
+)
+
+// defaultHookWaitTimeout is how long to wait for a restored pod's container to be
+// running before giving up on its exec hooks, if the hook doesn't specify a timeout.
+const defaultHookWaitTimeout = 10 * time.Minute
+
+// restoreHook is a RestoreResourceHookSpec with its namespace, resource and label
+// selectors resolved.
+type restoreHook struct {
+	name          string
+	namespaces    *collections.IncludesExcludes
+	resources     *collections.IncludesExcludes
+	labelSelector labels.Selector
+	postHooks     []api.RestoreResourceHook
+}
+
+// getRestoreHooks resolves the provided hook specs. Resources are matched using their
+// group-resource names (e.g. "pods"), since hooks are only supported for pods.
+func getRestoreHooks(hookSpecs []api.RestoreResourceHookSpec) ([]restoreHook, error) {
+	restoreHooks := make([]restoreHook, 0, len(hookSpecs))
+
+	for _, s := range hookSpecs {
+		h := restoreHook{
+			name:       s.Name,
+			namespaces: collections.NewIncludesExcludes().Includes(s.IncludedNamespaces...).Excludes(s.ExcludedNamespaces...),
+			resources: collections.GenerateIncludesExcludes(
+				s.IncludedResources,
+				s.ExcludedResources,
+				func(item string) string { return schema.ParseGroupResource(item).String() },
+			),
+			postHooks: s.PostHooks,
+		}
+
+		if s.LabelSelector != nil {
+			labelSelector, err := metav1.LabelSelectorAsSelector(s.LabelSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error parsing label selector for restore hook %s", s.Name)
+			}
+			h.labelSelector = labelSelector
+		}
+
+		restoreHooks = append(restoreHooks, h)
+	}
+
+	return restoreHooks, nil
+}
+
+func (h restoreHook) applicableTo(groupResource schema.GroupResource, namespace string, labels labels.Set) bool {
+	if h.namespaces != nil && !h.namespaces.ShouldInclude(namespace) {
+		return false
+	}
+	if h.resources != nil && !h.resources.ShouldInclude(groupResource.String()) {
+		return false
+	}
+	if h.labelSelector != nil && !h.labelSelector.Matches(labels) {
+		return false
+	}
+	return true
+}
+
+// addInitHookContainers adds the init containers from any applicable init hooks to the
+// pod. They're added after the restic init container, if present, so that they run once
+// the pod's volumes have been restored. Existing init containers with the same names as
+// hook containers (e.g. from a pod that was backed up after being restored with hooks)
+// are replaced.
+func addInitHookContainers(pod *corev1api.Pod, restoreHooks []restoreHook) {
+	var hookContainers []corev1api.Container
+	for _, h := range restoreHooks {
+		if !h.applicableTo(kuberesource.Pods, pod.Namespace, labels.Set(pod.Labels)) {
+			continue
+		}
+		for _, hook := range h.postHooks {
+			if hook.Init != nil {
+				hookContainers = append(hookContainers, hook.Init.InitContainers...)
+			}
+		}
+	}
+
+	if len(hookContainers) == 0 {
+		return
+	}
+
+	hookContainerNames := make(map[string]struct{}, len(hookContainers))
+	for _, c := range hookContainers {
+		hookContainerNames[c.Name] = struct{}{}
+	}
+
+	var resticContainers, otherContainers []corev1api.Container
+	for _, c := range pod.Spec.InitContainers {
+		if _, ok := hookContainerNames[c.Name]; ok {
+			continue
+		}
+		if c.Name == restic.InitContainer {
+			resticContainers = append(resticContainers, c)
+		} else {
+			otherContainers = append(otherContainers, c)
+		}
+	}
+
+	initContainers := append(resticContainers, hookContainers...)
+	pod.Spec.InitContainers = append(initContainers, otherContainers...)
+}
+
+// getExecHooks returns the exec hooks from restoreHooks that apply to a pod with the
+// given backed-up namespace and labels, along with the names of the hook specs they
+// came from.
+func getExecHooks(namespace string, podLabels labels.Set, restoreHooks []restoreHook) []namedExecHook {
+	var execHooks []namedExecHook
+	for _, h := range restoreHooks {
+		if !h.applicableTo(kuberesource.Pods, namespace, podLabels) {
+			continue
+		}
+		for _, hook := range h.postHooks {
+			if hook.Exec != nil {
+				execHooks = append(execHooks, namedExecHook{name: h.name, hook: hook.Exec})
+			}
+		}
+	}
+
+	return execHooks
+}
+
+type namedExecHook struct {
+	name string
+	hook *api.ExecRestoreHook
+}
+
+// execHookRunner runs a restored pod's exec hooks once their containers are running.
+type execHookRunner struct {
+	podCommandExecutor podexec.PodCommandExecutor
+	podClient          client.Dynamic
+	pollInterval       time.Duration
+	// done is closed if the restore is canceled, so that hooks that
+	// haven't started yet aren't run.
+	done <-chan struct{}
+}
+
+// runHooks executes the provided hooks in order against the named pod, waiting for each
+// hook's container to be running first. An error from a hook with an OnError mode of
+// Continue is returned as a warning; any other error is returned as an error and stops
+// execution of the remaining hooks. If the restore is canceled, no more hooks are run.
+func (r *execHookRunner) runHooks(log logrus.FieldLogger, namespace, name string, hooks []namedExecHook) (warnings []error, errs []error) {
+	for _, h := range hooks {
+		if r.canceled() {
+			errs = append(errs, errors.Errorf("restore was canceled before restore hook %s could be run in pod %s/%s", h.name, namespace, name))
+			break
+		}
+
+		hookLog := log.WithFields(logrus.Fields{
+			"hookSource": "restoreSpec",
+			"hookType":   "exec",
+			"hookPhase":  "post",
+			"hookName":   h.name,
+		})
+
+		err := r.runHook(hookLog, namespace, name, h)
+		if err == nil {
+			continue
+		}
+
+		hookLog.WithError(err).Error("Error executing hook")
+		err = errors.Wrapf(err, "error executing restore hook %s in pod %s/%s", h.name, namespace, name)
+		if h.hook.OnError == api.HookErrorModeContinue {
+			warnings = append(warnings, err)
+			continue
+		}
+		errs = append(errs, err)
+		break
+	}
+
+	return warnings, errs
+}
+
+func (r *execHookRunner) runHook(log logrus.FieldLogger, namespace, name string, h namedExecHook) error {
+	waitTimeout := h.hook.WaitTimeout.Duration
+	if waitTimeout == 0 {
+		waitTimeout = defaultHookWaitTimeout
+	}
+
+	var pod *unstructured.Unstructured
+	err := wait.PollImmediate(r.pollInterval, waitTimeout, func() (bool, error) {
+		if r.canceled() {
+			return false, errors.New("restore was canceled while waiting for container to be running")
+		}
+
+		obj, err := r.podClient.Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		running, err := isContainerRunning(obj, h.hook.Container)
+		if err != nil {
+			return false, err
+		}
+		if running {
+			pod = obj
+		}
+		return running, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out after %v waiting for container to be running", waitTimeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	execHook := &api.ExecHook{
+		Container: h.hook.Container,
+		Command:   h.hook.Command,
+		OnError:   h.hook.OnError,
+		Timeout:   h.hook.ExecTimeout,
+	}
+
+	return r.podCommandExecutor.ExecutePodCommand(log, pod.UnstructuredContent(), namespace, name, h.name, execHook)
+}
+
+// canceled returns whether the restore that the hooks are being run for has
+// been canceled.
+func (r *execHookRunner) canceled() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// isContainerRunning returns whether the named container in the pod is running. If
+// containerName is empty, the pod's first container is checked. An error is returned
+// if the pod has terminated, since its hooks can never be run.
+func isContainerRunning(obj *unstructured.Unstructured, containerName string) (bool, error) {
+	pod := new(corev1api.Pod)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), pod); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	switch pod.Status.Phase {
+	case corev1api.PodSucceeded, corev1api.PodFailed:
+		return false, errors.Errorf("pod has terminated with phase %s", pod.Status.Phase)
+	case corev1api.PodRunning:
+	default:
+		return false, nil
+	}
+
+	if containerName == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return false, errors.New("pod has no containers")
+		}
+		containerName = pod.Spec.Containers[0].Name
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.State.Running != nil, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/restic"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestAddInitHookContainers(t *testing.T) {
+	initHook := func(containers ...*corev1api.Container) velerov1api.RestoreResourceHook {
+		hook := velerov1api.RestoreResourceHook{Init: &velerov1api.InitRestoreHook{}}
+		for _, c := range containers {
+			hook.Init.InitContainers = append(hook.Init.InitContainers, *c)
+		}
+		return hook
+	}
+
+	tests := []struct {
+		name      string
+		pod       *corev1api.Pod
+		hookSpecs []velerov1api.RestoreResourceHookSpec
+		want      *corev1api.Pod
+	}{
+		{
+			name: "no hooks leaves init containers unchanged",
+			pod:  builder.ForPod("ns-1", "pod-1").InitContainers(builder.ForContainer("init-1", "image-1").Result()).Result(),
+			want: builder.ForPod("ns-1", "pod-1").InitContainers(builder.ForContainer("init-1", "image-1").Result()).Result(),
+		},
+		{
+			name: "hook containers are added before existing init containers",
+			pod:  builder.ForPod("ns-1", "pod-1").InitContainers(builder.ForContainer("init-1", "image-1").Result()).Result(),
+			hookSpecs: []velerov1api.RestoreResourceHookSpec{
+				{
+					Name:      "hook-1",
+					PostHooks: []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init", "hook-image").Result())},
+				},
+			},
+			want: builder.ForPod("ns-1", "pod-1").InitContainers(
+				builder.ForContainer("hook-init", "hook-image").Result(),
+				builder.ForContainer("init-1", "image-1").Result(),
+			).Result(),
+		},
+		{
+			name: "hook containers are added after the restic init container",
+			pod: builder.ForPod("ns-1", "pod-1").InitContainers(
+				builder.ForContainer(restic.InitContainer, "velero-restic-restore-helper").Result(),
+				builder.ForContainer("init-1", "image-1").Result(),
+			).Result(),
+			hookSpecs: []velerov1api.RestoreResourceHookSpec{
+				{
+					Name:      "hook-1",
+					PostHooks: []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init", "hook-image").Result())},
+				},
+			},
+			want: builder.ForPod("ns-1", "pod-1").InitContainers(
+				builder.ForContainer(restic.InitContainer, "velero-restic-restore-helper").Result(),
+				builder.ForContainer("hook-init", "hook-image").Result(),
+				builder.ForContainer("init-1", "image-1").Result(),
+			).Result(),
+		},
+		{
+			name: "existing init containers with the same name as a hook container are replaced",
+			pod:  builder.ForPod("ns-1", "pod-1").InitContainers(builder.ForContainer("hook-init", "old-image").Result()).Result(),
+			hookSpecs: []velerov1api.RestoreResourceHookSpec{
+				{
+					Name:      "hook-1",
+					PostHooks: []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init", "hook-image").Result())},
+				},
+			},
+			want: builder.ForPod("ns-1", "pod-1").InitContainers(builder.ForContainer("hook-init", "hook-image").Result()).Result(),
+		},
+		{
+			name: "hooks for excluded namespaces and non-matching label selectors are not added",
+			pod:  builder.ForPod("ns-1", "pod-1").ObjectMeta(builder.WithLabels("app", "db")).Result(),
+			hookSpecs: []velerov1api.RestoreResourceHookSpec{
+				{
+					Name:               "excluded-namespace",
+					ExcludedNamespaces: []string{"ns-1"},
+					PostHooks:          []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init-1", "hook-image").Result())},
+				},
+				{
+					Name:          "non-matching-selector",
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					PostHooks:     []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init-2", "hook-image").Result())},
+				},
+				{
+					Name:              "excluded-resource",
+					ExcludedResources: []string{"pods"},
+					PostHooks:         []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init-3", "hook-image").Result())},
+				},
+				{
+					Name:          "matching-selector",
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					PostHooks:     []velerov1api.RestoreResourceHook{initHook(builder.ForContainer("hook-init-4", "hook-image").Result())},
+				},
+			},
+			want: builder.ForPod("ns-1", "pod-1").
+				ObjectMeta(builder.WithLabels("app", "db")).
+				InitContainers(builder.ForContainer("hook-init-4", "hook-image").Result()).
+				Result(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restoreHooks, err := getRestoreHooks(tc.hookSpecs)
+			require.NoError(t, err)
+
+			addInitHookContainers(tc.pod, restoreHooks)
+
+			assert.Equal(t, tc.want, tc.pod)
+		})
+	}
+}
+
+func TestExecHookRunnerRunHooks(t *testing.T) {
+	runningPod := builder.ForPod("ns-1", "pod-1").
+		Containers(builder.ForContainer("container-1", "image-1").Result()).
+		Phase(corev1api.PodRunning).
+		ContainerStatuses(&corev1api.ContainerStatus{
+			Name:  "container-1",
+			State: corev1api.ContainerState{Running: &corev1api.ContainerStateRunning{}},
+		}).
+		Result()
+
+	succeededPod := builder.ForPod("ns-1", "pod-1").
+		Containers(builder.ForContainer("container-1", "image-1").Result()).
+		Phase(corev1api.PodSucceeded).
+		Result()
+
+	tests := []struct {
+		name         string
+		pod          *corev1api.Pod
+		hooks        []namedExecHook
+		execErr      error
+		canceled     bool
+		wantExecs    int
+		wantWarnings int
+		wantErrs     int
+	}{
+		{
+			name: "hook is executed once the container is running",
+			pod:  runningPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}}},
+			},
+			wantExecs: 1,
+		},
+		{
+			name: "failed hook with OnError Continue is a warning and remaining hooks are run",
+			pod:  runningPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}, OnError: velerov1api.HookErrorModeContinue}},
+				{name: "hook-2", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}, OnError: velerov1api.HookErrorModeContinue}},
+			},
+			execErr:      errors.New("exec failed"),
+			wantExecs:    2,
+			wantWarnings: 2,
+		},
+		{
+			name: "failed hook with OnError Fail is an error and remaining hooks are skipped",
+			pod:  runningPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}, OnError: velerov1api.HookErrorModeFail}},
+				{name: "hook-2", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}}},
+			},
+			execErr:   errors.New("exec failed"),
+			wantExecs: 1,
+			wantErrs:  1,
+		},
+		{
+			name: "hook is not executed when the pod has terminated",
+			pod:  succeededPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}}},
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "hook is not executed when its container doesn't become running",
+			pod:  runningPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{
+					Container:   "container-2",
+					Command:     []string{"ls"},
+					WaitTimeout: metav1.Duration{Duration: 10 * time.Millisecond},
+				}},
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "hooks are not executed when the restore has been canceled",
+			pod:  runningPod,
+			hooks: []namedExecHook{
+				{name: "hook-1", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}, OnError: velerov1api.HookErrorModeContinue}},
+				{name: "hook-2", hook: &velerov1api.ExecRestoreHook{Command: []string{"ls"}}},
+			},
+			canceled: true,
+			wantErrs: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tc.pod)
+			require.NoError(t, err)
+
+			podClient := new(velerotest.FakeDynamicClient)
+			podClient.On("Get", tc.pod.Name, metav1.GetOptions{}).Return(&unstructured.Unstructured{Object: obj}, nil)
+
+			podCommandExecutor := new(velerotest.MockPodCommandExecutor)
+			podCommandExecutor.On("ExecutePodCommand", mock.Anything, obj, tc.pod.Namespace, tc.pod.Name, mock.Anything, mock.Anything).Return(tc.execErr)
+
+			runner := &execHookRunner{
+				podCommandExecutor: podCommandExecutor,
+				podClient:          podClient,
+				pollInterval:       time.Millisecond,
+			}
+			if tc.canceled {
+				done := make(chan struct{})
+				close(done)
+				runner.done = done
+			}
+
+			warnings, errs := runner.runHooks(velerotest.NewLogger(), tc.pod.Namespace, tc.pod.Name, tc.hooks)
+
+			assert.Len(t, warnings, tc.wantWarnings)
+			assert.Len(t, errs, tc.wantErrs)
+			podCommandExecutor.AssertNumberOfCalls(t, "ExecutePodCommand", tc.wantExecs)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/heptio/velero/pkg/discovery"
+)
+
+// ParseIncludedItem parses a reference to an item to restore, in the form
+// <resource>/<namespace>/<name>, or <resource>/<name> for a cluster-scoped
+// item.
+func ParseIncludedItem(ref string) (resource, namespace, name string, err error) {
+	parts := strings.Split(ref, "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return parts[0], "", parts[1], nil
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	default:
+		return "", "", "", errors.Errorf("%q must be in the form <resource>/<namespace>/<name> or <resource>/<name>", ref)
+	}
+}
+
+// includedItems tracks the individual items a restore is limited to.
+type includedItems struct {
+	// ids are the resource IDs, as returned by getResourceID, of the items.
+	ids sets.String
+	// namespaces are the namespaces containing the items.
+	namespaces sets.String
+}
+
+// resolveIncludedItems parses refs and uses the discovery helper to resolve
+// their resources to fully-qualified group-resource names. It returns nil if
+// refs is empty, meaning the restore isn't limited to individual items.
+func resolveIncludedItems(helper discovery.Helper, refs []string) (*includedItems, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	res := &includedItems{
+		ids:        sets.NewString(),
+		namespaces: sets.NewString(),
+	}
+
+	for _, ref := range refs {
+		resource, namespace, name, err := ParseIncludedItem(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		gvr, _, err := helper.ResourceFor(schema.ParseGroupResource(resource).WithVersion(""))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error resolving resource of included item %q", ref)
+		}
+
+		res.ids.Insert(getResourceID(gvr.GroupResource(), namespace, name))
+		if namespace != "" {
+			res.namespaces.Insert(namespace)
+		}
+	}
+
+	return res, nil
+}
+
+// includesNamespace returns whether any of the items are in namespace. It
+// returns true if the restore isn't limited to individual items.
+func (i *includedItems) includesNamespace(namespace string) bool {
+	return i == nil || i.namespaces.Has(namespace)
+}
+
+// includes returns whether the item is one of the items. It returns true if
+// the restore isn't limited to individual items.
+func (i *includedItems) includes(groupResource schema.GroupResource, namespace, name string) bool {
+	return i == nil || i.ids.Has(getResourceID(groupResource, namespace, name))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/velero/pkg/kuberesource"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestParseIncludedItem(t *testing.T) {
+	tests := []struct {
+		ref           string
+		wantResource  string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{ref: "deployments.apps/ns-1/deploy-1", wantResource: "deployments.apps", wantNamespace: "ns-1", wantName: "deploy-1"},
+		{ref: "persistentvolumes/pv-1", wantResource: "persistentvolumes", wantName: "pv-1"},
+		{ref: "deploy-1", wantErr: true},
+		{ref: "deployments/ns-1/deploy-1/extra", wantErr: true},
+		{ref: "deployments//deploy-1", wantErr: true},
+		{ref: "deployments/", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			resource, namespace, name, err := ParseIncludedItem(tc.ref)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResource, resource)
+			assert.Equal(t, tc.wantNamespace, namespace)
+			assert.Equal(t, tc.wantName, name)
+		})
+	}
+}
+
+func TestResolveIncludedItems(t *testing.T) {
+	helper := velerotest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Resource: "deployments"}:                {Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Resource: "deployments"}: {Group: "apps", Version: "v1", Resource: "deployments"},
+		{Resource: "persistentvolumes"}:          {Version: "v1", Resource: "persistentvolumes"},
+	})
+
+	items, err := resolveIncludedItems(helper, nil)
+	require.NoError(t, err)
+	assert.Nil(t, items)
+	assert.True(t, items.includesNamespace("ns-1"))
+	assert.True(t, items.includes(kuberesource.Pods, "ns-1", "pod-1"))
+
+	items, err = resolveIncludedItems(helper, []string{"deployments/ns-1/deploy-1", "deployments.apps/ns-2/deploy-2", "persistentvolumes/pv-1"})
+	require.NoError(t, err)
+
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	assert.True(t, items.includes(deployments, "ns-1", "deploy-1"))
+	assert.True(t, items.includes(deployments, "ns-2", "deploy-2"))
+	assert.True(t, items.includes(kuberesource.PersistentVolumes, "", "pv-1"))
+	assert.False(t, items.includes(deployments, "ns-2", "deploy-1"))
+	assert.False(t, items.includes(kuberesource.Pods, "ns-1", "deploy-1"))
+
+	assert.True(t, items.includesNamespace("ns-1"))
+	assert.True(t, items.includesNamespace("ns-2"))
+	assert.False(t, items.includesNamespace("ns-3"))
+
+	_, err = resolveIncludedItems(helper, []string{"widgets/ns-1/widget-1"})
+	assert.Error(t, err)
+
+	_, err = resolveIncludedItems(helper, []string{"deploy-1"})
+	assert.Error(t, err)
+}
@@ -81,6 +81,14 @@ func (a *PodAction) Execute(input *velero.RestoreItemActionExecuteInput) (*veler
 		pod.Spec.InitContainers[i].VolumeMounts = preservedVolumeMounts
 	}
 
+	if input.Restore != nil {
+		restoreHooks, err := getRestoreHooks(input.Restore.Spec.Hooks.Resources)
+		if err != nil {
+			return nil, err
+		}
+		addInitHookContainers(pod, restoreHooks)
+	}
+
 	res, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
 	if err != nil {
 		return nil, errors.WithStack(err)
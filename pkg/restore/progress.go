@@ -0,0 +1,164 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+)
+
+// progressUpdateInterval is how often a restore's status.progress is patched
+// while the restore is running.
+const progressUpdateInterval = time.Second
+
+// progressTracker keeps track of how many items of each resource a restore is
+// expected to contain and how many have been restored so far. All methods are
+// safe to call on a nil progressTracker, in which case they're no-ops.
+type progressTracker struct {
+	lock      sync.Mutex
+	resources map[string]*api.RestoreResourceProgress
+}
+
+func (p *progressTracker) resource(resource string) *api.RestoreResourceProgress {
+	if p.resources == nil {
+		p.resources = make(map[string]*api.RestoreResourceProgress)
+	}
+
+	res, ok := p.resources[resource]
+	if !ok {
+		res = new(api.RestoreResourceProgress)
+		p.resources[resource] = res
+	}
+	return res
+}
+
+// addTotalItems changes the expected number of items for the resource by n,
+// which may be negative if items are found not to need restoring.
+func (p *progressTracker) addTotalItems(resource string, n int) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.resource(resource).TotalItems += n
+}
+
+// itemRestored records that an item of the resource has been processed.
+func (p *progressTracker) itemRestored(resource string) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	res := p.resource(resource)
+	res.ItemsRestored++
+	// make sure the total never falls behind what's actually been restored.
+	if res.ItemsRestored > res.TotalItems {
+		res.TotalItems = res.ItemsRestored
+	}
+}
+
+// progress returns the current state of the tracker.
+func (p *progressTracker) progress() api.RestoreProgress {
+	if p == nil {
+		return api.RestoreProgress{}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var progress api.RestoreProgress
+	for resource, res := range p.resources {
+		if progress.Resources == nil {
+			progress.Resources = make(map[string]api.RestoreResourceProgress)
+		}
+
+		progress.Resources[resource] = *res
+		progress.TotalItems += res.TotalItems
+		progress.ItemsRestored += res.ItemsRestored
+	}
+
+	return progress
+}
+
+// progressReporter periodically patches a restore's status.progress with the
+// state of a progressTracker.
+type progressReporter struct {
+	restoreClient velerov1client.RestoresGetter
+	restore       *api.Restore
+	tracker       *progressTracker
+	interval      time.Duration
+	log           logrus.FieldLogger
+}
+
+// run patches the restore's progress every interval, if it has changed, until
+// stop is closed.
+func (r *progressReporter) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var last api.RestoreProgress
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			current := r.tracker.progress()
+			if reflect.DeepEqual(current, last) {
+				continue
+			}
+
+			if err := r.patch(current); err != nil {
+				r.log.WithError(err).Warn("Error updating restore's progress")
+				continue
+			}
+			last = current
+		}
+	}
+}
+
+func (r *progressReporter) patch(progress api.RestoreProgress) error {
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"progress": progress,
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling restore progress patch")
+	}
+
+	if _, err := r.restoreClient.Restores(r.restore.Namespace).Patch(r.restore.Name, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching restore")
+	}
+
+	return nil
+}
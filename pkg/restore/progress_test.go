@@ -0,0 +1,105 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/generated/clientset/versioned/fake"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var nilTracker *progressTracker
+	nilTracker.addTotalItems("pods", 1)
+	nilTracker.itemRestored("pods")
+	assert.Equal(t, velerov1api.RestoreProgress{}, nilTracker.progress())
+
+	tracker := new(progressTracker)
+	tracker.addTotalItems("pods", 3)
+	tracker.addTotalItems("deployments.apps", 2)
+	tracker.itemRestored("pods")
+	tracker.itemRestored("pods")
+
+	// items filtered out by the label selector are removed from the total
+	tracker.addTotalItems("deployments.apps", -1)
+
+	assert.Equal(t, velerov1api.RestoreProgress{
+		TotalItems:    4,
+		ItemsRestored: 2,
+		Resources: map[string]velerov1api.RestoreResourceProgress{
+			"pods":             {TotalItems: 3, ItemsRestored: 2},
+			"deployments.apps": {TotalItems: 1},
+		},
+	}, tracker.progress())
+
+	// restoring more items than estimated increases the total
+	tracker.itemRestored("secrets")
+	assert.Equal(t, velerov1api.RestoreResourceProgress{TotalItems: 1, ItemsRestored: 1}, tracker.progress().Resources["secrets"])
+}
+
+func TestProgressReporterPatchesRestore(t *testing.T) {
+	restore := builder.ForRestore(velerov1api.DefaultNamespace, "restore-1").Phase(velerov1api.RestorePhaseInProgress).Result()
+	client := fake.NewSimpleClientset(restore)
+
+	tracker := new(progressTracker)
+	tracker.addTotalItems("pods", 10)
+	tracker.itemRestored("pods")
+
+	reporter := &progressReporter{
+		restoreClient: client.VeleroV1(),
+		restore:       restore,
+		tracker:       tracker,
+		interval:      time.Millisecond,
+		log:           velerotest.NewLogger(),
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reporter.run(stop)
+	}()
+
+	var res *velerov1api.Restore
+	err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		var err error
+		res, err = client.VeleroV1().Restores(restore.Namespace).Get(restore.Name, metav1.GetOptions{})
+		return err == nil && res.Status.Progress != nil, nil
+	})
+	require.NoError(t, err)
+
+	close(stop)
+	<-done
+
+	assert.Equal(t, &velerov1api.RestoreProgress{
+		TotalItems:    10,
+		ItemsRestored: 1,
+		Resources: map[string]velerov1api.RestoreResourceProgress{
+			"pods": {TotalItems: 10, ItemsRestored: 1},
+		},
+	}, res.Status.Progress)
+	assert.Equal(t, velerov1api.RestorePhaseInProgress, res.Status.Phase)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/discovery"
+	"github.com/heptio/velero/pkg/plugin/velero"
+)
+
+// ResourceModifierAction modifies items according to the resource modifier
+// rules in the ConfigMap referenced by the restore's spec.resourceModifiers.
+type ResourceModifierAction struct {
+	logger          logrus.FieldLogger
+	configMapClient corev1client.ConfigMapInterface
+	discoveryHelper discovery.Helper
+
+	// the rules are loaded and parsed once per restore, rather than for
+	// every item.
+	lock       sync.Mutex
+	restoreUID types.UID
+	modifiers  *ResourceModifiers
+}
+
+// NewResourceModifierAction is the constructor for ResourceModifierAction.
+func NewResourceModifierAction(
+	logger logrus.FieldLogger,
+	configMapClient corev1client.ConfigMapInterface,
+	discoveryHelper discovery.Helper,
+) *ResourceModifierAction {
+	return &ResourceModifierAction{
+		logger:          logger,
+		configMapClient: configMapClient,
+		discoveryHelper: discoveryHelper,
+	}
+}
+
+// AppliesTo returns the resources that ResourceModifierAction should be
+// run for, which is all of them.
+func (a *ResourceModifierAction) AppliesTo() (velero.ResourceSelector, error) {
+	return velero.ResourceSelector{}, nil
+}
+
+// Execute applies the restore's resource modifier rules to the item.
+func (a *ResourceModifierAction) Execute(input *velero.RestoreItemActionExecuteInput) (*velero.RestoreItemActionExecuteOutput, error) {
+	ref := input.Restore.Spec.ResourceModifiers
+	if ref == nil || ref.Name == "" {
+		return velero.NewRestoreItemActionExecuteOutput(input.Item), nil
+	}
+
+	obj, ok := input.Item.(*unstructured.Unstructured)
+	if !ok {
+		return nil, errors.Errorf("object was of unexpected type %T", input.Item)
+	}
+
+	log := a.logger.WithFields(logrus.Fields{
+		"kind":      obj.GetKind(),
+		"namespace": obj.GetNamespace(),
+		"name":      obj.GetName(),
+	})
+
+	modifiers, err := a.modifiersFor(input.Restore)
+	if err != nil {
+		return nil, err
+	}
+
+	groupResource, err := a.groupResourceFor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	modified, err := modifiers.Apply(groupResource.String(), obj, log)
+	if err != nil {
+		return nil, err
+	}
+	if modified {
+		log.Info("Modified item using resource modifier rules")
+	}
+
+	return velero.NewRestoreItemActionExecuteOutput(obj), nil
+}
+
+// modifiersFor returns the resource modifier rules of restore, getting and
+// parsing them from its ConfigMap only the first time they're needed.
+func (a *ResourceModifierAction) modifiersFor(restore *api.Restore) (*ResourceModifiers, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.modifiers != nil && a.restoreUID == restore.UID {
+		return a.modifiers, nil
+	}
+
+	name := restore.Spec.ResourceModifiers.Name
+	configMap, err := a.configMapClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting resource modifiers ConfigMap %s", name)
+	}
+
+	modifiers, err := GetResourceModifiers(configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	a.restoreUID = restore.UID
+	a.modifiers = modifiers
+
+	return modifiers, nil
+}
+
+// groupResourceFor returns the group-qualified resource of an item, based on
+// its apiVersion and kind.
+func (a *ResourceModifierAction) groupResourceFor(obj *unstructured.Unstructured) (schema.GroupResource, error) {
+	gvk := obj.GroupVersionKind()
+
+	for _, resourceList := range a.discoveryHelper.ServedResources() {
+		if resourceList.GroupVersion != gvk.GroupVersion().String() {
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if resource.Kind == gvk.Kind {
+				return schema.GroupResource{Group: gvk.Group, Resource: resource.Name}, nil
+			}
+		}
+	}
+
+	return schema.GroupResource{}, errors.Errorf("unable to find the resource for %s", gvk)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+
+	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/builder"
+	"github.com/heptio/velero/pkg/plugin/velero"
+	velerotest "github.com/heptio/velero/pkg/util/test"
+)
+
+func TestResourceModifierActionExecute(t *testing.T) {
+	rules := `
+version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: deployments.apps
+  mergePatches:
+  - metadata:
+      labels:
+        migrated: "true"
+`
+
+	newDeployment := func(labels map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"namespace": "ns-1", "name": "deploy-1"}
+		if labels != nil {
+			metadata["labels"] = labels
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   metadata,
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		restore   *velerov1api.Restore
+		configMap *corev1api.ConfigMap
+		item      *unstructured.Unstructured
+		want      *unstructured.Unstructured
+		wantErr   string
+	}{
+		{
+			name:    "item is returned as-is when the restore has no resource modifiers",
+			restore: builder.ForRestore("velero", "restore-1").Result(),
+			item:    newDeployment(nil),
+			want:    newDeployment(nil),
+		},
+		{
+			name:      "item is modified by matching rules",
+			restore:   builder.ForRestore("velero", "restore-1").ResourceModifiers("modifiers").Result(),
+			configMap: builder.ForConfigMap("velero", "modifiers").Data("rules.yaml", rules).Result(),
+			item:      newDeployment(nil),
+			want:      newDeployment(map[string]interface{}{"migrated": "true"}),
+		},
+		{
+			name:    "error is returned when the ConfigMap doesn't exist",
+			restore: builder.ForRestore("velero", "restore-1").ResourceModifiers("modifiers").Result(),
+			item:    newDeployment(nil),
+			wantErr: "error getting resource modifiers ConfigMap modifiers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			if tc.configMap != nil {
+				_, err := clientset.CoreV1().ConfigMaps(tc.configMap.Namespace).Create(tc.configMap)
+				require.NoError(t, err)
+			}
+
+			discoveryHelper := &velerotest.FakeDiscoveryHelper{
+				ResourceList: []*metav1.APIResourceList{
+					{
+						GroupVersion: "apps/v1",
+						APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}},
+					},
+				},
+			}
+
+			a := NewResourceModifierAction(logrus.StandardLogger(), clientset.CoreV1().ConfigMaps("velero"), discoveryHelper)
+
+			res, err := a.Execute(&velero.RestoreItemActionExecuteInput{
+				Item:    tc.item,
+				Restore: tc.restore,
+			})
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res.UpdatedItem)
+		})
+	}
+}
+
+func TestResourceModifierActionLoadsRulesOncePerRestore(t *testing.T) {
+	rules := `
+version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: deployments.apps
+  mergePatches:
+  - metadata:
+      labels:
+        migrated: "true"
+`
+
+	clientset := fake.NewSimpleClientset(builder.ForConfigMap("velero", "modifiers").Data("rules.yaml", rules).Result())
+	discoveryHelper := &velerotest.FakeDiscoveryHelper{
+		ResourceList: []*metav1.APIResourceList{
+			{
+				GroupVersion: "apps/v1",
+				APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}},
+			},
+		},
+	}
+	a := NewResourceModifierAction(logrus.StandardLogger(), clientset.CoreV1().ConfigMaps("velero"), discoveryHelper)
+
+	configMapGets := func() int {
+		var gets int
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "get" && action.GetResource().Resource == "configmaps" {
+				gets++
+			}
+		}
+		return gets
+	}
+
+	execute := func(restore *velerov1api.Restore, name string) {
+		item := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"namespace": "ns-1", "name": name},
+		}}
+
+		res, err := a.Execute(&velero.RestoreItemActionExecuteInput{Item: item, Restore: restore})
+		require.NoError(t, err)
+		assert.Equal(t, "true", res.UpdatedItem.(*unstructured.Unstructured).GetLabels()["migrated"])
+	}
+
+	restore1 := builder.ForRestore("velero", "restore-1").ResourceModifiers("modifiers").ObjectMeta(builder.WithUID("uid-1")).Result()
+	for _, name := range []string{"deploy-1", "deploy-2", "deploy-3"} {
+		execute(restore1, name)
+	}
+	assert.Equal(t, 1, configMapGets())
+
+	// a different restore gets the rules again
+	restore2 := builder.ForRestore("velero", "restore-2").ResourceModifiers("modifiers").ObjectMeta(builder.WithUID("uid-2")).Result()
+	execute(restore2, "deploy-1")
+	assert.Equal(t, 2, configMapGets())
+}
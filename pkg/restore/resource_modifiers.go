@@ -0,0 +1,211 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceModifiersVersion is the only supported version of the resource
+// modifier rules format.
+const ResourceModifiersVersion = "v1"
+
+// ResourceModifiers is the set of rules, held in a ConfigMap referenced by a
+// restore's spec.resourceModifiers, for modifying items before they're restored.
+type ResourceModifiers struct {
+	Version string                 `json:"version"`
+	Rules   []ResourceModifierRule `json:"resourceModifierRules"`
+}
+
+// ResourceModifierRule patches the items that match its conditions. JSON
+// patches are applied before merge patches.
+type ResourceModifierRule struct {
+	Conditions   ResourceModifierConditions `json:"conditions"`
+	Patches      []JSONPatchOperation       `json:"patches,omitempty"`
+	MergePatches []map[string]interface{}   `json:"mergePatches,omitempty"`
+
+	groupResource *regexp.Regexp
+	namespace     *regexp.Regexp
+	name          *regexp.Regexp
+	patch         jsonpatch.Patch
+	mergePatches  [][]byte
+}
+
+// ResourceModifierConditions select the items a rule applies to. Each is a
+// regular expression that must match the whole of the corresponding value,
+// and an empty one matches anything.
+type ResourceModifierConditions struct {
+	// GroupResource is matched against the item's resource qualified by its
+	// group, e.g. "deployments.apps", or just its resource if it's in the
+	// core group, e.g. "pods".
+	GroupResource string `json:"groupResource,omitempty"`
+
+	// Namespace is matched against the item's namespace, which is empty for
+	// cluster-scoped items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is matched against the item's name.
+	Name string `json:"name,omitempty"`
+}
+
+// JSONPatchOperation is a JSON patch (RFC 6902) operation.
+type JSONPatchOperation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// GetResourceModifiers parses the resource modifier rules held in a ConfigMap,
+// which must have a single key whose value is the rules as YAML or JSON.
+func GetResourceModifiers(configMap *corev1api.ConfigMap) (*ResourceModifiers, error) {
+	if len(configMap.Data) != 1 {
+		return nil, errors.Errorf("ConfigMap %s must have exactly one key holding the resource modifier rules, but it has %d", configMap.Name, len(configMap.Data))
+	}
+
+	var data string
+	for _, val := range configMap.Data {
+		data = val
+	}
+
+	modifiers := new(ResourceModifiers)
+	if err := yaml.UnmarshalStrict([]byte(data), modifiers); err != nil {
+		return nil, errors.Wrapf(err, "error parsing resource modifier rules in ConfigMap %s", configMap.Name)
+	}
+
+	if modifiers.Version != ResourceModifiersVersion {
+		return nil, errors.Errorf("unsupported resource modifier rules version %q in ConfigMap %s, must be %q", modifiers.Version, configMap.Name, ResourceModifiersVersion)
+	}
+
+	for i := range modifiers.Rules {
+		if err := modifiers.Rules[i].compile(); err != nil {
+			return nil, errors.Wrapf(err, "invalid resource modifier rule %d in ConfigMap %s", i, configMap.Name)
+		}
+	}
+
+	return modifiers, nil
+}
+
+func (r *ResourceModifierRule) compile() error {
+	var err error
+
+	if r.groupResource, err = compileCondition("groupResource", r.Conditions.GroupResource); err != nil {
+		return err
+	}
+	if r.namespace, err = compileCondition("namespace", r.Conditions.Namespace); err != nil {
+		return err
+	}
+	if r.name, err = compileCondition("name", r.Conditions.Name); err != nil {
+		return err
+	}
+
+	if len(r.Patches) == 0 && len(r.MergePatches) == 0 {
+		return errors.New("rule has no patches or merge patches")
+	}
+
+	if len(r.Patches) > 0 {
+		patchBytes, err := json.Marshal(r.Patches)
+		if err != nil {
+			return errors.Wrap(err, "error marshalling patches")
+		}
+		if r.patch, err = jsonpatch.DecodePatch(patchBytes); err != nil {
+			return errors.Wrap(err, "invalid patches")
+		}
+	}
+
+	for _, mergePatch := range r.MergePatches {
+		patchBytes, err := json.Marshal(mergePatch)
+		if err != nil {
+			return errors.Wrap(err, "error marshalling merge patch")
+		}
+		r.mergePatches = append(r.mergePatches, patchBytes)
+	}
+
+	return nil
+}
+
+func compileCondition(field, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", expr))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s condition", field)
+	}
+	return re, nil
+}
+
+func (r *ResourceModifierRule) matches(groupResource string, obj *unstructured.Unstructured) bool {
+	return matchesCondition(r.groupResource, groupResource) &&
+		matchesCondition(r.namespace, obj.GetNamespace()) &&
+		matchesCondition(r.name, obj.GetName())
+}
+
+func matchesCondition(re *regexp.Regexp, val string) bool {
+	return re == nil || re.MatchString(val)
+}
+
+// Apply modifies obj, which is an item of the given group-qualified resource,
+// according to each rule that it matches, in order. It returns whether any
+// rules matched.
+func (m *ResourceModifiers) Apply(groupResource string, obj *unstructured.Unstructured, log logrus.FieldLogger) (bool, error) {
+	var modified bool
+
+	for i, rule := range m.Rules {
+		if !rule.matches(groupResource, obj) {
+			continue
+		}
+
+		log.Debugf("Applying resource modifier rule %d", i)
+
+		itemBytes, err := json.Marshal(obj.Object)
+		if err != nil {
+			return false, errors.Wrap(err, "error marshalling item")
+		}
+
+		if rule.patch != nil {
+			if itemBytes, err = rule.patch.Apply(itemBytes); err != nil {
+				return false, errors.Wrapf(err, "error applying patches from resource modifier rule %d", i)
+			}
+		}
+
+		for _, mergePatch := range rule.mergePatches {
+			if itemBytes, err = jsonpatch.MergePatch(itemBytes, mergePatch); err != nil {
+				return false, errors.Wrapf(err, "error applying merge patch from resource modifier rule %d", i)
+			}
+		}
+
+		updated := make(map[string]interface{})
+		if err := json.Unmarshal(itemBytes, &updated); err != nil {
+			return false, errors.Wrap(err, "error unmarshalling patched item")
+		}
+		obj.Object = updated
+		modified = true
+	}
+
+	return modified, nil
+}
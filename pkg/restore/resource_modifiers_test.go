@@ -0,0 +1,304 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/heptio/velero/pkg/builder"
+)
+
+func TestGetResourceModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]string
+		wantRules int
+		wantErr   string
+	}{
+		{
+			name: "valid rules are parsed",
+			data: map[string]string{"rules.yaml": `
+version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: deployments.apps
+    namespace: team-.*
+  patches:
+  - op: replace
+    path: /spec/replicas
+    value: 1
+- conditions:
+    name: web
+  mergePatches:
+  - metadata:
+      annotations:
+        foo: bar
+`},
+			wantRules: 2,
+		},
+		{
+			name:    "ConfigMap with no keys is invalid",
+			data:    map[string]string{},
+			wantErr: "ConfigMap modifiers must have exactly one key holding the resource modifier rules, but it has 0",
+		},
+		{
+			name:    "ConfigMap with more than one key is invalid",
+			data:    map[string]string{"a": "version: v1", "b": "version: v1"},
+			wantErr: "ConfigMap modifiers must have exactly one key holding the resource modifier rules, but it has 2",
+		},
+		{
+			name:    "unsupported version is invalid",
+			data:    map[string]string{"rules.yaml": "version: v2"},
+			wantErr: `unsupported resource modifier rules version "v2" in ConfigMap modifiers, must be "v1"`,
+		},
+		{
+			name:    "unknown fields are invalid",
+			data:    map[string]string{"rules.yaml": "version: v1\nrules: []"},
+			wantErr: "error parsing resource modifier rules in ConfigMap modifiers",
+		},
+		{
+			name: "invalid regex is invalid",
+			data: map[string]string{"rules.yaml": `
+version: v1
+resourceModifierRules:
+- conditions:
+    name: "web-("
+  mergePatches:
+  - metadata: {}
+`},
+			wantErr: "invalid resource modifier rule 0 in ConfigMap modifiers: invalid name condition",
+		},
+		{
+			name: "rule without patches is invalid",
+			data: map[string]string{"rules.yaml": `
+version: v1
+resourceModifierRules:
+- conditions:
+    name: web
+`},
+			wantErr: "invalid resource modifier rule 0 in ConfigMap modifiers: rule has no patches or merge patches",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			configMap := builder.ForConfigMap("velero", "modifiers").Result()
+			configMap.Data = tc.data
+
+			modifiers, err := GetResourceModifiers(configMap)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, modifiers.Rules, tc.wantRules)
+		})
+	}
+}
+
+func TestResourceModifiersApply(t *testing.T) {
+	rules := `
+version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: deployments.apps
+    namespace: team-.*
+  patches:
+  - op: replace
+    path: /spec/template/spec/containers/0/image
+    value: registry.example.com/app:1.0
+- conditions:
+    groupResource: ingresses.extensions
+  patches:
+  - op: replace
+    path: /spec/rules/0/host
+    value: app.new.example.com
+- conditions:
+    name: web
+  mergePatches:
+  - metadata:
+      annotations:
+        migrated: "true"
+`
+
+	deployment := func(namespace, name, image string, annotations map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": image},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	ingress := func(host string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "extensions/v1beta1",
+			"kind":       "Ingress",
+			"metadata": map[string]interface{}{
+				"namespace": "default",
+				"name":      "ingress",
+			},
+			"spec": map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{"host": host},
+				},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name          string
+		groupResource string
+		item          *unstructured.Unstructured
+		want          *unstructured.Unstructured
+		wantModified  bool
+	}{
+		{
+			name:          "matching rule's patches are applied",
+			groupResource: "deployments.apps",
+			item:          deployment("team-a", "api", "registry.old.com/app:1.0", nil),
+			want:          deployment("team-a", "api", "registry.example.com/app:1.0", nil),
+			wantModified:  true,
+		},
+		{
+			name:          "all matching rules are applied in order",
+			groupResource: "deployments.apps",
+			item:          deployment("team-a", "web", "registry.old.com/app:1.0", nil),
+			want:          deployment("team-a", "web", "registry.example.com/app:1.0", map[string]interface{}{"migrated": "true"}),
+			wantModified:  true,
+		},
+		{
+			name:          "item in a namespace that doesn't match isn't modified",
+			groupResource: "deployments.apps",
+			item:          deployment("ops", "api", "registry.old.com/app:1.0", nil),
+			want:          deployment("ops", "api", "registry.old.com/app:1.0", nil),
+		},
+		{
+			name:          "conditions must match the whole value",
+			groupResource: "deployments.apps",
+			item:          deployment("my-team-a", "api", "registry.old.com/app:1.0", nil),
+			want:          deployment("my-team-a", "api", "registry.old.com/app:1.0", nil),
+		},
+		{
+			name:          "ingress host is rewritten",
+			groupResource: "ingresses.extensions",
+			item:          ingress("app.old.example.com"),
+			want:          ingress("app.new.example.com"),
+			wantModified:  true,
+		},
+	}
+
+	configMap := builder.ForConfigMap("velero", "modifiers").Data("rules.yaml", rules).Result()
+	modifiers, err := GetResourceModifiers(configMap)
+	require.NoError(t, err)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			modified, err := modifiers.Apply(tc.groupResource, tc.item, logrus.StandardLogger())
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantModified, modified)
+			assert.Equal(t, tc.want, tc.item)
+		})
+	}
+}
+
+func TestResourceModifiersApplyNullValues(t *testing.T) {
+	rules := `
+version: v1
+resourceModifierRules:
+- conditions:
+    groupResource: configmaps
+  patches:
+  - op: replace
+    path: /data/replaced
+    value: null
+  - op: add
+    path: /data/added
+    value: null
+`
+
+	configMap := builder.ForConfigMap("velero", "modifiers").Data("rules.yaml", rules).Result()
+	modifiers, err := GetResourceModifiers(configMap)
+	require.NoError(t, err)
+
+	item := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "cm"},
+		"data":       map[string]interface{}{"replaced": "value"},
+	}}
+
+	modified, err := modifiers.Apply("configmaps", item, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, map[string]interface{}{"replaced": nil, "added": nil}, item.Object["data"])
+
+	// null values are kept when the operations are marshalled into a patch
+	patchBytes, err := json.Marshal(JSONPatchOperation{Op: "replace", Path: "/data/replaced"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"op": "replace", "path": "/data/replaced", "value": null}`, string(patchBytes))
+}
+
+func TestResourceModifiersApplyReturnsPatchErrors(t *testing.T) {
+	rules := `
+version: v1
+resourceModifierRules:
+- patches:
+  - op: replace
+    path: /spec/missing
+    value: foo
+`
+	configMap := builder.ForConfigMap("velero", "modifiers").Data("rules.yaml", rules).Result()
+	modifiers, err := GetResourceModifiers(configMap)
+	require.NoError(t, err)
+
+	item := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "cm"},
+		"spec":       map[string]interface{}{},
+	}}
+
+	_, err = modifiers.Apply("configmaps", item, logrus.StandardLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error applying patches from resource modifier rule 0")
+}
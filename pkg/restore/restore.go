@@ -21,11 +21,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -44,12 +43,16 @@ import (
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/client"
+	"github.com/heptio/velero/pkg/credentials"
 	"github.com/heptio/velero/pkg/discovery"
+	velerov1client "github.com/heptio/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	listers "github.com/heptio/velero/pkg/generated/listers/velero/v1"
 	"github.com/heptio/velero/pkg/kuberesource"
 	"github.com/heptio/velero/pkg/label"
 	"github.com/heptio/velero/pkg/plugin/velero"
+	"github.com/heptio/velero/pkg/podexec"
 	"github.com/heptio/velero/pkg/restic"
 	"github.com/heptio/velero/pkg/util/boolptr"
 	"github.com/heptio/velero/pkg/util/collections"
@@ -63,30 +66,51 @@ type VolumeSnapshotterGetter interface {
 	GetVolumeSnapshotter(name string) (velero.VolumeSnapshotter, error)
 }
 
+// BackupContents is where a restore reads a backup's items from. If the backup's
+// tarball has an index, items are read from the tarball as they're needed;
+// otherwise, the whole tarball is extracted to a temp directory first.
+type BackupContents struct {
+	// Reader reads the backup's tarball. It's only used if Index is nil.
+	Reader io.Reader
+
+	// Index is the index of the files in the backup's tarball, if it has one.
+	Index *archive.Index
+
+	// Source reads parts of the backup's tarball. It must be set if Index is.
+	Source io.ReaderAt
+}
+
 // Restorer knows how to restore a backup.
 type Restorer interface {
-	// Restore restores the backup data from backupReader, returning warnings and errors.
-	Restore(log logrus.FieldLogger,
+	// Restore restores the backup data from backupContents, returning warnings and errors.
+	// If the restore is a dry run, the cluster isn't changed, and a report of what
+	// the restore would do is returned too. If ctx is canceled, no more items are
+	// restored.
+	Restore(ctx go_context.Context,
+		log logrus.FieldLogger,
 		restore *api.Restore,
 		backup *api.Backup,
 		volumeSnapshots []*volume.Snapshot,
-		backupReader io.Reader,
+		backupContents BackupContents,
 		actions []velero.RestoreItemAction,
 		snapshotLocationLister listers.VolumeSnapshotLocationLister,
 		volumeSnapshotterGetter VolumeSnapshotterGetter,
-	) (Result, Result)
+	) (Result, Result, *DryRunReport)
 }
 
 // kubernetesRestorer implements Restorer for restoring into a Kubernetes cluster.
 type kubernetesRestorer struct {
+	restoreClient              velerov1client.RestoresGetter
 	discoveryHelper            discovery.Helper
 	dynamicFactory             client.DynamicFactory
 	namespaceClient            corev1.NamespaceInterface
+	podCommandExecutor         podexec.PodCommandExecutor
 	resticRestorerFactory      restic.RestorerFactory
 	resticTimeout              time.Duration
 	resourceTerminatingTimeout time.Duration
 	resourcePriorities         []string
 	fileSystem                 filesystem.Interface
+	credentialFileStore        credentials.FileStore
 	logger                     logrus.FieldLogger
 }
 
@@ -150,41 +174,50 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 
 // NewKubernetesRestorer creates a new kubernetesRestorer.
 func NewKubernetesRestorer(
+	restoreClient velerov1client.RestoresGetter,
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
 	resourcePriorities []string,
 	namespaceClient corev1.NamespaceInterface,
+	podCommandExecutor podexec.PodCommandExecutor,
 	resticRestorerFactory restic.RestorerFactory,
 	resticTimeout time.Duration,
 	resourceTerminatingTimeout time.Duration,
+	credentialFileStore credentials.FileStore,
 	logger logrus.FieldLogger,
 ) (Restorer, error) {
 	return &kubernetesRestorer{
+		restoreClient:              restoreClient,
 		discoveryHelper:            discoveryHelper,
 		dynamicFactory:             dynamicFactory,
 		namespaceClient:            namespaceClient,
+		podCommandExecutor:         podCommandExecutor,
 		resticRestorerFactory:      resticRestorerFactory,
 		resticTimeout:              resticTimeout,
 		resourceTerminatingTimeout: resourceTerminatingTimeout,
 		resourcePriorities:         resourcePriorities,
+		credentialFileStore:        credentialFileStore,
 		logger:                     logger,
 		fileSystem:                 filesystem.NewFileSystem(),
 	}, nil
 }
 
 // Restore executes a restore into the target Kubernetes cluster according to the restore spec
-// and using data from the provided backup/backup reader. Returns a warnings and errors RestoreResult,
-// respectively, summarizing info about the restore.
+// and using data from the provided backup/backup contents. Returns a warnings and errors RestoreResult,
+// respectively, summarizing info about the restore, and for dry runs, a report of what the restore
+// would do. If ctx is canceled, no more items are restored, and any restic restores
+// and restore hooks that are still waiting are stopped.
 func (kr *kubernetesRestorer) Restore(
+	ctx go_context.Context,
 	log logrus.FieldLogger,
 	restore *api.Restore,
 	backup *api.Backup,
 	volumeSnapshots []*volume.Snapshot,
-	backupReader io.Reader,
+	backupContents BackupContents,
 	actions []velero.RestoreItemAction,
 	snapshotLocationLister listers.VolumeSnapshotLocationLister,
 	volumeSnapshotterGetter VolumeSnapshotterGetter,
-) (Result, Result) {
+) (Result, Result, *DryRunReport) {
 	// metav1.LabelSelectorAsSelector converts a nil LabelSelector to a
 	// Nothing Selector, i.e. a selector that matches nothing. We want
 	// a selector that matches everything. This can be accomplished by
@@ -196,14 +229,14 @@ func (kr *kubernetesRestorer) Restore(
 
 	selector, err := metav1.LabelSelectorAsSelector(ls)
 	if err != nil {
-		return Result{}, Result{Velero: []string{err.Error()}}
+		return Result{}, Result{Velero: []string{err.Error()}}, nil
 	}
 
 	// get resource includes-excludes
 	resourceIncludesExcludes := getResourceIncludesExcludes(kr.discoveryHelper, restore.Spec.IncludedResources, restore.Spec.ExcludedResources)
 	prioritizedResources, err := prioritizeResources(kr.discoveryHelper, kr.resourcePriorities, resourceIncludesExcludes, log)
 	if err != nil {
-		return Result{}, Result{Velero: []string{err.Error()}}
+		return Result{}, Result{Velero: []string{err.Error()}}, nil
 	}
 
 	// get namespace includes-excludes
@@ -211,9 +244,19 @@ func (kr *kubernetesRestorer) Restore(
 		Includes(restore.Spec.IncludedNamespaces...).
 		Excludes(restore.Spec.ExcludedNamespaces...)
 
+	includedItems, err := resolveIncludedItems(kr.discoveryHelper, restore.Spec.IncludedItems)
+	if err != nil {
+		return Result{}, Result{Velero: []string{err.Error()}}, nil
+	}
+
 	resolvedActions, err := resolveActions(actions, kr.discoveryHelper)
 	if err != nil {
-		return Result{}, Result{Velero: []string{err.Error()}}
+		return Result{}, Result{Velero: []string{err.Error()}}, nil
+	}
+
+	restoreHooks, err := getRestoreHooks(restore.Spec.Hooks.Resources)
+	if err != nil {
+		return Result{}, Result{Velero: []string{err.Error()}}, nil
 	}
 
 	podVolumeTimeout := kr.resticTimeout
@@ -226,14 +269,14 @@ func (kr *kubernetesRestorer) Restore(
 		}
 	}
 
-	ctx, cancelFunc := go_context.WithTimeout(go_context.Background(), podVolumeTimeout)
+	resticCtx, cancelFunc := go_context.WithTimeout(ctx, podVolumeTimeout)
 	defer cancelFunc()
 
 	var resticRestorer restic.Restorer
 	if kr.resticRestorerFactory != nil {
-		resticRestorer, err = kr.resticRestorerFactory.NewRestorer(ctx, restore)
+		resticRestorer, err = kr.resticRestorerFactory.NewRestorer(resticCtx, restore)
 		if err != nil {
-			return Result{}, Result{Velero: []string{err.Error()}}
+			return Result{}, Result{Velero: []string{err.Error()}}, nil
 		}
 	}
 
@@ -245,14 +288,17 @@ func (kr *kubernetesRestorer) Restore(
 		volumeSnapshots:         volumeSnapshots,
 		volumeSnapshotterGetter: volumeSnapshotterGetter,
 		snapshotLocationLister:  snapshotLocationLister,
+		credentialFileStore:     kr.credentialFileStore,
 	}
 
 	restoreCtx := &context{
+		cancelCtx:                  ctx,
 		backup:                     backup,
-		backupReader:               backupReader,
+		backupContents:             backupContents,
 		restore:                    restore,
 		resourceIncludesExcludes:   resourceIncludesExcludes,
 		namespaceIncludesExcludes:  namespaceIncludesExcludes,
+		includedItems:              includedItems,
 		prioritizedResources:       prioritizedResources,
 		selector:                   selector,
 		log:                        log,
@@ -260,6 +306,8 @@ func (kr *kubernetesRestorer) Restore(
 		fileSystem:                 kr.fileSystem,
 		namespaceClient:            kr.namespaceClient,
 		actions:                    resolvedActions,
+		restoreHooks:               restoreHooks,
+		podCommandExecutor:         kr.podCommandExecutor,
 		volumeSnapshotterGetter:    volumeSnapshotterGetter,
 		resticRestorer:             resticRestorer,
 		pvsToProvision:             sets.NewString(),
@@ -272,9 +320,44 @@ func (kr *kubernetesRestorer) Restore(
 		},
 		resourceClients: make(map[resourceClientKey]client.Dynamic),
 		restoredItems:   make(map[velero.ResourceIdentifier]struct{}),
+		progress:        new(progressTracker),
+		discoveryHelper: kr.discoveryHelper,
+	}
+
+	if restore.Spec.DryRun {
+		log.Info("Restore is a dry run, the cluster won't be changed")
+		restoreCtx.dryRun = new(DryRunReport)
 	}
 
-	return restoreCtx.execute()
+	if kr.restoreClient != nil {
+		reporter := &progressReporter{
+			restoreClient: kr.restoreClient,
+			restore:       restore,
+			tracker:       restoreCtx.progress,
+			interval:      progressUpdateInterval,
+			log:           log,
+		}
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			reporter.run(stop)
+		}()
+		defer func() {
+			close(stop)
+			<-done
+		}()
+	}
+
+	warnings, errs := restoreCtx.execute()
+
+	// record the final progress so it's included in the restore's
+	// final status.
+	progress := restoreCtx.progress.progress()
+	restore.Status.Progress = &progress
+
+	return warnings, errs, restoreCtx.dryRun
 }
 
 // getResourceIncludesExcludes takes the lists of resources to include and exclude, uses the
@@ -340,11 +423,12 @@ func resolveActions(actions []velero.RestoreItemAction, helper discovery.Helper)
 
 type context struct {
 	backup                     *api.Backup
-	backupReader               io.Reader
+	backupContents             BackupContents
 	restore                    *api.Restore
 	restoreDir                 string
 	resourceIncludesExcludes   *collections.IncludesExcludes
 	namespaceIncludesExcludes  *collections.IncludesExcludes
+	includedItems              *includedItems
 	prioritizedResources       []schema.GroupResource
 	selector                   labels.Selector
 	log                        logrus.FieldLogger
@@ -352,9 +436,15 @@ type context struct {
 	fileSystem                 filesystem.Interface
 	namespaceClient            corev1.NamespaceInterface
 	actions                    []resolvedAction
+	restoreHooks               []restoreHook
+	podCommandExecutor         podexec.PodCommandExecutor
 	volumeSnapshotterGetter    VolumeSnapshotterGetter
 	resticRestorer             restic.Restorer
 	globalWaitGroup            velerosync.ErrorGroup
+	hooksWaitGroup             sync.WaitGroup
+	hooksLock                  sync.Mutex
+	hooksWarnings              Result
+	hooksErrs                  Result
 	pvsToProvision             sets.String
 	pvRestorer                 PVRestorer
 	volumeSnapshots            []*volume.Snapshot
@@ -362,6 +452,19 @@ type context struct {
 	extractor                  *backupExtractor
 	resourceClients            map[resourceClientKey]client.Dynamic
 	restoredItems              map[velero.ResourceIdentifier]struct{}
+	progress                   *progressTracker
+	discoveryHelper            discovery.Helper
+	// cancelCtx is canceled if the restore is canceled while it's running.
+	cancelCtx go_context.Context
+	// dryRun records what the restore would do if it's a dry run, and is nil
+	// otherwise.
+	dryRun *DryRunReport
+	// resourceDirs maps each resource in the backup to the directory its items
+	// are restored from.
+	resourceDirs map[string]string
+	// csiSnapshots maps the namespace/name of each PersistentVolumeClaim that was
+	// backed up using a CSI snapshot to the name of its VolumeSnapshot.
+	csiSnapshots map[string]string
 }
 
 type resourceClientKey struct {
@@ -372,7 +475,18 @@ type resourceClientKey struct {
 func (ctx *context) execute() (Result, Result) {
 	ctx.log.Infof("Starting restore of backup %s", kube.NamespaceAndName(ctx.backup))
 
-	dir, err := ctx.extractor.unzipAndExtractBackup(ctx.backupReader)
+	if ctx.backupContents.Index != nil {
+		// read items from the tarball as they're needed instead of
+		// extracting it, so that restoring part of a large backup
+		// doesn't need space for all of it.
+		ctx.log.Info("Restoring from the backup tarball's index")
+		ctx.fileSystem = archive.NewFileSystem(ctx.backupContents.Index, ctx.backupContents.Source)
+		ctx.restoreDir = "/"
+
+		return ctx.restoreFromDir()
+	}
+
+	dir, err := ctx.extractor.unzipAndExtractBackup(ctx.backupContents.Reader, archive.Codec(ctx.backup.Status.CompressionCodec))
 	if err != nil {
 		ctx.log.Infof("error unzipping and extracting: %v", err)
 		return Result{}, Result{Velero: []string{err.Error()}}
@@ -408,28 +522,45 @@ func (ctx *context) restoreFromDir() (Result, Result) {
 		return warnings, errs
 	}
 
-	resourceDirsMap := make(map[string]os.FileInfo)
+	ctx.resourceDirs = make(map[string]string)
 	for _, rscDir := range resourceDirs {
 		rscName := rscDir.Name()
-		resourceDirsMap[rscName] = rscDir
+		resourcePath, err := ctx.resolveResourceDir(rscName, filepath.Join(resourcesDir, rscName))
+		if err != nil {
+			addVeleroError(&errs, err)
+			return warnings, errs
+		}
+		ctx.resourceDirs[rscName] = resourcePath
+	}
+
+	if err := ctx.loadCSISnapshots(); err != nil {
+		addVeleroError(&errs, err)
+		return warnings, errs
+	}
+
+	if err := ctx.estimateTotalItems(); err != nil {
+		ctx.log.WithError(err).Warn("Error estimating the number of items to restore")
 	}
 
 	existingNamespaces := sets.NewString()
 
 	for _, resource := range ctx.prioritizedResources {
+		if ctx.canceled() {
+			ctx.log.Info("Restore was canceled, not restoring any more resources")
+			break
+		}
+
 		// we don't want to explicitly restore namespace API objs because we'll handle
 		// them as a special case prior to restoring anything into them
 		if resource == kuberesource.Namespaces {
 			continue
 		}
 
-		rscDir := resourceDirsMap[resource.String()]
-		if rscDir == nil {
+		resourcePath, ok := ctx.resourceDirs[resource.String()]
+		if !ok {
 			continue
 		}
 
-		resourcePath := filepath.Join(resourcesDir, rscDir.Name())
-
 		clusterSubDir := filepath.Join(resourcePath, api.ClusterScopedDir)
 		clusterSubDirExists, err := ctx.fileSystem.DirExists(clusterSubDir)
 		if err != nil {
@@ -471,6 +602,13 @@ func (ctx *context) restoreFromDir() (Result, Result) {
 				continue
 			}
 
+			// any of the included items' additional items in this namespace
+			// are restored along with them, so there's nothing to restore
+			// here unless the namespace contains one of the included items.
+			if !ctx.includedItems.includesNamespace(nsName) {
+				continue
+			}
+
 			// fetch mapped NS name
 			mappedNsName := nsName
 			if target, ok := ctx.restore.Spec.NamespaceMapping[nsName]; ok {
@@ -483,8 +621,13 @@ func (ctx *context) restoreFromDir() (Result, Result) {
 			// create a blank one.
 			if !existingNamespaces.Has(mappedNsName) {
 				logger := ctx.log.WithField("namespace", nsName)
-				ns := getNamespace(logger, getItemFilePath(ctx.restoreDir, "namespaces", "", nsName), mappedNsName)
-				if _, err := kube.EnsureNamespaceExistsAndIsReady(ns, ctx.namespaceClient, ctx.resourceTerminatingTimeout); err != nil {
+				ns := getNamespace(logger, ctx.fileSystem, ctx.itemFilePath(kuberesource.Namespaces.String(), "", nsName), mappedNsName)
+				if ctx.dryRun != nil {
+					if err := ctx.dryRunNamespace(mappedNsName); err != nil {
+						addVeleroError(&errs, err)
+						continue
+					}
+				} else if _, err := kube.EnsureNamespaceExistsAndIsReady(ns, ctx.namespaceClient, ctx.resourceTerminatingTimeout); err != nil {
 					addVeleroError(&errs, err)
 					continue
 				}
@@ -512,27 +655,112 @@ func (ctx *context) restoreFromDir() (Result, Result) {
 		errs.Velero = append(errs.Velero, err.Error())
 	}
 
+	ctx.log.Debug("Waiting for restore hooks to complete")
+	ctx.hooksWaitGroup.Wait()
+	ctx.log.Debug("Done waiting for restore hooks to complete")
+
+	merge(&warnings, &ctx.hooksWarnings)
+	merge(&errs, &ctx.hooksErrs)
+
 	return warnings, errs
 }
 
+// estimateTotalItems records the number of items in the backup for each resource
+// that's going to be restored, so that progress can be reported as the restore
+// proceeds. Items that are later filtered out by the restore's label selector or
+// included items are removed from the totals as they're encountered.
+func (ctx *context) estimateTotalItems() error {
+	for _, resource := range ctx.prioritizedResources {
+		if resource == kuberesource.Namespaces {
+			continue
+		}
+
+		resourcePath, ok := ctx.resourceDirs[resource.String()]
+		if !ok {
+			continue
+		}
+
+		clusterSubDir := filepath.Join(resourcePath, api.ClusterScopedDir)
+		clusterSubDirExists, err := ctx.fileSystem.DirExists(clusterSubDir)
+		if err != nil {
+			return err
+		}
+		if clusterSubDirExists {
+			if boolptr.IsSetToFalse(ctx.restore.Spec.IncludeClusterResources) {
+				continue
+			}
+
+			files, err := ctx.fileSystem.ReadDir(clusterSubDir)
+			if err != nil {
+				return errors.Wrapf(err, "error reading %q resource directory", resource.String())
+			}
+			ctx.progress.addTotalItems(resource.String(), len(files))
+			continue
+		}
+
+		nsSubDir := filepath.Join(resourcePath, api.NamespaceScopedDir)
+		nsSubDirExists, err := ctx.fileSystem.DirExists(nsSubDir)
+		if err != nil {
+			return err
+		}
+		if !nsSubDirExists {
+			continue
+		}
+
+		nsDirs, err := ctx.fileSystem.ReadDir(nsSubDir)
+		if err != nil {
+			return errors.Wrapf(err, "error reading %q resource directory", resource.String())
+		}
+
+		for _, nsDir := range nsDirs {
+			if !nsDir.IsDir() || !ctx.namespaceIncludesExcludes.ShouldInclude(nsDir.Name()) || !ctx.includedItems.includesNamespace(nsDir.Name()) {
+				continue
+			}
+
+			files, err := ctx.fileSystem.ReadDir(filepath.Join(nsSubDir, nsDir.Name()))
+			if err != nil {
+				return errors.Wrapf(err, "error reading %q resource directory", resource.String())
+			}
+			ctx.progress.addTotalItems(resource.String(), len(files))
+		}
+	}
+
+	return nil
+}
+
 func getItemFilePath(rootDir, groupResource, namespace, name string) string {
+	return getItemFilePathInDir(filepath.Join(rootDir, api.ResourcesDir, groupResource), namespace, name)
+}
+
+// getItemFilePathInDir returns the path of an item within the directory containing
+// its resource's cluster-scoped or namespaced items.
+func getItemFilePathInDir(resourceDir, namespace, name string) string {
 	switch namespace {
 	case "":
-		return filepath.Join(rootDir, api.ResourcesDir, groupResource, api.ClusterScopedDir, name+".json")
+		return filepath.Join(resourceDir, api.ClusterScopedDir, name+".json")
 	default:
-		return filepath.Join(rootDir, api.ResourcesDir, groupResource, api.NamespaceScopedDir, namespace, name+".json")
+		return filepath.Join(resourceDir, api.NamespaceScopedDir, namespace, name+".json")
+	}
+}
+
+// itemFilePath returns the path of an item in the extracted backup, taking into
+// account which API version of its resource is being restored.
+func (ctx *context) itemFilePath(groupResource, namespace, name string) string {
+	if resourceDir, ok := ctx.resourceDirs[groupResource]; ok {
+		return getItemFilePathInDir(resourceDir, namespace, name)
 	}
+	return getItemFilePath(ctx.restoreDir, groupResource, namespace, name)
 }
 
 // getNamespace returns a namespace API object that we should attempt to
 // create before restoring anything into it. It will come from the backup
 // tarball if it exists, else will be a new one. If from the tarball, it
 // will retain its labels, annotations, and spec.
-func getNamespace(logger logrus.FieldLogger, path, remappedName string) *v1.Namespace {
+func getNamespace(logger logrus.FieldLogger, fileSystem filesystem.Interface, path, remappedName string) *v1.Namespace {
 	var nsBytes []byte
 	var err error
 
-	if nsBytes, err = ioutil.ReadFile(path); err != nil {
+	if nsBytes, err = fileSystem.ReadFile(path); err != nil {
 		return &v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: remappedName,
@@ -582,13 +810,17 @@ func addVeleroError(r *Result, err error) {
 // the cluster-scoped list (if ns == "") or within the provided namespace's
 // entry.
 func addToResult(r *Result, ns string, e error) {
+	addMessage(r, ns, e.Error())
+}
+
+func addMessage(r *Result, ns string, msg string) {
 	if ns == "" {
-		r.Cluster = append(r.Cluster, e.Error())
+		r.Cluster = append(r.Cluster, msg)
 	} else {
 		if r.Namespaces == nil {
 			r.Namespaces = make(map[string][]string)
 		}
-		r.Namespaces[ns] = append(r.Namespaces[ns], e.Error())
+		r.Namespaces[ns] = append(r.Namespaces[ns], msg)
 	}
 }
 
@@ -734,25 +966,42 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (R
 	groupResource := schema.ParseGroupResource(resource)
 
 	for _, file := range files {
+		if ctx.canceled() {
+			break
+		}
+
 		fullPath := filepath.Join(resourcePath, file.Name())
 		obj, err := ctx.unmarshal(fullPath)
 		if err != nil {
-			addToResult(&errs, namespace, fmt.Errorf("error decoding %q: %v", strings.Replace(fullPath, ctx.restoreDir+"/", "", -1), err))
+			addToResult(&errs, namespace, fmt.Errorf("error decoding %q: %v", strings.TrimPrefix(fullPath, strings.TrimSuffix(ctx.restoreDir, "/")+"/"), err))
+			ctx.progress.itemRestored(resource)
 			continue
 		}
 
 		if !ctx.selector.Matches(labels.Set(obj.GetLabels())) {
+			ctx.progress.addTotalItems(resource, -1)
+			continue
+		}
+
+		if !ctx.includedItems.includes(groupResource, obj.GetNamespace(), obj.GetName()) {
+			ctx.progress.addTotalItems(resource, -1)
 			continue
 		}
 
 		w, e := ctx.restoreItem(obj, groupResource, namespace)
 		merge(&warnings, &w)
 		merge(&errs, &e)
+		ctx.progress.itemRestored(resource)
 	}
 
 	return warnings, errs
 }
 
+// canceled returns whether the restore has been canceled.
+func (ctx *context) canceled() bool {
+	return ctx.cancelCtx.Err() != nil
+}
+
 func (ctx *context) getResourceClient(groupResource schema.GroupResource, obj *unstructured.Unstructured, namespace string) (client.Dynamic, error) {
 	key := resourceClientKey{
 		resource:  groupResource,
@@ -802,6 +1051,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 			"name":          obj.GetName(),
 			"groupResource": groupResource.String(),
 		}).Info("Not restoring item because resource is excluded")
+		ctx.dryRun.skipped(namespace, resourceID, "its resource is excluded")
 		return warnings, errs
 	}
 
@@ -816,6 +1066,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 				"name":          obj.GetName(),
 				"groupResource": groupResource.String(),
 			}).Info("Not restoring item because namespace is excluded")
+			ctx.dryRun.skipped(namespace, resourceID, "its namespace is excluded")
 			return warnings, errs
 		}
 	} else {
@@ -825,6 +1076,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 				"name":          obj.GetName(),
 				"groupResource": groupResource.String(),
 			}).Info("Not restoring item because it's cluster-scoped")
+			ctx.dryRun.skipped(namespace, resourceID, "cluster-scoped resources are excluded")
 			return warnings, errs
 		}
 	}
@@ -840,9 +1092,26 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 	}
 	if complete {
 		ctx.log.Infof("%s is complete - skipping", kube.NamespaceAndName(obj))
+		ctx.dryRun.skipped(namespace, resourceID, "it's complete")
 		return warnings, errs
 	}
 
+	// CSI snapshot objects are restored as pre-provisioned snapshots, which may
+	// change their names, so this has to happen before checking whether they've
+	// already been restored.
+	switch groupResource {
+	case kuberesource.VolumeSnapshotContents:
+		if err := prepareVolumeSnapshotContent(obj, ctx.restore); err != nil {
+			addToResult(&errs, namespace, err)
+			return warnings, errs
+		}
+	case kuberesource.VolumeSnapshots:
+		if err := prepareVolumeSnapshot(obj, ctx.restore); err != nil {
+			addToResult(&errs, namespace, err)
+			return warnings, errs
+		}
+	}
+
 	name := obj.GetName()
 
 	// Check if we've already restored this
@@ -860,6 +1129,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 	// TODO: move to restore item action if/when we add a ShouldRestore() method to the interface
 	if groupResource == kuberesource.Pods && obj.GetAnnotations()[v1.MirrorPodAnnotationKey] != "" {
 		ctx.log.Infof("Not restoring pod because it's a mirror pod")
+		ctx.dryRun.skipped(namespace, resourceID, "it's a mirror pod")
 		return warnings, errs
 	}
 
@@ -870,6 +1140,15 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 	}
 
 	if groupResource == kuberesource.PersistentVolumes {
+		claimNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "claimRef", "namespace")
+		claimName, _, _ := unstructured.NestedString(obj.Object, "spec", "claimRef", "name")
+		if _, ok := ctx.csiSnapshotFor(claimNamespace, claimName); ok {
+			ctx.log.Infof("Not restoring PV because its claim will be provisioned from a CSI snapshot.")
+			ctx.pvsToProvision.Insert(name)
+			ctx.dryRun.skipped(namespace, resourceID, "its claim would be provisioned from a CSI snapshot")
+			return warnings, errs
+		}
+
 		var hasSnapshot bool
 
 		for _, snapshot := range ctx.volumeSnapshots {
@@ -882,6 +1161,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 		if !hasSnapshot && hasDeleteReclaimPolicy(obj.Object) {
 			ctx.log.Infof("Not restoring PV because it doesn't have a snapshot and its reclaim policy is Delete.")
 			ctx.pvsToProvision.Insert(name)
+			ctx.dryRun.skipped(namespace, resourceID, "it doesn't have a snapshot and its reclaim policy is Delete, so it would be dynamically provisioned")
 			return warnings, errs
 		}
 
@@ -894,7 +1174,9 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 		}
 
 		// PV's existence will be recorded later. Just skip the volume restore logic.
-		if shouldRestoreSnapshot {
+		if shouldRestoreSnapshot && ctx.dryRun != nil {
+			ctx.log.Infof("Not restoring PV from snapshot because the restore is a dry run")
+		} else if shouldRestoreSnapshot {
 			// restore the PV from snapshot (if applicable)
 			updatedObj, err := ctx.pvRestorer.executePVAction(obj)
 			if err != nil {
@@ -933,6 +1215,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 
 		if executeOutput.SkipRestore {
 			ctx.log.Infof("Skipping restore of %s: %v because a registered plugin discarded it", obj.GroupVersionKind().Kind, name)
+			ctx.dryRun.skipped(namespace, resourceID, "a restore item action discarded it")
 			return warnings, errs
 		}
 		unstructuredObj, ok := executeOutput.UpdatedItem.(*unstructured.Unstructured)
@@ -944,7 +1227,7 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 		obj = unstructuredObj
 
 		for _, additionalItem := range executeOutput.AdditionalItems {
-			itemPath := getItemFilePath(ctx.restoreDir, additionalItem.GroupResource.String(), additionalItem.Namespace, additionalItem.Name)
+			itemPath := ctx.itemFilePath(additionalItem.GroupResource.String(), additionalItem.Namespace, additionalItem.Name)
 
 			if _, err := ctx.fileSystem.Stat(itemPath); err != nil {
 				ctx.log.WithError(err).WithFields(logrus.Fields{
@@ -1005,6 +1288,21 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 		}
 	}
 
+	if groupResource == kuberesource.PersistentVolumeClaims {
+		if snapshotName, ok := ctx.csiSnapshotFor(obj.GetNamespace(), name); ok {
+			ctx.log.Infof("Restoring PersistentVolumeClaim %s/%s from CSI VolumeSnapshot %s", namespace, name, snapshotName)
+
+			w, e := ctx.restoreCSISnapshot(obj.GetNamespace(), snapshotName, namespace)
+			merge(&warnings, &w)
+			merge(&errs, &e)
+
+			if err := setCSISnapshotDataSource(obj, snapshotName); err != nil {
+				addToResult(&errs, namespace, err)
+				return warnings, errs
+			}
+		}
+	}
+
 	// necessary because we may have remapped the namespace
 	// if the namespace is blank, don't create the key
 	originalNamespace := obj.GetNamespace()
@@ -1017,6 +1315,13 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 	// and which backup they came from
 	addRestoreLabels(obj, ctx.restore.Name, ctx.restore.Spec.BackupName)
 
+	if ctx.dryRun != nil {
+		if err := ctx.dryRunItem(resourceClient, obj, groupResource, namespace); err != nil {
+			addToResult(&errs, namespace, err)
+		}
+		return warnings, errs
+	}
+
 	ctx.log.Infof("Attempting to restore %s: %v", obj.GroupVersionKind().Kind, name)
 	createdObj, restoreErr := resourceClient.Create(obj)
 	if apierrors.IsAlreadyExists(restoreErr) {
@@ -1034,48 +1339,82 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 			return warnings, errs
 		}
 
+		// keep a copy of the cluster version as it is, so that updating it also
+		// adds the restore labels
+		liveObj := fromCluster.DeepCopy()
+
 		// We know the object from the cluster won't have the backup/restore name labels, so
 		// copy them from the object we attempted to restore.
 		labels := obj.GetLabels()
 		addRestoreLabels(fromCluster, labels[api.RestoreNameLabel], labels[api.BackupNameLabel])
 
-		if !equality.Semantic.DeepEqual(fromCluster, obj) {
-			switch groupResource {
-			case kuberesource.ServiceAccounts:
-				desired, err := mergeServiceAccounts(fromCluster, obj)
-				if err != nil {
-					ctx.log.Infof("error merging secrets for ServiceAccount %s: %v", kube.NamespaceAndName(obj), err)
-					addToResult(&warnings, namespace, err)
-					return warnings, errs
-				}
+		if equality.Semantic.DeepEqual(fromCluster, obj) {
+			ctx.log.Infof("Skipping restore of %s: %v because it already exists in the cluster and is unchanged from the backed up version", obj.GroupVersionKind().Kind, name)
+			return warnings, errs
+		}
 
-				patchBytes, err := generatePatch(fromCluster, desired)
-				if err != nil {
-					ctx.log.Infof("error generating patch for ServiceAccount %s: %v", kube.NamespaceAndName(obj), err)
-					addToResult(&warnings, namespace, err)
-					return warnings, errs
-				}
+		policy := ctx.restore.Spec.ExistingResourcePolicy
+		if policy == api.ExistingResourcePolicyRecreate && !canRecreate(groupResource) {
+			ctx.log.Infof("Updating %s %s instead of recreating it, because deleting it would delete its contents", groupResource, kube.NamespaceAndName(obj))
+			policy = api.ExistingResourcePolicyUpdate
+		}
 
-				if patchBytes == nil {
-					// In-cluster and desired state are the same, so move on to the next item
-					return warnings, errs
-				}
+		switch {
+		case groupResource == kuberesource.ServiceAccounts:
+			desired, err := mergeServiceAccounts(fromCluster, obj)
+			if err != nil {
+				ctx.log.Infof("error merging secrets for ServiceAccount %s: %v", kube.NamespaceAndName(obj), err)
+				addToResult(&warnings, namespace, err)
+				return warnings, errs
+			}
 
-				_, err = resourceClient.Patch(name, patchBytes)
-				if err != nil {
-					addToResult(&warnings, namespace, err)
-				} else {
-					ctx.log.Infof("ServiceAccount %s successfully updated", kube.NamespaceAndName(obj))
-				}
-			default:
-				e := errors.Errorf("not restored: %s and is different from backed up version.", restoreErr)
-				addToResult(&warnings, namespace, e)
+			patchBytes, err := generatePatch(fromCluster, desired)
+			if err != nil {
+				ctx.log.Infof("error generating patch for ServiceAccount %s: %v", kube.NamespaceAndName(obj), err)
+				addToResult(&warnings, namespace, err)
+				return warnings, errs
+			}
+
+			if patchBytes == nil {
+				// In-cluster and desired state are the same, so move on to the next item
+				return warnings, errs
+			}
+
+			_, err = resourceClient.Patch(name, patchBytes)
+			if err != nil {
+				addToResult(&warnings, namespace, err)
+			} else {
+				ctx.log.Infof("ServiceAccount %s successfully updated", kube.NamespaceAndName(obj))
 			}
 			return warnings, errs
-		}
+		case policy == api.ExistingResourcePolicyUpdate:
+			patchBytes, err := existingItemPatch(liveObj, obj)
+			if err != nil {
+				ctx.log.Infof("error generating patch for %s %s: %v", groupResource, kube.NamespaceAndName(obj), err)
+				addToResult(&warnings, namespace, err)
+				return warnings, errs
+			}
 
-		ctx.log.Infof("Skipping restore of %s: %v because it already exists in the cluster and is unchanged from the backed up version", obj.GroupVersionKind().Kind, name)
-		return warnings, errs
+			if patchBytes == nil {
+				return warnings, errs
+			}
+
+			if _, err := resourceClient.Patch(name, patchBytes); err != nil {
+				addToResult(&warnings, namespace, errors.Errorf("error updating existing %s: %v", resourceID, err))
+			} else {
+				ctx.log.Infof("%s %s successfully updated", groupResource, kube.NamespaceAndName(obj))
+			}
+			return warnings, errs
+		case policy == api.ExistingResourcePolicyRecreate:
+			ctx.log.Infof("Recreating %s %s", groupResource, kube.NamespaceAndName(obj))
+			// carry on as if the item was just created, so e.g. restic restores
+			// and hooks run for recreated pods.
+			createdObj, restoreErr = recreateItem(resourceClient, obj, ctx.resourceTerminatingTimeout)
+		default:
+			e := errors.Errorf("not restored: %s and is different from backed up version.", restoreErr)
+			addToResult(&warnings, namespace, e)
+			return warnings, errs
+		}
 	}
 
 	// Error was something other than an AlreadyExists
@@ -1106,9 +1445,56 @@ func (ctx *context) restoreItem(obj *unstructured.Unstructured, groupResource sc
 		}
 	}
 
+	if groupResource == kuberesource.Pods {
+		ctx.runExecHooks(createdObj, resourceClient, originalNamespace)
+	}
+
 	return warnings, errs
 }
 
+// runExecHooks starts executing any exec restore hooks that apply to the restored pod
+// in the background. The hooks wait for their containers to be running, so this
+// returns immediately; results are recorded once ctx.hooksWaitGroup is done.
+func (ctx *context) runExecHooks(pod *unstructured.Unstructured, podClient client.Dynamic, originalNamespace string) {
+	execHooks := getExecHooks(originalNamespace, labels.Set(pod.GetLabels()), ctx.restoreHooks)
+	if len(execHooks) == 0 {
+		return
+	}
+
+	if ctx.podCommandExecutor == nil {
+		ctx.log.Warn("No pod command executor, not running pod's restore hooks")
+		return
+	}
+
+	runner := &execHookRunner{
+		podCommandExecutor: ctx.podCommandExecutor,
+		podClient:          podClient,
+		pollInterval:       time.Second,
+		done:               ctx.cancelCtx.Done(),
+	}
+	namespace, name := pod.GetNamespace(), pod.GetName()
+	log := ctx.log.WithFields(logrus.Fields{
+		"namespace": namespace,
+		"name":      name,
+	})
+
+	ctx.hooksWaitGroup.Add(1)
+	go func() {
+		defer ctx.hooksWaitGroup.Done()
+
+		warnings, errs := runner.runHooks(log, namespace, name, execHooks)
+
+		ctx.hooksLock.Lock()
+		defer ctx.hooksLock.Unlock()
+		for _, err := range warnings {
+			addToResult(&ctx.hooksWarnings, namespace, err)
+		}
+		for _, err := range errs {
+			addToResult(&ctx.hooksErrs, namespace, err)
+		}
+	}()
+}
+
 func hasDeleteReclaimPolicy(obj map[string]interface{}) bool {
 	policy, _, _ := unstructured.NestedString(obj, "spec", "persistentVolumeReclaimPolicy")
 	return policy == string(v1.PersistentVolumeReclaimDelete)
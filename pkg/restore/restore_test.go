@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	go_context "context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -44,6 +45,7 @@ import (
 	kubetesting "k8s.io/client-go/testing"
 
 	velerov1api "github.com/heptio/velero/pkg/apis/velero/v1"
+	"github.com/heptio/velero/pkg/archive"
 	"github.com/heptio/velero/pkg/builder"
 	"github.com/heptio/velero/pkg/client"
 	"github.com/heptio/velero/pkg/discovery"
@@ -507,12 +509,98 @@ func TestRestoreResourceFiltering(t *testing.T) {
 			}
 			require.NoError(t, h.restorer.discoveryHelper.Refresh())
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
+				nil, // actions
+				nil, // snapshot location lister
+				nil, // volume snapshotter getter
+			)
+
+			assertEmptyResults(t, warnings, errs)
+			assertAPIContents(t, h, tc.want)
+		})
+	}
+}
+
+// TestRestoreFromIndexedTarball runs restores from tarballs' indexes instead of
+// extracting them, and verifies that the set of items created in the API are correct.
+func TestRestoreFromIndexedTarball(t *testing.T) {
+	tests := []struct {
+		name         string
+		restore      *velerov1api.Restore
+		backup       *velerov1api.Backup
+		apiResources []*test.APIResource
+		contents     BackupContents
+		want         map[*test.APIResource][]string
+	}{
+		{
+			name:    "no filters restores everything",
+			restore: defaultRestore().Result(),
+			backup:  defaultBackup().Result(),
+			contents: newIndexedTarWriter(t).
+				addItems("pods",
+					builder.ForPod("ns-1", "pod-1").Result(),
+					builder.ForPod("ns-2", "pod-2").Result(),
+				).
+				addItems("persistentvolumes",
+					builder.ForPersistentVolume("pv-1").Result(),
+				).
+				doneIndexed(),
+			apiResources: []*test.APIResource{
+				test.Pods(),
+				test.PVs(),
+			},
+			want: map[*test.APIResource][]string{
+				test.Pods(): {"ns-1/pod-1", "ns-2/pod-2"},
+				test.PVs():  {"/pv-1"},
+			},
+		},
+		{
+			name:    "included namespaces filter only restores items in those namespaces",
+			restore: defaultRestore().IncludedNamespaces("ns-1").Result(),
+			backup:  defaultBackup().Result(),
+			contents: newIndexedTarWriter(t).
+				addItems("pods",
+					builder.ForPod("ns-1", "pod-1").Result(),
+					builder.ForPod("ns-2", "pod-2").Result(),
+				).
+				addItems("deployments.apps",
+					builder.ForDeployment("ns-1", "deploy-1").Result(),
+					builder.ForDeployment("ns-2", "deploy-2").Result(),
+				).
+				doneIndexed(),
+			apiResources: []*test.APIResource{
+				test.Pods(),
+				test.Deployments(),
+			},
+			want: map[*test.APIResource][]string{
+				test.Pods():        {"ns-1/pod-1"},
+				test.Deployments(): {"ns-1/deploy-1"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t)
+
+			for _, r := range tc.apiResources {
+				h.DiscoveryClient.WithAPIResource(r)
+			}
+			require.NoError(t, h.restorer.discoveryHelper.Refresh())
+
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
+				h.log,
+				tc.restore,
+				tc.backup,
+				nil, // volume snapshots
+				tc.contents,
 				nil, // actions
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -566,12 +654,13 @@ func TestRestoreNamespaceMapping(t *testing.T) {
 			}
 			require.NoError(t, h.restorer.discoveryHelper.Refresh())
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				nil, // actions
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -644,12 +733,13 @@ func TestRestoreResourcePriorities(t *testing.T) {
 		}
 		require.NoError(t, h.restorer.discoveryHelper.Refresh())
 
-		warnings, errs := h.restorer.Restore(
+		warnings, errs, _ := h.restorer.Restore(
+			go_context.Background(),
 			h.log,
 			tc.restore,
 			tc.backup,
 			nil, // volume snapshots
-			tc.tarball,
+			BackupContents{Reader: tc.tarball},
 			nil, // actions
 			nil, // snapshot location lister
 			nil, // volume snapshotter getter
@@ -717,12 +807,13 @@ func TestInvalidTarballContents(t *testing.T) {
 			}
 			require.NoError(t, h.restorer.discoveryHelper.Refresh())
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				nil, // actions
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -928,12 +1019,13 @@ func TestRestoreItems(t *testing.T) {
 				h.addItems(t, r)
 			}
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				nil, // actions
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -1118,12 +1210,13 @@ func TestRestoreActionsRunForCorrectItems(t *testing.T) {
 				actions = append(actions, action)
 			}
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				actions,
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -1253,12 +1346,13 @@ func TestRestoreActionModifications(t *testing.T) {
 				}
 			}
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				tc.actions,
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -1416,12 +1510,13 @@ func TestRestoreActionAdditionalItems(t *testing.T) {
 				h.addItems(t, r)
 			}
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				nil, // volume snapshots
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				tc.actions,
 				nil, // snapshot location lister
 				nil, // volume snapshotter getter
@@ -2025,12 +2120,13 @@ func TestRestorePersistentVolumes(t *testing.T) {
 				}
 			}
 
-			warnings, errs := h.restorer.Restore(
+			warnings, errs, _ := h.restorer.Restore(
+				go_context.Background(),
 				h.log,
 				tc.restore,
 				tc.backup,
 				tc.volumeSnapshots,
-				tc.tarball,
+				BackupContents{Reader: tc.tarball},
 				nil, // actions
 				vslInformer.Lister(),
 				tc.volumeSnapshotterGetter,
@@ -2342,7 +2438,13 @@ type tarWriter struct {
 	t   *testing.T
 	buf *bytes.Buffer
 	gzw *gzip.Writer
-	tw  *tar.Writer
+	tw  interface {
+		WriteHeader(*tar.Header) error
+		Write([]byte) (int, error)
+		Close() error
+	}
+	// aw is set for tarballs written with an index.
+	aw *archive.Writer
 }
 
 func newTarWriter(t *testing.T) *tarWriter {
@@ -2355,6 +2457,20 @@ func newTarWriter(t *testing.T) *tarWriter {
 	return tw
 }
 
+// newIndexedTarWriter returns a tarWriter that writes its tarball the way backups
+// do, with an index of where each file is in it.
+func newIndexedTarWriter(t *testing.T) *tarWriter {
+	tw := new(tarWriter)
+	tw.t = t
+	tw.buf = new(bytes.Buffer)
+	aw, err := archive.NewWriter(tw.buf, archive.CodecGzip, 0)
+	require.NoError(t, err)
+	tw.aw = aw
+	tw.tw = aw
+
+	return tw
+}
+
 func (tw *tarWriter) addItems(groupResource string, items ...metav1.Object) *tarWriter {
 	tw.t.Helper()
 
@@ -2405,11 +2521,24 @@ func (tw *tarWriter) add(name string, obj interface{}) *tarWriter {
 
 func (tw *tarWriter) done() *bytes.Buffer {
 	require.NoError(tw.t, tw.tw.Close())
-	require.NoError(tw.t, tw.gzw.Close())
+	if tw.gzw != nil {
+		require.NoError(tw.t, tw.gzw.Close())
+	}
 
 	return tw.buf
 }
 
+// doneIndexed finishes an indexed tarball and returns its contents for restoring
+// from its index.
+func (tw *tarWriter) doneIndexed() BackupContents {
+	buf := tw.done()
+
+	return BackupContents{
+		Index:  tw.aw.Index(),
+		Source: bytes.NewReader(buf.Bytes()),
+	}
+}
+
 type harness struct {
 	*test.APIServer
 
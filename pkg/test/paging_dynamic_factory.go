@@ -0,0 +1,141 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/velero/pkg/client"
+)
+
+// PagingDynamicFactory wraps a client.DynamicFactory so that the clients it
+// returns honor the Limit and Continue list options the way a real API server
+// does, which the dynamic client fake doesn't. It records the options of every
+// List call made through its clients.
+type PagingDynamicFactory struct {
+	client.DynamicFactory
+
+	// ExpireAfterPages, if greater than zero, is the number of pages each
+	// client serves before rejecting continue tokens as expired.
+	ExpireAfterPages int
+
+	lock      sync.Mutex
+	listCalls []metav1.ListOptions
+}
+
+// NewPagingDynamicFactory returns a PagingDynamicFactory wrapping factory.
+func NewPagingDynamicFactory(factory client.DynamicFactory) *PagingDynamicFactory {
+	return &PagingDynamicFactory{DynamicFactory: factory}
+}
+
+func (f *PagingDynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, resource metav1.APIResource, namespace string) (client.Dynamic, error) {
+	c, err := f.DynamicFactory.ClientForGroupVersionResource(gv, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pagingDynamicClient{Dynamic: c, factory: f}, nil
+}
+
+// ListCalls returns the options of every List call made so far, in order.
+func (f *PagingDynamicFactory) ListCalls() []metav1.ListOptions {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]metav1.ListOptions(nil), f.listCalls...)
+}
+
+func (f *PagingDynamicFactory) recordList(opts metav1.ListOptions) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.listCalls = append(f.listCalls, opts)
+}
+
+type pagingDynamicClient struct {
+	client.Dynamic
+
+	factory     *PagingDynamicFactory
+	pagesServed int
+}
+
+// List returns the page of items described by opts. Continue tokens are the
+// offset of the page's first item in the full, sorted list.
+func (c *pagingDynamicClient) List(opts metav1.ListOptions) (runtime.Object, error) {
+	c.factory.recordList(opts)
+
+	if opts.Continue != "" && c.factory.ExpireAfterPages > 0 && c.pagesServed >= c.factory.ExpireAfterPages {
+		return nil, apierrors.NewResourceExpired("continue token expired")
+	}
+
+	fullOpts := opts
+	fullOpts.Limit = 0
+	fullOpts.Continue = ""
+
+	res, err := c.Dynamic.List(fullOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := res.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, errors.Errorf("unexpected list type %T", res)
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].GetNamespace() != list.Items[j].GetNamespace() {
+			return list.Items[i].GetNamespace() < list.Items[j].GetNamespace()
+		}
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	if opts.Limit <= 0 {
+		return list, nil
+	}
+
+	start := 0
+	if opts.Continue != "" {
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, apierrors.NewBadRequest("invalid continue token")
+		}
+	}
+	if start > len(list.Items) {
+		start = len(list.Items)
+	}
+
+	end := start + int(opts.Limit)
+	if end > len(list.Items) {
+		end = len(list.Items)
+	}
+
+	if end < len(list.Items) {
+		list.SetContinue(strconv.Itoa(end))
+	}
+	list.Items = list.Items[start:end]
+	c.pagesServed++
+
+	return list, nil
+}
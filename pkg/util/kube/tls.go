@@ -0,0 +1,46 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"crypto/tls"
+
+	"github.com/pkg/errors"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// TLSConfigFromSecret returns a TLS configuration for a server that uses the
+// certificate and private key in the named kubernetes.io/tls Secret, i.e. in its
+// tls.crt and tls.key entries.
+func TLSConfigFromSecret(secretsGetter corev1client.SecretsGetter, namespace, name string) (*tls.Config, error) {
+	secret, err := secretsGetter.Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting TLS secret %s/%s", namespace, name)
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1api.TLSCertKey], secret.Data[corev1api.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading certificate and key from secret %s/%s", namespace, name)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
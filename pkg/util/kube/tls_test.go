@@ -0,0 +1,88 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestCertificate returns a PEM-encoded self-signed certificate and its key.
+func newTestCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "velero.velero.svc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"velero.velero.svc"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLSConfigFromSecret(t *testing.T) {
+	cert, key := newTestCertificate(t)
+
+	client := fake.NewSimpleClientset(
+		&corev1api.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "velero", Name: "tls"},
+			Type:       corev1api.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1api.TLSCertKey:       cert,
+				corev1api.TLSPrivateKeyKey: key,
+			},
+		},
+		&corev1api.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "velero", Name: "no-key"},
+			Data: map[string][]byte{
+				corev1api.TLSCertKey: cert,
+			},
+		},
+	)
+
+	config, err := TLSConfigFromSecret(client.CoreV1(), "velero", "tls")
+	require.NoError(t, err)
+	require.Len(t, config.Certificates, 1)
+
+	_, err = TLSConfigFromSecret(client.CoreV1(), "velero", "no-key")
+	assert.Error(t, err)
+
+	_, err = TLSConfigFromSecret(client.CoreV1(), "velero", "missing")
+	assert.Error(t, err)
+}
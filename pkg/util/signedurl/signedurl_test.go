@@ -0,0 +1,60 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signedurl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+
+	now := time.Now()
+	query := url.Values{}
+	Sign(key, query, now.Add(time.Minute), "bucket-1", "backups/backup-1/backup-1.tar.gz")
+
+	assert.NoError(t, Verify(key, query, now, "bucket-1", "backups/backup-1/backup-1.tar.gz"))
+
+	// the signature only covers the given fields
+	assert.Equal(t, ErrInvalidSignature, Verify(key, query, now, "bucket-1", "backups/backup-2/backup-2.tar.gz"))
+	assert.Equal(t, ErrInvalidSignature, Verify(key, query, now, "bucket-1"))
+
+	// a different key invalidates it
+	otherKey, err := NewKey()
+	require.NoError(t, err)
+	assert.Equal(t, ErrInvalidSignature, Verify(otherKey, query, now, "bucket-1", "backups/backup-1/backup-1.tar.gz"))
+
+	// as does changing the expiry time
+	extended := url.Values{}
+	for k, v := range query {
+		extended[k] = v
+	}
+	extended.Set(expiresParam, "9999999999")
+	assert.Equal(t, ErrInvalidSignature, Verify(key, extended, now, "bucket-1", "backups/backup-1/backup-1.tar.gz"))
+
+	// and it expires
+	assert.Equal(t, ErrExpired, Verify(key, query, now.Add(2*time.Minute), "bucket-1", "backups/backup-1/backup-1.tar.gz"))
+
+	// unsigned URLs are invalid
+	assert.Equal(t, ErrInvalidSignature, Verify(key, url.Values{}, now, "bucket-1", "backups/backup-1/backup-1.tar.gz"))
+}
@@ -77,6 +77,10 @@ func (dh *FakeDiscoveryHelper) Resources() []*metav1.APIResourceList {
 	return dh.ResourceList
 }
 
+func (dh *FakeDiscoveryHelper) ServedResources() []*metav1.APIResourceList {
+	return dh.ResourceList
+}
+
 func (dh *FakeDiscoveryHelper) Refresh() error {
 	return nil
 }
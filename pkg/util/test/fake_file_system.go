@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -69,6 +69,10 @@ func (fs *FakeFileSystem) Stat(path string) (os.FileInfo, error) {
 	return fs.fs.Stat(path)
 }
 
+func (fs *FakeFileSystem) Rename(oldpath, newpath string) error {
+	return fs.fs.Rename(oldpath, newpath)
+}
+
 func (fs *FakeFileSystem) WithFile(path string, data []byte) *FakeFileSystem {
 	file, _ := fs.fs.Create(path)
 	file.Write(data)